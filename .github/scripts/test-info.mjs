@@ -452,6 +452,10 @@ const components = {
         conformanceSetup: 'docker-compose.sh solace',
         conformanceLogs: 'docker-compose-logs.sh solace',
     },
+    'pubsub.sqlite': {
+        conformance: true,
+        sourcePkg: ['pubsub/sqlite', 'common/component/sql'],
+    },
     'secretstores.azure.keyvault': {
         certification: true,
         requiredSecrets: [
@@ -18,6 +18,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Interface that contains methods for querying.
@@ -35,6 +36,7 @@ type PGXPoolConn interface {
 
 	Begin(context.Context) (pgx.Tx, error)
 	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Acquire(context.Context) (*pgxpool.Conn, error)
 	Ping(context.Context) error
 	Close()
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/dapr/components-contrib/common/authentication/aws"
 	pgauth "github.com/dapr/components-contrib/common/authentication/postgresql"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/codec"
 	"github.com/dapr/kit/metadata"
 	"github.com/dapr/kit/ptr"
 )
@@ -38,8 +39,12 @@ type pgMetadata struct {
 	MetadataTableName string         `mapstructure:"metadataTableName"` // Could be in the format "schema.table" or just "table"
 	Timeout           time.Duration  `mapstructure:"timeout" mapstructurealiases:"timeoutInSeconds"`
 	CleanupInterval   *time.Duration `mapstructure:"cleanupInterval" mapstructurealiases:"cleanupIntervalInSeconds"`
+	HistoryLimit      int            `mapstructure:"historyLimit"`
+	HistoryRetention  time.Duration  `mapstructure:"historyRetention"`
 
 	aws.DeprecatedPostgresIAM `mapstructure:",squash"`
+
+	codec.Metadata `mapstructure:",squash"`
 }
 
 func (m *pgMetadata) InitWithMetadata(meta state.Metadata, opts pgauth.InitWithMetadataOpts) error {
@@ -49,6 +54,8 @@ func (m *pgMetadata) InitWithMetadata(meta state.Metadata, opts pgauth.InitWithM
 	m.MetadataTableName = defaultMetadataTableName
 	m.CleanupInterval = ptr.Of(defaultCleanupInternal)
 	m.Timeout = defaultTimeout
+	m.HistoryLimit = 0
+	m.HistoryRetention = 0
 
 	// Decode the metadata
 	err := metadata.DecodeMetadata(meta.Properties, &m)
@@ -67,6 +74,11 @@ func (m *pgMetadata) InitWithMetadata(meta state.Metadata, opts pgauth.InitWithM
 		return errors.New("invalid value for 'timeout': must be greater than 1s")
 	}
 
+	// Versioning
+	if m.HistoryLimit < 0 || m.HistoryRetention < 0 {
+		return errors.New("invalid value for 'historyLimit' or 'historyRetention': must not be negative")
+	}
+
 	// Cleanup interval
 	// Non-positive value from meta means disable auto cleanup.
 	// We need to do this check because an empty string and "0" are treated differently by DecodeMetadata
@@ -255,4 +255,31 @@ func TestMetadata(t *testing.T) {
 		require.NotNil(t, m.CleanupInterval)
 		assert.Equal(t, defaultCleanupInternal, *m.CleanupInterval)
 	})
+
+	t.Run("history settings", func(t *testing.T) {
+		m := pgMetadata{}
+		props := map[string]string{
+			"connectionString": "foo=bar",
+			"historyLimit":     "10",
+			"historyRetention": "24h",
+		}
+
+		opts := postgresql.InitWithMetadataOpts{}
+		err := m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 10, m.HistoryLimit)
+		assert.Equal(t, 24*time.Hour, m.HistoryRetention)
+	})
+
+	t.Run("negative historyLimit", func(t *testing.T) {
+		m := pgMetadata{}
+		props := map[string]string{
+			"connectionString": "foo=bar",
+			"historyLimit":     "-1",
+		}
+
+		opts := postgresql.InitWithMetadataOpts{}
+		err := m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}}, opts)
+		require.ErrorContains(t, err, "historyLimit")
+	})
 }
@@ -22,6 +22,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -36,6 +38,7 @@ import (
 	commonsql "github.com/dapr/components-contrib/common/component/sql"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/codec"
 	stateutils "github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
@@ -56,8 +59,19 @@ type PostgreSQL struct {
 	etagColumn    string
 	enableAzureAD bool
 	enableAWSIAM  bool
+	enableWatch   bool
+	enableAtomic  bool
+	enableHistory bool
+	enableRange   bool
+	enableOutbox  bool
+	watchChannel  string
+	codec         *codec.Codec
 
 	awsAuthProvider awsAuth.Provider
+
+	closed  atomic.Bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
 }
 
 type Options struct {
@@ -66,12 +80,30 @@ type Options struct {
 	ETagColumn    string
 	EnableAzureAD bool
 	EnableAWSIAM  bool
+	// EnableWatch is set when the migrations create the trigger that notifies the changes to the state table, which is required by Watch.
+	EnableWatch bool
+	// EnableAtomicOperations enables the AtomicOperator interface, which requires the ETag column to be updated automatically.
+	EnableAtomicOperations bool
+	// EnableVersioning is set when the migrations create the history table and the trigger that populates it, which are required by the VersionedStore interface.
+	EnableVersioning bool
+	// EnableRangeScan enables the RangeScanner interface, which compares keys with the C collation so they are sorted byte-wise.
+	// The migrations should create an index on the keys with the C collation.
+	EnableRangeScan bool
+	// EnableOutbox enables OutboxRequest operations in transactions and the OutboxStore interface.
+	// The migrations should create the outbox table.
+	EnableOutbox bool
 }
 
 type MigrateOptions struct {
 	Logger            logger.Logger
 	StateTableName    string
 	MetadataTableName string
+	// Name of the channel notified of the changes to the state table, when watching is enabled
+	WatchChannel string
+	// Name of the table that contains the revisions of the keys, when versioning is enabled
+	HistoryTableName string
+	// Name of the table that contains the messages saved by transactions, when the outbox is enabled
+	OutboxTableName string
 }
 
 type SetQueryOptions struct {
@@ -88,6 +120,12 @@ func NewPostgreSQLStateStore(logger logger.Logger, opts Options) state.Store {
 		etagColumn:    opts.ETagColumn,
 		enableAzureAD: opts.EnableAzureAD,
 		enableAWSIAM:  opts.EnableAWSIAM,
+		enableWatch:   opts.EnableWatch,
+		enableAtomic:  opts.EnableAtomicOperations,
+		enableHistory: opts.EnableVersioning,
+		enableRange:   opts.EnableRangeScan,
+		enableOutbox:  opts.EnableOutbox,
+		closeCh:       make(chan struct{}),
 	}
 	s.BulkStore = state.NewDefaultBulkStore(s)
 	return s
@@ -103,8 +141,16 @@ func (p *PostgreSQL) Init(ctx context.Context, meta state.Metadata) error {
 	if err := p.metadata.InitWithMetadata(meta, opts); err != nil {
 		return fmt.Errorf("failed to parse metadata: %w", err)
 	}
+	if !p.enableHistory && (p.metadata.HistoryLimit > 0 || p.metadata.HistoryRetention > 0) {
+		return errors.New("versioning is not supported by this state store")
+	}
 
 	var err error
+	p.codec, err = codec.New(p.metadata.Metadata)
+	if err != nil {
+		return err
+	}
+
 	config, err := p.metadata.GetPgxPoolConfig()
 	if err != nil {
 		return err
@@ -139,15 +185,38 @@ func (p *PostgreSQL) Init(ctx context.Context, meta state.Metadata) error {
 		return fmt.Errorf("failed to ping the database: %w", err)
 	}
 
+	if p.enableWatch {
+		p.watchChannel = watchChannelName(p.metadata.TableName)
+	}
+
+	var historyTableName string
+	if p.enableHistory {
+		historyTableName = p.historyTableName()
+	}
+	var outboxTableName string
+	if p.enableOutbox {
+		outboxTableName = p.outboxTableName()
+	}
+
 	err = p.migrateFn(ctx, p.db, MigrateOptions{
 		Logger:            p.logger,
 		StateTableName:    p.metadata.TableName,
 		MetadataTableName: p.metadata.MetadataTableName,
+		WatchChannel:      p.watchChannel,
+		HistoryTableName:  historyTableName,
+		OutboxTableName:   outboxTableName,
 	})
 	if err != nil {
 		return err
 	}
 
+	if p.enableHistory {
+		err = p.initHistory(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	if p.metadata.CleanupInterval != nil {
 		gc, err := commonsql.ScheduleGarbageCollector(commonsql.GCOptions{
 			Logger: p.logger,
@@ -179,12 +248,30 @@ func (p *PostgreSQL) Init(ctx context.Context, meta state.Metadata) error {
 
 // Features returns the features available in this state store.
 func (p *PostgreSQL) Features() []state.Feature {
-	return []state.Feature{
+	features := []state.Feature{
 		state.FeatureETag,
 		state.FeatureTransactional,
 		state.FeatureTTL,
 		state.FeatureKeysLike,
+		state.FeatureDeleteWithPrefix,
+		state.FeatureTTLManagement,
+	}
+	if p.enableWatch {
+		features = append(features, state.FeatureWatch)
+	}
+	if p.enableAtomic {
+		features = append(features, state.FeatureAtomicOperations)
+	}
+	if p.versioningEnabled() {
+		features = append(features, state.FeatureVersioning)
+	}
+	if p.enableRange {
+		features = append(features, state.FeatureRangeScan)
 	}
+	if p.enableOutbox {
+		features = append(features, state.FeatureOutbox)
+	}
+	return features
 }
 
 func (p *PostgreSQL) GetDB() *pgxpool.Pool {
@@ -207,16 +294,11 @@ func (p *PostgreSQL) doSet(parentCtx context.Context, db pginterfaces.DBQuerier,
 		return errors.New("missing key in set operation")
 	}
 
-	v := req.Value
-	byteArray, isBinary := req.Value.([]uint8)
-	if isBinary {
-		v = base64.StdEncoding.EncodeToString(byteArray)
+	value, isBinary, err := p.storedValue(req.Value, req.ContentType)
+	if err != nil {
+		return err
 	}
 
-	// Convert to json string
-	bt, _ := stateutils.Marshal(v, json.Marshal)
-	value := string(bt)
-
 	// TTL
 	var ttlSeconds int
 	ttl, ttlerr := stateutils.ParseTTL(req.Metadata)
@@ -268,22 +350,37 @@ func (p *PostgreSQL) doSet(parentCtx context.Context, db pginterfaces.DBQuerier,
 	return nil
 }
 
+// storedValue returns the value as stored in the state table, after encoding it with the codec if the store has one.
+func (p *PostgreSQL) storedValue(v any, contentType *string) (value string, isBinary bool, err error) {
+	if p.codec != nil {
+		v, err = p.codec.Encode(v, contentType)
+		if err != nil {
+			return "", false, err
+		}
+	}
+	value, isBinary = encodeValue(v)
+	return value, isBinary, nil
+}
+
+// encodeValue returns the value as stored in the state table: byte slices are encoded with base64, and all values are serialized as JSON.
+func encodeValue(v any) (value string, isBinary bool) {
+	byteArray, isBinary := v.([]uint8)
+	if isBinary {
+		v = base64.StdEncoding.EncodeToString(byteArray)
+	}
+
+	// Convert to json string
+	bt, _ := stateutils.Marshal(v, json.Marshal)
+	return string(bt), isBinary
+}
+
 // Get returns data from the database. If data does not exist for the key an empty state.GetResponse will be returned.
 func (p *PostgreSQL) Get(parentCtx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	if req.Key == "" {
 		return nil, errors.New("missing key in get operation")
 	}
 
-	query := `SELECT
-			key, value, isbinary, ` + p.etagColumn + ` AS etag, expiredate
-		FROM ` + p.metadata.TableName + `
-			WHERE
-				key = $1
-				AND (expiredate IS NULL OR expiredate >= CURRENT_TIMESTAMP)`
-	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
-	defer cancel()
-	row := p.db.QueryRow(ctx, query, req.Key)
-	_, value, etag, expireTime, err := readRow(row)
+	value, etag, expireTime, contentType, err := p.getRow(parentCtx, req.Key)
 	if err != nil {
 		// If no rows exist, return an empty response, otherwise return the error.
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -293,8 +390,9 @@ func (p *PostgreSQL) Get(parentCtx context.Context, req *state.GetRequest) (*sta
 	}
 
 	resp := &state.GetResponse{
-		Data: value,
-		ETag: etag,
+		Data:        value,
+		ETag:        etag,
+		ContentType: contentType,
 	}
 
 	if expireTime != nil {
@@ -306,6 +404,21 @@ func (p *PostgreSQL) Get(parentCtx context.Context, req *state.GetRequest) (*sta
 	return resp, nil
 }
 
+// getRow returns the value of a key that is not expired, or pgx.ErrNoRows if the key doesn't exist.
+func (p *PostgreSQL) getRow(parentCtx context.Context, key string) (value []byte, etag *string, expireTime *time.Time, contentType *string, err error) {
+	query := `SELECT
+			key, value, isbinary, ` + p.etagColumn + ` AS etag, expiredate
+		FROM ` + p.metadata.TableName + `
+			WHERE
+				key = $1
+				AND (expiredate IS NULL OR expiredate >= CURRENT_TIMESTAMP)`
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	row := p.db.QueryRow(ctx, query, key)
+	_, value, etag, expireTime, contentType, err = readRow(row, p.codec != nil)
+	return value, etag, expireTime, contentType, err
+}
+
 func (p *PostgreSQL) BulkGet(parentCtx context.Context, req []state.GetRequest, _ state.BulkGetOpts) ([]state.BulkGetResponse, error) {
 	if len(req) == 0 {
 		return []state.BulkGetResponse{}, nil
@@ -347,7 +460,7 @@ func (p *PostgreSQL) BulkGet(parentCtx context.Context, req []state.GetRequest,
 
 		r := state.BulkGetResponse{}
 		var expireTime *time.Time
-		r.Key, r.Data, r.ETag, expireTime, err = readRow(rows)
+		r.Key, r.Data, r.ETag, expireTime, r.ContentType, err = readRow(rows, p.codec != nil)
 		if err != nil {
 			r.Error = err.Error()
 		}
@@ -382,7 +495,9 @@ func (p *PostgreSQL) BulkGet(parentCtx context.Context, req []state.GetRequest,
 	return res[:n], nil
 }
 
-func readRow(row pgx.Row) (key string, value []byte, etagS *string, expireTime *time.Time, err error) {
+// readRow reads a row with the key, value, isbinary, etag and expiredate columns.
+// Binary values are decoded with the codec only if decode is true.
+func readRow(row pgx.Row, decode bool) (key string, value []byte, etagS *string, expireTime *time.Time, contentType *string, err error) {
 	var (
 		isBinary bool
 		etag     pgtype.Int8
@@ -390,7 +505,7 @@ func readRow(row pgx.Row) (key string, value []byte, etagS *string, expireTime *
 	)
 	err = row.Scan(&key, &value, &isBinary, &etag, &expT)
 	if err != nil {
-		return key, nil, nil, nil, err
+		return key, nil, nil, nil, nil, err
 	}
 
 	if etag.Valid {
@@ -402,25 +517,33 @@ func readRow(row pgx.Row) (key string, value []byte, etagS *string, expireTime *
 	}
 
 	if isBinary {
-		var (
-			s    string
-			data []byte
-		)
-
-		err = json.Unmarshal(value, &s)
+		value, contentType, err = decodeBinaryValue(value, decode)
 		if err != nil {
-			return key, nil, nil, nil, fmt.Errorf("failed to unmarshal JSON data: %w", err)
+			return key, nil, nil, nil, nil, err
 		}
+	}
 
-		data, err = base64.StdEncoding.DecodeString(s)
-		if err != nil {
-			return key, nil, nil, nil, fmt.Errorf("failed to decode base64 data: %w", err)
-		}
+	return key, value, etagS, expireTime, contentType, nil
+}
+
+// decodeBinaryValue decodes a value stored with base64, and returns the original value and content type if it has been encoded by a codec.
+// Values are decoded with the codec only if decode is true, which is the case when the store has a codec, so plain values are never altered.
+func decodeBinaryValue(value []byte, decode bool) ([]byte, *string, error) {
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal JSON data: %w", err)
+	}
 
-		return key, data, etagS, expireTime, nil
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode base64 data: %w", err)
 	}
 
-	return key, value, etagS, expireTime, nil
+	if !decode {
+		return data, nil, nil
+	}
+	return codec.Decode(data)
 }
 
 // Delete removes an item from the state store.
@@ -460,6 +583,27 @@ func (p *PostgreSQL) doDelete(parentCtx context.Context, db pginterfaces.DBQueri
 	return nil
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the cleanup of expired keys.
+func (p *PostgreSQL) DeleteWithPrefix(parentCtx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	match, exclude := stateutils.DeleteWithPrefixPatterns(req.Prefix)
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	result, err := p.db.Exec(ctx, `DELETE FROM `+p.metadata.TableName+`
+		WHERE key LIKE $1 ESCAPE '\'
+			AND key NOT LIKE $2 ESCAPE '\'
+			AND (expiredate IS NULL OR expiredate > CURRENT_TIMESTAMP)`,
+		match, exclude)
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: result.RowsAffected()}, nil
+}
+
 func (p *PostgreSQL) Multi(parentCtx context.Context, request *state.TransactionalStateRequest) error {
 	if request == nil {
 		return nil
@@ -492,6 +636,8 @@ func (p *PostgreSQL) execMultiOperation(ctx context.Context, op state.Transactio
 		return p.doSet(ctx, db, &x)
 	case state.DeleteRequest:
 		return p.doDelete(ctx, db, &x)
+	case state.OutboxRequest:
+		return p.doSaveOutbox(ctx, db, &x)
 	default:
 		return fmt.Errorf("unsupported operation: %s", op.Operation())
 	}
@@ -506,6 +652,12 @@ func (p *PostgreSQL) CleanupExpired() error {
 
 // Close implements io.Close.
 func (p *PostgreSQL) Close() error {
+	// Stop the watchers before closing the pool
+	if p.closed.CompareAndSwap(false, true) && p.closeCh != nil {
+		close(p.closeCh)
+	}
+	p.wg.Wait()
+
 	if p.db != nil {
 		p.db.Close()
 		p.db = nil
@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dapr/components-contrib/state"
+	stateutils "github.com/dapr/components-contrib/state/utils"
+	"github.com/dapr/kit/ptr"
+)
+
+// Condition for rows that have expired but haven't been garbage collected yet, which are treated as if they didn't exist.
+const expiredCondition = "(t.expiredate IS NOT NULL AND t.expiredate < CURRENT_TIMESTAMP)"
+
+// Increment adds the delta to the integer value of the key with a single upsert, which fails to update values that aren't integers.
+func (p *PostgreSQL) Increment(parentCtx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	if err := p.checkAtomicEnabled(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO ` + p.metadata.TableName + ` AS t
+			(key, value, isbinary, expiredate)
+		VALUES ($1, to_jsonb($2::bigint), false, ` + insertExp + `)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN ` + expiredCondition + ` THEN excluded.value ELSE to_jsonb((t.value #>> '{}')::bigint + $2::bigint) END,
+			updatedate = CURRENT_TIMESTAMP,
+			expiredate = ` + updateExp + `
+		WHERE ` + expiredCondition + `
+			OR (NOT t.isbinary AND jsonb_typeof(t.value) = 'number' AND (t.value #>> '{}') ~ '^-?[0-9]+$')
+		RETURNING (t.value #>> '{}')::bigint, t.` + p.etagColumn
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	var (
+		res  state.IncrementResponse
+		etag pgtype.Int8
+	)
+	err = p.db.QueryRow(ctx, query, req.Key, req.Delta).Scan(&res.Value, &etag)
+	if err != nil {
+		return nil, atomicError(err)
+	}
+	res.ETag = formatETag(etag)
+	return &res, nil
+}
+
+// Append adds the values at the end of the JSON array stored in the key with a single upsert, which fails to update values that aren't arrays.
+func (p *PostgreSQL) Append(parentCtx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	if err := p.checkAtomicEnabled(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	values, err := json.Marshal(req.Values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize values: %w", err)
+	}
+
+	query := `INSERT INTO ` + p.metadata.TableName + ` AS t
+			(key, value, isbinary, expiredate)
+		VALUES ($1, $2::jsonb, false, ` + insertExp + `)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN ` + expiredCondition + ` THEN excluded.value ELSE t.value || excluded.value END,
+			updatedate = CURRENT_TIMESTAMP,
+			expiredate = ` + updateExp + `
+		WHERE ` + expiredCondition + `
+			OR (NOT t.isbinary AND jsonb_typeof(t.value) = 'array')
+		RETURNING jsonb_array_length(t.value), t.` + p.etagColumn
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	var (
+		res  state.AppendResponse
+		etag pgtype.Int8
+	)
+	err = p.db.QueryRow(ctx, query, req.Key, string(values)).Scan(&res.Length, &etag)
+	if err != nil {
+		return nil, atomicError(err)
+	}
+	res.ETag = formatETag(etag)
+	return &res, nil
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one.
+// Values are compared as JSON documents, so the formatting of the expected value doesn't matter.
+func (p *PostgreSQL) CompareAndSwap(parentCtx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	if err := p.checkAtomicEnabled(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	value, isBinary, err := p.storedValue(req.Value, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		query  string
+		params []any
+	)
+	if req.Expected == nil {
+		// Insert the key, replacing it only if it has expired
+		query = `INSERT INTO ` + p.metadata.TableName + ` AS t
+				(key, value, isbinary, expiredate)
+			VALUES ($1, $2, $3, ` + insertExp + `)
+			ON CONFLICT (key) DO UPDATE SET
+				value = excluded.value,
+				isbinary = excluded.isbinary,
+				updatedate = CURRENT_TIMESTAMP,
+				expiredate = excluded.expiredate
+			WHERE ` + expiredCondition + `
+			RETURNING t.` + p.etagColumn
+		params = []any{req.Key, value, isBinary}
+	} else {
+		expected, expectedIsBinary, err := p.storedValue(req.Expected, nil)
+		if err != nil {
+			return nil, err
+		}
+		query = `UPDATE ` + p.metadata.TableName + ` AS t SET
+				value = $2,
+				isbinary = $3,
+				updatedate = CURRENT_TIMESTAMP,
+				expiredate = ` + updateExp + `
+			WHERE t.key = $1
+				AND t.value = $4::jsonb
+				AND t.isbinary = $5
+				AND NOT ` + expiredCondition + `
+			RETURNING t.` + p.etagColumn
+		params = []any{req.Key, value, isBinary, expected, expectedIsBinary}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	var etag pgtype.Int8
+	err = p.db.QueryRow(ctx, query, params...).Scan(&etag)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// The value didn't match
+		return &state.CompareAndSwapResponse{}, nil
+	case err != nil:
+		return nil, err
+	}
+	return &state.CompareAndSwapResponse{
+		Swapped: true,
+		ETag:    formatETag(etag),
+	}, nil
+}
+
+func (p *PostgreSQL) checkAtomicEnabled() error {
+	if !p.enableAtomic {
+		return errors.New("atomic operations are not supported by this state store")
+	}
+	return nil
+}
+
+// atomicExpiration returns the expressions for the expiration time of inserted and updated rows.
+// Unless a TTL is set, updated rows keep their expiration time, except for expired rows that are replaced.
+func atomicExpiration(metadata map[string]string) (insertExp string, updateExp string, err error) {
+	ttl, err := stateutils.ParseTTL(metadata)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing TTL: %w", err)
+	}
+	switch {
+	case ttl == nil:
+		return "NULL", "CASE WHEN " + expiredCondition + " THEN NULL ELSE t.expiredate END", nil
+	case *ttl > 0:
+		exp := "CURRENT_TIMESTAMP + interval '" + strconv.Itoa(*ttl) + " seconds'"
+		return exp, exp, nil
+	default:
+		return "NULL", "NULL", nil
+	}
+}
+
+// atomicError returns ErrAtomicOperandType when no row was returned because the current value has the wrong type.
+func atomicError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return state.ErrAtomicOperandType
+	}
+	return err
+}
+
+func formatETag(etag pgtype.Int8) *string {
+	if !etag.Valid {
+		return nil
+	}
+	return ptr.Of(strconv.FormatInt(etag.Int64, 10))
+}
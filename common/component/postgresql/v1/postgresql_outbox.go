@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	pginterfaces "github.com/dapr/components-contrib/common/component/postgresql/interfaces"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+var errOutboxDisabled = errors.New("the outbox is not supported by this state store")
+
+// outboxTableName returns the name of the table that contains the messages saved by transactions.
+func (p *PostgreSQL) outboxTableName() string {
+	return p.metadata.TableName + "_outbox"
+}
+
+// doSaveOutbox saves a message in the outbox, unless a message with the same ID is already there.
+func (p *PostgreSQL) doSaveOutbox(parentCtx context.Context, db pginterfaces.DBQuerier, req *state.OutboxRequest) error {
+	if !p.enableOutbox {
+		return errOutboxDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var md *string
+	if len(req.Metadata) > 0 {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata of outbox message: %w", err)
+		}
+		md = ptr.Of(string(b))
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	_, err := db.Exec(ctx, `INSERT INTO `+p.outboxTableName()+`
+			(id, pubsub_name, topic, data, content_type, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING`,
+		req.ID, req.PubsubName, req.Topic, req.Data, req.ContentType, md,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
+
+// LeaseOutbox leases the oldest messages of the pubsub whose lease expired, or that were never leased.
+// Messages are leased with SKIP LOCKED, so concurrent relays don't wait for each other.
+func (p *PostgreSQL) LeaseOutbox(parentCtx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error) {
+	if !p.enableOutbox {
+		return nil, errOutboxDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	rows, err := p.db.Query(ctx, `UPDATE `+p.outboxTableName()+`
+		SET attempts = attempts + 1, lease_time = now() + $1::bigint * interval '1 millisecond'
+		WHERE seq IN (
+			SELECT seq FROM `+p.outboxTableName()+`
+			WHERE pubsub_name = $2 AND lease_time <= now()
+			ORDER BY seq
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING seq, id, topic, data, content_type, metadata, attempts`,
+		req.LeaseDuration.Milliseconds(), req.PubsubName, req.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	type leased struct {
+		seq int64
+		msg state.OutboxMessage
+	}
+	var res []leased
+	for rows.Next() {
+		var (
+			r  leased
+			md *string
+		)
+		err = rows.Scan(&r.seq, &r.msg.ID, &r.msg.Topic, &r.msg.Data, &r.msg.ContentType, &md, &r.msg.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+		}
+		r.msg.PubsubName = req.PubsubName
+		if md != nil {
+			err = json.Unmarshal([]byte(*md), &r.msg.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode metadata of outbox message %s: %w", r.msg.ID, err)
+			}
+		}
+		res = append(res, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+	}
+
+	// The order of the rows returned by RETURNING is not defined
+	slices.SortFunc(res, func(x, y leased) int {
+		return cmp.Compare(x.seq, y.seq)
+	})
+	msgs := make([]state.OutboxMessage, len(res))
+	for i := range res {
+		msgs[i] = res[i].msg
+	}
+	return msgs, nil
+}
+
+// DeleteOutbox removes messages that were published from the outbox.
+func (p *PostgreSQL) DeleteOutbox(parentCtx context.Context, ids []string) error {
+	if !p.enableOutbox {
+		return errOutboxDisabled
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	_, err := p.db.Exec(ctx, `DELETE FROM `+p.outboxTableName()+` WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOutbox makes a leased message available again after the delay.
+func (p *PostgreSQL) ReleaseOutbox(parentCtx context.Context, id string, delay time.Duration) error {
+	if !p.enableOutbox {
+		return errOutboxDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	_, err := p.db.Exec(ctx, `UPDATE `+p.outboxTableName()+`
+		SET lease_time = now() + $1::bigint * interval '1 millisecond'
+		WHERE id = $2`,
+		delay.Milliseconds(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message: %w", err)
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package postgresql
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -41,6 +42,12 @@ func NewPostgreSQLQueryStateStore(logger logger.Logger, opts Options) state.Stor
 			setQueryFn:    opts.SetQueryFn,
 			etagColumn:    opts.ETagColumn,
 			enableAzureAD: opts.EnableAzureAD,
+			enableWatch:   opts.EnableWatch,
+			enableAtomic:  opts.EnableAtomicOperations,
+			enableHistory: opts.EnableVersioning,
+			enableRange:   opts.EnableRangeScan,
+			enableOutbox:  opts.EnableOutbox,
+			closeCh:       make(chan struct{}),
 		},
 	}
 	s.BulkStore = state.NewDefaultBulkStore(s)
@@ -49,16 +56,22 @@ func NewPostgreSQLQueryStateStore(logger logger.Logger, opts Options) state.Stor
 
 // Features returns the features available in this component.
 func (p *PostgreSQLQuery) Features() []state.Feature {
-	return append(p.PostgreSQL.Features(), state.FeatureQueryAPI)
+	return append(p.PostgreSQL.Features(), state.FeatureQueryAPI, state.FeatureQueryAggregate)
+}
+
+// QueryAggregate executes a query with projections or aggregations against store.
+func (p *PostgreSQLQuery) QueryAggregate(parentCtx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	return p.Query(parentCtx, req)
 }
 
 // Query executes a query against store.
 func (p *PostgreSQLQuery) Query(parentCtx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
 	q := &Query{
-		query:      "",
-		params:     []any{},
-		tableName:  p.metadata.TableName,
-		etagColumn: p.etagColumn,
+		query:        "",
+		params:       []any{},
+		tableName:    p.metadata.TableName,
+		etagColumn:   p.etagColumn,
+		decodeValues: p.codec != nil,
 	}
 	qbuilder := query.NewQueryBuilder(q)
 	if err := qbuilder.BuildQuery(&req.Query); err != nil {
@@ -82,6 +95,15 @@ type Query struct {
 	skip       *int64
 	tableName  string
 	etagColumn string
+
+	// Set when the store has a codec, to decode the values stored as binary
+	decodeValues bool
+
+	// Set when the query has a projection or aggregations
+	projection string
+	aggregate  string
+	groupBy    []string
+	sortFields map[string]string
 }
 
 func (q *Query) VisitEQ(f *query.EQ) (string, error) {
@@ -199,7 +221,10 @@ func (q *Query) visitFilters(op string, filters []query.Filter) (string, error)
 			}
 			arr = append(arr, str)
 		default:
-			return "", fmt.Errorf("unsupported filter type %#v", f)
+			if str, err = query.VisitFilter(q, f); err != nil {
+				return "", err
+			}
+			arr = append(arr, str)
 		}
 	}
 
@@ -216,13 +241,116 @@ func (q *Query) VisitOR(f *query.OR) (string, error) {
 	return q.visitFilters("OR", f.Filters)
 }
 
+func (q *Query) VisitNOT(f *query.NOT) (string, error) {
+	str, err := query.VisitFilter(q, f.Filter)
+	if err != nil {
+		return "", err
+	}
+	// Conditions on missing fields evaluate to NULL, which must be negated to true
+	return "NOT COALESCE(" + str + ", FALSE)", nil
+}
+
+func (q *Query) VisitNIN(f *query.NIN) (string, error) {
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty NIN operator for key %q", f.Key)
+	}
+
+	positions := make([]string, len(f.Vals))
+	for i, v := range f.Vals {
+		positions[i] = "$" + strconv.Itoa(q.addParamValueAndReturnPosition(v))
+	}
+	// The ->> operator returns NULL if the field is missing or set to null, and those documents match too
+	field := translateFieldToFilter(f.Key)
+	return "(" + field + " IS NULL OR " + field + " NOT IN (" + strings.Join(positions, ", ") + "))", nil
+}
+
+func (q *Query) VisitEXISTS(f *query.EXISTS) (string, error) {
+	// The -> operator returns NULL only if the field is missing, and a JSON null if it's set to null
+	if f.Exists {
+		return translateFieldToJSON(f.Key) + " IS NOT NULL", nil
+	}
+	return translateFieldToJSON(f.Key) + " IS NULL", nil
+}
+
+func (q *Query) VisitLIKE(f *query.LIKE) (string, error) {
+	position := q.addParamValueAndReturnPosition(f.Pattern)
+	return translateFieldToFilter(f.Key) + " LIKE $" + strconv.Itoa(position), nil
+}
+
+func (q *Query) VisitCONTAINS(f *query.CONTAINS) (string, error) {
+	// Containment of a single-element array matches arrays that include the element
+	val, err := json.Marshal([]any{f.Val})
+	if err != nil {
+		return "", err
+	}
+	q.params = append(q.params, string(val))
+	return translateFieldToJSON(f.Key) + " @> $" + strconv.Itoa(len(q.params)) + "::jsonb", nil
+}
+
+func (q *Query) VisitBETWEEN(f *query.BETWEEN) (string, error) {
+	for _, v := range []any{f.From, f.To} {
+		if _, ok := v.(string); ok {
+			return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+		}
+	}
+	from := q.addParamValueAndReturnPosition(f.From)
+	to := q.addParamValueAndReturnPosition(f.To)
+	return translateFieldToFilter(f.Key) + " BETWEEN $" + strconv.Itoa(from) + " AND $" + strconv.Itoa(to), nil
+}
+
+func (q *Query) VisitProjection(qq *query.Query) error {
+	if !qq.IsAggregation() {
+		q.projection = translateSelectToJSON(qq.Select)
+		return nil
+	}
+
+	fields := make([]string, 0, len(qq.GroupBy)+len(qq.Aggregate))
+	q.groupBy = make([]string, len(qq.GroupBy))
+	q.sortFields = make(map[string]string, len(qq.GroupBy)+len(qq.Aggregate))
+	for i, g := range qq.GroupBy {
+		q.groupBy[i] = translateFieldToJSON(g)
+		q.sortFields[g] = q.groupBy[i]
+		fields = append(fields, quoteLiteral(g)+", "+q.groupBy[i])
+	}
+	for _, a := range qq.Aggregate {
+		var expr string
+		switch {
+		case a.Op == query.COUNT && a.Key == "":
+			expr = "COUNT(*)"
+		case a.Op == query.COUNT:
+			expr = "COUNT(" + translateFieldToJSON(a.Key) + ")"
+		default:
+			// Values that are not numbers are ignored
+			expr = a.Op + "(CASE WHEN jsonb_typeof(" + translateFieldToJSON(a.Key) + ") = 'number' THEN (" + translateFieldToFilter(a.Key) + ")::numeric END)"
+		}
+		q.sortFields[a.Name()] = expr
+		fields = append(fields, quoteLiteral(a.Name())+", "+expr)
+	}
+	q.aggregate = "jsonb_build_object(" + strings.Join(fields, ", ") + ")"
+
+	return nil
+}
+
 func (q *Query) Finalize(filters string, qq *query.Query) error {
-	q.query = fmt.Sprintf("SELECT key, value, %s as etag FROM "+q.tableName, q.etagColumn)
+	switch {
+	case q.aggregate != "":
+		q.query = "SELECT " + q.aggregate + " FROM " + q.tableName
+	case q.projection != "":
+		q.query = fmt.Sprintf("SELECT key, %s AS value, %s as etag FROM "+q.tableName, q.projection, q.etagColumn)
+	case q.decodeValues:
+		q.query = fmt.Sprintf("SELECT key, value, isbinary, %s as etag FROM "+q.tableName, q.etagColumn)
+	default:
+		q.query = fmt.Sprintf("SELECT key, value, %s as etag FROM "+q.tableName, q.etagColumn)
+	}
 
 	if filters != "" {
 		q.query += " WHERE " + filters
 	}
 
+	if len(q.groupBy) > 0 {
+		q.query += " GROUP BY " + strings.Join(q.groupBy, ", ")
+	}
+
 	if len(qq.Sort) > 0 {
 		q.query += " ORDER BY "
 
@@ -230,7 +358,11 @@ func (q *Query) Finalize(filters string, qq *query.Query) error {
 			if sortIndex > 0 {
 				q.query += ", "
 			}
-			q.query += translateFieldToFilter(sortItem.Key)
+			if q.aggregate != "" {
+				q.query += q.sortFields[sortItem.Key]
+			} else {
+				q.query += translateFieldToFilter(sortItem.Key)
+			}
 			if sortItem.Order != "" {
 				q.query += " " + sortItem.Order
 			}
@@ -268,13 +400,37 @@ func (q *Query) execute(ctx context.Context, db pginterfaces.DBQuerier) ([]state
 			data []byte
 			etag uint32
 		)
-		if err = rows.Scan(&key, &data, &etag); err != nil {
+		// Aggregation results have no key or etag
+		if q.aggregate != "" {
+			if err = rows.Scan(&data); err != nil {
+				return nil, "", err
+			}
+			ret = append(ret, state.QueryItem{Data: data})
+			continue
+		}
+		var (
+			isBinary    bool
+			contentType *string
+		)
+		if q.decodeValues && q.projection == "" {
+			err = rows.Scan(&key, &data, &isBinary, &etag)
+		} else {
+			err = rows.Scan(&key, &data, &etag)
+		}
+		if err != nil {
 			return nil, "", err
 		}
+		if isBinary {
+			data, contentType, err = decodeBinaryValue(data, q.decodeValues)
+			if err != nil {
+				return nil, "", err
+			}
+		}
 		result := state.QueryItem{
-			Key:  key,
-			Data: data,
-			ETag: ptr.Of(strconv.FormatUint(uint64(etag), 10)),
+			Key:         key,
+			Data:        data,
+			ETag:        ptr.Of(strconv.FormatUint(uint64(etag), 10)),
+			ContentType: contentType,
 		}
 		ret = append(ret, result)
 	}
@@ -321,6 +477,77 @@ func translateFieldToFilter(key string) string {
 	return filterField
 }
 
+// translateFieldToJSON returns the expression that selects the field as JSON, rather than as text.
+func translateFieldToJSON(key string) string {
+	filterField := "value"
+	for _, fieldPart := range strings.Split(key, ".") {
+		filterField += "->'" + fieldPart + "'"
+	}
+
+	return filterField
+}
+
+// translateSelectToJSON returns the expression that builds a JSON object with only the selected fields, keeping their nesting.
+// Fields that are missing are omitted, as by query.Evaluator, rather than set to null.
+func translateSelectToJSON(fields []string) string {
+	root := &projectionNode{}
+	for _, field := range fields {
+		root.add(strings.Split(field, "."))
+	}
+	return root.toJSON("value")
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+type projectionNode struct {
+	names    []string
+	children map[string]*projectionNode
+	leaf     bool
+}
+
+func (n *projectionNode) add(parts []string) {
+	if n.leaf {
+		// A parent field is already selected in full
+		return
+	}
+	if len(parts) == 0 {
+		n.leaf = true
+		n.names = nil
+		n.children = nil
+		return
+	}
+	if n.children == nil {
+		n.children = make(map[string]*projectionNode)
+	}
+	child, ok := n.children[parts[0]]
+	if !ok {
+		child = &projectionNode{}
+		n.children[parts[0]] = child
+		n.names = append(n.names, parts[0])
+	}
+	child.add(parts[1:])
+}
+
+// toJSON returns the expression that builds the object with the selected children of the field, which is empty if none of them is present.
+func (n *projectionNode) toJSON(field string) string {
+	if n.leaf {
+		return field
+	}
+	rows := make([]string, len(n.names))
+	for i, name := range n.names {
+		child := n.children[name]
+		val := child.toJSON(field + "->'" + name + "'")
+		if !child.leaf {
+			val = "NULLIF(" + val + ", '{}'::jsonb)"
+		}
+		rows[i] = "(" + quoteLiteral(name) + ", " + val + ")"
+	}
+	// Missing fields are SQL NULLs, unlike fields that are set to null
+	return "(SELECT COALESCE(jsonb_object_agg(f.k, f.v), '{}'::jsonb) FROM (VALUES " + strings.Join(rows, ", ") + ") AS f(k, v) WHERE f.v IS NOT NULL)"
+}
+
 func (q *Query) whereFieldEqual(key string, value interface{}) string {
 	position := q.addParamValueAndReturnPosition(value)
 	filterField := translateFieldToFilter(key)
@@ -61,6 +61,18 @@ func TestPostgresqlQueryBuildQuery(t *testing.T) {
 			input: "../../../../tests/state/query/q8.json",
 			query: "SELECT key, value, xmin as etag FROM state WHERE (value->'person'->>'org'>=$1 OR (value->'person'->>'org'<$2 AND (value->>'state'=$3 OR value->>'state'=$4))) ORDER BY value->>'state' DESC, value->'person'->>'name' LIMIT 2",
 		},
+		{
+			input: "../../../../tests/state/query/q9.json",
+			query: "SELECT key, value, xmin as etag FROM state WHERE (NOT COALESCE(value->>'state'=$1, FALSE) AND (value->'person'->>'org' IS NULL OR value->'person'->>'org' NOT IN ($2, $3)) AND value->'person'->'name' IS NOT NULL AND value->'person'->>'name' LIKE $4 AND value->'tags' @> $5::jsonb AND value->>'age' BETWEEN $6 AND $7) ORDER BY value->>'age'",
+		},
+		{
+			input: "../../../../tests/state/query/q10.json",
+			query: "SELECT jsonb_build_object('state', value->'state', 'count', COUNT(*), 'total', SUM(CASE WHEN jsonb_typeof(value->'person'->'id') = 'number' THEN (value->'person'->>'id')::numeric END), 'max_person.id', MAX(CASE WHEN jsonb_typeof(value->'person'->'id') = 'number' THEN (value->'person'->>'id')::numeric END)) FROM state WHERE value->>'state'!=$1 GROUP BY value->'state' ORDER BY COUNT(*) DESC, value->'state' LIMIT 2",
+		},
+		{
+			input: "../../../../tests/state/query/q11.json",
+			query: "SELECT key, (SELECT COALESCE(jsonb_object_agg(f.k, f.v), '{}'::jsonb) FROM (VALUES ('person', NULLIF((SELECT COALESCE(jsonb_object_agg(f.k, f.v), '{}'::jsonb) FROM (VALUES ('name', value->'person'->'name'), ('org', value->'person'->'org')) AS f(k, v) WHERE f.v IS NOT NULL), '{}'::jsonb)), ('state', value->'state')) AS f(k, v) WHERE f.v IS NOT NULL) AS value, xmin as etag FROM state WHERE value->>'state'=$1",
+		},
 	}
 	for _, test := range tests {
 		data, err := os.ReadFile(test.input)
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+// Keys are compared with the C collation, so they are sorted byte-wise regardless of the collation of the database.
+func (p *PostgreSQL) RangeScan(parentCtx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	if !p.enableRange {
+		return nil, errors.New("range scans are not supported by this state store")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	after, order := ">", "ASC"
+	if req.Reverse {
+		after, order = "<", "DESC"
+	}
+	var token string
+	if req.ContinuationToken != nil {
+		token = *req.ContinuationToken
+	}
+	// A NULL limit means no limit; one more row is read to know if there's another page
+	var limit any
+	if req.Limit > 0 {
+		limit = int64(req.Limit) + 1
+	}
+
+	query := `SELECT key, value, isbinary, ` + p.etagColumn + ` AS etag, expiredate
+		FROM ` + p.metadata.TableName + `
+		WHERE
+			key COLLATE "C" >= $1
+			AND ($2 = '' OR key COLLATE "C" < $2)
+			AND ($3 = '' OR key COLLATE "C" ` + after + ` $3)
+			AND (expiredate IS NULL OR expiredate >= CURRENT_TIMESTAMP)
+		ORDER BY key COLLATE "C" ` + order + `
+		LIMIT $4`
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	rows, err := p.db.Query(ctx, query, req.Start, req.End, token, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan range: %w", err)
+	}
+	defer rows.Close()
+
+	res := &state.RangeScanResponse{}
+	for rows.Next() {
+		if req.Limit > 0 && len(res.Items) == int(req.Limit) {
+			res.ContinuationToken = ptr.Of(res.Items[len(res.Items)-1].Key)
+			break
+		}
+		key, value, etag, expireTime, contentType, err := readRow(rows, p.codec != nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan range: %w", err)
+		}
+		item := state.RangeScanItem{
+			Key:         key,
+			Data:        value,
+			ETag:        etag,
+			ContentType: contentType,
+		}
+		if expireTime != nil {
+			item.Metadata = map[string]string{
+				state.GetRespMetaKeyTTLExpireTime: expireTime.UTC().Format(time.RFC3339),
+			}
+		}
+		res.Items = append(res.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan range: %w", err)
+	}
+	return res, nil
+}
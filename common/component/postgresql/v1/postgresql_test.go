@@ -17,17 +17,23 @@ package postgresql
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	pgxmock "github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	pginterfaces "github.com/dapr/components-contrib/common/component/postgresql/interfaces"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/codec"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 type mocks struct {
@@ -169,6 +175,23 @@ func TestValidMultiDeleteRequest(t *testing.T) {
 	})
 }
 
+func TestDeleteWithPrefix(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+
+	m.db.ExpectExec("DELETE FROM state").
+		WithArgs(`my\_app||%`, `my\_app||%||%`).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	res, err := m.pg.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "my_app"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Count)
+	require.NoError(t, m.db.ExpectationsWereMet())
+
+	_, err = m.pg.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "||"})
+	require.ErrorContains(t, err, "prefix is required")
+}
+
 func TestInvalidMultiDeleteRequestNoKey(t *testing.T) {
 	// Arrange
 	m, _ := mockDatabase(t)
@@ -263,6 +286,317 @@ func mockDatabase(t *testing.T) (*mocks, error) {
 	}, err
 }
 
+func TestWatchEvent(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+
+	t.Run("delete", func(t *testing.T) {
+		e, err := m.pg.watchEvent(t.Context(), &state.WatchRequest{Prefix: "app||"}, `{"key":"app||a","op":"delete"}`)
+		require.NoError(t, err)
+		assert.Equal(t, &state.WatchEvent{Type: state.WatchEventDelete, Key: "app||a"}, e)
+	})
+
+	t.Run("key not matched", func(t *testing.T) {
+		e, err := m.pg.watchEvent(t.Context(), &state.WatchRequest{Key: "app||a"}, `{"key":"app||b","op":"upsert"}`)
+		require.NoError(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := m.pg.watchEvent(t.Context(), &state.WatchRequest{Key: "app||a"}, `not json`)
+		require.Error(t, err)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		err := m.pg.Watch(t.Context(), &state.WatchRequest{Key: "app||a"}, nil)
+		require.Error(t, err)
+		assert.NotContains(t, m.pg.Features(), state.FeatureWatch)
+	})
+}
+
+func TestAtomicOperations(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	m.pg.etagColumn = "xmin"
+	m.pg.enableAtomic = true
+	assert.Contains(t, m.pg.Features(), state.FeatureAtomicOperations)
+
+	t.Run("increment", func(t *testing.T) {
+		m.db.ExpectQuery("INSERT INTO state AS t").
+			WithArgs("counter", int64(5)).
+			WillReturnRows(pgxmock.NewRows([]string{"value", "xmin"}).AddRow(int64(7), int64(10)))
+
+		res, err := m.pg.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: 5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), res.Value)
+		assert.Equal(t, "10", *res.ETag)
+	})
+
+	t.Run("increment a value that is not an integer", func(t *testing.T) {
+		m.db.ExpectQuery("INSERT INTO state AS t").
+			WithArgs("counter", int64(1)).
+			WillReturnRows(pgxmock.NewRows([]string{"value", "xmin"}))
+
+		_, err := m.pg.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: 1})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		m.db.ExpectQuery("INSERT INTO state AS t").
+			WithArgs("list", `["a",1]`).
+			WillReturnRows(pgxmock.NewRows([]string{"length", "xmin"}).AddRow(int64(3), int64(11)))
+
+		res, err := m.pg.Append(t.Context(), &state.AppendRequest{Key: "list", Values: []any{"a", 1}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Length)
+		assert.Equal(t, "11", *res.ETag)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		m.db.ExpectQuery("UPDATE state AS t").
+			WithArgs("key", `"v2"`, false, `"v1"`, false).
+			WillReturnRows(pgxmock.NewRows([]string{"xmin"}).AddRow(int64(12)))
+
+		res, err := m.pg.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "key", Expected: "v1", Value: "v2"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, "12", *res.ETag)
+
+		m.db.ExpectQuery("INSERT INTO state AS t").
+			WithArgs("key", `"v1"`, false).
+			WillReturnRows(pgxmock.NewRows([]string{"xmin"}))
+
+		res, err = m.pg.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "key", Value: "v1"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+	})
+
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestTTLManagement(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	m.pg.etagColumn = "xmin"
+	assert.Contains(t, m.pg.Features(), state.FeatureTTLManagement)
+
+	expire := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	t.Run("expire", func(t *testing.T) {
+		m.db.ExpectQuery("UPDATE state AS t").
+			WithArgs("session", int64(60)).
+			WillReturnRows(pgxmock.NewRows([]string{"xmin", "expiredate"}).AddRow(int64(10), expire))
+
+		res, err := m.pg.Expire(t.Context(), &state.ExpireRequest{Key: "session", TTLInSeconds: 60})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Equal(t, "10", *res.ETag)
+		require.NotNil(t, res.ExpireTime)
+		assert.True(t, expire.Equal(*res.ExpireTime))
+	})
+
+	t.Run("remove the expiration time", func(t *testing.T) {
+		m.db.ExpectQuery("UPDATE state AS t").
+			WithArgs("session").
+			WillReturnRows(pgxmock.NewRows([]string{"xmin", "expiredate"}).AddRow(int64(11), nil))
+
+		res, err := m.pg.Expire(t.Context(), &state.ExpireRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+	})
+
+	t.Run("get TTL of a missing key", func(t *testing.T) {
+		m.db.ExpectQuery("SELECT t.xmin, t.expiredate").
+			WithArgs("missing").
+			WillReturnRows(pgxmock.NewRows([]string{"xmin", "expiredate"}))
+
+		res, err := m.pg.GetTTL(t.Context(), &state.GetTTLRequest{Key: "missing"})
+		require.NoError(t, err)
+		assert.False(t, res.Found)
+	})
+
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestRangeScan(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	m.pg.etagColumn = "xmin"
+
+	_, err := m.pg.RangeScan(t.Context(), &state.RangeScanRequest{})
+	require.ErrorContains(t, err, "not supported")
+	assert.NotContains(t, m.pg.Features(), state.FeatureRangeScan)
+
+	m.pg.enableRange = true
+	assert.Contains(t, m.pg.Features(), state.FeatureRangeScan)
+
+	row := func(key string, etag int64) []any {
+		return []any{key, []byte(`"` + key + `"`), false, pgtype.Int8{Int64: etag, Valid: true}, pgtype.Timestamp{}}
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		m.db.ExpectQuery(`SELECT key, value, isbinary, xmin AS etag, expiredate\s+FROM state(.|\n)+key COLLATE "C" > \$3(.|\n)+ORDER BY key COLLATE "C" ASC`).
+			WithArgs("a||", "a||~", "", int64(3)).
+			WillReturnRows(pgxmock.NewRows([]string{"key", "value", "isbinary", "etag", "expiredate"}).
+				AddRow(row("a||1", 1)...).
+				AddRow(row("a||2", 2)...).
+				AddRow(row("a||3", 3)...))
+
+		res, err := m.pg.RangeScan(t.Context(), &state.RangeScanRequest{Start: "a||", End: "a||~", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		assert.Equal(t, "a||1", res.Items[0].Key)
+		assert.Equal(t, `"a||1"`, string(res.Items[0].Data))
+		assert.Equal(t, "1", *res.Items[0].ETag)
+		require.NotNil(t, res.ContinuationToken)
+		assert.Equal(t, "a||2", *res.ContinuationToken)
+	})
+
+	t.Run("last page in reverse order", func(t *testing.T) {
+		m.db.ExpectQuery(`key COLLATE "C" < \$3(.|\n)+ORDER BY key COLLATE "C" DESC`).
+			WithArgs("a||", "a||~", "a||2", int64(3)).
+			WillReturnRows(pgxmock.NewRows([]string{"key", "value", "isbinary", "etag", "expiredate"}).
+				AddRow(row("a||1", 1)...))
+
+		res, err := m.pg.RangeScan(t.Context(), &state.RangeScanRequest{Start: "a||", End: "a||~", Limit: 2, Reverse: true, ContinuationToken: ptr.Of("a||2")})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Nil(t, res.ContinuationToken)
+	})
+
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestOutbox(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+
+	msg := state.OutboxRequest{ID: "msg1", PubsubName: "events", Topic: "orders", Data: []byte("created"), Metadata: map[string]string{"k": "v"}}
+	err := m.pg.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{msg},
+	})
+	require.ErrorContains(t, err, "not supported")
+	assert.NotContains(t, m.pg.Features(), state.FeatureOutbox)
+
+	m.pg.enableOutbox = true
+	assert.Contains(t, m.pg.Features(), state.FeatureOutbox)
+
+	t.Run("messages are saved in the transaction", func(t *testing.T) {
+		m.db.ExpectBegin()
+		m.db.ExpectExec("INSERT INTO state").
+			WithArgs("key1", `"value1"`, false).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		m.db.ExpectExec(`INSERT INTO state_outbox(.|\n)+ON CONFLICT \(id\) DO NOTHING`).
+			WithArgs("msg1", "events", "orders", []byte("created"), pgxmock.AnyArg(), ptr.Of(`{"k":"v"}`)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		m.db.ExpectCommit()
+
+		err := m.pg.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "key1", Value: "value1"},
+				msg,
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid messages fail the transaction", func(t *testing.T) {
+		m.db.ExpectBegin()
+		m.db.ExpectRollback()
+
+		err := m.pg.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.OutboxRequest{ID: "msg2", PubsubName: "events"},
+				state.SetRequest{Key: "key1", Value: "value1"},
+			},
+		})
+		require.ErrorContains(t, err, "missing topic")
+	})
+
+	t.Run("lease returns the messages in order", func(t *testing.T) {
+		m.db.ExpectQuery(`UPDATE state_outbox(.|\n)+FOR UPDATE SKIP LOCKED`).
+			WithArgs(int64(30000), "events", 10).
+			WillReturnRows(pgxmock.NewRows([]string{"seq", "id", "topic", "data", "content_type", "metadata", "attempts"}).
+				AddRow(int64(2), "msg2", "orders", []byte("b"), (*string)(nil), (*string)(nil), 1).
+				AddRow(int64(1), "msg1", "orders", []byte("a"), ptr.Of("text/plain"), ptr.Of(`{"k":"v"}`), 2))
+
+		msgs, err := m.pg.LeaseOutbox(t.Context(), &state.LeaseOutboxRequest{PubsubName: "events", Limit: 10, LeaseDuration: 30 * time.Second})
+		require.NoError(t, err)
+		require.Len(t, msgs, 2)
+		assert.Equal(t, "msg1", msgs[0].ID)
+		assert.Equal(t, "events", msgs[0].PubsubName)
+		assert.Equal(t, "text/plain", *msgs[0].ContentType)
+		assert.Equal(t, map[string]string{"k": "v"}, msgs[0].Metadata)
+		assert.Equal(t, 2, msgs[0].Attempts)
+		assert.Equal(t, "msg2", msgs[1].ID)
+		assert.Nil(t, msgs[1].Metadata)
+	})
+
+	t.Run("delete and release", func(t *testing.T) {
+		m.db.ExpectExec(`DELETE FROM state_outbox WHERE id = ANY\(\$1\)`).
+			WithArgs([]string{"msg1", "msg2"}).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		m.db.ExpectExec("UPDATE state_outbox").
+			WithArgs(int64(5000), "msg3").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, m.pg.DeleteOutbox(t.Context(), []string{"msg1", "msg2"}))
+		require.NoError(t, m.pg.ReleaseOutbox(t.Context(), "msg3", 5*time.Second))
+	})
+
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestCodec(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	var err error
+	m.pg.codec, err = codec.New(codec.Metadata{ValueCodec: "gzip"})
+	require.NoError(t, err)
+
+	value := map[string]string{"session": strings.Repeat("abc", 100)}
+	encoded, err := m.pg.codec.Encode(value, ptr.Of("application/json"))
+	require.NoError(t, err)
+	stored, _ := json.Marshal(base64.StdEncoding.EncodeToString(encoded))
+
+	m.db.ExpectExec("INSERT INTO").
+		WithArgs("key", string(stored), true).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	err = m.pg.Set(t.Context(), &state.SetRequest{Key: "key", Value: value, ContentType: ptr.Of("application/json")})
+	require.NoError(t, err)
+
+	m.db.ExpectQuery("SELECT").
+		WithArgs("key").
+		WillReturnRows(pgxmock.NewRows([]string{"key", "value", "isbinary", "etag", "expiredate"}).
+			AddRow("key", stored, true, pgtype.Int8{Int64: 1, Valid: true}, pgtype.Timestamp{}))
+	res, err := m.pg.Get(t.Context(), &state.GetRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"session":"`+strings.Repeat("abc", 100)+`"}`, string(res.Data))
+	require.NotNil(t, res.ContentType)
+	assert.Equal(t, "application/json", *res.ContentType)
+
+	// Without a codec, binary values are returned as they are
+	m.pg.codec = nil
+	m.db.ExpectQuery("SELECT").
+		WithArgs("key").
+		WillReturnRows(pgxmock.NewRows([]string{"key", "value", "isbinary", "etag", "expiredate"}).
+			AddRow("key", stored, true, pgtype.Int8{Int64: 1, Valid: true}, pgtype.Timestamp{}))
+	res, err = m.pg.Get(t.Context(), &state.GetRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, encoded, res.Data)
+	assert.Nil(t, res.ContentType)
+
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestWatchChannelName(t *testing.T) {
+	assert.Equal(t, "state_changes", watchChannelName("state"))
+	name := watchChannelName(strings.Repeat("a", 60))
+	assert.Len(t, name, 43)
+	assert.Equal(t, name, watchChannelName(strings.Repeat("a", 60)))
+}
+
 func randomKey() string {
 	return uuid.New().String()
 }
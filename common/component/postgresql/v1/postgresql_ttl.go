@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// Expire sets or removes the expiration time of the key with a single update, keeping its value.
+// When the ETag column is xmin, the update assigns a new ETag to the key.
+func (p *PostgreSQL) Expire(parentCtx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	expiration := "NULL"
+	args := []any{req.Key}
+	if req.TTLInSeconds > 0 {
+		expiration = "CURRENT_TIMESTAMP + $2::bigint * interval '1 second'"
+		args = append(args, req.TTLInSeconds)
+	}
+	query := `UPDATE ` + p.metadata.TableName + ` AS t
+		SET expiredate = ` + expiration + `
+		WHERE t.key = $1 AND NOT ` + expiredCondition + `
+		RETURNING t.` + p.etagColumn + `, t.expiredate`
+	return p.readTTL(parentCtx, query, args...)
+}
+
+// GetTTL returns the expiration time of the key.
+func (p *PostgreSQL) GetTTL(parentCtx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT t.` + p.etagColumn + `, t.expiredate
+		FROM ` + p.metadata.TableName + ` AS t
+		WHERE t.key = $1 AND NOT ` + expiredCondition
+	return p.readTTL(parentCtx, query, req.Key)
+}
+
+// readTTL executes a query that returns the ETag and expiration time of the key, if it exists.
+func (p *PostgreSQL) readTTL(parentCtx context.Context, query string, args ...any) (*state.TTLResponse, error) {
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+
+	var (
+		etag   pgtype.Int8
+		expire pgtype.Timestamp
+	)
+	err := p.db.QueryRow(ctx, query, args...).Scan(&etag, &expire)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &state.TTLResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to read expiration time of key %s: %w", args[0], err)
+	}
+
+	res := &state.TTLResponse{Found: true, ETag: formatETag(etag)}
+	if expire.Valid {
+		res.ExpireTime = &expire.Time
+	}
+	return res, nil
+}
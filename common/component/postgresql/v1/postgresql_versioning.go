@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// HistoryConfigKey is the key of the history configuration in the metadata table.
+// The trigger on the state table records revisions only when it's present.
+const HistoryConfigKey = "history"
+
+var errVersioningDisabled = errors.New("versioning is not enabled: set historyLimit or historyRetention in the metadata")
+
+// historyConfig is the history configuration saved in the metadata table, which is read by the trigger.
+type historyConfig struct {
+	Limit int `json:"limit"`
+	// Retention window, in seconds.
+	Retention int64 `json:"retention"`
+}
+
+// historyTableName returns the name of the table populated by the trigger with the revisions of the keys.
+func (p *PostgreSQL) historyTableName() string {
+	return p.metadata.TableName + "_history"
+}
+
+// versioningEnabled returns true if the revisions of the keys are recorded.
+func (p *PostgreSQL) versioningEnabled() bool {
+	return p.enableHistory && (p.metadata.HistoryLimit > 0 || p.metadata.HistoryRetention > 0)
+}
+
+// initHistory saves the history configuration in the metadata table, or removes it if versioning is disabled.
+// All instances of the component that use the same state table must have the same configuration.
+func (p *PostgreSQL) initHistory(parentCtx context.Context) error {
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+
+	if !p.versioningEnabled() {
+		_, err := p.db.Exec(ctx, "DELETE FROM "+p.metadata.MetadataTableName+" WHERE key = $1", HistoryConfigKey)
+		if err != nil {
+			return fmt.Errorf("failed to disable versioning: %w", err)
+		}
+		return nil
+	}
+
+	cfg, err := json.Marshal(historyConfig{
+		Limit:     p.metadata.HistoryLimit,
+		Retention: int64(p.metadata.HistoryRetention.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(ctx,
+		"INSERT INTO "+p.metadata.MetadataTableName+` (key, value) VALUES ($1, $2)
+			ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		HistoryConfigKey, string(cfg),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enable versioning: %w", err)
+	}
+	return nil
+}
+
+// GetRevisions returns the revisions of the key that are retained, from the most recent one.
+func (p *PostgreSQL) GetRevisions(parentCtx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if !p.versioningEnabled() {
+		return nil, errVersioningDisabled
+	}
+
+	revs, err := p.retainedRevisions(parentCtx, req.Key, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &state.GetRevisionsResponse{Revisions: revs}, nil
+}
+
+// GetRevision returns the value of the key at a revision, or at a point in time.
+func (p *PostgreSQL) GetRevision(parentCtx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if !p.versioningEnabled() {
+		return nil, errVersioningDisabled
+	}
+
+	revs, err := p.retainedRevisions(parentCtx, req.Key, 0)
+	if err != nil {
+		return nil, err
+	}
+	var found *state.Revision
+	for i := range revs {
+		if (req.Revision != nil && revs[i].Revision == *req.Revision) ||
+			(req.Time != nil && !revs[i].Time.After(*req.Time)) {
+			found = &revs[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, state.ErrRevisionNotFound
+	}
+	if found.Deleted {
+		return &state.GetResponse{}, nil
+	}
+
+	query := `SELECT key, value, isbinary, etag, expiredate
+		FROM ` + p.historyTableName() + `
+		WHERE key = $1 AND revision = $2`
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	_, value, etag, expireTime, contentType, err := readRow(p.db.QueryRow(ctx, query, req.Key, found.Revision), p.codec != nil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The revision has been removed in the meanwhile
+			return nil, state.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	res := &state.GetResponse{
+		Data:        value,
+		ETag:        etag,
+		ContentType: contentType,
+	}
+	if expireTime != nil {
+		if req.Time != nil && expireTime.Before(*req.Time) {
+			return &state.GetResponse{}, nil
+		}
+		res.Metadata = map[string]string{
+			state.GetRespMetaKeyTTLExpireTime: expireTime.UTC().Format(time.RFC3339),
+		}
+	}
+	return res, nil
+}
+
+// retainedRevisions returns up to limit revisions of the key that are retained, from the most recent one.
+// Revisions that exceed the history limit or are older than the retention window are discarded, except the most recent one of a key that exists, even if the trigger hasn't removed them yet.
+func (p *PostgreSQL) retainedRevisions(parentCtx context.Context, key string, limit int) ([]state.Revision, error) {
+	query := `SELECT revision, revision_time, deleted, etag FROM (
+			SELECT revision, revision_time, deleted, etag, ROW_NUMBER() OVER (ORDER BY revision DESC) AS n
+			FROM ` + p.historyTableName() + `
+			WHERE key = $1
+		) AS h
+		WHERE
+			($2::bigint = 0 OR n <= $2::bigint)
+			AND ($3::bigint = 0 OR revision_time >= now() - make_interval(secs => $3::bigint) OR (n = 1 AND NOT deleted))
+			AND ($4::bigint = 0 OR n <= $4::bigint)
+		ORDER BY revision DESC`
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	rows, err := p.db.Query(ctx, query, key, p.metadata.HistoryLimit, int64(p.metadata.HistoryRetention.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revs []state.Revision
+	for rows.Next() {
+		var (
+			r    state.Revision
+			etag pgtype.Int8
+		)
+		err = rows.Scan(&r.Revision, &r.Time, &r.Deleted, &etag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read revisions: %w", err)
+		}
+		if etag.Valid && !r.Deleted {
+			r.ETag = ptr.Of(strconv.FormatInt(etag.Int64, 10))
+		}
+		revs = append(revs, r)
+	}
+	return revs, rows.Err()
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// Maximum length of identifiers, including channel names, in PostgreSQL.
+const maxIdentifierLength = 63
+
+// watchNotification is the payload of the notifications sent by the trigger on the state table.
+type watchNotification struct {
+	Key string `json:"key"`
+	Op  string `json:"op"`
+}
+
+// watchChannelName returns the name of the channel notified of the changes to the state table.
+// Names that would be longer than the maximum length of identifiers are replaced with a hash of the table name.
+func watchChannelName(tableName string) string {
+	name := tableName + "_changes"
+	if len(name) > maxIdentifierLength {
+		h := sha256.Sum256([]byte(tableName))
+		name = "dapr_state_" + hex.EncodeToString(h[:16])
+	}
+	return name
+}
+
+// Watch delivers the changes to the keys matched by the request to the handler.
+// Changes are notified by a trigger on the state table, and received on a dedicated connection with LISTEN.
+// Delete events for expired keys are delivered when the garbage collector removes them.
+func (p *PostgreSQL) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	if !p.enableWatch {
+		return errors.New("watch is not supported by this state store")
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if p.closed.Load() {
+		return errors.New("state store is closed")
+	}
+
+	// The connection is removed from the pool, as it's used exclusively for the notifications until the watch ends
+	poolConn, err := p.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring connection: %w", err)
+	}
+	conn := poolConn.Hijack()
+	listenCtx, listenCancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	_, err = conn.Exec(listenCtx, "LISTEN "+pgx.Identifier{p.watchChannel}.Sanitize())
+	listenCancel()
+	if err != nil {
+		conn.Close(context.Background())
+		return fmt.Errorf("error listening to channel: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		defer cancel()
+		select {
+		case <-watchCtx.Done():
+		case <-p.closeCh:
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		defer cancel()
+		defer conn.Close(context.Background())
+
+		for {
+			notification, err := conn.WaitForNotification(watchCtx)
+			if err != nil {
+				if !pgconn.Timeout(err) && !errors.Is(err, context.Canceled) {
+					p.logger.Errorf("Error waiting for notification: %v", err)
+				}
+				return
+			}
+
+			e, err := p.watchEvent(watchCtx, req, notification.Payload)
+			if err != nil {
+				p.logger.Errorf("Error processing notification: %v", err)
+				continue
+			}
+			if e == nil {
+				continue
+			}
+			if err = handler(watchCtx, e); err != nil {
+				p.logger.Errorf("Error from watch handler for key %s: %v", e.Key, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// watchEvent returns the event for a notification, or nil if the key doesn't match the request.
+// The values of upserted keys are read from the state table, so they may be more recent than the change.
+func (p *PostgreSQL) watchEvent(ctx context.Context, req *state.WatchRequest, payload string) (*state.WatchEvent, error) {
+	var n watchNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if !req.Matches(n.Key) {
+		return nil, nil
+	}
+
+	e := &state.WatchEvent{
+		Type: state.WatchEventType(n.Op),
+		Key:  n.Key,
+	}
+	if e.Type == state.WatchEventDelete {
+		return e, nil
+	}
+
+	var err error
+	e.Value, e.ETag, e.ExpireTime, _, err = p.getRow(ctx, n.Key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The key has been deleted or has expired since; the delete event follows
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get value for key %s: %w", n.Key, err)
+	}
+	return e, nil
+}
@@ -74,12 +74,14 @@ type RedisClient interface {
 	Context() context.Context
 	DoRead(ctx context.Context, args ...interface{}) (interface{}, error)
 	DoWrite(ctx context.Context, args ...interface{}) error
+	DoWriteResult(ctx context.Context, args ...interface{}) (interface{}, error)
 	Del(ctx context.Context, keys ...string) error
 	Get(ctx context.Context, key string) (string, error)
 	GetDel(ctx context.Context, key string) (string, error)
 	Close() error
 	PingResult(ctx context.Context) (string, error)
 	ConfigurationSubscribe(ctx context.Context, args *ConfigurationSubscribeArgs)
+	PSubscribe(ctx context.Context, args *PSubscribeArgs) error
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (*bool, error)
 	EvalInt(ctx context.Context, script string, keys []string, args ...interface{}) (*int, error, error)
 	XAdd(ctx context.Context, stream string, maxLenApprox int64, streamTTL string, values map[string]interface{}) (string, error)
@@ -102,6 +104,14 @@ type ConfigurationSubscribeArgs struct {
 	ID                     string
 }
 
+// PSubscribeArgs contains the arguments for PSubscribe.
+type PSubscribeArgs struct {
+	// Pattern of the channels to subscribe to
+	Pattern string
+	// Handler invoked with the channel and the payload of each message
+	Handler func(ctx context.Context, channel string, payload string)
+}
+
 func ParseClientFromProperties(properties map[string]string, componentType metadata.ComponentType, ctx context.Context, logger *kitlogger.Logger) (RedisClient, *Settings, error) {
 	settings := Settings{}
 
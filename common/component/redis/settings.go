@@ -90,6 +90,8 @@ type Settings struct {
 	// == state only properties ==
 	TTLInSeconds *int   `mapstructure:"ttlInSeconds" mdonly:"state"`
 	QueryIndexes string `mapstructure:"queryIndexes" mdonly:"state"`
+	// Name of the sorted set that indexes the keys for range scans; range scans are disabled if empty
+	RangeIndex string `mapstructure:"rangeIndex" mdonly:"state"`
 
 	// == pubsub only properties ==
 	// The consumer identifier
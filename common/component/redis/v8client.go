@@ -63,6 +63,16 @@ func (c v8Client) DoWrite(ctx context.Context, args ...interface{}) error {
 	return c.client.Do(ctx, args...).Err()
 }
 
+// DoWriteResult executes a command that modifies data, like DoWrite, and returns its reply.
+func (c v8Client) DoWriteResult(ctx context.Context, args ...interface{}) (interface{}, error) {
+	if c.writeTimeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.writeTimeout))
+		defer cancel()
+		return c.client.Do(timeoutCtx, args...).Result()
+	}
+	return c.client.Do(ctx, args...).Result()
+}
+
 func (c v8Client) DoRead(ctx context.Context, args ...interface{}) (interface{}, error) {
 	if c.readTimeout > 0 {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.readTimeout))
@@ -94,6 +104,34 @@ func (c v8Client) ConfigurationSubscribe(ctx context.Context, args *Configuratio
 	}
 }
 
+// PSubscribe subscribes to the channels matching the pattern.
+// It returns once the subscription is confirmed, then delivers messages to the handler in background until the context is canceled.
+func (c v8Client) PSubscribe(ctx context.Context, args *PSubscribeArgs) error {
+	p := c.client.PSubscribe(ctx, args.Pattern)
+	if _, err := p.Receive(ctx); err != nil {
+		p.Close()
+		return err
+	}
+
+	go func() {
+		defer p.Close()
+		ch := p.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				args.Handler(ctx, msg.Channel, msg.Payload)
+			}
+		}
+	}()
+
+	return nil
+}
+
 func (c v8Client) Del(ctx context.Context, keys ...string) error {
 	err := c.client.Del(ctx, keys...).Err()
 	if err != nil {
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlpubsub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/pubsub"
+)
+
+const (
+	defaultVisibilityTimeout   = 30 * time.Second
+	defaultMaxDeliveryAttempts = 5
+	defaultRetryInterval       = 5 * time.Second
+	defaultFetchBatchSize      = 10
+	defaultCleanupInterval     = time.Hour
+)
+
+// Metadata contains the properties shared by the SQL pubsub components.
+// It's embedded in the metadata of the components with `mapstructure:",squash"`.
+type Metadata struct {
+	// Consumer group of the subscriptions: each message is delivered to one subscriber of each consumer group.
+	// It can be overridden in the metadata of the subscriptions.
+	ConsumerID string `mapstructure:"consumerID" mdignore:"true"`
+	// Duration for which a leased message is hidden from the other subscribers.
+	VisibilityTimeout time.Duration `mapstructure:"visibilityTimeout"`
+	// Number of times a message is delivered before it's moved to the dead-letter table.
+	MaxDeliveryAttempts int `mapstructure:"maxDeliveryAttempts"`
+	// Delay before a message that failed is delivered again.
+	RetryInterval time.Duration `mapstructure:"retryInterval"`
+	// Interval between the queries for new messages, when there are none.
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	// Maximum number of messages leased at a time by each subscriber.
+	FetchBatchSize int `mapstructure:"fetchBatchSize"`
+	// Interval between the deletions of expired messages; 0 disables the cleanup.
+	CleanupInterval time.Duration `mapstructure:"cleanupInterval"`
+}
+
+// Reset sets the default values; the default poll interval depends on the component.
+func (m *Metadata) Reset(pollInterval time.Duration) {
+	m.ConsumerID = ""
+	m.VisibilityTimeout = defaultVisibilityTimeout
+	m.MaxDeliveryAttempts = defaultMaxDeliveryAttempts
+	m.RetryInterval = defaultRetryInterval
+	m.PollInterval = pollInterval
+	m.FetchBatchSize = defaultFetchBatchSize
+	m.CleanupInterval = defaultCleanupInterval
+}
+
+// Validate the metadata.
+func (m *Metadata) Validate() error {
+	if m.VisibilityTimeout < time.Second {
+		return errors.New("invalid value for 'visibilityTimeout': must be at least 1s")
+	}
+	if m.MaxDeliveryAttempts < 1 {
+		return errors.New("invalid value for 'maxDeliveryAttempts': must be at least 1")
+	}
+	if m.RetryInterval < 0 {
+		return errors.New("invalid value for 'retryInterval': must not be negative")
+	}
+	if m.PollInterval <= 0 {
+		return errors.New("invalid value for 'pollInterval': must be positive")
+	}
+	if m.FetchBatchSize < 1 {
+		return errors.New("invalid value for 'fetchBatchSize': must be at least 1")
+	}
+	if m.CleanupInterval < 0 {
+		m.CleanupInterval = 0
+	}
+	return nil
+}
+
+// SubscriptionConsumerID returns the consumer ID of a subscription, which can override the one of the component.
+func (m *Metadata) SubscriptionConsumerID(req pubsub.SubscribeRequest) (string, error) {
+	consumerID := req.Metadata[pubsub.RuntimeConsumerIDKey]
+	if consumerID == "" {
+		consumerID = m.ConsumerID
+	}
+	if consumerID == "" {
+		return "", errors.New("a consumer ID is required to subscribe")
+	}
+	return consumerID, nil
+}
+
+// EncodeMetadata returns the JSON encoding of the metadata of a message, or nil if it's empty.
+func EncodeMetadata(md map[string]string) ([]byte, error) {
+	if len(md) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the message metadata: %w", err)
+	}
+	return b, nil
+}
+
+// DecodeMetadata decodes the metadata of a message encoded with EncodeMetadata.
+func DecodeMetadata(b []byte) (map[string]string, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var md map[string]string
+	err := json.Unmarshal(b, &md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the message metadata: %w", err)
+	}
+	return md, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlpubsub
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/ptr"
+)
+
+// NewMessage is a message to be saved in the messages table.
+type NewMessage struct {
+	Data []byte
+	// Metadata encoded with EncodeMetadata.
+	Metadata    []byte
+	ContentType *string
+	// Time to live of the message; nil if it doesn't expire.
+	TTL *time.Duration
+}
+
+// PublishRequestMessage returns the message to save for a publish request.
+func PublishRequestMessage(req *pubsub.PublishRequest) (NewMessage, error) {
+	return newMessage(req.Data, req.Metadata, req.ContentType)
+}
+
+// BulkPublishRequestMessages returns the messages to save for a bulk publish request.
+// The metadata of each entry is added to the metadata of the request.
+func BulkPublishRequestMessages(req *pubsub.BulkPublishRequest) ([]NewMessage, error) {
+	msgs := make([]NewMessage, len(req.Entries))
+	for i, entry := range req.Entries {
+		md := maps.Clone(req.Metadata)
+		if md == nil {
+			md = make(map[string]string, len(entry.Metadata))
+		}
+		maps.Copy(md, entry.Metadata)
+		var contentType *string
+		if entry.ContentType != "" {
+			contentType = ptr.Of(entry.ContentType)
+		}
+
+		var err error
+		msgs[i], err = newMessage(entry.Event, md, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %s: %w", entry.EntryId, err)
+		}
+	}
+	return msgs, nil
+}
+
+func newMessage(data []byte, md map[string]string, contentType *string) (NewMessage, error) {
+	ttl, ok, err := metadata.TryGetTTL(md)
+	if err != nil {
+		return NewMessage{}, fmt.Errorf("invalid TTL: %w", err)
+	}
+	encoded, err := EncodeMetadata(md)
+	if err != nil {
+		return NewMessage{}, err
+	}
+	msg := NewMessage{
+		Data:        data,
+		Metadata:    encoded,
+		ContentType: contentType,
+	}
+	if ok {
+		msg.TTL = ptr.Of(ttl)
+	}
+	return msg, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sqlpubsub contains the logic shared by the pubsub components that store messages in a SQL database.
+//
+// Messages are saved in a table with a row for each consumer group (consumer ID) that subscribed to the topic.
+// Subscribers lease batches of messages, which are hidden from the other subscribers until the visibility timeout expires; the messages are deleted when they're processed, made visible again after a delay when they fail, or moved to a dead-letter table after the maximum number of attempts.
+package sqlpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+// Message is a message leased by a subscriber.
+type Message struct {
+	ID          int64
+	Topic       string
+	Data        []byte
+	Metadata    map[string]string
+	ContentType *string
+	// Number of times the message has been leased, including this one.
+	// It's also used as a fencing token, so a subscriber whose lease has expired can't modify the message.
+	Attempts int
+}
+
+// Queue is implemented by the components to access the messages in the database.
+// Implementations must not modify messages whose number of attempts is different from the one of the Message, which means they've been leased again.
+type Queue interface {
+	// Lease returns up to n messages for the consumer group that are visible and not expired, in the order they were published.
+	// It increments the number of attempts of the messages and hides them until the visibility timeout expires.
+	Lease(ctx context.Context, topic string, consumerID string, n int) ([]Message, error)
+	// Delete removes a message that was processed.
+	Delete(ctx context.Context, msg Message) error
+	// Retry makes a message visible again after the delay.
+	Retry(ctx context.Context, msg Message, delay time.Duration) error
+	// DeadLetter moves a message to the dead-letter table, recording the reason.
+	DeadLetter(ctx context.Context, msg Message, reason string) error
+	// Release makes messages that weren't delivered visible again, without counting the attempt.
+	Release(ctx context.Context, msgs []Message) error
+}
+
+// SubscriberOptions contains the options for Subscribe.
+type SubscriberOptions struct {
+	Logger     logger.Logger
+	Queue      Queue
+	Topic      string
+	ConsumerID string
+	Handler    pubsub.Handler
+
+	// Maximum number of messages leased at a time.
+	BatchSize int
+	// Interval between the queries for new messages, when there are none.
+	PollInterval time.Duration
+	// Delay before a message that failed is delivered again.
+	RetryInterval time.Duration
+	// Number of times a message is delivered before it's moved to the dead-letter table.
+	MaxDeliveryAttempts int
+	// Receives a value when messages are published to the topic, to query for them before the poll interval.
+	Wakeup <-chan struct{}
+	// Timeout of each query.
+	Timeout time.Duration
+}
+
+// Subscribe delivers the messages of the topic to the handler, one at a time, until the context is canceled.
+// Messages that were leased but not delivered when the context is canceled are released.
+func Subscribe(ctx context.Context, opts SubscriberOptions) {
+	s := &subscriber{opts: opts}
+	s.run(ctx)
+}
+
+type subscriber struct {
+	opts SubscriberOptions
+}
+
+func (s *subscriber) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		n, err := s.deliverBatch(ctx)
+		if ctx.Err() != nil {
+			if errors.Is(context.Cause(ctx), pubsub.ErrGracefulShutdown) {
+				s.opts.Logger.Debugf("Component is shutting down: stopped delivering messages of topic %s", s.opts.Topic)
+			}
+			return
+		}
+		if err != nil {
+			s.opts.Logger.Errorf("Error delivering messages of topic %s: %v", s.opts.Topic, err)
+		} else if n == s.opts.BatchSize {
+			// There may be more messages
+			continue
+		}
+
+		timer.Reset(s.opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-s.opts.Wakeup:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliverBatch leases a batch of messages and delivers them, returning the number of leased messages.
+func (s *subscriber) deliverBatch(ctx context.Context) (int, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	msgs, err := s.opts.Queue.Lease(queryCtx, s.opts.Topic, s.opts.ConsumerID, s.opts.BatchSize)
+	cancel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease messages: %w", err)
+	}
+
+	for i, msg := range msgs {
+		if ctx.Err() != nil {
+			// Use a new context, as the subscription's one is canceled
+			releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.Timeout)
+			err = s.opts.Queue.Release(releaseCtx, msgs[i:])
+			releaseCancel()
+			if err != nil {
+				s.opts.Logger.Warnf("Failed to release %d messages of topic %s, which will be delivered after the visibility timeout: %v", len(msgs)-i, s.opts.Topic, err)
+			}
+			return len(msgs), nil
+		}
+		s.deliver(ctx, msg)
+	}
+	return len(msgs), nil
+}
+
+func (s *subscriber) deliver(ctx context.Context, msg Message) {
+	var err error
+	if msg.Attempts > s.opts.MaxDeliveryAttempts {
+		// The message was leased, but its lease expired before it was processed
+		err = fmt.Errorf("message was not processed within the visibility timeout after %d attempts", s.opts.MaxDeliveryAttempts)
+	} else {
+		err = s.opts.Handler(ctx, &pubsub.NewMessage{
+			Data:        msg.Data,
+			Topic:       s.opts.Topic,
+			Metadata:    msg.Metadata,
+			ContentType: msg.ContentType,
+		})
+	}
+
+	// Use a new context, so the result is saved even if the subscription was canceled while the handler was running
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.Timeout)
+	defer cancel()
+	switch {
+	case err == nil:
+		err = s.opts.Queue.Delete(queryCtx, msg)
+		if err != nil {
+			s.opts.Logger.Errorf("Failed to delete processed message %d of topic %s, which will be delivered again: %v", msg.ID, s.opts.Topic, err)
+		}
+	case msg.Attempts >= s.opts.MaxDeliveryAttempts:
+		s.opts.Logger.Warnf("Failed to process message %d of topic %s after %d attempts, moving it to the dead-letter table: %v", msg.ID, s.opts.Topic, msg.Attempts, err)
+		err = s.opts.Queue.DeadLetter(queryCtx, msg, err.Error())
+		if err != nil {
+			s.opts.Logger.Errorf("Failed to move message %d of topic %s to the dead-letter table: %v", msg.ID, s.opts.Topic, err)
+		}
+	default:
+		s.opts.Logger.Warnf("Error processing message %d of topic %s: %v. Retrying in %v...", msg.ID, s.opts.Topic, err, s.opts.RetryInterval)
+		err = s.opts.Queue.Retry(queryCtx, msg, s.opts.RetryInterval)
+		if err != nil {
+			s.opts.Logger.Errorf("Failed to schedule the redelivery of message %d of topic %s, which will be delivered after the visibility timeout: %v", msg.ID, s.opts.Topic, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlpubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+// fakeQueue returns the messages once, and records the operations.
+type fakeQueue struct {
+	lock       sync.Mutex
+	msgs       []Message
+	deleted    []int64
+	retried    []int64
+	deadLetter map[int64]string
+	released   []int64
+}
+
+func (q *fakeQueue) Lease(context.Context, string, string, int) ([]Message, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	msgs := q.msgs
+	q.msgs = nil
+	return msgs, nil
+}
+
+func (q *fakeQueue) Delete(_ context.Context, msg Message) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.deleted = append(q.deleted, msg.ID)
+	return nil
+}
+
+func (q *fakeQueue) Retry(_ context.Context, msg Message, _ time.Duration) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.retried = append(q.retried, msg.ID)
+	return nil
+}
+
+func (q *fakeQueue) DeadLetter(_ context.Context, msg Message, reason string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.deadLetter[msg.ID] = reason
+	return nil
+}
+
+func (q *fakeQueue) Release(_ context.Context, msgs []Message) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for _, msg := range msgs {
+		q.released = append(q.released, msg.ID)
+	}
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	q := &fakeQueue{
+		msgs: []Message{
+			{ID: 1, Data: []byte("ok"), Attempts: 1},
+			{ID: 2, Data: []byte("fail"), Attempts: 1},
+			{ID: 3, Data: []byte("fail"), Attempts: 3},
+			// The lease of this message expired after the last attempt
+			{ID: 4, Data: []byte("ok"), Attempts: 4},
+			{ID: 5, Data: []byte("cancel"), Attempts: 1},
+			{ID: 6, Data: []byte("ok"), Attempts: 1},
+		},
+		deadLetter: map[int64]string{},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var delivered []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Subscribe(ctx, SubscriberOptions{
+			Logger:     logger.NewLogger("test"),
+			Queue:      q,
+			Topic:      "orders",
+			ConsumerID: "app",
+			Handler: func(_ context.Context, msg *pubsub.NewMessage) error {
+				delivered = append(delivered, len(delivered))
+				switch string(msg.Data) {
+				case "fail":
+					return errors.New("simulated error")
+				case "cancel":
+					cancel()
+				}
+				return nil
+			},
+			BatchSize:           10,
+			PollInterval:        time.Second,
+			MaxDeliveryAttempts: 3,
+			Timeout:             time.Second,
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber did not stop")
+	}
+
+	// Messages after the one that canceled the subscription are released
+	assert.Len(t, delivered, 4)
+	assert.Equal(t, []int64{1, 5}, q.deleted)
+	assert.Equal(t, []int64{2}, q.retried)
+	require.Len(t, q.deadLetter, 2)
+	assert.Equal(t, "simulated error", q.deadLetter[3])
+	assert.Contains(t, q.deadLetter[4], "visibility timeout")
+	assert.Equal(t, []int64{6}, q.released)
+}
+
+func TestWakeups(t *testing.T) {
+	var w Wakeups
+	a, unregisterA := w.Register("a")
+	b, unregisterB := w.Register("b")
+	defer unregisterB()
+
+	// Notifications are coalesced
+	w.Notify("a")
+	w.Notify("a")
+	assert.Len(t, a, 1)
+	assert.Empty(t, b)
+	<-a
+
+	w.NotifyAll()
+	assert.Len(t, a, 1)
+	assert.Len(t, b, 1)
+	<-a
+
+	unregisterA()
+	w.Notify("a")
+	assert.Empty(t, a)
+}
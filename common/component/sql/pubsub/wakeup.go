@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlpubsub
+
+import (
+	"sync"
+)
+
+// Wakeups notifies the subscribers of a topic in this process when messages are published to it, so they don't have to wait for the poll interval.
+// The zero value is ready to use.
+type Wakeups struct {
+	lock     sync.Mutex
+	channels map[string]map[chan struct{}]struct{}
+}
+
+// Register returns the channel that receives the notifications for the topic, and a function that must be called to unregister it.
+func (w *Wakeups) Register(topic string) (<-chan struct{}, func()) {
+	// The channel is buffered so notifications are not lost while the subscriber is busy, and coalesced
+	ch := make(chan struct{}, 1)
+
+	w.lock.Lock()
+	if w.channels == nil {
+		w.channels = make(map[string]map[chan struct{}]struct{})
+	}
+	if w.channels[topic] == nil {
+		w.channels[topic] = make(map[chan struct{}]struct{})
+	}
+	w.channels[topic][ch] = struct{}{}
+	w.lock.Unlock()
+
+	return ch, func() {
+		w.lock.Lock()
+		delete(w.channels[topic], ch)
+		if len(w.channels[topic]) == 0 {
+			delete(w.channels, topic)
+		}
+		w.lock.Unlock()
+	}
+}
+
+// Notify wakes up the subscribers of the topic.
+func (w *Wakeups) Notify(topic string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for ch := range w.channels[topic] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// There's already a pending notification
+		}
+	}
+}
+
+// NotifyAll wakes up all the subscribers, for example after missing notifications.
+func (w *Wakeups) NotifyAll() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, channels := range w.channels {
+		for ch := range channels {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
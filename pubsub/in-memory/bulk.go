@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	commonutils "github.com/dapr/components-contrib/common/utils"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/ptr"
+	"github.com/dapr/kit/retry"
+)
+
+const (
+	defaultMaxBulkSubCount           = 100
+	defaultMaxBulkSubAwaitDurationMs = 1000
+)
+
+// BulkPublish publishes the entries to the topic; the metadata of each entry is added to the metadata of the request.
+// If the metadata of an entry is invalid, no entry is published.
+func (a *bus) BulkPublish(_ context.Context, req *pubsub.BulkPublishRequest) (pubsub.BulkPublishResponse, error) {
+	if a.closed.Load() {
+		return pubsub.BulkPublishResponse{}, errors.New("component is closed")
+	}
+
+	msgs := make([]*message, len(req.Entries))
+	for i, entry := range req.Entries {
+		md := maps.Clone(req.Metadata)
+		if md == nil {
+			md = make(map[string]string, len(entry.Metadata))
+		}
+		maps.Copy(md, entry.Metadata)
+		var contentType *string
+		if entry.ContentType != "" {
+			contentType = ptr.Of(entry.ContentType)
+		}
+
+		var err error
+		msgs[i], err = newMessage(entry.Event, md, contentType)
+		if err != nil {
+			err = fmt.Errorf("invalid entry %s: %w", entry.EntryId, err)
+			return pubsub.NewBulkPublishResponse(req.Entries, err), err
+		}
+	}
+
+	for _, msg := range msgs {
+		a.bus.Publish(req.Topic, msg)
+	}
+	return pubsub.BulkPublishResponse{}, nil
+}
+
+// BulkSubscribe delivers the messages published to the topic in batches of up to MaxMessagesCount messages.
+// A batch is delivered when it's full, or MaxAwaitDurationMs milliseconds after its first message was received.
+// The entries that fail are redelivered, in a new batch, like the messages of Subscribe.
+func (a *bus) BulkSubscribe(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.BulkHandler) error {
+	if a.closed.Load() {
+		return errors.New("component is closed")
+	}
+
+	maxCount := commonutils.GetIntValOrDefault(req.BulkSubscribeConfig.MaxMessagesCount, defaultMaxBulkSubCount)
+	maxAwait := time.Duration(commonutils.GetIntValOrDefault(req.BulkSubscribeConfig.MaxAwaitDurationMs, defaultMaxBulkSubAwaitDurationMs)) * time.Millisecond
+
+	msgCh := make(chan *message)
+	subCtx, err := a.subscribe(ctx, req, func(ctx context.Context, msg *message) {
+		select {
+		case msgCh <- msg:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		var (
+			batch []*message
+			// Set when the batch has its first message
+			timer   *time.Timer
+			timerCh <-chan time.Time
+		)
+		flush := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, timerCh = nil, nil
+			}
+			a.deliverBulk(subCtx, req.Topic, batch, handler)
+			batch = make([]*message, 0, maxCount)
+		}
+		for {
+			select {
+			case <-subCtx.Done():
+				if len(batch) > 0 {
+					a.deliveryFailed(subCtx, req.Topic, batch, context.Cause(subCtx))
+				}
+				return
+			case msg := <-msgCh:
+				batch = append(batch, msg)
+				if len(batch) >= maxCount {
+					flush()
+				} else if timer == nil {
+					timer = time.NewTimer(maxAwait)
+					timerCh = timer.C
+				}
+			case <-timerCh:
+				flush()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// deliverBulk invokes the handler with the messages until they're all processed or the maximum number of attempts is reached.
+// Each attempt includes only the messages that failed in the previous one and haven't expired yet.
+func (a *bus) deliverBulk(ctx context.Context, topic string, msgs []*message, handler pubsub.BulkHandler) {
+	type bulkEntry struct {
+		id  string
+		msg *message
+	}
+	pending := make([]bulkEntry, len(msgs))
+	for i, msg := range msgs {
+		pending[i] = bulkEntry{id: uuid.NewString(), msg: msg}
+	}
+
+	err := retry.NotifyRecover(func() error {
+		bulkMsg := &pubsub.BulkMessage{
+			Topic:   topic,
+			Entries: make([]pubsub.BulkMessageEntry, 0, len(pending)),
+		}
+		pending = slices.DeleteFunc(pending, func(e bulkEntry) bool {
+			return e.msg.expired()
+		})
+		if len(pending) == 0 {
+			return nil
+		}
+		for _, e := range pending {
+			entry := pubsub.BulkMessageEntry{
+				EntryId:  e.id,
+				Event:    e.msg.data,
+				Metadata: e.msg.metadata,
+			}
+			if e.msg.contentType != nil {
+				entry.ContentType = *e.msg.contentType
+			}
+			bulkMsg.Entries = append(bulkMsg.Entries, entry)
+		}
+
+		statuses, err := handler(ctx, bulkMsg)
+		if err != nil && statuses == nil {
+			return err
+		}
+		failed := make(map[string]error, len(statuses))
+		for _, status := range statuses {
+			if status.Error != nil {
+				failed[status.EntryId] = status.Error
+				err = status.Error
+			}
+		}
+		pending = slices.DeleteFunc(pending, func(e bulkEntry) bool {
+			_, ok := failed[e.id]
+			return !ok
+		})
+		if len(pending) > 0 {
+			return fmt.Errorf("failed to process %d message(s): %w", len(pending), err)
+		}
+		return nil
+	}, a.newBackOff(ctx), func(err error, d time.Duration) {
+		a.log.Warnf("Error processing bulk message on topic %s: %v. Retrying in %v...", topic, err, d)
+	}, func() {
+		a.log.Infof("Successfully processed bulk message on topic %s after it previously failed", topic)
+	})
+	if err != nil && len(pending) > 0 {
+		failed := make([]*message, len(pending))
+		for i, e := range pending {
+			failed[i] = e.msg
+		}
+		a.deliveryFailed(ctx, topic, failed, err)
+	}
+}
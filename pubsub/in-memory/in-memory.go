@@ -16,27 +16,59 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
 
 	"github.com/dapr/components-contrib/common/eventbus"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
+	kitmd "github.com/dapr/kit/metadata"
+	"github.com/dapr/kit/retry"
+)
+
+type inMemoryMetadata struct {
+	// Maximum number of times a message is delivered to a subscriber, before it's sent to the dead-letter topic or dropped.
+	MaxDeliveryAttempts int `mapstructure:"maxDeliveryAttempts"`
+	// Topic where the messages that couldn't be delivered are published.
+	DeadLetterTopic string `mapstructure:"deadLetterTopic"`
+	// How the messages are distributed between the subscribers of a consumer group: "roundRobin" or "leastBusy".
+	ConsumerGroupBalancing string `mapstructure:"consumerGroupBalancing"`
+	// Consumer group of the subscriptions that don't set one in their metadata.
+	// Note: the runtime sets the default value to the Dapr app ID.
+	ConsumerID string `mapstructure:"consumerID" mdignore:"true"`
+}
+
+const (
+	balancingRoundRobin = "roundRobin"
+	balancingLeastBusy  = "leastBusy"
 )
 
 type bus struct {
-	bus     eventbus.Bus
-	log     logger.Logger
-	closed  atomic.Bool
-	closeCh chan struct{}
-	wg      sync.WaitGroup
+	bus           eventbus.Bus
+	log           logger.Logger
+	metadata      inMemoryMetadata
+	backOffConfig retry.Config
+	closed        atomic.Bool
+	closeCh       chan struct{}
+	wg            sync.WaitGroup
+
+	// Protects topics and the subscribers of each topic
+	lock   sync.Mutex
+	topics map[string]*topicSubscribers
 }
 
 func New(logger logger.Logger) pubsub.PubSub {
 	return &bus{
 		log:     logger,
 		closeCh: make(chan struct{}),
+		topics:  make(map[string]*topicSubscribers),
 	}
 }
 
@@ -45,14 +77,45 @@ func (a *bus) Close() error {
 		close(a.closeCh)
 	}
 	a.wg.Wait()
+	// Wait for the messages being delivered
+	if a.bus != nil {
+		a.bus.WaitAsync()
+	}
 	return nil
 }
 
 func (a *bus) Features() []pubsub.Feature {
-	return []pubsub.Feature{pubsub.FeatureSubscribeWildcards}
+	return []pubsub.Feature{
+		pubsub.FeatureSubscribeWildcards,
+		pubsub.FeatureBulkPublish,
+		pubsub.FeatureMessageTTL,
+	}
 }
 
 func (a *bus) Init(_ context.Context, metadata pubsub.Metadata) error {
+	a.metadata = inMemoryMetadata{
+		MaxDeliveryAttempts:    1,
+		ConsumerGroupBalancing: balancingRoundRobin,
+	}
+	err := kitmd.DecodeMetadata(metadata.Properties, &a.metadata)
+	if err != nil {
+		return fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if a.metadata.MaxDeliveryAttempts < 1 {
+		return fmt.Errorf("invalid maxDeliveryAttempts %d: must be at least 1", a.metadata.MaxDeliveryAttempts)
+	}
+	switch a.metadata.ConsumerGroupBalancing {
+	case balancingRoundRobin, balancingLeastBusy:
+	default:
+		return fmt.Errorf("invalid consumerGroupBalancing '%s': must be '%s' or '%s'", a.metadata.ConsumerGroupBalancing, balancingRoundRobin, balancingLeastBusy)
+	}
+
+	// Default retry configuration is used if no backOff properties are set.
+	err = retry.DecodeConfigWithPrefix(&a.backOffConfig, metadata.Properties, "backOff")
+	if err != nil {
+		return err
+	}
+
 	a.bus = eventbus.New(true)
 
 	return nil
@@ -63,7 +126,11 @@ func (a *bus) Publish(_ context.Context, req *pubsub.PublishRequest) error {
 		return errors.New("component is closed")
 	}
 
-	a.bus.Publish(req.Topic, req.Data, req.Metadata)
+	msg, err := newMessage(req.Data, req.Metadata, req.ContentType)
+	if err != nil {
+		return err
+	}
+	a.bus.Publish(req.Topic, msg)
 
 	return nil
 }
@@ -73,36 +140,78 @@ func (a *bus) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, handle
 		return errors.New("component is closed")
 	}
 
-	loghandler := func(data []byte, md map[string]string) {
-		err := handler(ctx, &pubsub.NewMessage{Data: data, Topic: req.Topic, Metadata: md})
-		if err != nil {
-			a.log.Error(err)
+	_, err := a.subscribe(ctx, req, func(ctx context.Context, msg *message) {
+		a.deliver(ctx, req.Topic, msg, handler)
+	})
+	return err
+}
+
+// deliver invokes the handler until it succeeds or the maximum number of attempts is reached, waiting between attempts as configured by the backOff properties.
+// Messages that can't be delivered are published to the dead-letter topic, if any; messages that expire are dropped.
+// Delivery stops when the subscription is canceled: if that's due to a graceful shutdown, the message is not considered failed.
+func (a *bus) deliver(ctx context.Context, topic string, msg *message, handler pubsub.Handler) {
+	err := retry.NotifyRecover(func() error {
+		if msg.expired() {
+			return backoff.Permanent(errMessageExpired)
 		}
+		return handler(ctx, &pubsub.NewMessage{Data: msg.data, Topic: topic, Metadata: msg.metadata, ContentType: msg.contentType})
+	}, a.newBackOff(ctx), func(err error, d time.Duration) {
+		a.log.Warnf("Error processing message on topic %s: %v. Retrying in %v...", topic, err, d)
+	}, func() {
+		a.log.Infof("Successfully processed message on topic %s after it previously failed", topic)
+	})
+	if err != nil {
+		a.deliveryFailed(ctx, topic, []*message{msg}, err)
 	}
+}
 
-	err := a.bus.SubscribeAsync(req.Topic, loghandler, true)
-	if err != nil {
-		return err
+func (a *bus) newBackOff(ctx context.Context) backoff.BackOff {
+	return backoff.WithMaxRetries(a.backOffConfig.NewBackOffWithContext(ctx), uint64(a.metadata.MaxDeliveryAttempts-1)) //nolint:gosec
+}
+
+// deliveryFailed handles the messages that couldn't be delivered to a subscription to the topic.
+func (a *bus) deliveryFailed(ctx context.Context, topic string, msgs []*message, err error) {
+	if errors.Is(err, errMessageExpired) {
+		a.log.Debugf("Message on topic %s has expired, dropping it", topic)
+		return
 	}
 
-	// Unsubscribe when context is done
-	a.wg.Add(1)
-	go func() {
-		defer a.wg.Done()
-		select {
-		case <-ctx.Done():
-		case <-a.closeCh:
+	if ctx.Err() != nil {
+		if errors.Is(context.Cause(ctx), pubsub.ErrGracefulShutdown) {
+			a.log.Debugf("Component is shutting down: %d message(s) on topic %s will not be redelivered", len(msgs), topic)
+		} else {
+			a.log.Warnf("Subscription to topic %s canceled: %d message(s) will not be redelivered after error: %v", topic, len(msgs), err)
 		}
-		err := a.bus.Unsubscribe(req.Topic, loghandler)
-		if err != nil {
-			a.log.Errorf("error while unsubscribing from topic %s: %v", req.Topic, err)
-		}
-	}()
+		return
+	}
 
-	return nil
+	dlt := a.metadata.DeadLetterTopic
+	if dlt == "" {
+		a.log.Errorf("Failed to process %d message(s) on topic %s after %d attempts, dropping them: %v", len(msgs), topic, a.metadata.MaxDeliveryAttempts, err)
+		return
+	}
+	// A subscription that receives the messages of the dead-letter topic can't publish its own messages there
+	if matchesTopic(topic, dlt) {
+		a.log.Errorf("Failed to process %d message(s) on dead-letter topic %s after %d attempts, dropping them: %v", len(msgs), topic, a.metadata.MaxDeliveryAttempts, err)
+		return
+	}
+	a.log.Warnf("Failed to process %d message(s) on topic %s after %d attempts, publishing them to dead-letter topic %s: %v", len(msgs), topic, a.metadata.MaxDeliveryAttempts, dlt, err)
+	for _, msg := range msgs {
+		a.bus.Publish(dlt, msg)
+	}
+}
+
+// matchesTopic returns true if a subscription to the topic, which can have a wildcard suffix, receives the messages published to the other topic.
+func matchesTopic(subscription string, topic string) bool {
+	if prefix, ok := strings.CutSuffix(subscription, "*"); ok && prefix != topic {
+		return strings.HasPrefix(topic, prefix)
+	}
+	return subscription == topic
 }
 
 // GetComponentMetadata returns the metadata of the component.
 func (a *bus) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := inMemoryMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.PubSubType)
 	return
 }
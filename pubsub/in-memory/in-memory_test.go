@@ -15,12 +15,18 @@ package inmemory
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 func TestNewInMemoryBus(t *testing.T) {
@@ -100,6 +106,433 @@ func TestMessageMetadataPropagation(t *testing.T) {
 	}, <-metadataCh)
 }
 
+func TestInvalidMetadata(t *testing.T) {
+	bus := New(logger.NewLogger("test"))
+	err := bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"maxDeliveryAttempts": "0",
+	}}})
+	require.Error(t, err)
+}
+
+func TestRedelivery(t *testing.T) {
+	newBus := func(t *testing.T, properties map[string]string) pubsub.PubSub {
+		bus := New(logger.NewLogger("test"))
+		properties["backOffDuration"] = "10ms"
+		require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: properties}}))
+		t.Cleanup(func() {
+			bus.Close()
+		})
+		return bus
+	}
+	errFailed := errors.New("failed")
+
+	t.Run("messages are redelivered until they're processed", func(t *testing.T) {
+		bus := newBus(t, map[string]string{"maxDeliveryAttempts": "3"})
+
+		var attempts atomic.Int32
+		ch := make(chan []byte)
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			if attempts.Add(1) < 3 {
+				return errFailed
+			}
+			return publish(ch, msg)
+		}))
+
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo"}))
+		assert.Equal(t, "ABCD", string(<-ch))
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("messages that can't be processed are sent to the dead-letter topic", func(t *testing.T) {
+		bus := newBus(t, map[string]string{"maxDeliveryAttempts": "2", "deadLetterTopic": "poison"})
+
+		var attempts atomic.Int32
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			attempts.Add(1)
+			return errFailed
+		}))
+		ch := make(chan []byte)
+		metadataCh := make(chan map[string]string)
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "poison"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			return publishWithMetadata(ch, metadataCh, msg)
+		}))
+
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo", Metadata: map[string]string{"test": "test"}}))
+		assert.Equal(t, "ABCD", string(<-ch))
+		assert.Equal(t, map[string]string{"test": "test"}, <-metadataCh)
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("messages of the dead-letter topic are not sent to it again", func(t *testing.T) {
+		bus := newBus(t, map[string]string{"maxDeliveryAttempts": "2", "deadLetterTopic": "poison"})
+
+		var attempts atomic.Int32
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "po*"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			attempts.Add(1)
+			return errFailed
+		}))
+
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "poison"}))
+		assert.Eventually(t, func() bool {
+			return attempts.Load() == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, bus.Close())
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("redeliveries stop on graceful shutdown", func(t *testing.T) {
+		bus := New(logger.NewLogger("test"))
+		require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"maxDeliveryAttempts": "10",
+			"deadLetterTopic":     "poison",
+			"backOffDuration":     "1h",
+		}}}))
+
+		called := make(chan struct{}, 1)
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			called <- struct{}{}
+			return errFailed
+		}))
+		var deadLetters atomic.Int32
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "poison"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			deadLetters.Add(1)
+			return nil
+		}))
+
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo"}))
+		<-called
+
+		closed := make(chan struct{})
+		go func() {
+			bus.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("component was not closed")
+		}
+		assert.Zero(t, deadLetters.Load())
+	})
+}
+
+func TestFailingSubscriber(t *testing.T) {
+	bus := New(logger.NewLogger("test"))
+	require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"maxDeliveryAttempts": "100",
+		"backOffDuration":     "1s",
+	}}}))
+	defer bus.Close()
+
+	// The first message is retried for longer than the test runs
+	var attempts atomic.Int32
+	require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+		attempts.Add(1)
+		return errors.New("failed")
+	}))
+	var other, group atomic.Int32
+	require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+		other.Add(1)
+		return nil
+	}))
+	require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{
+		Topic:    "demo",
+		Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: "g1"},
+	}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+		group.Add(1)
+		return nil
+	}))
+
+	for range 5 {
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo"}))
+	}
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, int32(5), other.Load())
+		assert.Equal(c, int32(5), group.Load())
+	}, 900*time.Millisecond, 10*time.Millisecond)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestMessageTTL(t *testing.T) {
+	bus := New(logger.NewLogger("test"))
+	require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"maxDeliveryAttempts": "5",
+		"deadLetterTopic":     "poison",
+		"backOffDuration":     "50ms",
+	}}}))
+	t.Cleanup(func() {
+		bus.Close()
+	})
+	assert.Contains(t, bus.Features(), pubsub.FeatureMessageTTL)
+
+	err := bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo", Metadata: map[string]string{"ttlInSeconds": "invalid"}})
+	require.Error(t, err)
+
+	var attempts atomic.Int32
+	require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+		attempts.Add(1)
+		return errors.New("failed")
+	}))
+	var deadLetters atomic.Int32
+	require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "poison"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+		deadLetters.Add(1)
+		return nil
+	}))
+
+	// The message expires before it's redelivered
+	require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo", Metadata: map[string]string{"ttlInSeconds": "20ms"}}))
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Zero(t, deadLetters.Load())
+}
+
+func TestBulk(t *testing.T) {
+	newBus := func(t *testing.T) pubsub.PubSub {
+		bus := New(logger.NewLogger("test"))
+		require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"maxDeliveryAttempts": "3",
+			"backOffDuration":     "10ms",
+		}}}))
+		t.Cleanup(func() {
+			bus.Close()
+		})
+		return bus
+	}
+	entries := func(events ...string) []pubsub.BulkMessageEntry {
+		res := make([]pubsub.BulkMessageEntry, len(events))
+		for i, e := range events {
+			res[i] = pubsub.BulkMessageEntry{EntryId: e, Event: []byte(e), ContentType: "text/plain", Metadata: map[string]string{"entry": e}}
+		}
+		return res
+	}
+
+	t.Run("bulk publish", func(t *testing.T) {
+		bus := newBus(t)
+		assert.Contains(t, bus.Features(), pubsub.FeatureBulkPublish)
+
+		ch := make(chan *pubsub.NewMessage, 3)
+		require.NoError(t, bus.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "demo"}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			ch <- msg
+			return nil
+		}))
+
+		res, err := bus.(pubsub.BulkPublisher).BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+			Topic:    "demo",
+			Entries:  entries("1", "2", "3"),
+			Metadata: map[string]string{"test": "test"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, res.FailedEntries)
+		for _, e := range []string{"1", "2", "3"} {
+			msg := <-ch
+			assert.Equal(t, e, string(msg.Data))
+			assert.Equal(t, map[string]string{"test": "test", "entry": e}, msg.Metadata)
+			require.NotNil(t, msg.ContentType)
+			assert.Equal(t, "text/plain", *msg.ContentType)
+		}
+
+		invalid := entries("4")
+		invalid[0].Metadata["ttlInSeconds"] = "invalid"
+		res, err = bus.(pubsub.BulkPublisher).BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+			Topic:   "demo",
+			Entries: append(entries("5"), invalid...),
+		})
+		require.Error(t, err)
+		assert.Len(t, res.FailedEntries, 2)
+	})
+
+	t.Run("bulk subscribe", func(t *testing.T) {
+		bus := newBus(t)
+
+		ch := make(chan []string, 10)
+		var failed atomic.Bool
+		err := bus.(pubsub.BulkSubscriber).BulkSubscribe(t.Context(), pubsub.SubscribeRequest{
+			Topic: "demo",
+			BulkSubscribeConfig: pubsub.BulkSubscribeConfig{
+				MaxMessagesCount:   3,
+				MaxAwaitDurationMs: 100,
+			},
+		}, func(ctx context.Context, msg *pubsub.BulkMessage) ([]pubsub.BulkSubscribeResponseEntry, error) {
+			var (
+				events   []string
+				statuses []pubsub.BulkSubscribeResponseEntry
+			)
+			for _, e := range msg.Entries {
+				events = append(events, string(e.Event))
+				assert.Equal(t, "text/plain", e.ContentType)
+				assert.Equal(t, string(e.Event), e.Metadata["entry"])
+				status := pubsub.BulkSubscribeResponseEntry{EntryId: e.EntryId}
+				// Fail the first delivery of message 2
+				if string(e.Event) == "2" && failed.CompareAndSwap(false, true) {
+					status.Error = errors.New("failed")
+				}
+				statuses = append(statuses, status)
+			}
+			ch <- events
+			return statuses, nil
+		})
+		require.NoError(t, err)
+
+		// Batches are delivered when they're full
+		_, err = bus.(pubsub.BulkPublisher).BulkPublish(t.Context(), &pubsub.BulkPublishRequest{Topic: "demo", Entries: entries("1", "2", "3")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, <-ch)
+		// Only the failed entries are redelivered
+		assert.Equal(t, []string{"2"}, <-ch)
+
+		// Or when the maximum await duration has passed
+		require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("4"), Topic: "demo", ContentType: ptr.Of("text/plain"), Metadata: map[string]string{"entry": "4"}}))
+		select {
+		case events := <-ch:
+			assert.Equal(t, []string{"4"}, events)
+		case <-time.After(5 * time.Second):
+			t.Fatal("batch was not delivered")
+		}
+	})
+}
+
+func TestConsumerGroups(t *testing.T) {
+	newBus := func(t *testing.T, properties map[string]string) pubsub.PubSub {
+		bus := New(logger.NewLogger("test"))
+		require.NoError(t, bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: properties}}))
+		t.Cleanup(func() {
+			bus.Close()
+		})
+		return bus
+	}
+	subscribe := func(t *testing.T, ctx context.Context, bus pubsub.PubSub, consumerID string, counter *atomic.Int32) {
+		require.NoError(t, bus.Subscribe(ctx, pubsub.SubscribeRequest{
+			Topic:    "demo",
+			Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: consumerID},
+		}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			counter.Add(1)
+			return nil
+		}))
+	}
+	publishN := func(t *testing.T, bus pubsub.PubSub, n int) {
+		for range n {
+			require.NoError(t, bus.Publish(t.Context(), &pubsub.PublishRequest{Data: []byte("ABCD"), Topic: "demo"}))
+		}
+	}
+
+	t.Run("invalid balancing", func(t *testing.T) {
+		bus := New(logger.NewLogger("test"))
+		err := bus.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"consumerGroupBalancing": "random",
+		}}})
+		require.Error(t, err)
+	})
+
+	t.Run("messages are balanced between the members of a group", func(t *testing.T) {
+		bus := newBus(t, map[string]string{})
+
+		var g1a, g1b, g2, all atomic.Int32
+		subscribe(t, t.Context(), bus, "g1", &g1a)
+		subscribe(t, t.Context(), bus, "g1", &g1b)
+		subscribe(t, t.Context(), bus, "g2", &g2)
+		subscribe(t, t.Context(), bus, "", &all)
+
+		publishN(t, bus, 10)
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Equal(c, int32(5), g1a.Load())
+			assert.Equal(c, int32(5), g1b.Load())
+			assert.Equal(c, int32(10), g2.Load())
+			assert.Equal(c, int32(10), all.Load())
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("consumer ID of the component", func(t *testing.T) {
+		bus := newBus(t, map[string]string{pubsub.RuntimeConsumerIDKey: "app"})
+
+		var a, b atomic.Int32
+		subscribe(t, t.Context(), bus, "", &a)
+		subscribe(t, t.Context(), bus, "", &b)
+
+		publishN(t, bus, 10)
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Equal(c, int32(5), a.Load())
+			assert.Equal(c, int32(5), b.Load())
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("least busy balancing", func(t *testing.T) {
+		ps := newBus(t, map[string]string{"consumerGroupBalancing": "leastBusy"})
+
+		block := make(chan struct{})
+		defer close(block)
+		var busy, idle atomic.Int32
+		require.NoError(t, ps.Subscribe(t.Context(), pubsub.SubscribeRequest{
+			Topic:    "demo",
+			Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: "g1"},
+		}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			busy.Add(1)
+			<-block
+			return nil
+		}))
+		subscribe(t, t.Context(), ps, "g1", &idle)
+
+		// Wait until the idle member has processed each message, so it has no pending messages when the next one is published
+		b := ps.(*bus)
+		b.lock.Lock()
+		idleMember := b.topics["demo"].groups["g1"].members[1]
+		b.lock.Unlock()
+		for i := range 5 {
+			publishN(t, ps, 1)
+			require.Eventually(t, func() bool {
+				return busy.Load()+idle.Load() == int32(i+1) && idleMember.pending.Load() == 0
+			}, 5*time.Second, time.Millisecond)
+		}
+		assert.Equal(t, int32(1), busy.Load())
+		assert.Equal(t, int32(4), idle.Load())
+	})
+
+	t.Run("members leave the group when their subscription is canceled", func(t *testing.T) {
+		bus := newBus(t, map[string]string{})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		var a, b atomic.Int32
+		subscribe(t, ctx, bus, "g1", &a)
+		subscribe(t, t.Context(), bus, "g1", &b)
+		cancel()
+
+		publishN(t, bus, 10)
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Equal(c, int32(10), b.Load())
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Zero(t, a.Load())
+	})
+
+	t.Run("messages queued for a member that leaves the group are sent to the other members", func(t *testing.T) {
+		bus := newBus(t, map[string]string{})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		block := make(chan struct{})
+		var a, b atomic.Int32
+		require.NoError(t, bus.Subscribe(ctx, pubsub.SubscribeRequest{
+			Topic:    "demo",
+			Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: "g1"},
+		}, func(ctx context.Context, msg *pubsub.NewMessage) error {
+			a.Add(1)
+			<-block
+			return nil
+		}))
+		subscribe(t, t.Context(), bus, "g1", &b)
+
+		// The first member is processing a message, and has another one queued
+		publishN(t, bus, 4)
+		require.Eventually(t, func() bool {
+			return a.Load() == 1 && b.Load() == 2
+		}, 5*time.Second, time.Millisecond)
+		cancel()
+		close(block)
+
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Equal(c, int32(3), b.Load())
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(1), a.Load())
+	})
+}
+
 func publish(ch chan []byte, msg *pubsub.NewMessage) error {
 	go func() { ch <- msg.Data }()
 
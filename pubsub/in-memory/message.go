@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+var errMessageExpired = errors.New("message has expired")
+
+// message is a message published to the bus.
+type message struct {
+	data        []byte
+	metadata    map[string]string
+	contentType *string
+	// Zero if the message doesn't expire
+	expiration time.Time
+}
+
+// newMessage returns a message, which expires after the TTL set in the metadata, if any.
+func newMessage(data []byte, md map[string]string, contentType *string) (*message, error) {
+	msg := &message{
+		data:        data,
+		metadata:    md,
+		contentType: contentType,
+	}
+	ttl, ok, err := metadata.TryGetTTL(md)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		msg.expiration = time.Now().Add(ttl)
+	}
+	return msg, nil
+}
+
+func (m *message) expired() bool {
+	return !m.expiration.IsZero() && !time.Now().Before(m.expiration)
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dapr/components-contrib/pubsub"
+)
+
+// subscriber receives the messages of a subscription, which are processed in order by a worker.
+// Messages are queued without blocking, so a subscriber that is slow, or retrying a message, doesn't hold up the other subscribers of the topic.
+type subscriber struct {
+	ctx      context.Context
+	lock     sync.Mutex
+	queue    []*message
+	notifyCh chan struct{}
+	// Number of messages sent to the subscriber that haven't been processed yet
+	pending atomic.Int64
+}
+
+// send queues the message for the worker, returning false if the subscription was canceled.
+func (s *subscriber) send(msg *message) bool {
+	s.lock.Lock()
+	if s.ctx.Err() != nil {
+		s.lock.Unlock()
+		return false
+	}
+	s.pending.Add(1)
+	s.queue = append(s.queue, msg)
+	s.lock.Unlock()
+
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+		// A notification is already pending
+	}
+	return true
+}
+
+// next removes the first message from the queue, returning nil if it's empty or the subscription was canceled.
+func (s *subscriber) next() *message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.queue) == 0 || s.ctx.Err() != nil {
+		return nil
+	}
+	msg := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	return msg
+}
+
+// drain removes the messages that haven't been processed from the queue, after the subscription was canceled.
+func (s *subscriber) drain() []*message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	msgs := s.queue
+	s.queue = nil
+	s.pending.Add(-int64(len(msgs)))
+	return msgs
+}
+
+// consumerGroup is a set of subscribers that share a consumer ID: each message is received by only one of them.
+type consumerGroup struct {
+	members []*subscriber
+	next    int
+}
+
+// topicSubscribers are the subscribers of a topic, which can have a wildcard suffix.
+// Each message is received by all the subscribers without a consumer ID, and by one member of each consumer group.
+type topicSubscribers struct {
+	// Handler registered in the event bus
+	dispatcher  func(msg *message)
+	subscribers []*subscriber
+	groups      map[string]*consumerGroup
+}
+
+// subscribe invokes fn for each message published to the topic of the subscription, serially, until the context is done or the component is closed.
+// Subscriptions with a consumer ID, set in their metadata or in the metadata of the component, join the consumer group with that ID.
+// It returns the context passed to fn, which is canceled with ErrGracefulShutdown when the component is closed, to stop the redeliveries.
+func (a *bus) subscribe(ctx context.Context, req pubsub.SubscribeRequest, fn func(ctx context.Context, msg *message)) (context.Context, error) {
+	consumerID := req.Metadata[pubsub.RuntimeConsumerIDKey]
+	if consumerID == "" {
+		consumerID = a.metadata.ConsumerID
+	}
+
+	subCtx, cancel := context.WithCancelCause(ctx)
+	sub := &subscriber{
+		ctx:      subCtx,
+		notifyCh: make(chan struct{}, 1),
+	}
+	err := a.addSubscriber(req.Topic, consumerID, sub)
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+
+	a.wg.Add(2)
+	go func() {
+		defer a.wg.Done()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case <-sub.notifyCh:
+			}
+
+			for msg := sub.next(); msg != nil; msg = sub.next() {
+				if msg.expired() {
+					a.log.Debugf("Message on topic %s has expired, dropping it", req.Topic)
+				} else {
+					fn(subCtx, msg)
+				}
+				sub.pending.Add(-1)
+			}
+		}
+	}()
+
+	// Unsubscribe when context is done
+	go func() {
+		defer a.wg.Done()
+		defer cancel(nil)
+		select {
+		case <-ctx.Done():
+		case <-a.closeCh:
+			cancel(pubsub.ErrGracefulShutdown)
+		}
+		err := a.removeSubscriber(req.Topic, consumerID, sub)
+		if err != nil {
+			a.log.Errorf("error while unsubscribing from topic %s: %v", req.Topic, err)
+		}
+
+		// Messages queued for a member of a consumer group are sent to another member, unless the component is closed
+		msgs := sub.drain()
+		if consumerID != "" && !errors.Is(context.Cause(subCtx), pubsub.ErrGracefulShutdown) {
+			a.requeue(req.Topic, consumerID, msgs)
+		}
+	}()
+
+	return subCtx, nil
+}
+
+func (a *bus) addSubscriber(topic string, consumerID string, sub *subscriber) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	ts, ok := a.topics[topic]
+	if !ok {
+		ts = &topicSubscribers{
+			groups: make(map[string]*consumerGroup),
+		}
+		ts.dispatcher = func(msg *message) {
+			a.dispatch(ts, msg)
+		}
+		// Only one handler is registered for each topic, so it can be found to unsubscribe
+		err := a.bus.SubscribeAsync(topic, ts.dispatcher, true)
+		if err != nil {
+			return err
+		}
+		a.topics[topic] = ts
+	}
+
+	if consumerID == "" {
+		ts.subscribers = append(ts.subscribers, sub)
+		return nil
+	}
+	group, ok := ts.groups[consumerID]
+	if !ok {
+		group = &consumerGroup{}
+		ts.groups[consumerID] = group
+	}
+	group.members = append(group.members, sub)
+	return nil
+}
+
+func (a *bus) removeSubscriber(topic string, consumerID string, sub *subscriber) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	ts, ok := a.topics[topic]
+	if !ok {
+		return nil
+	}
+	if consumerID == "" {
+		ts.subscribers = slices.DeleteFunc(ts.subscribers, func(s *subscriber) bool {
+			return s == sub
+		})
+	} else if group, ok := ts.groups[consumerID]; ok {
+		group.members = slices.DeleteFunc(group.members, func(s *subscriber) bool {
+			return s == sub
+		})
+		if len(group.members) == 0 {
+			delete(ts.groups, consumerID)
+		}
+	}
+
+	if len(ts.subscribers) > 0 || len(ts.groups) > 0 {
+		return nil
+	}
+	delete(a.topics, topic)
+	return a.bus.Unsubscribe(topic, ts.dispatcher)
+}
+
+// dispatch queues the message for the subscribers of the topic, without waiting for them to process it.
+func (a *bus) dispatch(ts *topicSubscribers, msg *message) {
+	a.lock.Lock()
+	subscribers := slices.Clone(ts.subscribers)
+	groups := make([]*consumerGroup, 0, len(ts.groups))
+	for _, group := range ts.groups {
+		groups = append(groups, group)
+	}
+	a.lock.Unlock()
+
+	for _, sub := range subscribers {
+		sub.send(msg)
+	}
+	for _, group := range groups {
+		a.sendToGroup(group, msg)
+	}
+}
+
+// sendToGroup queues the message for one member of the consumer group.
+func (a *bus) sendToGroup(group *consumerGroup, msg *message) {
+	// Choose another member if the subscription of the chosen one was canceled
+	for {
+		a.lock.Lock()
+		member := a.pickMember(group)
+		a.lock.Unlock()
+		if member == nil || member.send(msg) {
+			return
+		}
+	}
+}
+
+// requeue sends the messages that a member of a consumer group hasn't processed to the other members.
+func (a *bus) requeue(topic string, consumerID string, msgs []*message) {
+	if len(msgs) == 0 {
+		return
+	}
+	a.lock.Lock()
+	var group *consumerGroup
+	if ts, ok := a.topics[topic]; ok {
+		group = ts.groups[consumerID]
+	}
+	a.lock.Unlock()
+	if group == nil {
+		a.log.Debugf("No members left in consumer group %s of topic %s, dropping %d message(s)", consumerID, topic, len(msgs))
+		return
+	}
+	for _, msg := range msgs {
+		a.sendToGroup(group, msg)
+	}
+}
+
+// pickMember returns the member of the group that receives the next message, or nil if the group has no active members.
+// It must be called with the lock held.
+func (a *bus) pickMember(group *consumerGroup) *subscriber {
+	// Members are taken in turn; with the least busy balancing, the turn is used to break ties
+	idx := -1
+	for i := range group.members {
+		j := (group.next + i) % len(group.members)
+		if group.members[j].ctx.Err() != nil {
+			// The subscription is being removed
+			continue
+		}
+		if idx == -1 {
+			idx = j
+			if a.metadata.ConsumerGroupBalancing != balancingLeastBusy {
+				break
+			}
+		} else if group.members[j].pending.Load() < group.members[idx].pending.Load() {
+			idx = j
+		}
+	}
+	if idx == -1 {
+		return nil
+	}
+	group.next = idx + 1
+	return group.members[idx]
+}
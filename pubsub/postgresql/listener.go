@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// listen wakes up the subscriptions of this process when messages are published by any process, until the component is closed.
+// Notifications are received on a dedicated connection; if it fails, it's opened again after the poll interval, and subscribers rely on polling in the meanwhile.
+func (p *PostgreSQL) listen() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.closeCh
+		cancel()
+	}()
+
+	for {
+		err := p.waitForNotifications(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		p.logger.Warnf("Error receiving notifications of published messages, retrying in %v: %v", p.metadata.PollInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.metadata.PollInterval):
+		}
+	}
+}
+
+func (p *PostgreSQL) waitForNotifications(ctx context.Context) error {
+	// The connection is removed from the pool, as it's used exclusively for the notifications
+	poolConn, err := p.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring connection: %w", err)
+	}
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	listenCtx, listenCancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	_, err = conn.Exec(listenCtx, "LISTEN "+pgx.Identifier{p.metadata.notifyChannelName()}.Sanitize())
+	listenCancel()
+	if err != nil {
+		return fmt.Errorf("error listening to channel: %w", err)
+	}
+
+	// Messages may have been published while the connection wasn't listening
+	p.wakeups.NotifyAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("error waiting for notification: %w", err)
+		}
+		p.wakeups.Notify(notification.Payload)
+	}
+}
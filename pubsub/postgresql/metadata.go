@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	pgauth "github.com/dapr/components-contrib/common/authentication/postgresql"
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	defaultTableName         = "pubsub"
+	defaultMetadataTableName = "dapr_metadata"
+	defaultTimeout           = 20 * time.Second // Default timeout for network requests
+	// Subscribers are woken up by notifications, so polling is only a fallback
+	defaultPollInterval = 10 * time.Second
+
+	// Maximum length of identifiers, including channel names, in PostgreSQL.
+	maxIdentifierLength = 63
+)
+
+type pgMetadata struct {
+	pgauth.PostgresAuthMetadata `mapstructure:",squash"`
+	sqlpubsub.Metadata          `mapstructure:",squash"`
+
+	// Name of the messages table; the subscriptions and dead-letter tables are named after it.
+	TableName         string        `mapstructure:"tableName"`         // Could be in the format "schema.table" or just "table"
+	MetadataTableName string        `mapstructure:"metadataTableName"` // Could be in the format "schema.table" or just "table"
+	Timeout           time.Duration `mapstructure:"timeout" mapstructurealiases:"timeoutInSeconds"`
+}
+
+func (m *pgMetadata) InitWithMetadata(props map[string]string) error {
+	// Reset the object
+	m.PostgresAuthMetadata.Reset()
+	m.Metadata.Reset(defaultPollInterval)
+	m.TableName = defaultTableName
+	m.MetadataTableName = defaultMetadataTableName
+	m.Timeout = defaultTimeout
+
+	err := kitmd.DecodeMetadata(props, &m)
+	if err != nil {
+		return err
+	}
+
+	// Validate and sanitize input
+	// Azure AD auth is supported for this component
+	err = m.PostgresAuthMetadata.InitWithMetadata(props, pgauth.InitWithMetadataOpts{
+		AzureADEnabled: true,
+	})
+	if err != nil {
+		return err
+	}
+	err = m.Metadata.Validate()
+	if err != nil {
+		return err
+	}
+	if m.Timeout < 1*time.Second {
+		return errors.New("invalid value for 'timeout': must be greater than 1s")
+	}
+
+	return nil
+}
+
+func (m *pgMetadata) subscriptionsTableName() string {
+	return m.TableName + "_subscriptions"
+}
+
+func (m *pgMetadata) deadLetterTableName() string {
+	return m.TableName + "_deadletter"
+}
+
+// notifyChannelName returns the name of the channel notified when messages are published.
+// Names that would be longer than the maximum length of identifiers are replaced with a hash of the table name.
+func (m *pgMetadata) notifyChannelName() string {
+	name := m.TableName + "_notify"
+	if len(name) > maxIdentifierLength {
+		h := sha256.Sum256([]byte(m.TableName))
+		name = "dapr_pubsub_" + hex.EncodeToString(h[:16])
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	pginterfaces "github.com/dapr/components-contrib/common/component/postgresql/interfaces"
+	commonsql "github.com/dapr/components-contrib/common/component/sql"
+	pgmigrations "github.com/dapr/components-contrib/common/component/sql/migrations/postgres"
+	"github.com/dapr/kit/logger"
+)
+
+// Perform the required migrations
+func performMigrations(ctx context.Context, db pginterfaces.PGXPoolConn, logger logger.Logger, md pgMetadata) error {
+	m := pgmigrations.Migrations{
+		DB:                db,
+		Logger:            logger,
+		MetadataTableName: md.MetadataTableName,
+		MetadataKey:       "migrations-pubsub-" + md.TableName,
+	}
+
+	return m.Perform(ctx, []commonsql.MigrationFn{
+		// Migration 0: create the messages, subscriptions and dead-letter tables
+		func(ctx context.Context) error {
+			logger.Infof("Creating pubsub tables '%s', '%s' and '%s'", md.TableName, md.subscriptionsTableName(), md.deadLetterTableName())
+			_, err := db.Exec(ctx,
+				fmt.Sprintf(`
+CREATE TABLE %[1]s (
+  id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  topic text NOT NULL,
+  consumer_id text NOT NULL,
+  data bytea,
+  metadata text,
+  content_type text,
+  attempts integer NOT NULL DEFAULT 0,
+  visible_at timestamp with time zone NOT NULL DEFAULT now(),
+  expiration_time timestamp with time zone,
+  created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON %[1]s (topic, consumer_id, visible_at);
+CREATE INDEX ON %[1]s (expiration_time) WHERE expiration_time IS NOT NULL;
+
+CREATE TABLE %[2]s (
+  topic text NOT NULL,
+  consumer_id text NOT NULL,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  PRIMARY KEY (topic, consumer_id)
+);
+
+CREATE TABLE %[3]s (
+  id bigint NOT NULL PRIMARY KEY,
+  topic text NOT NULL,
+  consumer_id text NOT NULL,
+  data bytea,
+  metadata text,
+  content_type text,
+  attempts integer NOT NULL,
+  created_at timestamp with time zone NOT NULL,
+  error text NOT NULL,
+  failed_at timestamp with time zone NOT NULL DEFAULT now()
+);
+`, md.TableName, md.subscriptionsTableName(), md.deadLetterTableName()),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create pubsub tables: %w", err)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	pginterfaces "github.com/dapr/components-contrib/common/component/postgresql/interfaces"
+	pgtransactions "github.com/dapr/components-contrib/common/component/postgresql/transactions"
+	commonsql "github.com/dapr/components-contrib/common/component/sql"
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+// PostgreSQL is a pubsub component that stores the messages in a PostgreSQL database.
+// Publishers notify the subscribers with NOTIFY, and subscribers lease messages with SKIP LOCKED; the database is also polled, in case notifications are missed.
+type PostgreSQL struct {
+	logger   logger.Logger
+	metadata pgMetadata
+	db       pginterfaces.PGXPoolConn
+	queue    *queue
+	gc       commonsql.GarbageCollector
+	wakeups  sqlpubsub.Wakeups
+
+	// The listener is started by the first subscription
+	listenerOnce sync.Once
+
+	closed  atomic.Bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewPostgreSQL returns a new PostgreSQL pubsub component.
+func NewPostgreSQL(logger logger.Logger) pubsub.PubSub {
+	return &PostgreSQL{
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Init connects to the database and creates the tables.
+func (p *PostgreSQL) Init(ctx context.Context, md pubsub.Metadata) error {
+	err := p.metadata.InitWithMetadata(md.Properties)
+	if err != nil {
+		return fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	config, err := p.metadata.GetPgxPoolConfig()
+	if err != nil {
+		return err
+	}
+
+	connCtx, connCancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	p.db, err = pgxpool.NewWithConfig(connCtx, config)
+	connCancel()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	pingCtx, pingCancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	err = p.db.Ping(pingCtx)
+	pingCancel()
+	if err != nil {
+		return fmt.Errorf("failed to ping the database: %w", err)
+	}
+
+	err = performMigrations(ctx, p.db, p.logger, p.metadata)
+	if err != nil {
+		return err
+	}
+
+	p.queue = &queue{
+		db:       p.db,
+		metadata: &p.metadata,
+	}
+
+	p.gc, err = commonsql.ScheduleGarbageCollector(commonsql.GCOptions{
+		Logger: p.logger,
+		UpdateLastCleanupQuery: func(arg any) (string, any) {
+			return fmt.Sprintf(
+				`INSERT INTO %[1]s (key, value)
+				VALUES ('last-cleanup-pubsub-%[2]s', now()::text)
+				ON CONFLICT (key)
+				DO UPDATE SET value = now()::text
+					WHERE (EXTRACT('epoch' FROM now() - %[1]s.value::timestamp with time zone) * 1000)::bigint > $1`,
+				p.metadata.MetadataTableName, p.metadata.TableName,
+			), arg
+		},
+		DeleteExpiredValuesQuery: fmt.Sprintf(
+			`DELETE FROM %s WHERE expiration_time IS NOT NULL AND expiration_time <= now()`,
+			p.metadata.TableName,
+		),
+		CleanupInterval: p.metadata.CleanupInterval,
+		DB:              commonsql.AdaptPgxConn(p.db),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Features returns the features supported by the component.
+func (p *PostgreSQL) Features() []pubsub.Feature {
+	return []pubsub.Feature{
+		pubsub.FeatureMessageTTL,
+		pubsub.FeatureBulkPublish,
+	}
+}
+
+// Publish saves a copy of the message for each consumer group that subscribed to the topic, and notifies the subscribers.
+// Messages published before a consumer group subscribed to the topic for the first time are not delivered to it.
+func (p *PostgreSQL) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
+	if p.closed.Load() {
+		return errors.New("component is closed")
+	}
+
+	msg, err := sqlpubsub.PublishRequestMessage(req)
+	if err != nil {
+		return err
+	}
+
+	return p.publish(ctx, req.Topic, []sqlpubsub.NewMessage{msg})
+}
+
+// BulkPublish saves the messages in a single transaction, so either all of them or none are published.
+func (p *PostgreSQL) BulkPublish(ctx context.Context, req *pubsub.BulkPublishRequest) (pubsub.BulkPublishResponse, error) {
+	if p.closed.Load() {
+		return pubsub.BulkPublishResponse{}, errors.New("component is closed")
+	}
+
+	msgs, err := sqlpubsub.BulkPublishRequestMessages(req)
+	if err != nil {
+		return pubsub.NewBulkPublishResponse(req.Entries, err), err
+	}
+
+	err = p.publish(ctx, req.Topic, msgs)
+	if err != nil {
+		return pubsub.NewBulkPublishResponse(req.Entries, err), err
+	}
+	return pubsub.BulkPublishResponse{}, nil
+}
+
+func (p *PostgreSQL) publish(ctx context.Context, topic string, msgs []sqlpubsub.NewMessage) error {
+	// The notification is sent when the transaction is committed
+	_, err := pgtransactions.ExecuteInTransaction(ctx, p.logger, p.db, p.metadata.Timeout, func(ctx context.Context, tx pgx.Tx) (struct{}, error) {
+		for _, msg := range msgs {
+			err := p.queue.insert(ctx, tx, topic, msg)
+			if err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, p.queue.notify(ctx, tx, topic)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.wakeups.Notify(topic)
+	return nil
+}
+
+// Subscribe delivers the messages of the topic to the handler, until the context is canceled or the component is closed.
+// Each message is delivered to one subscriber of each consumer group, set with the "consumerID" metadata.
+func (p *PostgreSQL) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	if p.closed.Load() {
+		return errors.New("component is closed")
+	}
+
+	consumerID, err := p.metadata.SubscriptionConsumerID(req)
+	if err != nil {
+		return err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	defer cancel()
+	_, err = p.db.Exec(queryCtx,
+		fmt.Sprintf(`INSERT INTO %s (topic, consumer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, p.metadata.subscriptionsTableName()),
+		req.Topic, consumerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+
+	p.listenerOnce.Do(func() {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.listen()
+		}()
+	})
+
+	wakeup, unregister := p.wakeups.Register(req.Topic)
+	subCtx, subCancel := context.WithCancelCause(ctx)
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		select {
+		case <-subCtx.Done():
+		case <-p.closeCh:
+			subCancel(pubsub.ErrGracefulShutdown)
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		defer subCancel(nil)
+		defer unregister()
+		sqlpubsub.Subscribe(subCtx, sqlpubsub.SubscriberOptions{
+			Logger:              p.logger,
+			Queue:               p.queue,
+			Topic:               req.Topic,
+			ConsumerID:          consumerID,
+			Handler:             handler,
+			BatchSize:           p.metadata.FetchBatchSize,
+			PollInterval:        p.metadata.PollInterval,
+			RetryInterval:       p.metadata.RetryInterval,
+			MaxDeliveryAttempts: p.metadata.MaxDeliveryAttempts,
+			Wakeup:              wakeup,
+			Timeout:             p.metadata.Timeout,
+		})
+	}()
+
+	return nil
+}
+
+// Close stops the subscriptions and closes the connection to the database.
+func (p *PostgreSQL) Close() error {
+	if !p.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(p.closeCh)
+	p.wg.Wait()
+
+	var err error
+	if p.gc != nil {
+		err = p.gc.Close()
+	}
+	if p.db != nil {
+		p.db.Close()
+	}
+	return err
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (p *PostgreSQL) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := pgMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.PubSubType)
+	return
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	connectionStringEnvKey = "DAPR_TEST_POSTGRES_CONNSTRING" // Environment variable containing the connection string
+)
+
+func TestPostgreSQLIntegration(t *testing.T) {
+	connectionString := os.Getenv(connectionStringEnvKey)
+	if connectionString == "" {
+		t.Skipf("PostgreSQL pubsub integration tests skipped. To enable define the connection string using environment variable '%s' (example 'export %s=\"host=localhost user=postgres password=example port=5432 connect_timeout=10 database=dapr_test\")", connectionStringEnvKey, connectionStringEnvKey)
+	}
+
+	// Use a different table for each run
+	tableName := "pubsub_" + uuid.NewString()[:8]
+	newPubSub := func(t *testing.T) *PostgreSQL {
+		t.Helper()
+		p := NewPostgreSQL(logger.NewLogger("test")).(*PostgreSQL)
+		err := p.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"connectionString":    connectionString,
+			"tableName":           tableName,
+			"maxDeliveryAttempts": "2",
+			"retryInterval":       "0",
+		}}})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, p.Close())
+		})
+		return p
+	}
+	publisher := newPubSub(t)
+	subscriber := newPubSub(t)
+	t.Cleanup(func() {
+		for _, table := range []string{tableName, tableName + "_subscriptions", tableName + "_deadletter"} {
+			_, err := publisher.db.Exec(context.Background(), "DROP TABLE IF EXISTS "+table)
+			assert.NoError(t, err)
+		}
+		_, err := publisher.db.Exec(context.Background(), "DELETE FROM dapr_metadata WHERE key LIKE $1", "%"+tableName)
+		assert.NoError(t, err)
+	})
+
+	var (
+		lock     sync.Mutex
+		received []string
+	)
+	err := subscriber.Subscribe(t.Context(), pubsub.SubscribeRequest{
+		Topic:    "orders",
+		Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: "app"},
+	}, func(_ context.Context, msg *pubsub.NewMessage) error {
+		if string(msg.Data) == "poison" {
+			return errors.New("simulated error")
+		}
+		lock.Lock()
+		received = append(received, string(msg.Data))
+		lock.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Messages published by another process are received before the poll interval, thanks to the notifications
+	start := time.Now()
+	for i := range 3 {
+		require.NoError(t, publisher.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte(fmt.Sprintf("msg%d", i))}))
+	}
+	_, err = publisher.BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+		Topic: "orders",
+		Entries: []pubsub.BulkMessageEntry{
+			{EntryId: "1", Event: []byte("poison")},
+			{EntryId: "2", Event: []byte("msg3")},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(c, []string{"msg0", "msg1", "msg2", "msg3"}, received)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Less(t, time.Since(start), subscriber.metadata.PollInterval)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		var data []byte
+		err := publisher.db.QueryRow(t.Context(), "SELECT data FROM "+tableName+"_deadletter").Scan(&data)
+		assert.NoError(c, err)
+		assert.Equal(c, "poison", string(data))
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+func TestMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var md pgMetadata
+		require.NoError(t, md.InitWithMetadata(map[string]string{"connectionString": "postgres://localhost/dapr"}))
+		assert.Equal(t, "pubsub", md.TableName)
+		assert.Equal(t, "pubsub_subscriptions", md.subscriptionsTableName())
+		assert.Equal(t, "pubsub_deadletter", md.deadLetterTableName())
+		assert.Equal(t, "dapr_metadata", md.MetadataTableName)
+		assert.Equal(t, 30*time.Second, md.VisibilityTimeout)
+		assert.Equal(t, 5, md.MaxDeliveryAttempts)
+		assert.Equal(t, 10*time.Second, md.PollInterval)
+		assert.Equal(t, 20*time.Second, md.Timeout)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for k, v := range map[string]string{
+			"visibilityTimeout":   "10ms",
+			"maxDeliveryAttempts": "0",
+			"timeout":             "10ms",
+		} {
+			var md pgMetadata
+			err := md.InitWithMetadata(map[string]string{"connectionString": "postgres://localhost/dapr", k: v})
+			require.Error(t, err, k)
+		}
+	})
+
+	t.Run("connection string is required", func(t *testing.T) {
+		var md pgMetadata
+		require.Error(t, md.InitWithMetadata(map[string]string{}))
+	})
+
+	t.Run("notification channel name", func(t *testing.T) {
+		md := pgMetadata{TableName: "messages"}
+		assert.Equal(t, "messages_notify", md.notifyChannelName())
+
+		md.TableName = strings.Repeat("a", 60)
+		name := md.notifyChannelName()
+		assert.LessOrEqual(t, len(name), maxIdentifierLength)
+		assert.True(t, strings.HasPrefix(name, "dapr_pubsub_"))
+	})
+}
+
+func mockPubSub(t *testing.T) (*PostgreSQL, pgxmock.PgxPoolIface) {
+	t.Helper()
+	db, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	p := NewPostgreSQL(logger.NewLogger("test")).(*PostgreSQL)
+	require.NoError(t, p.metadata.InitWithMetadata(map[string]string{"connectionString": "postgres://localhost/dapr"}))
+	p.db = db
+	p.queue = &queue{
+		db:       db,
+		metadata: &p.metadata,
+	}
+	return p, db
+}
+
+func TestPublish(t *testing.T) {
+	p, db := mockPubSub(t)
+
+	t.Run("messages are inserted and notified in a transaction", func(t *testing.T) {
+		db.ExpectBegin()
+		db.ExpectExec("INSERT INTO pubsub").
+			WithArgs("orders", []byte("a"), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		db.ExpectExec("INSERT INTO pubsub").
+			WithArgs("orders", []byte("b"), pgxmock.AnyArg(), pgxmock.AnyArg(), ptr.Of(int64(10000))).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		db.ExpectExec("SELECT pg_notify").
+			WithArgs("pubsub_notify", "orders").
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		db.ExpectCommit()
+
+		res, err := p.BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+			Topic: "orders",
+			Entries: []pubsub.BulkMessageEntry{
+				{EntryId: "1", Event: []byte("a")},
+				{EntryId: "2", Event: []byte("b"), Metadata: map[string]string{"ttlInSeconds": "10"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, res.FailedEntries)
+		require.NoError(t, db.ExpectationsWereMet())
+	})
+
+	t.Run("errors roll back the transaction", func(t *testing.T) {
+		db.ExpectBegin()
+		db.ExpectExec("INSERT INTO pubsub").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(errors.New("simulated error"))
+		db.ExpectRollback()
+
+		err := p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte("a")})
+		require.ErrorContains(t, err, "simulated error")
+		require.NoError(t, db.ExpectationsWereMet())
+	})
+}
+
+func TestLease(t *testing.T) {
+	p, db := mockPubSub(t)
+
+	db.ExpectQuery("UPDATE pubsub").
+		WithArgs(int64(30000), "orders", "app", 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "data", "metadata", "content_type", "attempts"}).
+			AddRow(int64(2), []byte("b"), (*string)(nil), (*string)(nil), 1).
+			AddRow(int64(1), []byte("a"), ptr.Of(`{"key":"value"}`), ptr.Of("text/plain"), 2),
+		)
+
+	msgs, err := p.queue.Lease(t.Context(), "orders", "app", 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, int64(1), msgs[0].ID)
+	assert.Equal(t, map[string]string{"key": "value"}, msgs[0].Metadata)
+	assert.Equal(t, "text/plain", *msgs[0].ContentType)
+	assert.Equal(t, 2, msgs[0].Attempts)
+	assert.Equal(t, int64(2), msgs[1].ID)
+	assert.Nil(t, msgs[1].Metadata)
+	assert.Nil(t, msgs[1].ContentType)
+	require.NoError(t, db.ExpectationsWereMet())
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgresql
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	pginterfaces "github.com/dapr/components-contrib/common/component/postgresql/interfaces"
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	"github.com/dapr/kit/ptr"
+)
+
+// queue implements sqlpubsub.Queue.
+// Messages are leased with SKIP LOCKED, so concurrent subscribers don't wait for each other.
+type queue struct {
+	db       pginterfaces.PGXPoolConn
+	metadata *pgMetadata
+}
+
+// insert saves a copy of the message for each consumer group that subscribed to the topic.
+func (q *queue) insert(ctx context.Context, db pginterfaces.DBQuerier, topic string, msg sqlpubsub.NewMessage) error {
+	var ttl *int64
+	if msg.TTL != nil {
+		ttl = ptr.Of(msg.TTL.Milliseconds())
+	}
+	var md *string
+	if msg.Metadata != nil {
+		md = ptr.Of(string(msg.Metadata))
+	}
+	_, err := db.Exec(ctx,
+		fmt.Sprintf(`INSERT INTO %[1]s
+				(topic, consumer_id, data, metadata, content_type, expiration_time)
+			SELECT $1, consumer_id, $2, $3, $4, now() + $5::bigint * interval '1 millisecond'
+			FROM %[2]s
+			WHERE topic = $1`,
+			q.metadata.TableName, q.metadata.subscriptionsTableName(),
+		),
+		topic, msg.Data, md, msg.ContentType, ttl,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// notify wakes up the subscribers of the topic in all processes, when the transaction is committed.
+func (q *queue) notify(ctx context.Context, db pginterfaces.DBQuerier, topic string) error {
+	_, err := db.Exec(ctx, "SELECT pg_notify($1, $2)", q.metadata.notifyChannelName(), topic)
+	if err != nil {
+		return fmt.Errorf("failed to notify subscribers: %w", err)
+	}
+	return nil
+}
+
+func (q *queue) Lease(ctx context.Context, topic string, consumerID string, n int) ([]sqlpubsub.Message, error) {
+	rows, err := q.db.Query(ctx,
+		fmt.Sprintf(`UPDATE %[1]s
+			SET attempts = attempts + 1, visible_at = now() + $1::bigint * interval '1 millisecond'
+			WHERE id IN (
+				SELECT id FROM %[1]s
+				WHERE
+					topic = $2
+					AND consumer_id = $3
+					AND visible_at <= now()
+					AND (expiration_time IS NULL OR expiration_time > now())
+				ORDER BY id
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, data, metadata, content_type, attempts`,
+			q.metadata.TableName,
+		),
+		q.metadata.VisibilityTimeout.Milliseconds(), topic, consumerID, n,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []sqlpubsub.Message
+	for rows.Next() {
+		var (
+			msg sqlpubsub.Message
+			md  *string
+		)
+		err = rows.Scan(&msg.ID, &msg.Data, &md, &msg.ContentType, &msg.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		msg.Topic = topic
+		if md != nil {
+			msg.Metadata, err = sqlpubsub.DecodeMetadata([]byte(*md))
+			if err != nil {
+				return nil, err
+			}
+		}
+		msgs = append(msgs, msg)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// The order of the rows returned by RETURNING is not defined
+	slices.SortFunc(msgs, func(a, b sqlpubsub.Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return msgs, nil
+}
+
+func (q *queue) Delete(ctx context.Context, msg sqlpubsub.Message) error {
+	_, err := q.db.Exec(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND attempts = $2`, q.metadata.TableName),
+		msg.ID, msg.Attempts,
+	)
+	return err
+}
+
+func (q *queue) Retry(ctx context.Context, msg sqlpubsub.Message, delay time.Duration) error {
+	_, err := q.db.Exec(ctx,
+		fmt.Sprintf(`UPDATE %s SET visible_at = now() + $1::bigint * interval '1 millisecond' WHERE id = $2 AND attempts = $3`, q.metadata.TableName),
+		delay.Milliseconds(), msg.ID, msg.Attempts,
+	)
+	return err
+}
+
+func (q *queue) DeadLetter(ctx context.Context, msg sqlpubsub.Message, reason string) error {
+	// The message is moved atomically by a single statement
+	_, err := q.db.Exec(ctx,
+		fmt.Sprintf(`WITH deleted AS (
+				DELETE FROM %[1]s WHERE id = $1 AND attempts = $2
+				RETURNING id, topic, consumer_id, data, metadata, content_type, attempts, created_at
+			)
+			INSERT INTO %[2]s
+				(id, topic, consumer_id, data, metadata, content_type, attempts, created_at, error)
+			SELECT id, topic, consumer_id, data, metadata, content_type, attempts, created_at, $3
+			FROM deleted`,
+			q.metadata.TableName, q.metadata.deadLetterTableName(),
+		),
+		msg.ID, msg.Attempts, reason,
+	)
+	return err
+}
+
+func (q *queue) Release(ctx context.Context, msgs []sqlpubsub.Message) error {
+	ids := make([]int64, len(msgs))
+	attempts := make([]int32, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+		attempts[i] = int32(msg.Attempts) //nolint:gosec
+	}
+	_, err := q.db.Exec(ctx,
+		fmt.Sprintf(`UPDATE %s AS t
+			SET attempts = t.attempts - 1, visible_at = now()
+			FROM unnest($1::bigint[], $2::integer[]) AS l (id, attempts)
+			WHERE t.id = l.id AND t.attempts = l.attempts`,
+			q.metadata.TableName,
+		),
+		ids, attempts,
+	)
+	return err
+}
@@ -327,6 +327,10 @@ func (s *stubRedisClient) TxPipeline() commonredis.RedisPipeliner {
 	return &stubRedisPipeliner{}
 }
 
+func (s *stubRedisClient) PSubscribe(context.Context, *commonredis.PSubscribeArgs) error {
+	return nil
+}
+
 func (s *stubRedisClient) TTLResult(context.Context, string) (time.Duration, error) {
 	return 0, nil
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	authSqlite "github.com/dapr/components-contrib/common/authentication/sqlite"
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	defaultTableName         = "pubsub"
+	defaultMetadataTableName = "pubsub_metadata"
+	defaultPollInterval      = time.Second
+)
+
+type sqliteMetadata struct {
+	authSqlite.SqliteAuthMetadata `mapstructure:",squash"`
+	sqlpubsub.Metadata            `mapstructure:",squash"`
+
+	// Name of the messages table; the subscriptions and dead-letter tables are named after it.
+	TableName         string `mapstructure:"tableName"`
+	MetadataTableName string `mapstructure:"metadataTableName"`
+}
+
+func (m *sqliteMetadata) InitWithMetadata(props map[string]string) error {
+	// Reset the object
+	m.SqliteAuthMetadata.Reset()
+	m.Metadata.Reset(defaultPollInterval)
+	m.TableName = defaultTableName
+	m.MetadataTableName = defaultMetadataTableName
+
+	err := kitmd.DecodeMetadata(props, &m)
+	if err != nil {
+		return err
+	}
+
+	// Validate and sanitize input
+	err = m.SqliteAuthMetadata.Validate()
+	if err != nil {
+		return err
+	}
+	err = m.Metadata.Validate()
+	if err != nil {
+		return err
+	}
+	if !authSqlite.ValidIdentifier(m.TableName) {
+		return fmt.Errorf("invalid identifier: %s", m.TableName)
+	}
+	if !authSqlite.ValidIdentifier(m.MetadataTableName) {
+		return fmt.Errorf("invalid identifier: %s", m.MetadataTableName)
+	}
+
+	return nil
+}
+
+func (m *sqliteMetadata) subscriptionsTableName() string {
+	return m.TableName + "_subscriptions"
+}
+
+func (m *sqliteMetadata) deadLetterTableName() string {
+	return m.TableName + "_deadletter"
+}
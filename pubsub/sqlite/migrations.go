@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	commonsql "github.com/dapr/components-contrib/common/component/sql"
+	sqlitemigrations "github.com/dapr/components-contrib/common/component/sql/migrations/sqlite"
+	"github.com/dapr/kit/logger"
+)
+
+// Perform the required migrations
+func performMigrations(ctx context.Context, db *sql.DB, logger logger.Logger, md sqliteMetadata) error {
+	m := sqlitemigrations.Migrations{
+		Pool:              db,
+		Logger:            logger,
+		MetadataTableName: md.MetadataTableName,
+		MetadataKey:       "migrations",
+	}
+
+	return m.Perform(ctx, []commonsql.MigrationFn{
+		// Migration 0: create the messages, subscriptions and dead-letter tables
+		// Times are stored as milliseconds since the Unix epoch
+		func(ctx context.Context) error {
+			logger.Infof("Creating pubsub tables '%s', '%s' and '%s'", md.TableName, md.subscriptionsTableName(), md.deadLetterTableName())
+			_, err := m.GetConn().ExecContext(
+				ctx,
+				fmt.Sprintf(
+					`CREATE TABLE %[1]s (
+							id INTEGER PRIMARY KEY AUTOINCREMENT,
+							topic TEXT NOT NULL,
+							consumer_id TEXT NOT NULL,
+							data BLOB,
+							metadata TEXT,
+							content_type TEXT,
+							attempts INTEGER NOT NULL DEFAULT 0,
+							visible_at INTEGER NOT NULL,
+							expiration_time INTEGER DEFAULT NULL,
+							created_at INTEGER NOT NULL
+						);
+					CREATE INDEX %[1]s_lease ON %[1]s (topic, consumer_id, visible_at);
+					CREATE INDEX %[1]s_expiration ON %[1]s (expiration_time) WHERE expiration_time IS NOT NULL;
+					CREATE TABLE %[2]s (
+							topic TEXT NOT NULL,
+							consumer_id TEXT NOT NULL,
+							created_at INTEGER NOT NULL,
+							PRIMARY KEY (topic, consumer_id)
+						);
+					CREATE TABLE %[3]s (
+							id INTEGER PRIMARY KEY,
+							topic TEXT NOT NULL,
+							consumer_id TEXT NOT NULL,
+							data BLOB,
+							metadata TEXT,
+							content_type TEXT,
+							attempts INTEGER NOT NULL,
+							created_at INTEGER NOT NULL,
+							error TEXT NOT NULL,
+							failed_at INTEGER NOT NULL
+						);`,
+					md.TableName, md.subscriptionsTableName(), md.deadLetterTableName(),
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create pubsub tables: %w", err)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	sqltransactions "github.com/dapr/components-contrib/common/component/sql/transactions"
+	"github.com/dapr/kit/logger"
+)
+
+// Current time, in milliseconds since the Unix epoch.
+const nowExpr = `CAST(unixepoch('now', 'subsec') * 1000 AS INTEGER)`
+
+// Interface for both sql.DB and sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queue implements sqlpubsub.Queue.
+type queue struct {
+	db       *sql.DB
+	logger   logger.Logger
+	metadata *sqliteMetadata
+}
+
+// insert saves a copy of the message for each consumer group that subscribed to the topic.
+func (q *queue) insert(ctx context.Context, db querier, topic string, msg sqlpubsub.NewMessage) error {
+	var ttl any
+	if msg.TTL != nil {
+		ttl = msg.TTL.Milliseconds()
+	}
+	_, err := db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %[1]s
+				(topic, consumer_id, data, metadata, content_type, visible_at, expiration_time, created_at)
+			SELECT ?, consumer_id, ?, ?, ?, %[3]s, %[3]s + ?, %[3]s
+			FROM %[2]s
+			WHERE topic = ?`,
+			q.metadata.TableName, q.metadata.subscriptionsTableName(), nowExpr,
+		),
+		topic, msg.Data, nullableString(msg.Metadata), msg.ContentType, ttl, topic,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+func (q *queue) Lease(ctx context.Context, topic string, consumerID string, n int) ([]sqlpubsub.Message, error) {
+	rows, err := q.db.QueryContext(ctx,
+		fmt.Sprintf(`UPDATE %[1]s
+			SET attempts = attempts + 1, visible_at = %[2]s + ?
+			WHERE id IN (
+				SELECT id FROM %[1]s
+				WHERE
+					topic = ?
+					AND consumer_id = ?
+					AND visible_at <= %[2]s
+					AND (expiration_time IS NULL OR expiration_time > %[2]s)
+				ORDER BY id
+				LIMIT ?
+			)
+			RETURNING id, data, metadata, content_type, attempts`,
+			q.metadata.TableName, nowExpr,
+		),
+		q.metadata.VisibilityTimeout.Milliseconds(), topic, consumerID, n,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []sqlpubsub.Message
+	for rows.Next() {
+		var (
+			msg         sqlpubsub.Message
+			md          sql.NullString
+			contentType sql.NullString
+		)
+		err = rows.Scan(&msg.ID, &msg.Data, &md, &contentType, &msg.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		msg.Topic = topic
+		msg.Metadata, err = sqlpubsub.DecodeMetadata([]byte(md.String))
+		if err != nil {
+			return nil, err
+		}
+		if contentType.Valid {
+			msg.ContentType = &contentType.String
+		}
+		msgs = append(msgs, msg)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// The order of the rows returned by RETURNING is not defined
+	slices.SortFunc(msgs, func(a, b sqlpubsub.Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return msgs, nil
+}
+
+func (q *queue) Delete(ctx context.Context, msg sqlpubsub.Message) error {
+	_, err := q.db.ExecContext(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND attempts = ?`, q.metadata.TableName),
+		msg.ID, msg.Attempts,
+	)
+	return err
+}
+
+func (q *queue) Retry(ctx context.Context, msg sqlpubsub.Message, delay time.Duration) error {
+	_, err := q.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET visible_at = %s + ? WHERE id = ? AND attempts = ?`, q.metadata.TableName, nowExpr),
+		delay.Milliseconds(), msg.ID, msg.Attempts,
+	)
+	return err
+}
+
+func (q *queue) DeadLetter(ctx context.Context, msg sqlpubsub.Message, reason string) error {
+	_, err := sqltransactions.ExecuteInTransaction(ctx, q.logger, q.db, func(ctx context.Context, tx *sql.Tx) (struct{}, error) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`INSERT INTO %[2]s
+					(id, topic, consumer_id, data, metadata, content_type, attempts, created_at, error, failed_at)
+				SELECT id, topic, consumer_id, data, metadata, content_type, attempts, created_at, ?, %[3]s
+				FROM %[1]s
+				WHERE id = ? AND attempts = ?`,
+				q.metadata.TableName, q.metadata.deadLetterTableName(), nowExpr,
+			),
+			reason, msg.ID, msg.Attempts,
+		)
+		if err != nil {
+			return struct{}{}, err
+		}
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND attempts = ?`, q.metadata.TableName),
+			msg.ID, msg.Attempts,
+		)
+		return struct{}{}, err
+	})
+	return err
+}
+
+func (q *queue) Release(ctx context.Context, msgs []sqlpubsub.Message) error {
+	_, err := sqltransactions.ExecuteInTransaction(ctx, q.logger, q.db, func(ctx context.Context, tx *sql.Tx) (struct{}, error) {
+		for _, msg := range msgs {
+			_, err := tx.ExecContext(ctx,
+				fmt.Sprintf(`UPDATE %s SET attempts = attempts - 1, visible_at = %s WHERE id = ? AND attempts = ?`, q.metadata.TableName, nowExpr),
+				msg.ID, msg.Attempts,
+			)
+			if err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func nullableString(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
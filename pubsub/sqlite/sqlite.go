@@ -0,0 +1,267 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	// Blank import for the underlying SQLite Driver.
+	_ "modernc.org/sqlite"
+
+	authSqlite "github.com/dapr/components-contrib/common/authentication/sqlite"
+	commonsql "github.com/dapr/components-contrib/common/component/sql"
+	sqlpubsub "github.com/dapr/components-contrib/common/component/sql/pubsub"
+	sqltransactions "github.com/dapr/components-contrib/common/component/sql/transactions"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+// SQLite is a pubsub component that stores the messages in a SQLite database.
+// Subscribers poll the database for new messages; subscribers in the same process are also woken up when messages are published.
+type SQLite struct {
+	logger   logger.Logger
+	metadata sqliteMetadata
+	db       *sql.DB
+	queue    *queue
+	gc       commonsql.GarbageCollector
+	wakeups  sqlpubsub.Wakeups
+
+	closed  atomic.Bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewSQLite returns a new SQLite pubsub component.
+func NewSQLite(logger logger.Logger) pubsub.PubSub {
+	return &SQLite{
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Init connects to the database and creates the tables.
+func (p *SQLite) Init(ctx context.Context, md pubsub.Metadata) error {
+	err := p.metadata.InitWithMetadata(md.Properties)
+	if err != nil {
+		return fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	connString, err := p.metadata.GetConnectionString(p.logger, authSqlite.GetConnectionStringOpts{})
+	if err != nil {
+		// Already logged
+		return err
+	}
+
+	p.db, err = sql.Open("sqlite", connString)
+	if err != nil {
+		return fmt.Errorf("failed to create connection: %w", err)
+	}
+
+	// If the database is in-memory, we can't have more than 1 open connection
+	if p.metadata.IsInMemoryDB() {
+		p.db.SetMaxOpenConns(1)
+	}
+
+	pingCtx, pingCancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	err = p.db.PingContext(pingCtx)
+	pingCancel()
+	if err != nil {
+		return fmt.Errorf("failed to ping: %w", err)
+	}
+
+	err = performMigrations(ctx, p.db, p.logger, p.metadata)
+	if err != nil {
+		return fmt.Errorf("failed to perform migrations: %w", err)
+	}
+
+	p.queue = &queue{
+		db:       p.db,
+		logger:   p.logger,
+		metadata: &p.metadata,
+	}
+
+	p.gc, err = commonsql.ScheduleGarbageCollector(commonsql.GCOptions{
+		Logger: p.logger,
+		UpdateLastCleanupQuery: func(arg any) (string, any) {
+			return fmt.Sprintf(`INSERT INTO %s (key, value)
+				VALUES ('last-cleanup', CURRENT_TIMESTAMP)
+				ON CONFLICT (key)
+				DO UPDATE SET value = CURRENT_TIMESTAMP
+					WHERE (unixepoch(CURRENT_TIMESTAMP) - unixepoch(value)) * 1000 > ?;`,
+				p.metadata.MetadataTableName,
+			), arg
+		},
+		DeleteExpiredValuesQuery: fmt.Sprintf(`DELETE FROM %s
+			WHERE
+				expiration_time IS NOT NULL
+				AND expiration_time <= %s`,
+			p.metadata.TableName, nowExpr,
+		),
+		CleanupInterval: p.metadata.CleanupInterval,
+		DB:              commonsql.AdaptDatabaseSQLConn(p.db),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Features returns the features supported by the component.
+func (p *SQLite) Features() []pubsub.Feature {
+	return []pubsub.Feature{
+		pubsub.FeatureMessageTTL,
+		pubsub.FeatureBulkPublish,
+	}
+}
+
+// Publish saves a copy of the message for each consumer group that subscribed to the topic.
+// Messages published before a consumer group subscribed to the topic for the first time are not delivered to it.
+func (p *SQLite) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
+	if p.closed.Load() {
+		return errors.New("component is closed")
+	}
+
+	msg, err := sqlpubsub.PublishRequestMessage(req)
+	if err != nil {
+		return err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	defer cancel()
+	err = p.queue.insert(queryCtx, p.db, req.Topic, msg)
+	if err != nil {
+		return err
+	}
+
+	p.wakeups.Notify(req.Topic)
+	return nil
+}
+
+// BulkPublish saves the messages in a single transaction, so either all of them or none are published.
+func (p *SQLite) BulkPublish(ctx context.Context, req *pubsub.BulkPublishRequest) (pubsub.BulkPublishResponse, error) {
+	if p.closed.Load() {
+		return pubsub.BulkPublishResponse{}, errors.New("component is closed")
+	}
+
+	msgs, err := sqlpubsub.BulkPublishRequestMessages(req)
+	if err != nil {
+		return pubsub.NewBulkPublishResponse(req.Entries, err), err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	defer cancel()
+	_, err = sqltransactions.ExecuteInTransaction(queryCtx, p.logger, p.db, func(ctx context.Context, tx *sql.Tx) (struct{}, error) {
+		for _, msg := range msgs {
+			err := p.queue.insert(ctx, tx, req.Topic, msg)
+			if err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return pubsub.NewBulkPublishResponse(req.Entries, err), err
+	}
+
+	p.wakeups.Notify(req.Topic)
+	return pubsub.BulkPublishResponse{}, nil
+}
+
+// Subscribe delivers the messages of the topic to the handler, until the context is canceled or the component is closed.
+// Each message is delivered to one subscriber of each consumer group, set with the "consumerID" metadata.
+func (p *SQLite) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	if p.closed.Load() {
+		return errors.New("component is closed")
+	}
+
+	consumerID, err := p.metadata.SubscriptionConsumerID(req)
+	if err != nil {
+		return err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	defer cancel()
+	_, err = p.db.ExecContext(queryCtx,
+		fmt.Sprintf(`INSERT INTO %s (topic, consumer_id, created_at) VALUES (?, ?, %s) ON CONFLICT DO NOTHING`, p.metadata.subscriptionsTableName(), nowExpr),
+		req.Topic, consumerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+
+	wakeup, unregister := p.wakeups.Register(req.Topic)
+	subCtx, subCancel := context.WithCancelCause(ctx)
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		select {
+		case <-subCtx.Done():
+		case <-p.closeCh:
+			subCancel(pubsub.ErrGracefulShutdown)
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		defer subCancel(nil)
+		defer unregister()
+		sqlpubsub.Subscribe(subCtx, sqlpubsub.SubscriberOptions{
+			Logger:              p.logger,
+			Queue:               p.queue,
+			Topic:               req.Topic,
+			ConsumerID:          consumerID,
+			Handler:             handler,
+			BatchSize:           p.metadata.FetchBatchSize,
+			PollInterval:        p.metadata.PollInterval,
+			RetryInterval:       p.metadata.RetryInterval,
+			MaxDeliveryAttempts: p.metadata.MaxDeliveryAttempts,
+			Wakeup:              wakeup,
+			Timeout:             p.metadata.Timeout,
+		})
+	}()
+
+	return nil
+}
+
+// Close stops the subscriptions and closes the connection to the database.
+func (p *SQLite) Close() error {
+	if !p.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(p.closeCh)
+	p.wg.Wait()
+
+	errs := make([]error, 0, 2)
+	if p.gc != nil {
+		errs = append(errs, p.gc.Close())
+	}
+	if p.db != nil {
+		errs = append(errs, p.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (p *SQLite) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := sqliteMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.PubSubType)
+	return
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+func TestMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var md sqliteMetadata
+		require.NoError(t, md.InitWithMetadata(map[string]string{"connectionString": ":memory:"}))
+		assert.Equal(t, "pubsub", md.TableName)
+		assert.Equal(t, "pubsub_subscriptions", md.subscriptionsTableName())
+		assert.Equal(t, "pubsub_deadletter", md.deadLetterTableName())
+		assert.Equal(t, 30*time.Second, md.VisibilityTimeout)
+		assert.Equal(t, 5, md.MaxDeliveryAttempts)
+		assert.Equal(t, time.Second, md.PollInterval)
+		assert.Equal(t, time.Hour, md.CleanupInterval)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for k, v := range map[string]string{
+			"tableName":           "not valid",
+			"visibilityTimeout":   "10ms",
+			"maxDeliveryAttempts": "0",
+			"fetchBatchSize":      "0",
+			"pollInterval":        "0",
+		} {
+			var md sqliteMetadata
+			err := md.InitWithMetadata(map[string]string{"connectionString": ":memory:", k: v})
+			require.Error(t, err, k)
+		}
+	})
+}
+
+func newPubSub(t *testing.T, props map[string]string) *SQLite {
+	t.Helper()
+	p := NewSQLite(logger.NewLogger("test")).(*SQLite)
+	properties := map[string]string{
+		"connectionString": filepath.Join(t.TempDir(), "pubsub.db"),
+		"pollInterval":     "50ms",
+		"retryInterval":    "0",
+	}
+	maps.Copy(properties, props)
+	require.NoError(t, p.Init(t.Context(), pubsub.Metadata{Base: metadata.Base{Properties: properties}}))
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+	return p
+}
+
+// collector records the messages received by a subscription.
+type collector struct {
+	lock sync.Mutex
+	msgs []*pubsub.NewMessage
+}
+
+func (c *collector) handler(_ context.Context, msg *pubsub.NewMessage) error {
+	c.lock.Lock()
+	c.msgs = append(c.msgs, msg)
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *collector) data() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	res := make([]string, len(c.msgs))
+	for i, msg := range c.msgs {
+		res[i] = string(msg.Data)
+	}
+	return res
+}
+
+func subscribe(t *testing.T, p *SQLite, topic string, consumerID string, handler pubsub.Handler) {
+	t.Helper()
+	err := p.Subscribe(t.Context(), pubsub.SubscribeRequest{
+		Topic:    topic,
+		Metadata: map[string]string{pubsub.RuntimeConsumerIDKey: consumerID},
+	}, handler)
+	require.NoError(t, err)
+}
+
+// registerConsumerGroup subscribes a consumer group to the topic, without starting a subscriber.
+func registerConsumerGroup(t *testing.T, p *SQLite, topic string, consumerID string) {
+	t.Helper()
+	_, err := p.db.ExecContext(t.Context(), "INSERT INTO pubsub_subscriptions (topic, consumer_id, created_at) VALUES (?, ?, 0)", topic, consumerID)
+	require.NoError(t, err)
+}
+
+func countRows(t *testing.T, p *SQLite, table string) int {
+	t.Helper()
+	var n int
+	require.NoError(t, p.db.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM "+table).Scan(&n))
+	return n
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	p := newPubSub(t, nil)
+
+	assert.ElementsMatch(t, []pubsub.Feature{pubsub.FeatureMessageTTL, pubsub.FeatureBulkPublish}, p.Features())
+
+	err := p.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "orders"}, nil)
+	require.ErrorContains(t, err, "consumer ID")
+
+	var c collector
+	subscribe(t, p, "orders", "app", c.handler)
+
+	contentType := "text/plain"
+	for i := range 3 {
+		require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{
+			Topic:       "orders",
+			Data:        []byte(fmt.Sprintf("msg%d", i)),
+			Metadata:    map[string]string{"key": "value"},
+			ContentType: &contentType,
+		}))
+	}
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "other", Data: []byte("other")}))
+
+	assert.EventuallyWithT(t, func(c2 *assert.CollectT) {
+		assert.Equal(c2, []string{"msg0", "msg1", "msg2"}, c.data())
+	}, 5*time.Second, 10*time.Millisecond)
+	c.lock.Lock()
+	assert.Equal(t, "orders", c.msgs[0].Topic)
+	assert.Equal(t, map[string]string{"key": "value"}, c.msgs[0].Metadata)
+	assert.Equal(t, &contentType, c.msgs[0].ContentType)
+	c.lock.Unlock()
+
+	// Processed messages are deleted
+	assert.EventuallyWithT(t, func(c2 *assert.CollectT) {
+		var n int
+		assert.NoError(c2, p.db.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM pubsub").Scan(&n))
+		assert.Zero(c2, n)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConsumerGroups(t *testing.T) {
+	p := newPubSub(t, nil)
+
+	var groupA1, groupA2, groupB collector
+	subscribe(t, p, "orders", "a", groupA1.handler)
+	subscribe(t, p, "orders", "a", groupA2.handler)
+	subscribe(t, p, "orders", "b", groupB.handler)
+
+	const n = 20
+	for i := range n {
+		require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte(fmt.Sprintf("msg%02d", i))}))
+	}
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Len(c, groupB.data(), n)
+		assert.Len(c, append(groupA1.data(), groupA2.data()...), n)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, groupB.data(), append(groupA1.data(), groupA2.data()...))
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	p := newPubSub(t, map[string]string{"maxDeliveryAttempts": "3"})
+
+	var (
+		lock     sync.Mutex
+		attempts = map[string]int{}
+	)
+	subscribe(t, p, "orders", "app", func(_ context.Context, msg *pubsub.NewMessage) error {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts[string(msg.Data)]++
+		if string(msg.Data) == "poison" || attempts[string(msg.Data)] < 2 {
+			return errors.New("simulated error")
+		}
+		return nil
+	})
+
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte("poison")}))
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte("flaky")}))
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, 1, countRows(t, p, "pubsub_deadletter"))
+		assert.Zero(c, countRows(t, p, "pubsub"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	assert.Equal(t, 3, attempts["poison"])
+	assert.Equal(t, 2, attempts["flaky"])
+	lock.Unlock()
+
+	var (
+		data     string
+		attempt  int
+		errorMsg string
+	)
+	err := p.db.QueryRowContext(t.Context(), "SELECT data, attempts, error FROM pubsub_deadletter").Scan(&data, &attempt, &errorMsg)
+	require.NoError(t, err)
+	assert.Equal(t, "poison", data)
+	assert.Equal(t, 3, attempt)
+	assert.Equal(t, "simulated error", errorMsg)
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	p := newPubSub(t, map[string]string{"maxDeliveryAttempts": "2"})
+
+	registerConsumerGroup(t, p, "orders", "app")
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte("msg")}))
+
+	p.metadata.VisibilityTimeout = 100 * time.Millisecond
+	msgs, err := p.queue.Lease(t.Context(), "orders", "app", 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, 1, msgs[0].Attempts)
+
+	// The message is hidden until the visibility timeout expires
+	msgs, err = p.queue.Lease(t.Context(), "orders", "app", 10)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	var leased []int
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		msgs, err = p.queue.Lease(t.Context(), "orders", "app", 10)
+		assert.NoError(c, err)
+		if assert.Len(c, msgs, 1) {
+			leased = append(leased, msgs[0].Attempts)
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{2}, leased)
+
+	// A stale lease can't delete the message
+	stale := msgs[0]
+	stale.Attempts = 1
+	require.NoError(t, p.queue.Delete(t.Context(), stale))
+	assert.Equal(t, 1, countRows(t, p, "pubsub"))
+
+	// The message exceeded the maximum number of attempts when its lease expires again
+	time.Sleep(150 * time.Millisecond)
+	var received collector
+	subscribe(t, p, "orders", "app", received.handler)
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, 1, countRows(t, p, "pubsub_deadletter"))
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, received.data())
+}
+
+func TestMessageTTL(t *testing.T) {
+	p := newPubSub(t, nil)
+
+	registerConsumerGroup(t, p, "orders", "app")
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{
+		Topic:    "orders",
+		Data:     []byte("expired"),
+		Metadata: map[string]string{metadata.TTLMetadataKey: "100ms"},
+	}))
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{
+		Topic:    "orders",
+		Data:     []byte("valid"),
+		Metadata: map[string]string{metadata.TTLMetadataKey: "1h"},
+	}))
+	err := p.Publish(t.Context(), &pubsub.PublishRequest{
+		Topic:    "orders",
+		Data:     []byte("invalid"),
+		Metadata: map[string]string{metadata.TTLMetadataKey: "invalid"},
+	})
+	require.Error(t, err)
+	time.Sleep(150 * time.Millisecond)
+
+	// Expired messages are not delivered, and are removed by the garbage collector
+	var c collector
+	subscribe(t, p, "orders", "app", c.handler)
+	assert.EventuallyWithT(t, func(c2 *assert.CollectT) {
+		assert.Equal(c2, []string{"valid"}, c.data())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, countRows(t, p, "pubsub"))
+	require.NoError(t, p.gc.CleanupExpired())
+	assert.Zero(t, countRows(t, p, "pubsub"))
+}
+
+func TestBulkPublish(t *testing.T) {
+	p := newPubSub(t, nil)
+
+	var c collector
+	subscribe(t, p, "orders", "app", c.handler)
+
+	res, err := p.BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+		Topic:    "orders",
+		Metadata: map[string]string{"common": "1"},
+		Entries: []pubsub.BulkMessageEntry{
+			{EntryId: "1", Event: []byte("a"), ContentType: "text/plain", Metadata: map[string]string{"entry": "1"}},
+			{EntryId: "2", Event: []byte("b"), ContentType: "text/plain"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.FailedEntries)
+
+	assert.EventuallyWithT(t, func(c2 *assert.CollectT) {
+		assert.Equal(c2, []string{"a", "b"}, c.data())
+	}, 5*time.Second, 10*time.Millisecond)
+	c.lock.Lock()
+	assert.Equal(t, map[string]string{"common": "1", "entry": "1"}, c.msgs[0].Metadata)
+	assert.Equal(t, map[string]string{"common": "1"}, c.msgs[1].Metadata)
+	c.lock.Unlock()
+
+	// No message is published if an entry is invalid
+	res, err = p.BulkPublish(t.Context(), &pubsub.BulkPublishRequest{
+		Topic: "orders",
+		Entries: []pubsub.BulkMessageEntry{
+			{EntryId: "1", Event: []byte("c")},
+			{EntryId: "2", Event: []byte("d"), Metadata: map[string]string{metadata.TTLMetadataKey: "invalid"}},
+		},
+	})
+	require.Error(t, err)
+	assert.Len(t, res.FailedEntries, 2)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, c.data())
+}
+
+func TestClose(t *testing.T) {
+	p := newPubSub(t, nil)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	subscribe(t, p, "orders", "app", func(context.Context, *pubsub.NewMessage) error {
+		close(started)
+		<-block
+		return nil
+	})
+	require.NoError(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders", Data: []byte("msg")}))
+	<-started
+
+	// Close waits for the messages being processed
+	closed := make(chan error)
+	go func() {
+		closed <- p.Close()
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while a message was being processed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(block)
+	require.NoError(t, <-closed)
+
+	require.Error(t, p.Publish(t.Context(), &pubsub.PublishRequest{Topic: "orders"}))
+	require.Error(t, p.Subscribe(t.Context(), pubsub.SubscribeRequest{Topic: "orders"}, nil))
+}
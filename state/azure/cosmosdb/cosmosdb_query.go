@@ -192,7 +192,10 @@ func (q *Query) visitFilters(op string, filters []query.Filter) (string, error)
 			}
 			arr = append(arr, "("+str+")")
 		default:
-			return "", fmt.Errorf("unsupported filter type %#v", f)
+			if str, err = query.VisitFilter(q, f); err != nil {
+				return "", err
+			}
+			arr = append(arr, "("+str+")")
 		}
 	}
 
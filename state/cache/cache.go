@@ -0,0 +1,689 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache implements a state store decorator that caches the values of a state store in another, faster, state store.
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/utils"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// Default time after which cached values are stale.
+	defaultTTL = time.Minute
+	// Timeout for refreshing stale values in background.
+	revalidateTimeout = 30 * time.Second
+	// Metadata property with the partition key of requests, which isn't part of the cache key.
+	partitionKeyMetadata = "partitionKey"
+)
+
+// Options contains the options for the cached store.
+type Options struct {
+	// State store used as cache, such as the in-memory or the Redis state store.
+	// It must be initialized, support TTLs, and be dedicated to the cache, as values are saved with the same keys as in the cached store.
+	Cache state.Store
+	// Time after which cached values are stale.
+	// Defaults to 1 minute.
+	TTL time.Duration
+	// Maximum number of keys that are cached by this instance; when it's reached, the least recently used keys are removed from the cache.
+	// If 0, keys are removed from the cache only when they expire.
+	MaxEntries int
+	// Time after the TTL during which stale values are returned, while they are refreshed in background.
+	// If 0, stale values are never returned.
+	StaleWhileRevalidate time.Duration
+	// Logger for errors of the cache, which don't cause the operations to fail.
+	Logger logger.Logger
+}
+
+// Store is a state store decorator that caches the values read from the underlying store.
+// Values are read from the cache if present, and otherwise read from the underlying store and saved in the cache (read-through).
+// Write operations are executed on the underlying store, and remove the keys they modify from the cache (write-through).
+// The cache doesn't store values with the new ETags, as they aren't returned by the underlying store, so the next read retrieves them.
+//
+// Reads with strong consistency and reads for a partition key are always executed on the underlying store.
+// Other operations, such as queries, are not cached.
+type Store struct {
+	store    state.Store
+	cache    state.Store
+	ttl      time.Duration
+	stale    time.Duration
+	lru      *lru.Cache[string, struct{}]
+	logger   logger.Logger
+	closeCtx context.Context
+	closeFn  context.CancelFunc
+	wg       sync.WaitGroup
+
+	lock       sync.Mutex
+	fills      map[string]*fill
+	refreshing map[string]struct{}
+}
+
+// fill tracks the reads from the underlying store for a key, whose values are saved in the cache.
+type fill struct {
+	refs int
+	// Incremented every time the key is modified
+	version uint64
+}
+
+// NewStore returns a store that caches the values of store in opts.Cache.
+func NewStore(store state.Store, opts Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("state store is required")
+	}
+	if opts.Cache == nil {
+		return nil, errors.New("cache state store is required")
+	}
+	if opts.TTL < 0 || opts.StaleWhileRevalidate < 0 || opts.MaxEntries < 0 {
+		return nil, errors.New("cache options must not be negative")
+	}
+	if opts.TTL == 0 {
+		opts.TTL = defaultTTL
+	}
+	if opts.Logger == nil {
+		opts.Logger = logger.NewLogger("dapr.state.cache")
+	}
+
+	s := &Store{
+		store:      store,
+		cache:      opts.Cache,
+		ttl:        opts.TTL,
+		stale:      opts.StaleWhileRevalidate,
+		logger:     opts.Logger,
+		fills:      map[string]*fill{},
+		refreshing: map[string]struct{}{},
+	}
+	s.closeCtx, s.closeFn = context.WithCancel(context.Background())
+	if opts.MaxEntries > 0 {
+		var err error
+		s.lru, err = lru.NewWithEvict(opts.MaxEntries, func(key string, _ struct{}) {
+			s.deleteCached(context.Background(), key)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Init initializes the underlying store.
+func (s *Store) Init(ctx context.Context, metadata state.Metadata) error {
+	return s.store.Init(ctx, metadata)
+}
+
+// Features returns the features of the underlying store.
+func (s *Store) Features() []state.Feature {
+	return s.store.Features()
+}
+
+// Get retrieves the value of a key from the cache, or from the underlying store if it's not cached.
+func (s *Store) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	if partitioned(req) {
+		return s.store.Get(ctx, req)
+	}
+	if cacheable(req) {
+		if e, fresh := s.lookup(ctx, req.Key); e != nil {
+			if !fresh {
+				s.revalidate(req)
+			}
+			return e.getResponse(), nil
+		}
+	}
+	return s.load(ctx, req)
+}
+
+// BulkGet retrieves the values of multiple keys from the cache, and the ones that aren't cached from the underlying store.
+// Responses are returned in the order of the requests.
+func (s *Store) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	res := make([]state.BulkGetResponse, len(req))
+	misses := make([]state.GetRequest, 0, len(req))
+	missIdx := make([]int, 0, len(req))
+	cached := s.lookupBulk(ctx, req)
+	for i := range req {
+		e, fresh := cached[req[i].Key].entry, cached[req[i].Key].fresh
+		if e == nil || !cacheable(&req[i]) {
+			misses = append(misses, req[i])
+			missIdx = append(missIdx, i)
+			continue
+		}
+		if !fresh {
+			s.revalidate(&req[i])
+		}
+		res[i] = e.bulkGetResponse(req[i].Key)
+	}
+	if len(misses) == 0 {
+		return res, nil
+	}
+
+	versions := make(map[string]uint64, len(misses))
+	for i := range misses {
+		key := misses[i].Key
+		if _, ok := versions[key]; !ok && !partitioned(&misses[i]) {
+			versions[key] = s.beginFill(key)
+		}
+	}
+	loaded, err := s.store.BulkGet(ctx, misses, opts)
+	if err == nil {
+		for _, r := range loaded {
+			if _, ok := versions[r.Key]; ok && r.Error == "" {
+				s.put(ctx, r.Key, &state.GetResponse{Data: r.Data, ETag: r.ETag, Metadata: r.Metadata, ContentType: r.ContentType})
+			}
+		}
+	}
+	for key, version := range versions {
+		if !s.endFill(key, version) {
+			s.evict(ctx, key)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The underlying store may return the responses in any order
+	byKey := make(map[string][]state.BulkGetResponse, len(loaded))
+	for _, r := range loaded {
+		byKey[r.Key] = append(byKey[r.Key], r)
+	}
+	for j, i := range missIdx {
+		key := misses[j].Key
+		if len(byKey[key]) == 0 {
+			res[i] = state.BulkGetResponse{Key: key}
+			continue
+		}
+		res[i] = byKey[key][0]
+		byKey[key] = byKey[key][1:]
+	}
+	return res, nil
+}
+
+// Set saves the value of a key in the underlying store, and removes it from the cache.
+func (s *Store) Set(ctx context.Context, req *state.SetRequest) error {
+	defer s.invalidate(ctx, req.Key)
+	return s.store.Set(ctx, req)
+}
+
+// Delete deletes a key from the underlying store and from the cache.
+func (s *Store) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	defer s.invalidate(ctx, req.Key)
+	return s.store.Delete(ctx, req)
+}
+
+// BulkSet saves the values of multiple keys in the underlying store, and removes them from the cache.
+func (s *Store) BulkSet(ctx context.Context, req []state.SetRequest, opts state.BulkStoreOpts) error {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	defer s.invalidate(ctx, keys...)
+	return s.store.BulkSet(ctx, req, opts)
+}
+
+// BulkDelete deletes multiple keys from the underlying store and from the cache.
+func (s *Store) BulkDelete(ctx context.Context, req []state.DeleteRequest, opts state.BulkStoreOpts) error {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	defer s.invalidate(ctx, keys...)
+	return s.store.BulkDelete(ctx, req, opts)
+}
+
+// Multi executes the transaction in the underlying store, and removes the keys it modifies from the cache.
+func (s *Store) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	transactional, ok := s.store.(state.TransactionalStore)
+	if !ok {
+		return errors.New("transactions are not supported by the state store")
+	}
+	keys := make([]string, 0, len(request.Operations))
+	for _, o := range request.Operations {
+		// Outbox messages don't modify keys: their key is the message ID
+		if o.Operation() == state.OperationOutbox {
+			continue
+		}
+		keys = append(keys, o.GetKey())
+	}
+	defer s.invalidate(ctx, keys...)
+	return transactional.Multi(ctx, request)
+}
+
+// MultiMaxSize returns the maximum number of operations in a transaction of the underlying store.
+func (s *Store) MultiMaxSize() int {
+	if m, ok := s.store.(state.TransactionalStoreMultiMaxSize); ok {
+		return m.MultiMaxSize()
+	}
+	return -1
+}
+
+// Increment increments the value of a key in the underlying store, and removes it from the cache.
+func (s *Store) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	atomic, ok := s.store.(state.AtomicOperator)
+	if !ok {
+		return nil, errors.New("atomic operations are not supported by the state store")
+	}
+	defer s.invalidate(ctx, req.Key)
+	return atomic.Increment(ctx, req)
+}
+
+// Append appends values to the array of a key in the underlying store, and removes it from the cache.
+func (s *Store) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	atomic, ok := s.store.(state.AtomicOperator)
+	if !ok {
+		return nil, errors.New("atomic operations are not supported by the state store")
+	}
+	defer s.invalidate(ctx, req.Key)
+	return atomic.Append(ctx, req)
+}
+
+// CompareAndSwap replaces the value of a key in the underlying store, and removes it from the cache.
+func (s *Store) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	atomic, ok := s.store.(state.AtomicOperator)
+	if !ok {
+		return nil, errors.New("atomic operations are not supported by the state store")
+	}
+	defer s.invalidate(ctx, req.Key)
+	return atomic.CompareAndSwap(ctx, req)
+}
+
+// DeleteWithPrefix deletes the keys with the prefix from the underlying store and from the cache.
+// Keys are removed from the cache only if the cache store supports deleting with a prefix, or if the number of keys in the cache is limited.
+func (s *Store) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	deleter, ok := s.store.(state.DeleteWithPrefix)
+	if !ok {
+		return state.DeleteWithPrefixResponse{}, errors.New("delete with prefix is not supported by the state store")
+	}
+	defer s.invalidatePrefix(ctx, req)
+	return deleter.DeleteWithPrefix(ctx, req)
+}
+
+// Query executes the query on the underlying store.
+func (s *Store) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.store.(state.Querier)
+	if !ok {
+		return nil, errors.New("queries are not supported by the state store")
+	}
+	return querier.Query(ctx, req)
+}
+
+// QueryAggregate executes the query with projections or aggregations on the underlying store.
+func (s *Store) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.store.(state.AggregateQuerier)
+	if !ok {
+		return nil, errors.New("aggregate queries are not supported by the state store")
+	}
+	return querier.QueryAggregate(ctx, req)
+}
+
+// KeysLike returns the keys of the underlying store that match the pattern.
+func (s *Store) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	keysLiker, ok := s.store.(state.KeysLiker)
+	if !ok {
+		return nil, errors.New("keys like is not supported by the state store")
+	}
+	return keysLiker.KeysLike(ctx, req)
+}
+
+// RangeScan returns the keys in the range, with their values, from the underlying store.
+func (s *Store) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	scanner, ok := s.store.(state.RangeScanner)
+	if !ok {
+		return nil, errors.New("range scans are not supported by the state store")
+	}
+	return scanner.RangeScan(ctx, req)
+}
+
+// GetRevisions returns the revisions of a key from the underlying store.
+func (s *Store) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	versioned, ok := s.store.(state.VersionedStore)
+	if !ok {
+		return nil, errors.New("versioning is not supported by the state store")
+	}
+	return versioned.GetRevisions(ctx, req)
+}
+
+// GetRevision returns the value of a key at a revision from the underlying store, without caching it.
+func (s *Store) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	versioned, ok := s.store.(state.VersionedStore)
+	if !ok {
+		return nil, errors.New("versioning is not supported by the state store")
+	}
+	return versioned.GetRevision(ctx, req)
+}
+
+// Expire changes the expiration time of a key in the underlying store, and removes it from the cache.
+func (s *Store) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	defer s.invalidate(ctx, req.Key)
+	return ttl.Expire(ctx, req)
+}
+
+// GetTTL returns the expiration time of a key from the underlying store.
+func (s *Store) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.GetTTL(ctx, req)
+}
+
+// Watch delivers the changes of the underlying store to the handler.
+func (s *Store) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	watcher, ok := s.store.(state.Watcher)
+	if !ok {
+		return errors.New("watch is not supported by the state store")
+	}
+	return watcher.Watch(ctx, req, handler)
+}
+
+// Ping pings the underlying store.
+func (s *Store) Ping(ctx context.Context) error {
+	if pinger, ok := s.store.(health.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return state.ErrPingNotImplemented
+}
+
+// GetComponentMetadata returns the metadata of the underlying store.
+func (s *Store) GetComponentMetadata() metadata.MetadataMap {
+	if m, ok := s.store.(interface{ GetComponentMetadata() metadata.MetadataMap }); ok {
+		return m.GetComponentMetadata()
+	}
+	return nil
+}
+
+// Close waits for the values being refreshed in background, and closes the underlying store and the cache store.
+func (s *Store) Close() error {
+	s.closeFn()
+	s.lock.Lock()
+	s.refreshing = nil
+	s.lock.Unlock()
+	s.wg.Wait()
+	return errors.Join(s.store.Close(), s.cache.Close())
+}
+
+// cacheable returns true if the value of the request can be read from the cache.
+// Reads with strong consistency are executed on the underlying store, and refresh the cache.
+func cacheable(req *state.GetRequest) bool {
+	return req.Options.Consistency != state.Strong && !partitioned(req)
+}
+
+// partitioned returns true if the request is for a partition key, so it bypasses the cache.
+func partitioned(req *state.GetRequest) bool {
+	return req.Metadata[partitionKeyMetadata] != ""
+}
+
+// lookup returns the cached value of a key, and whether it's fresh.
+// It returns nil if the key isn't cached, or if the value can't be used anymore.
+func (s *Store) lookup(ctx context.Context, key string) (*entry, bool) {
+	res, err := s.cache.Get(ctx, &state.GetRequest{Key: key})
+	if err != nil {
+		s.logger.Warnf("Failed to read key %s from the cache: %v", key, err)
+		return nil, false
+	}
+	if res == nil {
+		return nil, false
+	}
+	return s.use(key, res.Data)
+}
+
+type lookupResult struct {
+	entry *entry
+	fresh bool
+}
+
+// lookupBulk returns the cached values of multiple keys.
+func (s *Store) lookupBulk(ctx context.Context, req []state.GetRequest) map[string]lookupResult {
+	cacheReq := make([]state.GetRequest, 0, len(req))
+	for i := range req {
+		if cacheable(&req[i]) {
+			cacheReq = append(cacheReq, state.GetRequest{Key: req[i].Key})
+		}
+	}
+	if len(cacheReq) == 0 {
+		return nil
+	}
+	res, err := s.cache.BulkGet(ctx, cacheReq, state.BulkGetOpts{})
+	if err != nil {
+		s.logger.Warnf("Failed to read keys from the cache: %v", err)
+		return nil
+	}
+	results := make(map[string]lookupResult, len(res))
+	for _, r := range res {
+		if r.Error != "" {
+			continue
+		}
+		e, fresh := s.use(r.Key, r.Data)
+		results[r.Key] = lookupResult{entry: e, fresh: fresh}
+	}
+	return results
+}
+
+// use parses a value read from the cache, and marks the key as recently used.
+func (s *Store) use(key string, data []byte) (*entry, bool) {
+	if data == nil {
+		return nil, false
+	}
+	e, err := parseEntry(data)
+	if err != nil {
+		s.logger.Warnf("Invalid value for key %s in the cache: %v", key, err)
+		return nil, false
+	}
+	now := time.Now()
+	if !now.Before(e.staleUntil()) {
+		return nil, false
+	}
+	if s.lru != nil {
+		// Keys cached by other instances are tracked as well
+		s.lru.Add(key, struct{}{})
+	}
+	return e, now.Before(e.freshUntil())
+}
+
+// load reads the value of a key from the underlying store, and saves it in the cache.
+func (s *Store) load(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	version := s.beginFill(req.Key)
+	res, err := s.store.Get(ctx, req)
+	if err == nil {
+		s.put(ctx, req.Key, res)
+	}
+	if !s.endFill(req.Key, version) {
+		// The key was modified while it was read, so the value may be stale
+		s.evict(ctx, req.Key)
+	}
+	return res, err
+}
+
+// put saves the value of a key read from the underlying store in the cache.
+// Values that don't exist are not cached, so they are removed from the cache.
+func (s *Store) put(ctx context.Context, key string, res *state.GetResponse) {
+	if res == nil || res.Data == nil {
+		s.evict(ctx, key)
+		return
+	}
+
+	now := time.Now()
+	e := &entry{
+		Data:        res.Data,
+		ETag:        res.ETag,
+		ContentType: res.ContentType,
+		Metadata:    res.Metadata,
+		Fresh:       now.Add(s.ttl).UnixMilli(),
+		Stale:       now.Add(s.ttl + s.stale).UnixMilli(),
+	}
+	// Values are not cached after they expire in the underlying store
+	if exp, ok := res.Metadata[state.GetRespMetaKeyTTLExpireTime]; ok {
+		expireTime, err := time.Parse(time.RFC3339, exp)
+		if err != nil {
+			s.logger.Warnf("Invalid expiration time for key %s: %v", key, err)
+			return
+		}
+		e.Fresh = min(e.Fresh, expireTime.UnixMilli())
+		e.Stale = min(e.Stale, expireTime.UnixMilli())
+	}
+	if !now.Before(e.staleUntil()) {
+		s.evict(ctx, key)
+		return
+	}
+
+	data, err := e.marshal()
+	if err != nil {
+		s.logger.Warnf("Failed to serialize value of key %s for the cache: %v", key, err)
+		return
+	}
+	err = s.cache.Set(ctx, &state.SetRequest{
+		Key:      key,
+		Value:    data,
+		Metadata: map[string]string{utils.MetadataTTLKey: ttlInSeconds(e.staleUntil().Sub(now))},
+	})
+	if err != nil {
+		s.logger.Warnf("Failed to save key %s in the cache: %v", key, err)
+		return
+	}
+	if s.lru != nil {
+		s.lru.Add(key, struct{}{})
+	}
+}
+
+// revalidate refreshes the stale value of a key in background.
+func (s *Store) revalidate(req *state.GetRequest) {
+	s.lock.Lock()
+	if s.refreshing == nil {
+		// The store is closed
+		s.lock.Unlock()
+		return
+	}
+	if _, ok := s.refreshing[req.Key]; ok {
+		s.lock.Unlock()
+		return
+	}
+	s.refreshing[req.Key] = struct{}{}
+	s.wg.Add(1)
+	s.lock.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		ctx, cancel := context.WithTimeout(s.closeCtx, revalidateTimeout)
+		defer cancel()
+		_, err := s.load(ctx, &state.GetRequest{Key: req.Key, Metadata: req.Metadata})
+		if err != nil {
+			s.logger.Warnf("Failed to refresh key %s in the cache: %v", req.Key, err)
+		}
+
+		s.lock.Lock()
+		delete(s.refreshing, req.Key)
+		s.lock.Unlock()
+	}()
+}
+
+// invalidate removes keys that were modified from the cache.
+// It must be invoked after the keys are modified in the underlying store.
+func (s *Store) invalidate(ctx context.Context, keys ...string) {
+	s.lock.Lock()
+	for _, key := range keys {
+		if f, ok := s.fills[key]; ok {
+			f.version++
+		}
+	}
+	s.lock.Unlock()
+
+	for _, key := range keys {
+		s.evict(ctx, key)
+	}
+}
+
+// invalidatePrefix removes keys that were deleted with a prefix from the cache.
+func (s *Store) invalidatePrefix(ctx context.Context, req state.DeleteWithPrefixRequest) {
+	// Prefixes end with the "||" separator, which is added by the store if missing
+	prefix := req.Prefix
+	if !strings.HasSuffix(prefix, "||") {
+		prefix += "||"
+	}
+
+	s.lock.Lock()
+	for key, f := range s.fills {
+		if strings.HasPrefix(key, prefix) {
+			f.version++
+		}
+	}
+	s.lock.Unlock()
+
+	if s.lru != nil {
+		for _, key := range s.lru.Keys() {
+			if strings.HasPrefix(key, prefix) {
+				s.lru.Remove(key)
+			}
+		}
+	}
+	if deleter, ok := s.cache.(state.DeleteWithPrefix); ok {
+		_, err := deleter.DeleteWithPrefix(ctx, req)
+		if err != nil {
+			s.logger.Errorf("Failed to delete keys with prefix %s from the cache: %v", req.Prefix, err)
+		}
+	}
+}
+
+// evict removes a key from the cache.
+func (s *Store) evict(ctx context.Context, key string) {
+	// Keys removed from the LRU are deleted by the eviction callback
+	if s.lru != nil && s.lru.Remove(key) {
+		return
+	}
+	s.deleteCached(ctx, key)
+}
+
+func (s *Store) deleteCached(ctx context.Context, key string) {
+	err := s.cache.Delete(ctx, &state.DeleteRequest{Key: key})
+	if err != nil {
+		s.logger.Errorf("Failed to delete key %s from the cache: %v", key, err)
+	}
+}
+
+// beginFill tracks a read of a key from the underlying store, and returns the current version of the key.
+func (s *Store) beginFill(key string) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, ok := s.fills[key]
+	if !ok {
+		f = &fill{}
+		s.fills[key] = f
+	}
+	f.refs++
+	return f.version
+}
+
+// endFill completes a read of a key from the underlying store.
+// It returns false if the key has been modified since the read started.
+// Because keys are removed from the cache after they're modified, a value that was saved in the cache before endFill returns true can't be stale.
+func (s *Store) endFill(key string, version uint64) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f := s.fills[key]
+	f.refs--
+	if f.refs == 0 {
+		delete(s.fills, key)
+	}
+	return f.version == version
+}
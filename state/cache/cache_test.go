@@ -0,0 +1,385 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+// countingStore counts the reads from the in-memory store.
+type countingStore struct {
+	*inmemory.InMemoryStore
+	gets atomic.Int32
+	// Delay of the responses to reads, after the values are read
+	readDelay time.Duration
+}
+
+func (c *countingStore) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	c.gets.Add(1)
+	res, err := c.InMemoryStore.Get(ctx, req)
+	time.Sleep(c.readDelay)
+	return res, err
+}
+
+func (c *countingStore) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	c.gets.Add(int32(len(req)))
+	res, err := c.InMemoryStore.BulkGet(ctx, req, opts)
+	time.Sleep(c.readDelay)
+	// Responses are returned in reverse order
+	slices.Reverse(res)
+	return res, err
+}
+
+func newTestStore(t *testing.T, opts Options) (*Store, *countingStore, state.Store) {
+	t.Helper()
+
+	log := logger.NewLogger("test")
+	backend := &countingStore{InMemoryStore: inmemory.NewInMemoryStateStore(log).(*inmemory.InMemoryStore)}
+	opts.Cache = inmemory.NewInMemoryStateStore(log)
+	require.NoError(t, opts.Cache.Init(t.Context(), state.Metadata{}))
+
+	s, err := NewStore(backend, opts)
+	require.NoError(t, err)
+	require.NoError(t, s.Init(t.Context(), state.Metadata{}))
+	t.Cleanup(func() { s.Close() })
+	return s, backend, opts.Cache
+}
+
+func TestInvalidOptions(t *testing.T) {
+	inner := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+
+	tests := map[string]struct {
+		store       state.Store
+		opts        Options
+		expectedErr string
+	}{
+		"Missing state store": {
+			opts:        Options{Cache: inner},
+			expectedErr: "state store is required",
+		},
+		"Missing cache store": {
+			store:       inner,
+			opts:        Options{},
+			expectedErr: "cache state store is required",
+		},
+		"Negative TTL": {
+			store:       inner,
+			opts:        Options{Cache: inner, TTL: -time.Second},
+			expectedErr: "must not be negative",
+		},
+		"Negative stale while revalidate": {
+			store:       inner,
+			opts:        Options{Cache: inner, StaleWhileRevalidate: -time.Second},
+			expectedErr: "must not be negative",
+		},
+		"Negative max entries": {
+			store:       inner,
+			opts:        Options{Cache: inner, MaxEntries: -1},
+			expectedErr: "must not be negative",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStore(tt.store, tt.opts)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	s, backend, cache := newTestStore(t, Options{})
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v1"}))
+
+	res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, string(res.Data))
+	require.NotNil(t, res.ETag)
+	etag := *res.ETag
+
+	res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, string(res.Data))
+	assert.Equal(t, etag, *res.ETag)
+	assert.Equal(t, int32(1), backend.gets.Load())
+
+	t.Run("reads with strong consistency use the underlying store", func(t *testing.T) {
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k", Options: state.GetStateOption{Consistency: state.Strong}})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, string(res.Data))
+		assert.Equal(t, int32(2), backend.gets.Load())
+	})
+
+	t.Run("missing keys are not cached", func(t *testing.T) {
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "missing"})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+		cached, err := cache.Get(t.Context(), &state.GetRequest{Key: "missing"})
+		require.NoError(t, err)
+		assert.Nil(t, cached.Data)
+	})
+
+	t.Run("bulk get", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k2", Value: "v2"}))
+		backend.gets.Store(0)
+
+		// Responses are in the order of the requests, whether they're cached or not
+		res, err := s.BulkGet(t.Context(), []state.GetRequest{{Key: "k2"}, {Key: "k"}, {Key: "missing"}}, state.BulkGetOpts{})
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		assert.Equal(t, "k2", res[0].Key)
+		assert.Equal(t, `"v2"`, string(res[0].Data))
+		assert.Equal(t, "k", res[1].Key)
+		assert.Equal(t, `"v1"`, string(res[1].Data))
+		assert.Equal(t, "missing", res[2].Key)
+		assert.Nil(t, res[2].Data)
+		assert.Equal(t, int32(2), backend.gets.Load())
+
+		res2, err := s.Get(t.Context(), &state.GetRequest{Key: "k2"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v2"`, string(res2.Data))
+		assert.Equal(t, int32(2), backend.gets.Load())
+	})
+}
+
+func TestInvalidation(t *testing.T) {
+	s, backend, _ := newTestStore(t, Options{})
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v1"}))
+	res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+	require.NoError(t, err)
+	etag := res.ETag
+
+	t.Run("set with ETag", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v2", ETag: etag}))
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v2"`, string(res.Data))
+		assert.NotEqual(t, *etag, *res.ETag)
+
+		// The ETag of the cached value is the current one
+		err = s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v3", ETag: etag})
+		require.ErrorAs(t, err, new(*state.ETagError))
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v3", ETag: res.ETag}))
+	})
+
+	t.Run("value modified in the underlying store after an ETag mismatch", func(t *testing.T) {
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		require.NoError(t, backend.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v4"}))
+
+		err = s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v5", ETag: res.ETag})
+		require.ErrorAs(t, err, new(*state.ETagError))
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v4"`, string(res.Data))
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k2", Value: "v"}))
+		_, err = s.Get(t.Context(), &state.GetRequest{Key: "k2"})
+		require.NoError(t, err)
+
+		err = s.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "k", Value: "tx"},
+				state.DeleteRequest{Key: "k2"},
+			},
+		})
+		require.NoError(t, err)
+
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"tx"`, string(res.Data))
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k2"})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+	})
+
+	t.Run("outbox messages don't invalidate keys", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "msg", Value: "v"}))
+		_, err = s.Get(t.Context(), &state.GetRequest{Key: "msg"})
+		require.NoError(t, err)
+		backend.gets.Store(0)
+
+		err = s.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "k", Value: "outbox"},
+				state.OutboxRequest{ID: "msg", PubsubName: "pubsub", Topic: "topic", Data: []byte("m")},
+			},
+		})
+		require.NoError(t, err)
+
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "msg"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v"`, string(res.Data))
+		assert.Equal(t, int32(0), backend.gets.Load())
+	})
+
+	t.Run("delete with prefix", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "app||k", Value: "v"}))
+		_, err = s.Get(t.Context(), &state.GetRequest{Key: "app||k"})
+		require.NoError(t, err)
+
+		_, err = s.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "app"})
+		require.NoError(t, err)
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "app||k"})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+	})
+}
+
+func TestExpiration(t *testing.T) {
+	t.Run("values are read again after the TTL", func(t *testing.T) {
+		s, backend, _ := newTestStore(t, Options{TTL: 50 * time.Millisecond})
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v1"}))
+		_, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		require.NoError(t, backend.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v2"}))
+
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, string(res.Data))
+
+		time.Sleep(100 * time.Millisecond)
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v2"`, string(res.Data))
+		assert.Equal(t, int32(2), backend.gets.Load())
+	})
+
+	t.Run("stale values are returned while they are refreshed", func(t *testing.T) {
+		s, backend, _ := newTestStore(t, Options{TTL: 50 * time.Millisecond, StaleWhileRevalidate: time.Minute})
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v1"}))
+		_, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		require.NoError(t, backend.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v2"}))
+
+		time.Sleep(100 * time.Millisecond)
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, string(res.Data))
+
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+			require.NoError(c, err)
+			assert.Equal(c, `"v2"`, string(res.Data))
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(2), backend.gets.Load())
+	})
+
+	t.Run("values are not cached after they expire in the underlying store", func(t *testing.T) {
+		s, _, cache := newTestStore(t, Options{TTL: time.Hour})
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v", Metadata: map[string]string{"ttlInSeconds": "1"}}))
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Contains(t, res.Metadata, state.GetRespMetaKeyTTLExpireTime)
+
+		cached, err := cache.Get(t.Context(), &state.GetRequest{Key: "k"})
+		require.NoError(t, err)
+		require.NotNil(t, cached.Data)
+		e, err := parseEntry(cached.Data)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, time.Until(e.staleUntil()), time.Second)
+	})
+}
+
+func TestLRU(t *testing.T) {
+	s, _, cache := newTestStore(t, Options{MaxEntries: 2})
+
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: k, Value: k}))
+	}
+	for _, k := range []string{"a", "b", "a", "c"} {
+		_, err := s.Get(t.Context(), &state.GetRequest{Key: k})
+		require.NoError(t, err)
+	}
+
+	// "b" is the least recently used key
+	for k, cachedExpected := range map[string]bool{"a": true, "b": false, "c": true} {
+		res, err := cache.Get(t.Context(), &state.GetRequest{Key: k})
+		require.NoError(t, err)
+		assert.Equal(t, cachedExpected, res.Data != nil, k)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	s, backend, _ := newTestStore(t, Options{TTL: time.Hour})
+	// Reads are slow, so writes happen while values are read
+	backend.readDelay = time.Millisecond
+
+	keys := []string{"a", "b", "c", "d"}
+	for _, k := range keys {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: k, Value: 0}))
+	}
+
+	var (
+		writers sync.WaitGroup
+		readers sync.WaitGroup
+		done    atomic.Bool
+	)
+	for _, k := range keys {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for i := 1; i <= 50; i++ {
+				assert.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: k, Value: i}))
+			}
+		}()
+	}
+	for range 4 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			bulkReq := make([]state.GetRequest, len(keys))
+			for i, k := range keys {
+				bulkReq[i] = state.GetRequest{Key: k}
+			}
+			for !done.Load() {
+				for _, k := range keys {
+					_, err := s.Get(t.Context(), &state.GetRequest{Key: k})
+					assert.NoError(t, err)
+				}
+				_, err := s.BulkGet(t.Context(), bulkReq, state.BulkGetOpts{})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	writers.Wait()
+	done.Store(true)
+	readers.Wait()
+
+	// No stale value remains in the cache after the last writes
+	for _, k := range keys {
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: k})
+		require.NoError(t, err)
+		assert.Equal(t, "50", string(res.Data), k)
+	}
+}
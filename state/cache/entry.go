@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// entry is the format of the values saved in the cache store.
+type entry struct {
+	Data        []byte            `json:"d"`
+	ETag        *string           `json:"e,omitempty"`
+	ContentType *string           `json:"c,omitempty"`
+	Metadata    map[string]string `json:"m,omitempty"`
+	// Times until which the value is fresh, and can be returned while it's refreshed, in Unix milliseconds.
+	Fresh int64 `json:"f"`
+	Stale int64 `json:"s"`
+}
+
+func parseEntry(data []byte) (*entry, error) {
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Fresh == 0 || e.Stale < e.Fresh {
+		return nil, errors.New("value is not a cache entry")
+	}
+	return &e, nil
+}
+
+func (e *entry) marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *entry) freshUntil() time.Time {
+	return time.UnixMilli(e.Fresh)
+}
+
+func (e *entry) staleUntil() time.Time {
+	return time.UnixMilli(e.Stale)
+}
+
+func (e *entry) getResponse() *state.GetResponse {
+	return &state.GetResponse{
+		Data:        e.Data,
+		ETag:        e.ETag,
+		Metadata:    e.Metadata,
+		ContentType: e.ContentType,
+	}
+}
+
+func (e *entry) bulkGetResponse(key string) state.BulkGetResponse {
+	return state.BulkGetResponse{
+		Key:         key,
+		Data:        e.Data,
+		ETag:        e.ETag,
+		Metadata:    e.Metadata,
+		ContentType: e.ContentType,
+	}
+}
+
+// ttlInSeconds returns the value of the TTL metadata property for a duration, rounded up.
+func ttlInSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package codec implements the encoding and compression of the values saved by state stores.
+//
+// Encoded values start with a header that records how they were encoded and their content type, so they can be decoded regardless of the codec configured in the store,
+// and values saved before the codec was enabled are returned as they are.
+// State stores decode values only when they have a codec, so values of stores without one are never altered, even if they happen to start with the header.
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dapr/components-contrib/state/utils"
+)
+
+// Metadata contains the metadata properties used to configure the codec.
+// State stores that support the codec embed it in their metadata struct.
+type Metadata struct {
+	// Compression algorithm for values: "gzip", "zstd", "snappy", or empty to disable compression.
+	ValueCodec string `mapstructure:"valueCodec"`
+	// Format of JSON values: "json" (the default), "msgpack" or "protobuf".
+	ValueFormat string `mapstructure:"valueFormat"`
+}
+
+// Codec encodes values with the configured format and compression.
+type Codec struct {
+	format      Format
+	compression Compression
+}
+
+// Header of encoded values: a magic string, which can't be the beginning of a JSON document, followed by the version of the header.
+var header = []byte{0x00, 'd', 's', 'c', 1}
+
+// New returns a codec for the metadata, or nil if neither compression nor format are configured.
+func New(md Metadata) (*Codec, error) {
+	c := &Codec{}
+
+	switch Compression(strings.ToLower(md.ValueCodec)) {
+	case "", CompressionNone:
+		c.compression = CompressionNone
+	case CompressionGzip:
+		c.compression = CompressionGzip
+	case CompressionZstd:
+		c.compression = CompressionZstd
+	case CompressionSnappy:
+		c.compression = CompressionSnappy
+	default:
+		return nil, fmt.Errorf("invalid value for 'valueCodec': %s", md.ValueCodec)
+	}
+
+	switch Format(strings.ToLower(md.ValueFormat)) {
+	case "", FormatJSON:
+		c.format = FormatJSON
+	case FormatMsgpack:
+		c.format = FormatMsgpack
+	case FormatProtobuf:
+		c.format = FormatProtobuf
+	default:
+		return nil, fmt.Errorf("invalid value for 'valueFormat': %s", md.ValueFormat)
+	}
+
+	if c.compression == CompressionNone && c.format == FormatJSON {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Encode serializes the value like utils.Marshal, then converts it to the configured format and compresses it.
+// Byte slices that don't contain JSON, or whose content type isn't JSON, are stored as they are.
+// Values that compression wouldn't make smaller are stored uncompressed.
+func (c *Codec) Encode(value any, contentType *string) ([]byte, error) {
+	data, err := utils.Marshal(value, json.Marshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize value: %w", err)
+	}
+
+	format := FormatRaw
+	if c.format != FormatJSON && isJSONContentType(contentType) && json.Valid(data) {
+		data, err = fromJSON(c.format, data)
+		if err != nil {
+			return nil, err
+		}
+		format = c.format
+	}
+
+	compression := CompressionNone
+	if c.compression != CompressionNone {
+		compressed, err := compress(c.compression, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(data) {
+			data = compressed
+			compression = c.compression
+		}
+	}
+
+	var ct string
+	if contentType != nil {
+		ct = *contentType
+	}
+	buf := make([]byte, 0, len(header)+2+binary.MaxVarintLen64+len(ct)+len(data))
+	buf = append(buf, header...)
+	buf = append(buf, format.id(), compression.id())
+	buf = binary.AppendUvarint(buf, uint64(len(ct)))
+	buf = append(buf, ct...)
+	buf = append(buf, data...)
+	return buf, nil
+}
+
+// IsEncoded returns true if the data has been encoded by a codec.
+func IsEncoded(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Decode returns the original value of data encoded by a codec, as JSON if it was converted to another format, and its content type.
+// Data that hasn't been encoded by a codec is returned as it is.
+func Decode(data []byte) ([]byte, *string, error) {
+	if !IsEncoded(data) {
+		return data, nil, nil
+	}
+	data = data[len(header):]
+	if len(data) < 2 {
+		return nil, nil, errors.New("invalid encoded value: header is truncated")
+	}
+	format, err := formatFromID(data[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	compression, err := compressionFromID(data[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	data = data[2:]
+
+	n, l := binary.Uvarint(data)
+	if l <= 0 || n > uint64(len(data)-l) {
+		return nil, nil, errors.New("invalid encoded value: content type is truncated")
+	}
+	var contentType *string
+	if n > 0 {
+		ct := string(data[l : l+int(n)])
+		contentType = &ct
+	}
+	data = data[l+int(n):]
+
+	data, err = decompress(compression, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format != FormatRaw {
+		data, err = toJSON(format, data)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, contentType, nil
+}
+
+// isJSONContentType returns true if the content type is not set, or is a JSON content type.
+func isJSONContentType(contentType *string) bool {
+	return contentType == nil || *contentType == "" || strings.Contains(strings.ToLower(*contentType), "json")
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/kit/ptr"
+)
+
+func TestNew(t *testing.T) {
+	c, err := New(Metadata{})
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = New(Metadata{ValueCodec: "none", ValueFormat: "JSON"})
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = New(Metadata{ValueCodec: "Zstd"})
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, CompressionZstd, c.compression)
+	assert.Equal(t, FormatJSON, c.format)
+
+	_, err = New(Metadata{ValueCodec: "lz4"})
+	require.ErrorContains(t, err, "valueCodec")
+
+	_, err = New(Metadata{ValueFormat: "xml"})
+	require.ErrorContains(t, err, "valueFormat")
+}
+
+func TestEncodeDecode(t *testing.T) {
+	large := map[string]any{
+		"session": strings.Repeat("abcdefgh", 200),
+		"count":   int64(9007199254740993),
+		"items":   []any{1.5, "a", true, nil},
+	}
+	largeJSON := `{"session":"` + strings.Repeat("abcdefgh", 200) + `","count":9007199254740993,"items":[1.5,"a",true,null]}`
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd, CompressionSnappy} {
+		for _, format := range []Format{FormatJSON, FormatMsgpack, FormatProtobuf} {
+			if compression == CompressionNone && format == FormatJSON {
+				continue
+			}
+			t.Run(string(compression)+"-"+string(format), func(t *testing.T) {
+				c, err := New(Metadata{ValueCodec: string(compression), ValueFormat: string(format)})
+				require.NoError(t, err)
+
+				t.Run("large JSON value", func(t *testing.T) {
+					enc, err := c.Encode(large, nil)
+					require.NoError(t, err)
+					assert.True(t, IsEncoded(enc))
+					if compression != CompressionNone {
+						assert.Less(t, len(enc), len(largeJSON)/5)
+					}
+
+					dec, ct, err := Decode(enc)
+					require.NoError(t, err)
+					assert.Nil(t, ct)
+					if format == FormatProtobuf {
+						// Numbers are converted to float64
+						assert.Contains(t, string(dec), strings.Repeat("abcdefgh", 200))
+					} else {
+						assert.JSONEq(t, largeJSON, string(dec))
+					}
+				})
+
+				t.Run("binary value with content type", func(t *testing.T) {
+					value := []byte{0xff, 0x00, 0x01, 0x02}
+					enc, err := c.Encode(value, ptr.Of("application/octet-stream"))
+					require.NoError(t, err)
+
+					dec, ct, err := Decode(enc)
+					require.NoError(t, err)
+					assert.Equal(t, value, dec)
+					require.NotNil(t, ct)
+					assert.Equal(t, "application/octet-stream", *ct)
+				})
+
+				t.Run("JSON bytes", func(t *testing.T) {
+					enc, err := c.Encode([]byte(`{"a":[1,2]}`), ptr.Of("application/json"))
+					require.NoError(t, err)
+
+					dec, ct, err := Decode(enc)
+					require.NoError(t, err)
+					assert.JSONEq(t, `{"a":[1,2]}`, string(dec))
+					assert.Equal(t, "application/json", *ct)
+				})
+			})
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("values that are not encoded are returned as they are", func(t *testing.T) {
+		dec, ct, err := Decode([]byte(`{"a":1}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":1}`, string(dec))
+		assert.Nil(t, ct)
+
+		dec, _, err = Decode(nil)
+		require.NoError(t, err)
+		assert.Nil(t, dec)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, _, err := Decode(header)
+		require.Error(t, err)
+
+		_, _, err = Decode(append(append([]byte{}, header...), 9, 0, 0))
+		require.ErrorContains(t, err, "unknown format")
+
+		_, _, err = Decode(append(append([]byte{}, header...), 0, 0, 10, 'a'))
+		require.ErrorContains(t, err, "content type is truncated")
+
+		_, _, err = Decode(append(append([]byte{}, header...), 0, 1, 0, 'a'))
+		require.ErrorContains(t, err, "failed to decompress")
+	})
+}
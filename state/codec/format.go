@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Format is the serialization format of JSON values.
+type Format string
+
+const (
+	// FormatRaw is used for values that are stored as they are, including JSON values when no other format is configured.
+	FormatRaw     Format = "raw"
+	FormatJSON    Format = "json"
+	FormatMsgpack Format = "msgpack"
+	// FormatProtobuf stores values as a google.protobuf.Value message, so all numbers are converted to float64.
+	FormatProtobuf Format = "protobuf"
+)
+
+// id returns the identifier of the format in the header of encoded values.
+func (f Format) id() byte {
+	switch f {
+	case FormatMsgpack:
+		return 1
+	case FormatProtobuf:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func formatFromID(id byte) (Format, error) {
+	switch id {
+	case 0:
+		return FormatRaw, nil
+	case 1:
+		return FormatMsgpack, nil
+	case 2:
+		return FormatProtobuf, nil
+	default:
+		return "", fmt.Errorf("invalid encoded value: unknown format %d", id)
+	}
+}
+
+// fromJSON converts a JSON document to the format.
+func fromJSON(f Format, data []byte) ([]byte, error) {
+	switch f {
+	case FormatMsgpack:
+		// Numbers are decoded as json.Number so integers are preserved
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON value: %w", err)
+		}
+		res, err := msgpack.Marshal(convertNumbers(v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize value with msgpack: %w", err)
+		}
+		return res, nil
+	case FormatProtobuf:
+		var v structpb.Value
+		if err := protojson.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON value: %w", err)
+		}
+		res, err := proto.Marshal(&v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize value with protobuf: %w", err)
+		}
+		return res, nil
+	default:
+		return data, nil
+	}
+}
+
+// toJSON converts a value in the format to a JSON document.
+func toJSON(f Format, data []byte) ([]byte, error) {
+	switch f {
+	case FormatMsgpack:
+		var v any
+		if err := msgpack.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse msgpack value: %w", err)
+		}
+		res, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize value to JSON: %w", err)
+		}
+		return res, nil
+	case FormatProtobuf:
+		var v structpb.Value
+		if err := proto.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse protobuf value: %w", err)
+		}
+		res, err := protojson.Marshal(&v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize value to JSON: %w", err)
+		}
+		return res, nil
+	default:
+		return data, nil
+	}
+}
+
+// convertNumbers replaces the json.Number values with int64 or float64 values.
+func convertNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = convertNumbers(e)
+		}
+		return v
+	case []any:
+		for i, e := range v {
+			v[i] = convertNumbers(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
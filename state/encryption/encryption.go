@@ -0,0 +1,422 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption implements a state store decorator that encrypts values on the client side with envelope encryption.
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	contribCrypto "github.com/dapr/components-contrib/crypto"
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/utils"
+)
+
+// Default algorithm used to wrap the data keys.
+const defaultAlgorithm = "A256KW"
+
+var errQueriesDisabled = errors.New("queries are evaluated in process by decrypting all the values of the state store, and must be enabled with the EnableInProcessQueries option")
+
+// Features of the underlying store that are preserved by the decorator.
+// Atomic operations and queries executed by the database can't work on encrypted values.
+var passthroughFeatures = []state.Feature{
+	state.FeatureETag,
+	state.FeatureTransactional,
+	state.FeatureTTL,
+	state.FeatureDeleteWithPrefix,
+	state.FeaturePartitionKey,
+	state.FeatureKeysLike,
+	state.FeatureWatch,
+	state.FeatureTTLManagement,
+	state.FeatureRangeScan,
+}
+
+// Options contains the options for the encrypted store.
+type Options struct {
+	// Crypto component used to wrap the data keys, which must be initialized.
+	Crypto contribCrypto.SubtleCrypto
+	// Name of the key used to wrap the data keys of the values that are saved, optionally including its version ("name/version").
+	// The key name is stored with each value, so values encrypted with previous keys can still be decrypted after the key is rotated.
+	KeyName string
+	// Algorithm used to wrap the data keys, which must be supported by the key.
+	// Defaults to "A256KW" (AES key wrap).
+	Algorithm string
+	// If true, queries are evaluated in process by listing all the keys of the underlying store and decrypting their values, which must implement KeysLiker.
+	// Because every query reads and decrypts the entire store, this is only suitable for small data sets.
+	EnableInProcessQueries bool
+}
+
+// Store is a state store decorator that encrypts values before saving them in the underlying store, and decrypts them after reading them.
+// Each value is encrypted with AES-GCM using a new data key, which is wrapped with the key in the crypto component and stored alongside the ciphertext.
+// The state key is used as associated data, so encrypted values can't be moved to another key.
+//
+// Queries are supported when enabled with the EnableInProcessQueries option, and are evaluated in process on the decrypted values.
+type Store struct {
+	store     state.Store
+	crypto    contribCrypto.SubtleCrypto
+	keyName   string
+	algorithm string
+	querier   *state.KeysLikeQuerier
+}
+
+// NewStore returns a store that encrypts the values saved in store.
+func NewStore(store state.Store, opts Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("state store is required")
+	}
+	if opts.Crypto == nil {
+		return nil, errors.New("crypto component is required")
+	}
+	if opts.KeyName == "" {
+		return nil, errors.New("key name is required")
+	}
+	if opts.Algorithm == "" {
+		opts.Algorithm = defaultAlgorithm
+	}
+
+	s := &Store{
+		store:     store,
+		crypto:    opts.Crypto,
+		keyName:   opts.KeyName,
+		algorithm: opts.Algorithm,
+	}
+	if opts.EnableInProcessQueries {
+		if _, ok := store.(state.KeysLiker); !ok {
+			return nil, errors.New("in-process queries require a state store that implements KeysLiker")
+		}
+		s.querier = state.NewKeysLikeQuerier(s)
+	}
+	return s, nil
+}
+
+// Init initializes the underlying store.
+func (s *Store) Init(ctx context.Context, metadata state.Metadata) error {
+	return s.store.Init(ctx, metadata)
+}
+
+// Features returns the features of the underlying store that are supported on encrypted values.
+func (s *Store) Features() []state.Feature {
+	inner := s.store.Features()
+	features := make([]state.Feature, 0, len(inner)+3)
+	for _, f := range inner {
+		if slices.Contains(passthroughFeatures, f) {
+			features = append(features, f)
+		}
+	}
+	if s.querier != nil {
+		features = append(features, state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
+	return features
+}
+
+// Get retrieves and decrypts the value of a key.
+func (s *Store) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	res, err := s.store.Get(ctx, req)
+	if err != nil || res == nil || res.Data == nil {
+		return res, err
+	}
+	res.Data, res.ContentType, err = s.decrypt(ctx, req.Key, res.Data)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Set encrypts and saves the value of a key.
+func (s *Store) Set(ctx context.Context, req *state.SetRequest) error {
+	encReq, err := s.encryptRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(ctx, encReq)
+}
+
+// Delete deletes a key from the underlying store.
+func (s *Store) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	return s.store.Delete(ctx, req)
+}
+
+// BulkGet retrieves and decrypts the values of multiple keys.
+// Values that can't be decrypted are returned with an error.
+func (s *Store) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	res, err := s.store.BulkGet(ctx, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if res[i].Error != "" || res[i].Data == nil {
+			continue
+		}
+		res[i].Data, res[i].ContentType, err = s.decrypt(ctx, res[i].Key, res[i].Data)
+		if err != nil {
+			res[i].Data = nil
+			res[i].Error = err.Error()
+		}
+	}
+	return res, nil
+}
+
+// BulkSet encrypts and saves the values of multiple keys.
+func (s *Store) BulkSet(ctx context.Context, req []state.SetRequest, opts state.BulkStoreOpts) error {
+	encReqs := make([]state.SetRequest, len(req))
+	for i := range req {
+		encReq, err := s.encryptRequest(ctx, &req[i])
+		if err != nil {
+			return err
+		}
+		encReqs[i] = *encReq
+	}
+	return s.store.BulkSet(ctx, encReqs, opts)
+}
+
+// BulkDelete deletes multiple keys from the underlying store.
+func (s *Store) BulkDelete(ctx context.Context, req []state.DeleteRequest, opts state.BulkStoreOpts) error {
+	return s.store.BulkDelete(ctx, req, opts)
+}
+
+// Multi encrypts the values of the upsert operations and executes the transaction in the underlying store.
+func (s *Store) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	transactional, ok := s.store.(state.TransactionalStore)
+	if !ok {
+		return errors.New("transactions are not supported by the state store")
+	}
+
+	encRequest := &state.TransactionalStateRequest{
+		Operations: make([]state.TransactionalStateOperation, len(request.Operations)),
+		Metadata:   withoutContentType(request.Metadata),
+	}
+	for i, o := range request.Operations {
+		switch req := o.(type) {
+		case state.SetRequest:
+			encReq, err := s.encryptRequest(ctx, &req)
+			if err != nil {
+				return err
+			}
+			encRequest.Operations[i] = *encReq
+		default:
+			encRequest.Operations[i] = o
+		}
+	}
+	return transactional.Multi(ctx, encRequest)
+}
+
+// MultiMaxSize returns the maximum number of operations in a transaction of the underlying store.
+func (s *Store) MultiMaxSize() int {
+	if m, ok := s.store.(state.TransactionalStoreMultiMaxSize); ok {
+		return m.MultiMaxSize()
+	}
+	return -1
+}
+
+// Query executes the query in process on the decrypted values.
+func (s *Store) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if s.querier == nil {
+		return nil, errQueriesDisabled
+	}
+	return s.querier.Query(ctx, req)
+}
+
+// QueryAggregate executes the query with projections or aggregations in process on the decrypted values.
+func (s *Store) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if s.querier == nil {
+		return nil, errQueriesDisabled
+	}
+	return s.querier.QueryAggregate(ctx, req)
+}
+
+// KeysLike returns the keys of the underlying store that match the pattern.
+func (s *Store) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	keysLiker, ok := s.store.(state.KeysLiker)
+	if !ok {
+		return nil, errors.New("keys like is not supported by the state store")
+	}
+	return keysLiker.KeysLike(ctx, req)
+}
+
+// RangeScan returns the keys in the range from the underlying store, with their values decrypted.
+// Only the values are encrypted, so the order of the keys is preserved.
+func (s *Store) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	scanner, ok := s.store.(state.RangeScanner)
+	if !ok {
+		return nil, errors.New("range scans are not supported by the state store")
+	}
+	res, err := scanner.RangeScan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Items {
+		if res.Items[i].Data == nil {
+			continue
+		}
+		res.Items[i].Data, res.Items[i].ContentType, err = s.decrypt(ctx, res.Items[i].Key, res.Items[i].Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt value of key %s: %w", res.Items[i].Key, err)
+		}
+	}
+	return res, nil
+}
+
+// DeleteWithPrefix deletes the keys with the prefix from the underlying store.
+func (s *Store) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	deleter, ok := s.store.(state.DeleteWithPrefix)
+	if !ok {
+		return state.DeleteWithPrefixResponse{}, errors.New("delete with prefix is not supported by the state store")
+	}
+	return deleter.DeleteWithPrefix(ctx, req)
+}
+
+// Expire changes the expiration time of a key in the underlying store, which doesn't need to decrypt the value.
+func (s *Store) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.Expire(ctx, req)
+}
+
+// GetTTL returns the expiration time of a key in the underlying store.
+func (s *Store) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.GetTTL(ctx, req)
+}
+
+// Watch delivers the changes of the underlying store to the handler, with the values decrypted.
+// Events whose value can't be decrypted are not delivered.
+func (s *Store) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	watcher, ok := s.store.(state.Watcher)
+	if !ok {
+		return errors.New("watch is not supported by the state store")
+	}
+	return watcher.Watch(ctx, req, func(ctx context.Context, e *state.WatchEvent) error {
+		if e.Type == state.WatchEventUpsert && e.Value != nil {
+			var err error
+			e.Value, _, err = s.decrypt(ctx, e.Key, e.Value)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt value of key %s: %w", e.Key, err)
+			}
+		}
+		return handler(ctx, e)
+	})
+}
+
+// Rewrap wraps the data key of the value of a key with the current key, if it was encrypted with a previous one.
+// The value itself isn't encrypted again, and its expiration time is preserved.
+// It returns true if the value has been updated.
+func (s *Store) Rewrap(ctx context.Context, key string) (bool, error) {
+	res, err := s.store.Get(ctx, &state.GetRequest{Key: key})
+	if err != nil || res == nil || res.Data == nil {
+		return false, err
+	}
+	env, err := parseEnvelope(res.Data)
+	if err != nil {
+		return false, err
+	}
+	if env.KeyName == s.keyName && env.Algorithm == s.algorithm {
+		return false, nil
+	}
+
+	dataKey, err := s.unwrapDataKey(ctx, env)
+	if err != nil {
+		return false, err
+	}
+	if err = s.wrapDataKey(ctx, env, dataKey); err != nil {
+		return false, err
+	}
+	data, err := env.marshal()
+	if err != nil {
+		return false, err
+	}
+
+	setReq := &state.SetRequest{
+		Key:   key,
+		Value: data,
+		ETag:  res.ETag,
+	}
+	if exp, ok := res.Metadata[state.GetRespMetaKeyTTLExpireTime]; ok {
+		expireTime, err := time.Parse(time.RFC3339, exp)
+		if err != nil {
+			return false, fmt.Errorf("invalid expiration time: %w", err)
+		}
+		// Round up, so the value doesn't expire before its original expiration time
+		ttl := int(time.Until(expireTime).Seconds()) + 1
+		setReq.Metadata = map[string]string{
+			utils.MetadataTTLKey: strconv.Itoa(max(ttl, 1)),
+		}
+	}
+	if err = s.store.Set(ctx, setReq); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ping pings the underlying store.
+func (s *Store) Ping(ctx context.Context) error {
+	if pinger, ok := s.store.(health.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return state.ErrPingNotImplemented
+}
+
+// GetComponentMetadata returns the metadata of the underlying store.
+func (s *Store) GetComponentMetadata() metadata.MetadataMap {
+	if m, ok := s.store.(interface{ GetComponentMetadata() metadata.MetadataMap }); ok {
+		return m.GetComponentMetadata()
+	}
+	return nil
+}
+
+// Close closes the underlying store.
+func (s *Store) Close() error {
+	return s.store.Close()
+}
+
+// encryptRequest returns a copy of the request with the value encrypted.
+func (s *Store) encryptRequest(ctx context.Context, req *state.SetRequest) (*state.SetRequest, error) {
+	plaintext, err := utils.Marshal(req.Value, json.Marshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize value: %w", err)
+	}
+	data, err := s.encrypt(ctx, req.Key, plaintext, req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content type is stored in the envelope, and removed from the metadata so the store saves the value as binary data
+	encReq := *req
+	encReq.Value = data
+	encReq.ContentType = nil
+	encReq.Metadata = withoutContentType(req.Metadata)
+	return &encReq, nil
+}
+
+// withoutContentType returns a copy of the metadata without the content type, if it's set.
+func withoutContentType(md map[string]string) map[string]string {
+	if _, ok := md[metadata.ContentType]; !ok {
+		return md
+	}
+	md = maps.Clone(md)
+	delete(md, metadata.ContentType)
+	return md
+}
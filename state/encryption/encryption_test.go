@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	contribCrypto "github.com/dapr/components-contrib/crypto"
+	"github.com/dapr/components-contrib/crypto/jwks"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+const testJWKS = `{"keys":[
+	{"kty":"oct","kid":"key1","alg":"A256KW","k":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+	{"kty":"oct","kid":"key2","alg":"A256GCM","k":"ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA"}
+]}`
+
+func newTestStore(t *testing.T) (*Store, state.Store, contribCrypto.SubtleCrypto) {
+	t.Helper()
+
+	log := logger.NewLogger("test")
+	crypto := jwks.NewJWKSCrypto(log)
+	err := crypto.Init(t.Context(), contribCrypto.Metadata{Base: metadata.Base{
+		Properties: map[string]string{"jwks": testJWKS},
+	}})
+	require.NoError(t, err)
+	t.Cleanup(func() { crypto.Close() })
+
+	inner := inmemory.NewInMemoryStateStore(log)
+	require.NoError(t, inner.Init(t.Context(), state.Metadata{}))
+	t.Cleanup(func() { inner.Close() })
+
+	s, err := NewStore(inner, Options{Crypto: crypto, KeyName: "key1", EnableInProcessQueries: true})
+	require.NoError(t, err)
+	return s, inner, crypto
+}
+
+func TestInvalidOptions(t *testing.T) {
+	log := logger.NewLogger("test")
+	inner := inmemory.NewInMemoryStateStore(log)
+	crypto := jwks.NewJWKSCrypto(log)
+
+	tests := map[string]struct {
+		store       state.Store
+		opts        Options
+		expectedErr string
+	}{
+		"Missing state store": {
+			opts:        Options{Crypto: crypto, KeyName: "key1"},
+			expectedErr: "state store is required",
+		},
+		"Missing crypto component": {
+			store:       inner,
+			opts:        Options{KeyName: "key1"},
+			expectedErr: "crypto component is required",
+		},
+		"Missing key name": {
+			store:       inner,
+			opts:        Options{Crypto: crypto},
+			expectedErr: "key name is required",
+		},
+		"In-process queries without KeysLiker": {
+			store:       &storeWithoutKeysLike{Store: inner},
+			opts:        Options{Crypto: crypto, KeyName: "key1", EnableInProcessQueries: true},
+			expectedErr: "require a state store that implements KeysLiker",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStore(tt.store, tt.opts)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	s, inner, _ := newTestStore(t)
+
+	features := s.Features()
+	assert.Contains(t, features, state.FeatureETag)
+	assert.Contains(t, features, state.FeatureTransactional)
+	assert.Contains(t, features, state.FeatureQueryAPI)
+	assert.Contains(t, features, state.FeatureQueryInProcess)
+	assert.NotContains(t, features, state.FeatureAtomicOperations)
+
+	t.Run("set and get", func(t *testing.T) {
+		err := s.Set(t.Context(), &state.SetRequest{
+			Key:         "app||secret",
+			Value:       map[string]string{"card": "4111111111111111"},
+			ContentType: ptr.Of("application/json"),
+		})
+		require.NoError(t, err)
+
+		// The value in the underlying store is encrypted
+		raw, err := inner.Get(t.Context(), &state.GetRequest{Key: "app||secret"})
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw.Data), "4111111111111111")
+		assert.Contains(t, string(raw.Data), `"kid":"key1"`)
+
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: "app||secret"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"card":"4111111111111111"}`, string(res.Data))
+		assert.Equal(t, "application/json", *res.ContentType)
+		assert.Equal(t, raw.ETag, res.ETag)
+
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "app||missing"})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+	})
+
+	t.Run("value moved to another key", func(t *testing.T) {
+		raw, err := inner.Get(t.Context(), &state.GetRequest{Key: "app||secret"})
+		require.NoError(t, err)
+		require.NoError(t, inner.Set(t.Context(), &state.SetRequest{Key: "app||other", Value: raw.Data}))
+
+		_, err = s.Get(t.Context(), &state.GetRequest{Key: "app||other"})
+		require.ErrorContains(t, err, "failed to decrypt")
+	})
+
+	t.Run("value not encrypted", func(t *testing.T) {
+		require.NoError(t, inner.Set(t.Context(), &state.SetRequest{Key: "app||plain", Value: "plain"}))
+
+		_, err := s.Get(t.Context(), &state.GetRequest{Key: "app||plain"})
+		require.ErrorIs(t, err, ErrNotEncrypted)
+
+		res, err := s.BulkGet(t.Context(), []state.GetRequest{{Key: "app||secret"}, {Key: "app||plain"}}, state.BulkGetOpts{})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		for _, r := range res {
+			if r.Key == "app||plain" {
+				assert.Equal(t, ErrNotEncrypted.Error(), r.Error)
+			} else {
+				assert.JSONEq(t, `{"card":"4111111111111111"}`, string(r.Data))
+			}
+		}
+		require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: "app||plain"}))
+		require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: "app||other"}))
+	})
+
+	t.Run("transactions and bulk set", func(t *testing.T) {
+		err := s.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "app||a", Value: map[string]int{"n": 1}},
+				state.DeleteRequest{Key: "app||secret"},
+			},
+		})
+		require.NoError(t, err)
+		err = s.BulkSet(t.Context(), []state.SetRequest{
+			{Key: "app||b", Value: map[string]int{"n": 2}},
+			{Key: "app||c", Value: []byte{0xff, 0x00}},
+		}, state.BulkStoreOpts{})
+		require.NoError(t, err)
+
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: "app||a"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"n":1}`, string(res.Data))
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "app||c"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xff, 0x00}, res.Data)
+		res, err = s.Get(t.Context(), &state.GetRequest{Key: "app||secret"})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"filter":{"EQ":{"n":2}}}`), &req.Query))
+		res, err := s.Query(t.Context(), &req)
+		require.NoError(t, err)
+		require.Len(t, res.Results, 1)
+		assert.Equal(t, "app||b", res.Results[0].Key)
+		assert.JSONEq(t, `{"n":2}`, string(res.Results[0].Data))
+	})
+
+	t.Run("range scan", func(t *testing.T) {
+		res, err := s.RangeScan(t.Context(), &state.RangeScanRequest{Start: "app||a", End: "app||c"})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		assert.Equal(t, "app||a", res.Items[0].Key)
+		assert.JSONEq(t, `{"n":1}`, string(res.Items[0].Data))
+		assert.Equal(t, "app||b", res.Items[1].Key)
+		assert.JSONEq(t, `{"n":2}`, string(res.Items[1].Data))
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		ch := make(chan *state.WatchEvent, 1)
+		err := s.Watch(t.Context(), &state.WatchRequest{Key: "app||w"}, func(_ context.Context, e *state.WatchEvent) error {
+			ch <- e
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "app||w", Value: "v"}))
+		e := <-ch
+		assert.Equal(t, `"v"`, string(e.Value))
+	})
+}
+
+func TestQueriesDisabledByDefault(t *testing.T) {
+	s, inner, crypto := newTestStore(t)
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "app||a", Value: map[string]int{"n": 1}}))
+
+	s, err := NewStore(inner, Options{Crypto: crypto, KeyName: "key1"})
+	require.NoError(t, err)
+	features := s.Features()
+	assert.NotContains(t, features, state.FeatureQueryAPI)
+	assert.NotContains(t, features, state.FeatureQueryAggregate)
+	assert.NotContains(t, features, state.FeatureQueryInProcess)
+
+	var req state.QueryRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"filter":{"EQ":{"n":1}}}`), &req.Query))
+	_, err = s.Query(t.Context(), &req)
+	require.ErrorIs(t, err, errQueriesDisabled)
+	_, err = s.QueryAggregate(t.Context(), &req)
+	require.ErrorIs(t, err, errQueriesDisabled)
+}
+
+// storeWithoutKeysLike hides the KeysLike method of the store.
+type storeWithoutKeysLike struct {
+	state.Store
+}
+
+func TestKeyRotation(t *testing.T) {
+	s, inner, crypto := newTestStore(t)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{
+		Key:      "app||k",
+		Value:    strings.Repeat("x", 100),
+		Metadata: map[string]string{"ttlInSeconds": "1000"},
+	}))
+
+	// Values encrypted with the previous key are still readable after the key is changed
+	rotated, err := NewStore(inner, Options{Crypto: crypto, KeyName: "key2", Algorithm: "A256GCM"})
+	require.NoError(t, err)
+	res, err := rotated.Get(t.Context(), &state.GetRequest{Key: "app||k"})
+	require.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("x", 100)+`"`, string(res.Data))
+
+	ok, err := rotated.Rewrap(t.Context(), "app||k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = rotated.Rewrap(t.Context(), "app||k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	raw, err := inner.Get(t.Context(), &state.GetRequest{Key: "app||k"})
+	require.NoError(t, err)
+	assert.Contains(t, string(raw.Data), `"kid":"key2"`)
+	assert.Contains(t, raw.Metadata, state.GetRespMetaKeyTTLExpireTime)
+
+	res, err = s.Get(t.Context(), &state.GetRequest{Key: "app||k"})
+	require.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("x", 100)+`"`, string(res.Data))
+}
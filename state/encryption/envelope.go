@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+const (
+	// Version of the envelope format.
+	envelopeVersion = 1
+	// Size of the data keys, for AES-256-GCM.
+	dataKeySize = 32
+)
+
+// ErrNotEncrypted is returned when reading a value that hasn't been encrypted by the store.
+var ErrNotEncrypted = errors.New("value is not encrypted")
+
+// envelope is the format of the encrypted values saved in the underlying store.
+type envelope struct {
+	Version int `json:"v"`
+	// Name of the key that wrapped the data key, and algorithm used.
+	KeyName   string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// Wrapped data key, with the nonce and tag used by authenticated wrapping algorithms.
+	WrappedKey []byte `json:"wk"`
+	WrapNonce  []byte `json:"wn,omitempty"`
+	WrapTag    []byte `json:"wt,omitempty"`
+	// Value encrypted with AES-GCM, with the tag appended.
+	Nonce      []byte `json:"n"`
+	Ciphertext []byte `json:"ct"`
+	// Content type of the value, if set.
+	ContentType *string `json:"cty,omitempty"`
+}
+
+func parseEnvelope(data []byte) (*envelope, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Version == 0 {
+		return nil, ErrNotEncrypted
+	}
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("unsupported version of encrypted value: %d", env.Version)
+	}
+	return &env, nil
+}
+
+func (e *envelope) marshal() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize encrypted value: %w", err)
+	}
+	return data, nil
+}
+
+// encrypt encrypts the value with a new data key, and returns the envelope.
+func (s *Store) encrypt(ctx context.Context, key string, plaintext []byte, contentType *string) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	env := &envelope{
+		Version:     envelopeVersion,
+		Nonce:       make([]byte, gcm.NonceSize()),
+		ContentType: contentType,
+	}
+	if _, err = rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plaintext, []byte(key))
+
+	if err = s.wrapDataKey(ctx, env, dataKey); err != nil {
+		return nil, err
+	}
+	return env.marshal()
+}
+
+// decrypt decrypts the envelope saved for the key, and returns the value and its content type.
+func (s *Store) decrypt(ctx context.Context, key string, data []byte) ([]byte, *string, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataKey, err := s.unwrapDataKey(ctx, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, nil, errors.New("invalid nonce in encrypted value")
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, []byte(key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, env.ContentType, nil
+}
+
+// wrapDataKey wraps the data key with the current key, and stores it in the envelope.
+func (s *Store) wrapDataKey(ctx context.Context, env *envelope, dataKey []byte) error {
+	jwkKey, err := jwk.FromRaw(dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to create JWK from data key: %w", err)
+	}
+
+	// Authenticated wrapping algorithms require a nonce, which is ignored by the other algorithms
+	var nonce []byte
+	if strings.HasSuffix(s.algorithm, "GCM") {
+		nonce = make([]byte, 12)
+		if _, err = rand.Read(nonce); err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+	}
+
+	wrapped, tag, err := s.crypto.WrapKey(ctx, jwkKey, s.algorithm, s.keyName, nonce, nil)
+	if err != nil {
+		return fmt.Errorf("failed to wrap data key with key %s: %w", s.keyName, err)
+	}
+	env.KeyName = s.keyName
+	env.Algorithm = s.algorithm
+	env.WrappedKey = wrapped
+	env.WrapNonce = nonce
+	env.WrapTag = tag
+	return nil
+}
+
+// unwrapDataKey unwraps the data key in the envelope with the key that wrapped it.
+func (s *Store) unwrapDataKey(ctx context.Context, env *envelope) ([]byte, error) {
+	jwkKey, err := s.crypto.UnwrapKey(ctx, env.WrappedKey, env.Algorithm, env.KeyName, env.WrapNonce, env.WrapTag, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %s: %w", env.KeyName, err)
+	}
+	var dataKey []byte
+	if err = jwkKey.Raw(&dataKey); err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	if len(dataKey) != dataKeySize {
+		return nil, errors.New("invalid data key size")
+	}
+	return dataKey, nil
+}
+
+func newGCM(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
@@ -30,6 +30,12 @@ const (
 
 var ErrKeysLikeEmptyPattern = errors.New("keys like pattern cannot be empty")
 
+// ErrAtomicOperandType is returned by AtomicOperator when the value of the key has a type that doesn't support the operation.
+var ErrAtomicOperandType = errors.New("the value of the key has a type that doesn't support the operation")
+
+// ErrRevisionNotFound is returned by VersionedStore when the requested revision is not retained by the state store.
+var ErrRevisionNotFound = errors.New("the revision is not retained by the state store")
+
 // ETagError is a custom error type for etag exceptions.
 type ETagError struct {
 	err  error
@@ -122,3 +128,67 @@ func (e BulkStoreError) ETagError() *ETagError {
 	}
 	return nil
 }
+
+// ChunkedTransactionError is returned by MultiChunked when a chunk of a transaction fails after other chunks have been applied.
+type ChunkedTransactionError struct {
+	chunk           int
+	chunks          int
+	err             error
+	compensationErr error
+}
+
+// Chunk returns the number of the chunk that failed, starting from 1.
+func (e *ChunkedTransactionError) Chunk() int {
+	return e.chunk
+}
+
+// Compensated returns true if the changes of the chunks that were applied have been reverted.
+func (e *ChunkedTransactionError) Compensated() bool {
+	return e.compensationErr == nil
+}
+
+// CompensationError returns the error that occurred while reverting the changes of the chunks that were applied, if any.
+func (e *ChunkedTransactionError) CompensationError() error {
+	return e.compensationErr
+}
+
+func (e *ChunkedTransactionError) Error() string {
+	msg := fmt.Sprintf("chunk %d of %d of the transaction failed: %v", e.chunk, e.chunks, e.err)
+	if e.compensationErr != nil {
+		return msg + "; failed to revert the chunks that were applied: " + e.compensationErr.Error()
+	}
+	return msg + "; the chunks that were applied have been reverted"
+}
+
+// Unwrap returns the error of the chunk that failed.
+func (e *ChunkedTransactionError) Unwrap() error {
+	return e.err
+}
+
+// SchemaValidationError is returned by state stores that validate values when a value doesn't conform to the JSON Schema of its key.
+type SchemaValidationError struct {
+	key string
+	err error
+}
+
+// NewSchemaValidationError returns a SchemaValidationError for the value of the key, wrapping the validation error.
+func NewSchemaValidationError(key string, err error) *SchemaValidationError {
+	return &SchemaValidationError{
+		key: key,
+		err: err,
+	}
+}
+
+// Key returns the key whose value doesn't conform to the schema.
+func (e *SchemaValidationError) Key() string {
+	return e.key
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("the value of key %s doesn't conform to the schema: %v", e.key, e.err)
+}
+
+// Unwrap returns the validation error.
+func (e *SchemaValidationError) Unwrap() error {
+	return e.err
+}
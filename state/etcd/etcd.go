@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -45,6 +46,8 @@ type Etcd struct {
 	logger        logger.Logger
 	schema        schemaMarshaller
 	maxTxnOps     int
+	// If true, queries are evaluated in process, reading all the keys
+	inProcessQueries bool
 }
 
 type etcdConfig struct {
@@ -57,6 +60,8 @@ type etcdConfig struct {
 	Key       string `json:"key"`
 	// Transaction server options
 	MaxTxnOps int `mapstructure:"maxTxnOps"`
+	// If true, queries are evaluated in process, reading all the keys
+	EnableInProcessQueries bool `mapstructure:"enableInProcessQueries"`
 }
 
 // NewEtcdStateStoreV1 returns a new etcd state store for schema V1.
@@ -78,6 +83,8 @@ func newETCD(logger logger.Logger, schema schemaMarshaller) state.Store {
 			state.FeatureTransactional,
 			state.FeatureTTL,
 			state.FeatureKeysLike,
+			state.FeatureVersioning,
+			state.FeatureRangeScan,
 		},
 	}
 	s.BulkStore = state.NewDefaultBulkStore(s)
@@ -99,6 +106,7 @@ func (e *Etcd) Init(_ context.Context, metadata state.Metadata) error {
 
 	e.keyPrefixPath = etcdConfig.KeyPrefixPath
 	e.maxTxnOps = etcdConfig.MaxTxnOps
+	e.inProcessQueries = etcdConfig.EnableInProcessQueries
 
 	return nil
 }
@@ -136,6 +144,9 @@ func (e *Etcd) ParseClientFromConfig(etcdConfig *etcdConfig) (*clientv3.Client,
 
 // Features returns the features available in this state store.
 func (e *Etcd) Features() []state.Feature {
+	if e.inProcessQueries {
+		return append(slices.Clone(e.features), state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
 	return e.features
 }
 
@@ -450,6 +461,23 @@ func NewTLSConfig(clientCert, clientKey, caCert string) (*tls.Config, error) {
 	return config, nil
 }
 
+// Query executes a query against the keys in etcd.
+// Queries are evaluated in process by state.KeysLikeQuerier, which reads all the keys for each query, so they must be enabled with the enableInProcessQueries metadata property.
+func (e *Etcd) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !e.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(e).Query(ctx, req)
+}
+
+// QueryAggregate executes a query with projections or aggregations against the keys in etcd.
+func (e *Etcd) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !e.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(e).QueryAggregate(ctx, req)
+}
+
 func (e *Etcd) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
 	if len(req.Pattern) == 0 {
 		return nil, state.ErrKeysLikeEmptyPattern
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+// Keys are read with a single range request, which is served by etcd in key order; expired keys are removed by etcd with their leases.
+func (e *Etcd) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(e.keyPrefixPath, "/") + "/"
+	from := base + req.Start
+	end := clientv3.GetPrefixRangeEnd(base)
+	if req.End != "" {
+		end = base + req.End
+	}
+	order := clientv3.SortAscend
+	if req.Reverse {
+		order = clientv3.SortDescend
+	}
+	if req.ContinuationToken != nil {
+		// Continue after the last key of the previous page, which is excluded from the range
+		token := base + *req.ContinuationToken
+		switch {
+		case req.Reverse && token < end:
+			end = token
+		case !req.Reverse && token+"\x00" > from:
+			from = token + "\x00"
+		}
+	}
+
+	res := &state.RangeScanResponse{}
+	if from >= end {
+		return res, nil
+	}
+	opts := []clientv3.OpOption{
+		clientv3.WithRange(end),
+		clientv3.WithSort(clientv3.SortByKey, order),
+	}
+	if req.Limit > 0 {
+		// One more key is read to know if there's another page
+		opts = append(opts, clientv3.WithLimit(int64(req.Limit)+1))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	resp, err := e.client.Get(ctx, from, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't scan range: %w", err)
+	}
+
+	res.Items = make([]state.RangeScanItem, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if req.Limit > 0 && len(res.Items) == int(req.Limit) {
+			res.ContinuationToken = ptr.Of(res.Items[len(res.Items)-1].Key)
+			break
+		}
+		item, err := e.getResponse(kv)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode key %s: %w", kv.Key, err)
+		}
+		res.Items = append(res.Items, state.RangeScanItem{
+			Key:      strings.TrimPrefix(string(kv.Key), base),
+			Data:     item.Data,
+			ETag:     item.ETag,
+			Metadata: item.Metadata,
+		})
+	}
+	return res, nil
+}
@@ -52,5 +52,15 @@ func TestGetEtcdMetadata(t *testing.T) {
 		assert.Equal(t, properties["endpoints"], metadata.Endpoints)
 		assert.Equal(t, properties["keyPrefixPath"], metadata.KeyPrefixPath)
 		assert.Equal(t, properties["tlsEnable"], metadata.TLSEnable)
+		assert.False(t, metadata.EnableInProcessQueries)
+	})
+
+	t.Run("with in-process queries", func(t *testing.T) {
+		metadata, err := metadataToConfig(map[string]string{
+			"endpoints":              "127.0.0.1:2379",
+			"enableInProcessQueries": "true",
+		})
+		require.NoError(t, err)
+		assert.True(t, metadata.EnableInProcessQueries)
 	})
 }
@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// Interval between the requests of progress notifications, while reading the history of a key.
+const progressRequestInterval = 100 * time.Millisecond
+
+// GetRevisions returns the revisions of the key, from the most recent one.
+// Revisions are the ones retained by etcd's MVCC store, until it's compacted.
+// The time of a revision is known only for values written with schema v2, and it's zero for deletions.
+func (e *Etcd) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	events, err := e.history(ctx, e.keyPrefixPath+"/"+req.Key)
+	if err != nil {
+		return nil, err
+	}
+	res := &state.GetRevisionsResponse{
+		Revisions: make([]state.Revision, 0, len(events)),
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if req.Limit > 0 && len(res.Revisions) == req.Limit {
+			break
+		}
+		res.Revisions = append(res.Revisions, e.revision(events[i]))
+	}
+	return res, nil
+}
+
+// GetRevision returns the value of the key at a revision, or at a point in time.
+// Reads at a point in time require values written with schema v2, and they don't consider deletions, whose time is unknown.
+func (e *Etcd) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	keyWithPath := e.keyPrefixPath + "/" + req.Key
+	if req.Revision != nil {
+		return e.getAtRevision(ctx, keyWithPath, *req.Revision)
+	}
+
+	if _, ok := e.schema.(schemaV1); ok {
+		return nil, errors.New("reading a value at a point in time requires schema v2")
+	}
+	events, err := e.history(ctx, keyWithPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type != mvccpb.PUT {
+			continue
+		}
+		r := e.revision(events[i])
+		if r.Time.After(*req.Time) {
+			continue
+		}
+		return e.getResponse(events[i].Kv)
+	}
+	return nil, state.ErrRevisionNotFound
+}
+
+// getAtRevision returns the value of the key written or deleted at the revision.
+func (e *Etcd) getAtRevision(ctx context.Context, keyWithPath string, rev int64) (*state.GetResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, keyWithPath, clientv3.WithRev(rev))
+	if err != nil {
+		if errors.Is(err, rpctypes.ErrCompacted) || errors.Is(err, rpctypes.ErrFutureRev) {
+			return nil, state.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("couldn't get key %s at revision %d: %w", keyWithPath, rev, err)
+	}
+	if len(resp.Kvs) > 0 {
+		if resp.Kvs[0].ModRevision != rev {
+			return nil, state.ErrRevisionNotFound
+		}
+		return e.getResponse(resp.Kvs[0])
+	}
+
+	// The key doesn't exist at the revision: check if it was deleted by it
+	if rev <= 1 {
+		return nil, state.ErrRevisionNotFound
+	}
+	resp, err = e.client.Get(ctx, keyWithPath, clientv3.WithRev(rev-1), clientv3.WithCountOnly())
+	if err != nil {
+		if errors.Is(err, rpctypes.ErrCompacted) {
+			return nil, state.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("couldn't get key %s at revision %d: %w", keyWithPath, rev-1, err)
+	}
+	if resp.Count == 0 {
+		return nil, state.ErrRevisionNotFound
+	}
+	return &state.GetResponse{}, nil
+}
+
+func (e *Etcd) getResponse(kv *mvccpb.KeyValue) (*state.GetResponse, error) {
+	data, metadata, err := e.schema.decode(kv.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &state.GetResponse{
+		Data:     data,
+		ETag:     ptr.Of(strconv.FormatInt(kv.ModRevision, 10)),
+		Metadata: metadata,
+	}, nil
+}
+
+func (e *Etcd) revision(ev *clientv3.Event) state.Revision {
+	r := state.Revision{
+		Revision: ev.Kv.ModRevision,
+		Deleted:  ev.Type == mvccpb.DELETE,
+	}
+	if !r.Deleted {
+		r.ETag = ptr.Of(strconv.FormatInt(ev.Kv.ModRevision, 10))
+		r.Time = e.schema.timestamp(ev.Kv.Value)
+	}
+	return r
+}
+
+// history returns the changes to the key retained by etcd, from the oldest one.
+// It replays the changes with a watch from the oldest revision that isn't compacted, up to the current revision.
+func (e *Etcd) history(parentCtx context.Context, keyWithPath string) ([]*clientv3.Event, error) {
+	ctx, cancel := context.WithTimeout(parentCtx, 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.Get(ctx, keyWithPath, clientv3.WithCountOnly())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get key %s: %w", keyWithPath, err)
+	}
+	current := resp.Header.Revision
+
+	var (
+		events []*clientv3.Event
+		from   int64 = 1
+	)
+	for {
+		done, compacted, err := e.replay(ctx, keyWithPath, from, current, &events)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return events, nil
+		}
+		// The revisions before compacted aren't available anymore: start again from there
+		events = events[:0]
+		from = compacted
+	}
+}
+
+// replay appends to events the changes to the key from the revision from, up to the revision to.
+// If the revision from has been compacted, it returns the oldest revision available.
+func (e *Etcd) replay(parentCtx context.Context, keyWithPath string, from, to int64, events *[]*clientv3.Event) (done bool, compacted int64, err error) {
+	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+
+	watchCh := e.client.Watch(ctx, keyWithPath, clientv3.WithRev(from))
+	ticker := time.NewTicker(progressRequestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, 0, fmt.Errorf("couldn't read the history of key %s: %w", keyWithPath, ctx.Err())
+		case <-ticker.C:
+			// Progress notifications are sent only when the watch has caught up with the current revision
+			err = e.client.RequestProgress(ctx)
+			if err != nil {
+				return false, 0, fmt.Errorf("couldn't read the history of key %s: %w", keyWithPath, err)
+			}
+		case wr, ok := <-watchCh:
+			if !ok {
+				return false, 0, fmt.Errorf("couldn't read the history of key %s: watch closed", keyWithPath)
+			}
+			if wr.CompactRevision != 0 {
+				return false, wr.CompactRevision, nil
+			}
+			if err = wr.Err(); err != nil {
+				return false, 0, fmt.Errorf("couldn't read the history of key %s: %w", keyWithPath, err)
+			}
+			for _, ev := range wr.Events {
+				if ev.Kv.ModRevision > to {
+					return true, 0, nil
+				}
+				*events = append(*events, ev)
+			}
+			if wr.Header.Revision >= to && (wr.IsProgressNotify() || len(wr.Events) > 0 && wr.Events[len(wr.Events)-1].Kv.ModRevision == to) {
+				return true, 0, nil
+			}
+		}
+	}
+}
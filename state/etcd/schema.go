@@ -36,6 +36,10 @@ type schemaMarshaller interface {
 	// decode the value from the correct storage schema, optionally returning
 	// metadata extracted from the envelope.
 	decode(data []byte) ([]byte, map[string]string, error)
+
+	// timestamp returns the time the value was written, or the zero time if
+	// it's not recorded by the storage schema.
+	timestamp(data []byte) time.Time
 }
 
 type schemaV1 struct{}
@@ -52,6 +56,10 @@ func (schemaV1) decode(data []byte) ([]byte, map[string]string, error) {
 	return data, nil, nil
 }
 
+func (schemaV1) timestamp([]byte) time.Time {
+	return time.Time{}
+}
+
 type schemaV2 struct{}
 
 func (schemaV2) encode(data any, ttlInSeconds *int64) (string, error) {
@@ -89,3 +97,11 @@ func (schemaV2) decode(data []byte) ([]byte, map[string]string, error) {
 
 	return value.GetData(), metadata, nil
 }
+
+func (schemaV2) timestamp(data []byte) time.Time {
+	var value pbv2.Value
+	if err := proto.Unmarshal(data, &value); err != nil || value.GetTs() == nil {
+		return time.Time{}
+	}
+	return value.GetTs().AsTime()
+}
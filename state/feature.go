@@ -24,6 +24,10 @@ const (
 	FeatureTransactional Feature = "TRANSACTIONAL"
 	// FeatureQueryAPI is the feature that performs query operations.
 	FeatureQueryAPI Feature = "QUERY_API"
+	// FeatureQueryInProcess is the feature that reports that queries are evaluated in process by reading all the items of the store, rather than by the database.
+	FeatureQueryInProcess Feature = "QUERY_IN_PROCESS"
+	// FeatureQueryAggregate is the feature that supports projections and aggregations in queries.
+	FeatureQueryAggregate Feature = "QUERY_AGGREGATE"
 	// FeatureTTL is the feature that supports TTLs.
 	FeatureTTL Feature = "TTL"
 	// FeatureDeleteWithPrefix is the feature that supports deleting with prefix.
@@ -32,6 +36,18 @@ const (
 	FeaturePartitionKey Feature = "PARTITION_KEY"
 	// FeatureKeysLike is the feature that supports keys like list operation.
 	FeatureKeysLike Feature = "KEYS_LIKE"
+	// FeatureWatch is the feature that supports watching changes to keys.
+	FeatureWatch Feature = "WATCH"
+	// FeatureAtomicOperations is the feature that supports atomic increments, appends and compare-and-swap operations.
+	FeatureAtomicOperations Feature = "ATOMIC_OPERATIONS"
+	// FeatureVersioning is the feature that supports reading the previous revisions of keys.
+	FeatureVersioning Feature = "VERSIONING"
+	// FeatureTTLManagement is the feature that supports changing and reading the expiration time of keys without rewriting their values.
+	FeatureTTLManagement Feature = "TTL_MANAGEMENT"
+	// FeatureRangeScan is the feature that supports reading the keys in a range, in lexical order.
+	FeatureRangeScan Feature = "RANGE_SCAN"
+	// FeatureOutbox is the feature that supports saving messages in a transactional outbox, to be published after the transaction is committed.
+	FeatureOutbox Feature = "TRANSACTIONAL_OUTBOX"
 )
 
 // Feature names a feature that can be implemented by state store components.
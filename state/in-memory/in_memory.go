@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
@@ -33,9 +34,26 @@ import (
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
+	kitmd "github.com/dapr/kit/metadata"
 	"github.com/dapr/kit/ptr"
 )
 
+type inMemoryMetadata struct {
+	// Directory where the state is persisted.
+	// If empty, the state is kept in memory only and is lost when the store is closed.
+	PersistenceDir string `mapstructure:"persistenceDir"`
+	// Policy for flushing the write-ahead log to disk: "always", "everysec" (default) or "no".
+	Fsync string `mapstructure:"fsync"`
+	// Interval between the snapshots that compact the write-ahead log.
+	// Defaults to 5 minutes.
+	SnapshotInterval time.Duration `mapstructure:"snapshotInterval"`
+	// Maximum number of revisions retained for each key, including the current one.
+	// Versioning is enabled if this or historyRetention is set.
+	HistoryLimit int `mapstructure:"historyLimit"`
+	// Duration for which previous revisions are retained.
+	HistoryRetention time.Duration `mapstructure:"historyRetention"`
+}
+
 type InMemoryStore struct {
 	state.BulkStore
 
@@ -48,6 +66,18 @@ type InMemoryStore struct {
 	closeCh chan struct{}
 	closed  atomic.Bool
 	wg      sync.WaitGroup
+
+	watchers     map[*watcher]struct{}
+	watchersLock sync.Mutex
+
+	// Nil if persistence is disabled.
+	persistence *persistence
+
+	// Nil if versioning is disabled.
+	history          map[string][]revision
+	historyLimit     int
+	historyRetention time.Duration
+	revision         int64
 }
 
 func NewInMemoryStateStore(log logger.Logger) state.Store {
@@ -56,16 +86,37 @@ func NewInMemoryStateStore(log logger.Logger) state.Store {
 
 func newStateStore(log logger.Logger) *InMemoryStore {
 	s := &InMemoryStore{
-		items:   map[string]*inMemStateStoreItem{},
-		log:     log,
-		closeCh: make(chan struct{}),
-		clock:   clock.RealClock{},
+		items:    map[string]*inMemStateStoreItem{},
+		watchers: map[*watcher]struct{}{},
+		log:      log,
+		closeCh:  make(chan struct{}),
+		clock:    clock.RealClock{},
 	}
 	s.BulkStore = state.NewDefaultBulkStore(s)
 	return s
 }
 
 func (store *InMemoryStore) Init(ctx context.Context, metadata state.Metadata) error {
+	var md inMemoryMetadata
+	err := kitmd.DecodeMetadata(metadata.Properties, &md)
+	if err != nil {
+		return err
+	}
+	if md.HistoryLimit < 0 || md.HistoryRetention < 0 {
+		return errors.New("historyLimit and historyRetention cannot be negative")
+	}
+	if md.HistoryLimit > 0 || md.HistoryRetention > 0 {
+		store.history = map[string][]revision{}
+		store.historyLimit = md.HistoryLimit
+		store.historyRetention = md.HistoryRetention
+	}
+	if md.PersistenceDir != "" {
+		err = store.initPersistence(md)
+		if err != nil {
+			return err
+		}
+	}
+
 	// start a background go routine to clean expired item
 	store.wg.Add(1)
 	go func() {
@@ -79,27 +130,45 @@ func (store *InMemoryStore) Close() error {
 	if store.closed.CompareAndSwap(false, true) {
 		close(store.closeCh)
 	}
+	store.wg.Wait()
 
-	// release memory reference
 	store.lock.Lock()
 	defer store.lock.Unlock()
+
+	// take a last snapshot, so the state is restored faster
+	var err error
+	if store.persistence != nil && store.persistence.wal != nil {
+		err = store.doTakeSnapshot()
+		err = errors.Join(err, store.persistence.close())
+	}
+
+	// release memory reference
 	for k := range store.items {
 		delete(store.items, k)
 	}
+	store.history = nil
 
-	store.wg.Wait()
-
-	return nil
+	return err
 }
 
 func (store *InMemoryStore) Features() []state.Feature {
-	return []state.Feature{
+	features := []state.Feature{
 		state.FeatureETag,
 		state.FeatureTransactional,
 		state.FeatureTTL,
 		state.FeatureDeleteWithPrefix,
 		state.FeatureKeysLike,
+		state.FeatureQueryAPI,
+		state.FeatureQueryAggregate,
+		state.FeatureWatch,
+		state.FeatureAtomicOperations,
+		state.FeatureTTLManagement,
+		state.FeatureRangeScan,
+	}
+	if store.history != nil {
+		features = append(features, state.FeatureVersioning)
 	}
+	return features
 }
 
 func (store *InMemoryStore) Delete(ctx context.Context, req *state.DeleteRequest) error {
@@ -120,7 +189,7 @@ func (store *InMemoryStore) Delete(ctx context.Context, req *state.DeleteRequest
 	// step3: do really delete
 	// this operation won't fail
 	store.doDelete(ctx, req.Key)
-	return nil
+	return store.persist()
 }
 
 func (store *InMemoryStore) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
@@ -143,11 +212,14 @@ func (store *InMemoryStore) DeleteWithPrefix(ctx context.Context, req state.Dele
 			// The string contains the prefix, now we check to make sure there aren't more || after
 			longerPrefix := strings.Contains(key[len(req.Prefix):], "||")
 			if !longerPrefix {
-				delete(store.items, key)
+				store.doDelete(ctx, key)
 				count++
 			}
 		}
 	}
+	if err = store.persist(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
 	return state.DeleteWithPrefixResponse{Count: count}, nil
 }
 
@@ -179,7 +251,36 @@ func (store *InMemoryStore) doValidateEtag(key string, etag *string, concurrency
 }
 
 func (store *InMemoryStore) doDelete(ctx context.Context, key string) {
+	item, ok := store.items[key]
+	if !ok {
+		return
+	}
+	store.recordRevision(key, nil)
+	delete(store.items, key)
+	store.changed(key, item, nil, &state.WatchEvent{
+		Type: state.WatchEventDelete,
+		Key:  key,
+	})
+}
+
+// changed records a change for the write-ahead log, if persistence is enabled, and notifies the watchers.
+// When the change is recorded, the watchers are notified by persist, only after it's written.
+func (store *InMemoryStore) changed(key string, prev *inMemStateStoreItem, next *inMemStateStoreItem, e *state.WatchEvent) {
+	if store.persistence != nil {
+		store.persistence.record(key, prev, next, e)
+		return
+	}
+	store.notify(e)
+}
+
+// removeItem removes an item that has expired and notifies the watchers.
+// Expirations aren't written to the write-ahead log, since expired items are discarded when the state is restored.
+func (store *InMemoryStore) removeItem(key string) {
 	delete(store.items, key)
+	store.notify(&state.WatchEvent{
+		Type: state.WatchEventDelete,
+		Key:  key,
+	})
 }
 
 func (store *InMemoryStore) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
@@ -247,7 +348,7 @@ func (store *InMemoryStore) getAndExpire(key string) *inMemStateStoreItem {
 		return nil
 	}
 	if item.isExpired(store.clock.Now()) {
-		delete(store.items, key)
+		store.removeItem(key)
 		return nil
 	}
 	return item
@@ -291,7 +392,7 @@ func (store *InMemoryStore) Set(ctx context.Context, req *state.SetRequest) erro
 
 	// this operation won't fail
 	store.doSet(ctx, req.Key, bt, ttlInSeconds)
-	return nil
+	return store.persist()
 }
 
 func (store *InMemoryStore) doSetValidateParameters(req *state.SetRequest) (int, error) {
@@ -327,20 +428,41 @@ func doParseTTLInSeconds(metadata map[string]string) (int, error) {
 }
 
 func (store *InMemoryStore) doSet(ctx context.Context, key string, data []byte, ttlInSeconds int) {
+	var expire *time.Time
+	if ttlInSeconds > 0 {
+		expire = ptr.Of(store.clock.Now().Add(time.Duration(ttlInSeconds) * time.Second))
+	}
+	store.doSetItem(key, data, expire)
+}
+
+// doSetItem stores the value with a new ETag and returns the item.
+func (store *InMemoryStore) doSetItem(key string, data []byte, expire *time.Time) *inMemStateStoreItem {
 	etag := uuid.New().String()
 	el := &inMemStateStoreItem{
-		data: data,
-		etag: &etag,
-		idx:  store.idx,
+		data:   data,
+		etag:   &etag,
+		expire: expire,
+		idx:    store.idx,
 	}
 
 	store.idx++
 
-	if ttlInSeconds > 0 {
-		el.expire = ptr.Of(store.clock.Now().Add(time.Duration(ttlInSeconds) * time.Second))
-	}
+	store.doStoreItem(key, el)
+	return el
+}
 
+// doStoreItem replaces the item of the key, recording and notifying the change.
+func (store *InMemoryStore) doStoreItem(key string, el *inMemStateStoreItem) {
+	prev := store.items[key]
+	store.recordRevision(key, el)
 	store.items[key] = el
+	store.changed(key, prev, el, &state.WatchEvent{
+		Type:       state.WatchEventUpsert,
+		Key:        key,
+		Value:      el.data,
+		ETag:       el.etag,
+		ExpireTime: el.expire,
+	})
 }
 
 // innerSetRequest is only used to pass ttlInSeconds and data with SetRequest.
@@ -426,7 +548,9 @@ func (store *InMemoryStore) Multi(ctx context.Context, request *state.Transactio
 			store.doDelete(ctx, req.Key)
 		}
 	}
-	return nil
+
+	// the transaction is written to the write-ahead log as a single record
+	return store.persist()
 }
 
 func (store *InMemoryStore) startCleanThread() {
@@ -434,6 +558,9 @@ func (store *InMemoryStore) startCleanThread() {
 		select {
 		case <-time.After(time.Second):
 			store.doCleanExpiredItems()
+			if store.persistence != nil {
+				store.doPersistenceMaintenance()
+			}
 		case <-store.closeCh:
 			return
 		}
@@ -446,13 +573,18 @@ func (store *InMemoryStore) doCleanExpiredItems() {
 
 	for key, item := range store.items {
 		if item.expire != nil && item.isExpired(store.clock.Now()) {
-			store.doDelete(context.Background(), key)
+			store.removeItem(key)
 		}
 	}
+
+	if store.history != nil {
+		store.doPruneHistory()
+	}
 }
 
 func (store *InMemoryStore) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
-	// no metadata, hence no metadata struct to convert here
+	metadataStruct := inMemoryMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.StateStoreType)
 	return
 }
 
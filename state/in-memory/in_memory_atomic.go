@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	stateutils "github.com/dapr/components-contrib/state/utils"
+	"github.com/dapr/kit/ptr"
+)
+
+// Increment adds the delta to the integer value of the key.
+func (store *InMemoryStore) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := &state.IncrementResponse{}
+	etag, err := store.doAtomic(req.Key, req.Metadata, func(item *inMemStateStoreItem) ([]byte, bool, error) {
+		var value int64
+		if item != nil {
+			var err error
+			value, err = strconv.ParseInt(string(bytes.TrimSpace(item.data)), 10, 64)
+			if err != nil {
+				return nil, false, state.ErrAtomicOperandType
+			}
+		}
+		res.Value = value + req.Delta
+		return strconv.AppendInt(nil, res.Value, 10), true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.ETag = etag
+	return res, nil
+}
+
+// Append adds the values at the end of the JSON array stored in the key.
+func (store *InMemoryStore) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := &state.AppendResponse{}
+	etag, err := store.doAtomic(req.Key, req.Metadata, func(item *inMemStateStoreItem) ([]byte, bool, error) {
+		// Existing items are kept as they are, so numbers don't lose precision
+		arr := []json.RawMessage{}
+		if item != nil {
+			if err := json.Unmarshal(item.data, &arr); err != nil || arr == nil {
+				return nil, false, state.ErrAtomicOperandType
+			}
+		}
+		for _, v := range req.Values {
+			bt, err := json.Marshal(v)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to serialize value: %w", err)
+			}
+			arr = append(arr, bt)
+		}
+		data, err := json.Marshal(arr)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to serialize values: %w", err)
+		}
+		res.Length = int64(len(arr))
+		return data, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.ETag = etag
+	return res, nil
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one.
+func (store *InMemoryStore) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var expected []byte
+	if req.Expected != nil {
+		var err error
+		expected, err = store.marshal(req.Expected)
+		if err != nil {
+			return nil, err
+		}
+	}
+	value, err := store.marshal(req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, err := store.doAtomic(req.Key, req.Metadata, func(item *inMemStateStoreItem) ([]byte, bool, error) {
+		if item == nil {
+			return value, expected == nil, nil
+		}
+		return value, expected != nil && bytes.Equal(item.data, expected), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state.CompareAndSwapResponse{
+		Swapped: etag != nil,
+		ETag:    etag,
+	}, nil
+}
+
+// doAtomic replaces the value of the key with the one returned by fn, while holding the write lock.
+// fn receives the current item, or nil if the key doesn't exist, and returns false if the value must not be updated.
+// It returns the ETag of the new value, or nil if the value wasn't updated.
+func (store *InMemoryStore) doAtomic(key string, metadata map[string]string, fn func(item *inMemStateStoreItem) ([]byte, bool, error)) (*string, error) {
+	ttl, err := stateutils.ParseTTL(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	item := store.getAndExpire(key)
+	data, ok, err := fn(item)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// Keep the current expiration time unless a TTL is set
+	var expire *time.Time
+	switch {
+	case ttl != nil && *ttl > 0:
+		expire = ptr.Of(store.clock.Now().Add(time.Duration(*ttl) * time.Second))
+	case ttl == nil && item != nil:
+		expire = item.expire
+	}
+
+	el := store.doSetItem(key, data, expire)
+	if err = store.persist(); err != nil {
+		return nil, err
+	}
+	return el.etag, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInMemoryAtomicOperations(t *testing.T) {
+	store := NewInMemoryStateStore(logger.NewLogger("test")).(*InMemoryStore)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	store.clock = fakeClock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	defer store.Close()
+
+	var _ state.AtomicOperator = store
+	assert.True(t, state.FeatureAtomicOperations.IsPresent(store.Features()))
+
+	get := func(t *testing.T, key string) *state.GetResponse {
+		t.Helper()
+		res, err := store.Get(t.Context(), &state.GetRequest{Key: key})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("increment", func(t *testing.T) {
+		res, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: 5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), res.Value)
+
+		res, err = store.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: -7})
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), res.Value)
+
+		got := get(t, "counter")
+		assert.Equal(t, "-2", string(got.Data))
+		assert.Equal(t, res.ETag, got.ETag)
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "concurrent", Delta: 1})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, "50", string(get(t, "concurrent").Data))
+	})
+
+	t.Run("increment with TTL", func(t *testing.T) {
+		_, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "rate", Delta: 1, Metadata: map[string]string{"ttlInSeconds": "10"}})
+		require.NoError(t, err)
+
+		// The expiration time is kept when no TTL is set
+		fakeClock.Step(5 * time.Second)
+		res, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "rate", Delta: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Value)
+
+		// Expired keys start again from zero
+		fakeClock.Step(6 * time.Second)
+		res, err = store.Increment(t.Context(), &state.IncrementRequest{Key: "rate", Delta: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Value)
+	})
+
+	t.Run("increment a value that is not an integer", func(t *testing.T) {
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "string", Value: "hello"}))
+		_, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "string", Delta: 1})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		res, err := store.Append(t.Context(), &state.AppendRequest{Key: "list", Values: []any{"a", 1}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Length)
+
+		res, err = store.Append(t.Context(), &state.AppendRequest{Key: "list", Values: []any{map[string]any{"b": true}}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Length)
+		assert.JSONEq(t, `["a",1,{"b":true}]`, string(get(t, "list").Data))
+
+		_, err = store.Append(t.Context(), &state.AppendRequest{Key: "string", Values: []any{"a"}})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+
+		_, err = store.Append(t.Context(), &state.AppendRequest{Key: "list"})
+		require.Error(t, err)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		// Expected nil requires the key not to exist
+		res, err := store.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Value: "v1"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, get(t, "cas").ETag, res.ETag)
+
+		res, err = store.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Value: "v1"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+		assert.Nil(t, res.ETag)
+
+		res, err = store.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Expected: "other", Value: "v2"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+
+		res, err = store.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Expected: "v1", Value: "v2"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, `"v2"`, string(get(t, "cas").Data))
+	})
+}
@@ -0,0 +1,563 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	// Changes are flushed to disk after each write.
+	fsyncAlways = "always"
+	// Changes are flushed to disk every second.
+	fsyncEverySec = "everysec"
+	// Changes are flushed to disk by the operating system.
+	fsyncNo = "no"
+
+	defaultSnapshotInterval = 5 * time.Minute
+
+	walFilePrefix      = "wal-"
+	walFileSuffix      = ".log"
+	snapshotFilePrefix = "snapshot-"
+	snapshotFileSuffix = ".dat"
+
+	// Size of the header of each record: length and CRC-32 checksum of the payload.
+	recordHeaderSize = 8
+	// Records larger than this are considered corrupted.
+	maxRecordSize = 1 << 30
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// walOp is a change saved in the write-ahead log or in a snapshot.
+type walOp struct {
+	Key    string     `json:"k"`
+	Data   []byte     `json:"d,omitempty"`
+	ETag   string     `json:"e,omitempty"`
+	Expire *time.Time `json:"x,omitempty"`
+	// If true, the key was deleted.
+	Delete bool `json:"del,omitempty"`
+}
+
+// change is a change to the items that hasn't been written to the write-ahead log yet.
+// It contains the previous item, so the change can be reverted if it can't be written.
+type change struct {
+	key  string
+	prev *inMemStateStoreItem
+	// Nil if the key was deleted.
+	next *inMemStateStoreItem
+	// Delivered to the watchers once the change is written.
+	event *state.WatchEvent
+}
+
+// persistence saves the changes to the state in a write-ahead log, which is compacted periodically into a snapshot.
+//
+// Files are numbered by generation: the snapshot of generation N contains the state at the beginning of the write-ahead log of generation N.
+// The state is restored by loading the most recent snapshot and replaying the logs of the same and later generations.
+// Both files are sequences of records, each containing a JSON array of operations preceded by its length and checksum, so a record that was written partially is detected and discarded.
+type persistence struct {
+	dir              string
+	fsync            string
+	snapshotInterval time.Duration
+	log              logger.Logger
+
+	wal          *os.File
+	gen          uint64
+	size         int64
+	lastSnapshot time.Time
+
+	pending []change
+}
+
+func newPersistence(md inMemoryMetadata, log logger.Logger) (*persistence, error) {
+	p := &persistence{
+		dir:              md.PersistenceDir,
+		fsync:            strings.ToLower(md.Fsync),
+		snapshotInterval: md.SnapshotInterval,
+		log:              log,
+	}
+	switch p.fsync {
+	case "":
+		p.fsync = fsyncEverySec
+	case fsyncAlways, fsyncEverySec, fsyncNo:
+	default:
+		return nil, fmt.Errorf("invalid value for fsync: %s", md.Fsync)
+	}
+	if p.snapshotInterval == 0 {
+		p.snapshotInterval = defaultSnapshotInterval
+	}
+
+	err := os.MkdirAll(p.dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create persistence directory: %w", err)
+	}
+	return p, nil
+}
+
+// restore invokes apply for each operation saved in the most recent snapshot and in the write-ahead logs, in order.
+// It then opens the write-ahead log for appending new changes.
+func (p *persistence) restore(apply func(op walOp)) error {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read persistence directory: %w", err)
+	}
+	var snapshots, wals []uint64
+	for _, e := range entries {
+		if gen, ok := parseGeneration(e.Name(), snapshotFilePrefix, snapshotFileSuffix); ok {
+			snapshots = append(snapshots, gen)
+		} else if gen, ok := parseGeneration(e.Name(), walFilePrefix, walFileSuffix); ok {
+			wals = append(wals, gen)
+		}
+	}
+	slices.Sort(wals)
+
+	// Snapshots are renamed to their final name only when they are complete, so they must not contain invalid records
+	var snapshotGen uint64
+	if len(snapshots) > 0 {
+		snapshotGen = slices.Max(snapshots)
+		path := p.snapshotPath(snapshotGen)
+		valid, complete, err := readRecords(path, apply)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+		if !complete {
+			return fmt.Errorf("snapshot %s is corrupted at offset %d", path, valid)
+		}
+	}
+
+	p.gen = max(snapshotGen, 1)
+	for _, gen := range wals {
+		if gen < snapshotGen {
+			continue
+		}
+		path := p.walPath(gen)
+		valid, complete, err := readRecords(path, apply)
+		if err != nil {
+			return fmt.Errorf("failed to read write-ahead log %s: %w", path, err)
+		}
+		if !complete {
+			// The last record was written partially, most likely because the process was terminated
+			p.log.Warnf("Discarding the invalid records at the end of write-ahead log %s, from offset %d", path, valid)
+			err = os.Truncate(path, valid)
+			if err != nil {
+				return fmt.Errorf("failed to truncate write-ahead log %s: %w", path, err)
+			}
+		}
+		p.gen = gen
+	}
+
+	p.wal, err = os.OpenFile(p.walPath(p.gen), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	info, err := p.wal.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	p.size = info.Size()
+	p.lastSnapshot = time.Now()
+
+	p.removeOldFiles(snapshotGen)
+	return nil
+}
+
+// record adds a change to the pending ones.
+func (p *persistence) record(key string, prev *inMemStateStoreItem, next *inMemStateStoreItem, event *state.WatchEvent) {
+	p.pending = append(p.pending, change{key: key, prev: prev, next: next, event: event})
+}
+
+// append writes the changes to the write-ahead log as a single record, so they are restored atomically.
+func (p *persistence) append(changes []change) error {
+	ops := make([]walOp, len(changes))
+	for i, c := range changes {
+		ops[i] = opFromItem(c.key, c.next)
+	}
+	record, err := encodeRecord(ops)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.wal.Write(record)
+	if err == nil && p.fsync == fsyncAlways {
+		err = p.wal.Sync()
+	}
+	if err != nil {
+		// Remove the record if it was written partially
+		if tErr := p.wal.Truncate(p.size); tErr != nil {
+			p.log.Errorf("Failed to truncate the write-ahead log after a failed write: %v", tErr)
+		}
+		return err
+	}
+	p.size += int64(len(record))
+	return nil
+}
+
+// sync flushes the write-ahead log to disk, if the policy requires it.
+func (p *persistence) sync() error {
+	if p.fsync != fsyncEverySec {
+		return nil
+	}
+	return p.wal.Sync()
+}
+
+// shouldSnapshot returns true if a snapshot must be taken, because the interval has passed and the write-ahead log contains new changes.
+func (p *persistence) shouldSnapshot(now time.Time) bool {
+	return p.size > 0 && now.Sub(p.lastSnapshot) >= p.snapshotInterval
+}
+
+// rotate starts a new generation of the write-ahead log, and returns it.
+// The snapshot of the new generation must contain the state at the moment rotate is invoked.
+func (p *persistence) rotate() (uint64, error) {
+	wal, err := os.OpenFile(p.walPath(p.gen+1), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create write-ahead log: %w", err)
+	}
+	err = p.closeWAL()
+	if err != nil {
+		wal.Close()
+		return 0, err
+	}
+
+	p.wal = wal
+	p.gen++
+	p.size = 0
+	p.lastSnapshot = time.Now()
+	return p.gen, nil
+}
+
+// writeSnapshot saves the items in the snapshot of the generation, and removes the files of the previous generations.
+// It can be invoked without holding the lock, as long as the items aren't modified.
+func (p *persistence) writeSnapshot(gen uint64, items map[string]*inMemStateStoreItem, now time.Time) error {
+	path := p.snapshotPath(gen)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	w := bufio.NewWriter(f)
+	err = func() error {
+		defer f.Close()
+		for key, item := range items {
+			if item.isExpired(now) {
+				continue
+			}
+			record, err := encodeRecord([]walOp{opFromItem(key, item)})
+			if err != nil {
+				return err
+			}
+			if _, err = w.Write(record); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	p.syncDir()
+	p.removeOldFiles(gen)
+	return nil
+}
+
+// close flushes and closes the write-ahead log.
+func (p *persistence) close() error {
+	if p.wal == nil {
+		return nil
+	}
+	err := p.closeWAL()
+	p.wal = nil
+	return err
+}
+
+func (p *persistence) closeWAL() error {
+	err := p.wal.Sync()
+	if err != nil {
+		return fmt.Errorf("failed to flush write-ahead log: %w", err)
+	}
+	return p.wal.Close()
+}
+
+// removeOldFiles removes the snapshots and write-ahead logs of generations before gen, which aren't needed anymore.
+func (p *persistence) removeOldFiles(gen uint64) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		p.log.Warnf("Failed to read persistence directory: %v", err)
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		fileGen, ok := parseGeneration(name, snapshotFilePrefix, snapshotFileSuffix)
+		if !ok {
+			fileGen, ok = parseGeneration(name, walFilePrefix, walFileSuffix)
+		}
+		if ok && fileGen < gen {
+			if err = os.Remove(filepath.Join(p.dir, name)); err != nil {
+				p.log.Warnf("Failed to remove %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// syncDir flushes the directory to disk, so renamed files are persisted.
+func (p *persistence) syncDir() {
+	d, err := os.Open(p.dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	// Not supported on all platforms
+	_ = d.Sync()
+}
+
+func (p *persistence) walPath(gen uint64) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%s%020d%s", walFilePrefix, gen, walFileSuffix))
+}
+
+func (p *persistence) snapshotPath(gen uint64) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%s%020d%s", snapshotFilePrefix, gen, snapshotFileSuffix))
+}
+
+func parseGeneration(name string, prefix string, suffix string) (uint64, bool) {
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return 0, false
+	}
+	gen, err := strconv.ParseUint(name[len(prefix):len(name)-len(suffix)], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return gen, true
+}
+
+func opFromItem(key string, item *inMemStateStoreItem) walOp {
+	if item == nil {
+		return walOp{Key: key, Delete: true}
+	}
+	op := walOp{
+		Key:    key,
+		Data:   item.data,
+		Expire: item.expire,
+	}
+	if item.etag != nil {
+		op.ETag = *item.etag
+	}
+	return op
+}
+
+func encodeRecord(ops []walOp) ([]byte, error) {
+	payload, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize changes: %w", err)
+	}
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+	return append(record, payload...), nil
+}
+
+// readRecords invokes apply for each operation in the file.
+// It returns the size of the valid records, and false if the file contains an invalid record after them.
+func readRecords(path string, apply func(op walOp)) (int64, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var (
+		valid  int64
+		header [recordHeaderSize]byte
+	)
+	for {
+		_, err = io.ReadFull(r, header[:])
+		if errors.Is(err, io.EOF) {
+			return valid, true, nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return valid, false, nil
+		}
+		if err != nil {
+			return valid, false, err
+		}
+
+		size := binary.LittleEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return valid, false, nil
+		}
+		payload := make([]byte, size)
+		_, err = io.ReadFull(r, payload)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return valid, false, nil
+		}
+		if err != nil {
+			return valid, false, err
+		}
+
+		var ops []walOp
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:8]) || json.Unmarshal(payload, &ops) != nil {
+			return valid, false, nil
+		}
+		for _, op := range ops {
+			apply(op)
+		}
+		valid += int64(recordHeaderSize) + int64(size)
+	}
+}
+
+// initPersistence restores the state saved in the directory, and starts saving the changes there.
+func (store *InMemoryStore) initPersistence(md inMemoryMetadata) error {
+	p, err := newPersistence(md, store.log)
+	if err != nil {
+		return err
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	now := store.clock.Now()
+	err = p.restore(func(op walOp) {
+		// Items that have expired in the meanwhile are discarded
+		if op.Delete || (op.Expire != nil && now.After(*op.Expire)) {
+			delete(store.items, op.Key)
+			return
+		}
+		data := op.Data
+		if data == nil {
+			data = []byte{}
+		}
+		store.items[op.Key] = &inMemStateStoreItem{
+			data:   data,
+			etag:   ptr.Of(op.ETag),
+			expire: op.Expire,
+			idx:    store.idx,
+		}
+		store.idx++
+	})
+	if err != nil {
+		if p.wal != nil {
+			p.wal.Close()
+		}
+		return fmt.Errorf("failed to restore state from %s: %w", md.PersistenceDir, err)
+	}
+	store.log.Infof("Restored %d keys from %s", len(store.items), md.PersistenceDir)
+
+	store.persistence = p
+	return nil
+}
+
+// persist writes the pending changes to the write-ahead log, if persistence is enabled.
+// It must be invoked while holding the write lock, after each operation that modifies the items.
+// The watchers are notified of the changes once they're written; if they can't be written, they are reverted without notifying them.
+func (store *InMemoryStore) persist() error {
+	p := store.persistence
+	if p == nil || len(p.pending) == 0 {
+		return nil
+	}
+
+	changes := p.pending
+	p.pending = nil
+	err := p.append(changes)
+	if err != nil {
+		for i := len(changes) - 1; i >= 0; i-- {
+			store.revertRevision(changes[i].key)
+			if changes[i].prev == nil {
+				delete(store.items, changes[i].key)
+			} else {
+				store.items[changes[i].key] = changes[i].prev
+			}
+		}
+		return fmt.Errorf("failed to write changes to the write-ahead log: %w", err)
+	}
+	for _, c := range changes {
+		store.notify(c.event)
+	}
+	return nil
+}
+
+// doPersistenceMaintenance flushes the write-ahead log and takes a snapshot, when needed.
+func (store *InMemoryStore) doPersistenceMaintenance() {
+	store.lock.RLock()
+	err := store.persistence.sync()
+	snapshot := store.persistence.shouldSnapshot(time.Now())
+	store.lock.RUnlock()
+	if err != nil {
+		store.log.Errorf("Failed to flush the write-ahead log: %v", err)
+	}
+
+	if snapshot {
+		err = store.takeSnapshot()
+		if err != nil {
+			store.log.Errorf("Failed to take a snapshot of the state: %v", err)
+		}
+	}
+}
+
+// takeSnapshot compacts the write-ahead log into a snapshot.
+// The write lock is held only while starting a new write-ahead log, and not while writing the snapshot.
+func (store *InMemoryStore) takeSnapshot() error {
+	store.lock.Lock()
+	if store.persistence.size == 0 {
+		store.lock.Unlock()
+		return nil
+	}
+	gen, err := store.persistence.rotate()
+	if err != nil {
+		store.lock.Unlock()
+		return err
+	}
+	// Items are never modified, only replaced, so a shallow copy is enough
+	items := maps.Clone(store.items)
+	store.lock.Unlock()
+
+	return store.persistence.writeSnapshot(gen, items, store.clock.Now())
+}
+
+// doTakeSnapshot compacts the write-ahead log into a snapshot, while holding the write lock.
+func (store *InMemoryStore) doTakeSnapshot() error {
+	if store.persistence.size == 0 {
+		return nil
+	}
+	gen, err := store.persistence.rotate()
+	if err != nil {
+		return err
+	}
+	return store.persistence.writeSnapshot(gen, store.items, store.clock.Now())
+}
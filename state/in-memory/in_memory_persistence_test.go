@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func newPersistentStore(t *testing.T, dir string, clock *clocktesting.FakeClock) *InMemoryStore {
+	t.Helper()
+
+	store := newStateStore(logger.NewLogger("test"))
+	store.clock = clock
+	err := store.Init(t.Context(), state.Metadata{Base: metadata.Base{
+		Properties: map[string]string{
+			"persistenceDir": dir,
+			"fsync":          "always",
+		},
+	}})
+	require.NoError(t, err)
+	return store
+}
+
+// crash stops the store without flushing or compacting its state.
+func crash(t *testing.T, store *InMemoryStore) {
+	t.Helper()
+
+	store.lock.Lock()
+	require.NoError(t, store.persistence.wal.Close())
+	store.persistence = nil
+	store.lock.Unlock()
+	require.NoError(t, store.Close())
+}
+
+func getValue(t *testing.T, store *InMemoryStore, key string) *state.GetResponse {
+	t.Helper()
+
+	res, err := store.Get(t.Context(), &state.GetRequest{Key: key})
+	require.NoError(t, err)
+	return res
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	clock := clocktesting.NewFakeClock(time.Now())
+
+	store := newPersistentStore(t, dir, clock)
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "a", Value: "v1"}))
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "b", Value: []byte{0x01}}))
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "short", Value: "v", Metadata: map[string]string{"ttlInSeconds": "10"}}))
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "long", Value: "v", Metadata: map[string]string{"ttlInSeconds": "100"}}))
+	require.NoError(t, store.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "c", Value: "tx"},
+			state.DeleteRequest{Key: "b"},
+		},
+	}))
+	_, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "n", Delta: 5})
+	require.NoError(t, err)
+	etag := getValue(t, store, "a").ETag
+	expire := getValue(t, store, "long").Metadata[state.GetRespMetaKeyTTLExpireTime]
+	crash(t, store)
+
+	t.Run("state is restored from the write-ahead log", func(t *testing.T) {
+		clock.Step(20 * time.Second)
+		store = newPersistentStore(t, dir, clock)
+
+		res := getValue(t, store, "a")
+		assert.Equal(t, `"v1"`, string(res.Data))
+		assert.Equal(t, *etag, *res.ETag)
+		assert.Nil(t, getValue(t, store, "b").Data)
+		assert.Equal(t, `"tx"`, string(getValue(t, store, "c").Data))
+		assert.Equal(t, "5", string(getValue(t, store, "n").Data))
+
+		// Expiration times are preserved
+		assert.Nil(t, getValue(t, store, "short").Data)
+		assert.Equal(t, expire, getValue(t, store, "long").Metadata[state.GetRespMetaKeyTTLExpireTime])
+	})
+
+	t.Run("state is restored from a snapshot", func(t *testing.T) {
+		require.NoError(t, store.takeSnapshot())
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "d", Value: "after"}))
+		_, err = store.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "x"})
+		require.NoError(t, err)
+		require.NoError(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "c"}))
+
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+		crash(t, store)
+
+		store = newPersistentStore(t, dir, clock)
+		assert.Equal(t, `"v1"`, string(getValue(t, store, "a").Data))
+		assert.Equal(t, `"after"`, string(getValue(t, store, "d").Data))
+		assert.Nil(t, getValue(t, store, "c").Data)
+	})
+
+	t.Run("state is compacted when the store is closed", func(t *testing.T) {
+		require.NoError(t, store.Close())
+
+		snapshots, err := filepath.Glob(filepath.Join(dir, "snapshot-*"))
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		wals, err := filepath.Glob(filepath.Join(dir, "wal-*"))
+		require.NoError(t, err)
+		require.Len(t, wals, 1)
+		info, err := os.Stat(wals[0])
+		require.NoError(t, err)
+		assert.Zero(t, info.Size())
+
+		store = newPersistentStore(t, dir, clock)
+		defer store.Close()
+		assert.Equal(t, `"after"`, string(getValue(t, store, "d").Data))
+		assert.Equal(t, "5", string(getValue(t, store, "n").Data))
+	})
+}
+
+func TestPersistenceTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	clock := clocktesting.NewFakeClock(time.Now())
+
+	store := newPersistentStore(t, dir, clock)
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "a", Value: "v1"}))
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "b", Value: "v2"}))
+	wal := store.persistence.wal.Name()
+	size := store.persistence.size
+	crash(t, store)
+
+	// Simulate a record that was written partially
+	f, err := os.OpenFile(wal, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0x10, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03, 0x04, '['})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	store = newPersistentStore(t, dir, clock)
+	defer store.Close()
+	assert.Equal(t, `"v1"`, string(getValue(t, store, "a").Data))
+	assert.Equal(t, `"v2"`, string(getValue(t, store, "b").Data))
+	info, err := os.Stat(wal)
+	require.NoError(t, err)
+	assert.Equal(t, size, info.Size())
+
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "c", Value: "v3"}))
+}
+
+func TestPersistenceWriteFailure(t *testing.T) {
+	store := newPersistentStore(t, t.TempDir(), clocktesting.NewFakeClock(time.Now()))
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "app||a", Value: "v1"}))
+
+	events := make(chan *state.WatchEvent, 10)
+	err := store.Watch(t.Context(), &state.WatchRequest{Prefix: "app||"}, func(_ context.Context, e *state.WatchEvent) error {
+		events <- e
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Writes to the write-ahead log fail once it's closed
+	store.lock.Lock()
+	require.NoError(t, store.persistence.wal.Close())
+	store.lock.Unlock()
+
+	require.Error(t, store.Set(t.Context(), &state.SetRequest{Key: "app||b", Value: "v2"}))
+	require.Error(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "app||a"}))
+	require.Error(t, store.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "app||c", Value: "tx"},
+			state.DeleteRequest{Key: "app||a"},
+		},
+	}))
+	_, err = store.Increment(t.Context(), &state.IncrementRequest{Key: "app||n", Delta: 1})
+	require.Error(t, err)
+
+	// The changes are reverted, and the watchers are not notified
+	assert.Equal(t, `"v1"`, string(getValue(t, store, "app||a").Data))
+	assert.Nil(t, getValue(t, store, "app||b").Data)
+	assert.Nil(t, getValue(t, store, "app||c").Data)
+	select {
+	case e := <-events:
+		assert.Failf(t, "unexpected event", "%s of key %s", e.Type, e.Key)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	store.lock.Lock()
+	store.persistence = nil
+	store.lock.Unlock()
+	require.NoError(t, store.Close())
+}
+
+func TestPersistenceMetadata(t *testing.T) {
+	store := newStateStore(logger.NewLogger("test"))
+	err := store.Init(t.Context(), state.Metadata{Base: metadata.Base{
+		Properties: map[string]string{
+			"persistenceDir": t.TempDir(),
+			"fsync":          "sometimes",
+		},
+	}})
+	require.ErrorContains(t, err, "invalid value for fsync")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/query"
+)
+
+// Query executes a query against the items held in memory.
+func (store *InMemoryStore) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	q := &query.Evaluator{}
+	qbuilder := query.NewQueryBuilder(q)
+	if err := qbuilder.BuildQuery(&req.Query); err != nil {
+		return &state.QueryResponse{}, err
+	}
+
+	kk := store.snapshot()
+	docs := make([]query.Document, len(kk.keys))
+	for i, key := range kk.keys {
+		docs[i] = query.Document{
+			Key:  key,
+			Data: kk.items[i].data,
+			ETag: kk.items[i].etag,
+		}
+	}
+	docs, token, err := q.Execute(docs)
+	if err != nil {
+		return &state.QueryResponse{}, err
+	}
+
+	data := make([]state.QueryItem, len(docs))
+	for i, d := range docs {
+		data[i] = state.QueryItem{
+			Key:  d.Key,
+			Data: d.Data,
+			ETag: d.ETag,
+		}
+	}
+
+	return &state.QueryResponse{
+		Results: data,
+		Token:   token,
+	}, nil
+}
+
+// QueryAggregate executes a query with projections or aggregations against the items held in memory.
+func (store *InMemoryStore) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	return store.Query(ctx, req)
+}
+
+// snapshot returns the keys and items that are not expired, in insertion order.
+func (store *InMemoryStore) snapshot() *sortingKeys {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	now := store.clock.Now()
+	kk := &sortingKeys{
+		keys:  make([]string, 0, len(store.items)),
+		items: make([]*inMemStateStoreItem, 0, len(store.items)),
+	}
+	for k, item := range store.items {
+		if item.isExpired(now) {
+			continue
+		}
+		kk.keys = append(kk.keys, k)
+		kk.items = append(kk.items, item)
+	}
+	sort.Stable(kk)
+
+	return kk
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInMemoryQuery(t *testing.T) {
+	store := NewInMemoryStateStore(logger.NewLogger("test")).(*InMemoryStore)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	store.clock = fakeClock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	defer store.Close()
+
+	var _ state.Querier = store
+	assert.True(t, state.FeatureQueryAPI.IsPresent(store.Features()))
+
+	docs := []struct {
+		key   string
+		value string
+		ttl   string
+	}{
+		{"k1", `{"person":{"org":"A","name":"alice","id":3},"state":"CA","tags":["vip","new"]}`, ""},
+		{"k2", `{"person":{"org":"B","name":"bob","id":1},"state":"WA","tags":[]}`, ""},
+		{"k3", `{"person":{"org":"B","name":"carl","id":2},"state":"CA","tags":["vip"]}`, ""},
+		{"k4", `{"person":{"org":"C","name":"dave","id":4},"state":"TX"}`, ""},
+		{"k5", `{"person":{"org":"A","name":"erin","id":5},"state":"WA"}`, "1"},
+		{"k6", `not json`, ""},
+	}
+	for _, d := range docs {
+		req := &state.SetRequest{
+			Key:   d.key,
+			Value: []byte(d.value),
+		}
+		if d.ttl != "" {
+			req.Metadata = map[string]string{"ttlInSeconds": d.ttl}
+		}
+		require.NoError(t, store.Set(t.Context(), req))
+	}
+	// Expire k5
+	fakeClock.Step(2 * time.Second)
+
+	runQuery := func(t *testing.T, q string) *state.QueryResponse {
+		t.Helper()
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(q), &req.Query))
+		resp, err := store.Query(t.Context(), &req)
+		require.NoError(t, err)
+		return resp
+	}
+	keys := func(resp *state.QueryResponse) []string {
+		res := make([]string, len(resp.Results))
+		for i, r := range resp.Results {
+			res[i] = r.Key
+		}
+		return res
+	}
+
+	t.Run("no filter returns all non-expired items in insertion order", func(t *testing.T) {
+		resp := runQuery(t, `{}`)
+		assert.Equal(t, []string{"k1", "k2", "k3", "k4", "k6"}, keys(resp))
+		assert.Empty(t, resp.Token)
+		assert.Equal(t, []byte("not json"), resp.Results[4].Data)
+		assert.NotNil(t, resp.Results[0].ETag)
+	})
+
+	t.Run("EQ on nested field", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"EQ":{"person.org":"B"}}}`)
+		assert.Equal(t, []string{"k2", "k3"}, keys(resp))
+	})
+
+	t.Run("NEQ", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"NEQ":{"state":"CA"}}}`)
+		assert.Equal(t, []string{"k2", "k4"}, keys(resp))
+	})
+
+	t.Run("numeric comparisons", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"AND":[{"GT":{"person.id":1}},{"LTE":{"person.id":3}}]}}`)
+		assert.Equal(t, []string{"k1", "k3"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":{"OR":[{"LT":{"person.id":2}},{"GTE":{"person.id":4}}]}}`)
+		assert.Equal(t, []string{"k2", "k4"}, keys(resp))
+	})
+
+	t.Run("IN", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"IN":{"state":["TX","WA"]}}}`)
+		assert.Equal(t, []string{"k2", "k4"}, keys(resp))
+	})
+
+	t.Run("NOT and NIN", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"NOT":{"OR":[{"EQ":{"state":"CA"}},{"EQ":{"state":"TX"}}]}}}`)
+		assert.Equal(t, []string{"k2"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":{"NIN":{"person.org":["A","B"]}}}`)
+		assert.Equal(t, []string{"k4"}, keys(resp))
+
+		// Documents where the field is missing match
+		resp = runQuery(t, `{"filter":{"NIN":{"tags":["x"]}}}`)
+		assert.Equal(t, []string{"k1", "k2", "k3", "k4"}, keys(resp))
+	})
+
+	t.Run("EXISTS", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"EXISTS":{"tags":true}}}`)
+		assert.Equal(t, []string{"k1", "k2", "k3"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":{"EXISTS":{"tags":false}}}`)
+		assert.Equal(t, []string{"k4"}, keys(resp))
+	})
+
+	t.Run("LIKE", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"LIKE":{"person.name":"%a%"}}}`)
+		assert.Equal(t, []string{"k1", "k3", "k4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":{"LIKE":{"person.name":"b_b"}}}`)
+		assert.Equal(t, []string{"k2"}, keys(resp))
+	})
+
+	t.Run("CONTAINS", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"CONTAINS":{"tags":"vip"}}}`)
+		assert.Equal(t, []string{"k1", "k3"}, keys(resp))
+	})
+
+	t.Run("BETWEEN", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"BETWEEN":{"person.id":[2,3]}}}`)
+		assert.Equal(t, []string{"k1", "k3"}, keys(resp))
+	})
+
+	t.Run("sorting", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"NEQ":{"state":"XX"}},"sort":[{"key":"state","order":"DESC"},{"key":"person.name"}]}`)
+		assert.Equal(t, []string{"k2", "k4", "k1", "k3"}, keys(resp))
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		const q = `{"filter":{"NEQ":{"state":"XX"}},"sort":[{"key":"person.id"}],"page":{"limit":3%s}}`
+		resp := runQuery(t, fmt.Sprintf(q, ""))
+		assert.Equal(t, []string{"k2", "k3", "k1"}, keys(resp))
+		assert.Equal(t, "3", resp.Token)
+
+		resp = runQuery(t, fmt.Sprintf(q, `,"token":"3"`))
+		assert.Equal(t, []string{"k4"}, keys(resp))
+		assert.Equal(t, "4", resp.Token)
+
+		resp = runQuery(t, fmt.Sprintf(q, `,"token":"4"`))
+		assert.Empty(t, resp.Results)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		resp := runQuery(t, `{"select":["person.name","state","missing"],"filter":{"EQ":{"person.org":"B"}}}`)
+		assert.Equal(t, []string{"k2", "k3"}, keys(resp))
+		assert.JSONEq(t, `{"person":{"name":"bob"},"state":"WA"}`, string(resp.Results[0].Data))
+		assert.JSONEq(t, `{"person":{"name":"carl"},"state":"CA"}`, string(resp.Results[1].Data))
+		assert.NotNil(t, resp.Results[0].ETag)
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		var _ state.AggregateQuerier = store
+		assert.True(t, state.FeatureQueryAggregate.IsPresent(store.Features()))
+
+		data, err := os.ReadFile("../../tests/state/query/q10.json")
+		require.NoError(t, err)
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal(data, &req.Query))
+		resp, err := store.QueryAggregate(t.Context(), &req)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		assert.Empty(t, resp.Results[0].Key)
+		assert.JSONEq(t, `{"state":"CA","count":2,"total":5,"max_person.id":3}`, string(resp.Results[0].Data))
+		assert.JSONEq(t, `{"state":"TX","count":1,"total":4,"max_person.id":4}`, string(resp.Results[1].Data))
+		assert.Equal(t, "2", resp.Token)
+
+		req.Query.Page.Token = resp.Token
+		resp, err = store.QueryAggregate(t.Context(), &req)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		assert.JSONEq(t, `{"state":"WA","count":1,"total":1,"max_person.id":1}`, string(resp.Results[0].Data))
+
+		resp = runQuery(t, `{"aggregate":[{"op":"COUNT"},{"op":"COUNT","key":"tags"},{"op":"MIN","key":"person.name"}],"filter":{"EQ":{"state":"XX"}}}`)
+		require.Len(t, resp.Results, 1)
+		assert.JSONEq(t, `{"count":0,"count_tags":0,"min_person.name":null}`, string(resp.Results[0].Data))
+
+		resp = runQuery(t, `{"aggregate":[{"op":"COUNT","key":"tags"}]}`)
+		assert.JSONEq(t, `{"count_tags":3}`, string(resp.Results[0].Data))
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"filter":{"GT":{"state":"CA"}}}`), &req.Query))
+		_, err := store.Query(t.Context(), &req)
+		require.Error(t, err)
+
+		req = state.QueryRequest{}
+		require.NoError(t, json.Unmarshal([]byte(`{"page":{"limit":2,"token":"abc"}}`), &req.Query))
+		_, err = store.Query(t.Context(), &req)
+		require.Error(t, err)
+
+		req = state.QueryRequest{}
+		require.NoError(t, json.Unmarshal([]byte(`{"aggregate":[{"op":"COUNT"}],"sort":[{"key":"state"}]}`), &req.Query))
+		_, err = store.Query(t.Context(), &req)
+		require.Error(t, err)
+	})
+}
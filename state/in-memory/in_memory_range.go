@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+// Keys aren't kept sorted, so each request sorts the keys in the range.
+func (store *InMemoryStore) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	now := store.clock.Now()
+	keys := make([]string, 0)
+	for k, item := range store.items {
+		if inRange(req, k) && !item.isExpired(now) {
+			keys = append(keys, k)
+		}
+	}
+	if req.Reverse {
+		slices.SortFunc(keys, func(a, b string) int {
+			return strings.Compare(b, a)
+		})
+	} else {
+		slices.Sort(keys)
+	}
+
+	res := &state.RangeScanResponse{}
+	if req.Limit > 0 && len(keys) > int(req.Limit) {
+		keys = keys[:req.Limit]
+		res.ContinuationToken = ptr.Of(keys[len(keys)-1])
+	}
+	res.Items = make([]state.RangeScanItem, len(keys))
+	for i, k := range keys {
+		item := store.items[k]
+		res.Items[i] = state.RangeScanItem{
+			Key:  k,
+			Data: item.data,
+			ETag: item.etag,
+		}
+		if item.expire != nil {
+			res.Items[i].Metadata = map[string]string{
+				state.GetRespMetaKeyTTLExpireTime: item.expire.UTC().Format(time.RFC3339),
+			}
+		}
+	}
+	return res, nil
+}
+
+// inRange returns true if the key is in the range of the request, and after its continuation token.
+func inRange(req *state.RangeScanRequest, key string) bool {
+	if key < req.Start || (req.End != "" && key >= req.End) {
+		return false
+	}
+	if req.ContinuationToken != nil && *req.ContinuationToken != "" {
+		if req.Reverse {
+			return key < *req.ContinuationToken
+		}
+		return key > *req.ContinuationToken
+	}
+	return true
+}
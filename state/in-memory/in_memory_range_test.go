@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInMemoryRangeScan(t *testing.T) {
+	store := NewInMemoryStateStore(logger.NewLogger("test")).(*InMemoryStore)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	store.clock = fakeClock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	defer store.Close()
+
+	var _ state.RangeScanner = store
+	assert.True(t, state.FeatureRangeScan.IsPresent(store.Features()))
+
+	for _, k := range []string{
+		"orders||2024-05-03||c",
+		"orders||2024-05-01||a",
+		"orders||2024-05-02||b",
+		"orders||2024-06-01||d",
+		"other||1",
+	} {
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: k, Value: k}))
+	}
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{
+		Key:      "orders||2024-05-04||expired",
+		Value:    "x",
+		Metadata: map[string]string{"ttlInSeconds": "1"},
+	}))
+	fakeClock.Step(2 * time.Second)
+
+	keys := func(res *state.RangeScanResponse) []string {
+		k := make([]string, len(res.Items))
+		for i, item := range res.Items {
+			k[i] = item.Key
+		}
+		return k
+	}
+
+	t.Run("range", func(t *testing.T) {
+		res, err := store.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||2024-05", End: "orders||2024-06"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-01||a", "orders||2024-05-02||b", "orders||2024-05-03||c"}, keys(res))
+		assert.Nil(t, res.ContinuationToken)
+		assert.Equal(t, `"orders||2024-05-01||a"`, string(res.Items[0].Data))
+		assert.NotNil(t, res.Items[0].ETag)
+	})
+
+	t.Run("open range", func(t *testing.T) {
+		res, err := store.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||2024-06"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-06-01||d", "other||1"}, keys(res))
+
+		res, err = store.RangeScan(t.Context(), &state.RangeScanRequest{End: "orders||2024-05-02"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-01||a"}, keys(res))
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		req := &state.RangeScanRequest{Start: "orders||", End: "orders||~", Limit: 2}
+		res, err := store.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-01||a", "orders||2024-05-02||b"}, keys(res))
+		require.NotNil(t, res.ContinuationToken)
+
+		req.ContinuationToken = res.ContinuationToken
+		res, err = store.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-03||c", "orders||2024-06-01||d"}, keys(res))
+		assert.Nil(t, res.ContinuationToken)
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		req := &state.RangeScanRequest{Start: "orders||", End: "orders||~", Limit: 3, Reverse: true}
+		res, err := store.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-06-01||d", "orders||2024-05-03||c", "orders||2024-05-02||b"}, keys(res))
+
+		req.ContinuationToken = res.ContinuationToken
+		res, err = store.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-01||a"}, keys(res))
+		assert.Nil(t, res.ContinuationToken)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := store.RangeScan(t.Context(), &state.RangeScanRequest{Start: "b", End: "a"})
+		require.Error(t, err)
+	})
+}
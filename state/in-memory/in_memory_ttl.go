@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// Expire sets or removes the expiration time of the key, keeping its value and ETag.
+func (store *InMemoryStore) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	item := store.getAndExpire(req.Key)
+	if item == nil {
+		return &state.TTLResponse{}, nil
+	}
+
+	// Items are never modified, since they can be referenced by snapshots and revisions
+	var expire *time.Time
+	if req.TTLInSeconds > 0 {
+		expire = ptr.Of(store.clock.Now().Add(time.Duration(req.TTLInSeconds) * time.Second))
+	}
+	el := &inMemStateStoreItem{
+		data:   item.data,
+		etag:   item.etag,
+		expire: expire,
+		idx:    item.idx,
+	}
+	store.doStoreItem(req.Key, el)
+	if err := store.persist(); err != nil {
+		return nil, err
+	}
+	return &state.TTLResponse{Found: true, ExpireTime: el.expire, ETag: el.etag}, nil
+}
+
+// GetTTL returns the expiration time of the key.
+func (store *InMemoryStore) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	item := store.items[req.Key]
+	if item == nil || item.isExpired(store.clock.Now()) {
+		return &state.TTLResponse{}, nil
+	}
+	return &state.TTLResponse{Found: true, ExpireTime: item.expire, ETag: item.etag}, nil
+}
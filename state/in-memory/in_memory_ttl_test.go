@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInMemoryTTLManagement(t *testing.T) {
+	store := NewInMemoryStateStore(logger.NewLogger("test")).(*InMemoryStore)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	store.clock = fakeClock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	defer store.Close()
+
+	var _ state.TTLManager = store
+	assert.True(t, state.FeatureTTLManagement.IsPresent(store.Features()))
+
+	require.NoError(t, store.Set(t.Context(), &state.SetRequest{
+		Key:      "session",
+		Value:    "data",
+		Metadata: map[string]string{"ttlInSeconds": "10"},
+	}))
+	got, err := store.Get(t.Context(), &state.GetRequest{Key: "session"})
+	require.NoError(t, err)
+
+	t.Run("get TTL", func(t *testing.T) {
+		res, err := store.GetTTL(t.Context(), &state.GetTTLRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		require.NotNil(t, res.ExpireTime)
+		assert.Equal(t, fakeClock.Now().Add(10*time.Second), *res.ExpireTime)
+		assert.Equal(t, got.ETag, res.ETag)
+	})
+
+	t.Run("sliding expiration", func(t *testing.T) {
+		for range 3 {
+			fakeClock.Step(8 * time.Second)
+			res, err := store.Expire(t.Context(), &state.ExpireRequest{Key: "session", TTLInSeconds: 10})
+			require.NoError(t, err)
+			assert.True(t, res.Found)
+			assert.Equal(t, fakeClock.Now().Add(10*time.Second), *res.ExpireTime)
+			assert.Equal(t, got.ETag, res.ETag)
+		}
+
+		res, err := store.Get(t.Context(), &state.GetRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.Equal(t, got.Data, res.Data)
+	})
+
+	t.Run("remove the expiration time", func(t *testing.T) {
+		res, err := store.Expire(t.Context(), &state.ExpireRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+
+		fakeClock.Step(time.Hour)
+		res, err = store.GetTTL(t.Context(), &state.GetTTLRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+	})
+
+	t.Run("expired and missing keys", func(t *testing.T) {
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{
+			Key:      "short",
+			Value:    "data",
+			Metadata: map[string]string{"ttlInSeconds": "1"},
+		}))
+		fakeClock.Step(2 * time.Second)
+
+		for _, key := range []string{"short", "missing"} {
+			res, err := store.Expire(t.Context(), &state.ExpireRequest{Key: key, TTLInSeconds: 10})
+			require.NoError(t, err)
+			assert.False(t, res.Found)
+
+			res, err = store.GetTTL(t.Context(), &state.GetTTLRequest{Key: key})
+			require.NoError(t, err)
+			assert.False(t, res.Found)
+		}
+	})
+
+	t.Run("missing key in request", func(t *testing.T) {
+		_, err := store.Expire(t.Context(), &state.ExpireRequest{})
+		require.Error(t, err)
+	})
+}
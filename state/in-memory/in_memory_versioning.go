@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+var errVersioningDisabled = errors.New("versioning is not enabled: set historyLimit or historyRetention in the metadata")
+
+// revision is a revision of a key, kept in the history when versioning is enabled.
+// History is kept in memory only, and it's not persisted.
+type revision struct {
+	rev  int64
+	time time.Time
+	// Nil if the key was deleted.
+	item *inMemStateStoreItem
+}
+
+// GetRevisions returns the revisions of the key that are retained, from the most recent one.
+func (store *InMemoryStore) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	if store.history == nil {
+		return nil, errVersioningDisabled
+	}
+
+	revs := store.retainedRevisions(req.Key)
+	res := &state.GetRevisionsResponse{
+		Revisions: make([]state.Revision, 0, len(revs)),
+	}
+	for i := len(revs) - 1; i >= 0; i-- {
+		if req.Limit > 0 && len(res.Revisions) == req.Limit {
+			break
+		}
+		r := state.Revision{
+			Revision: revs[i].rev,
+			Time:     revs[i].time,
+			Deleted:  revs[i].item == nil,
+		}
+		if revs[i].item != nil {
+			r.ETag = revs[i].item.etag
+		}
+		res.Revisions = append(res.Revisions, r)
+	}
+	return res, nil
+}
+
+// GetRevision returns the value of the key at a revision, or at a point in time.
+func (store *InMemoryStore) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	if store.history == nil {
+		return nil, errVersioningDisabled
+	}
+
+	var found *revision
+	revs := store.retainedRevisions(req.Key)
+	for i := len(revs) - 1; i >= 0; i-- {
+		if (req.Revision != nil && revs[i].rev == *req.Revision) ||
+			(req.Time != nil && !revs[i].time.After(*req.Time)) {
+			found = &revs[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, state.ErrRevisionNotFound
+	}
+
+	item := found.item
+	if item == nil || (req.Time != nil && item.isExpired(*req.Time)) {
+		return &state.GetResponse{}, nil
+	}
+
+	var metadata map[string]string
+	if item.expire != nil {
+		metadata = map[string]string{
+			state.GetRespMetaKeyTTLExpireTime: item.expire.UTC().Format(time.RFC3339),
+		}
+	}
+	return &state.GetResponse{Data: item.data, ETag: item.etag, Metadata: metadata}, nil
+}
+
+// recordRevision adds a revision to the history of the key, if versioning is enabled.
+// It must be invoked while holding the write lock.
+func (store *InMemoryStore) recordRevision(key string, item *inMemStateStoreItem) {
+	if store.history == nil {
+		return
+	}
+
+	store.revision++
+	revs := append(store.history[key], revision{
+		rev:  store.revision,
+		time: store.clock.Now(),
+		item: item,
+	})
+	if store.historyLimit > 0 && len(revs) > store.historyLimit {
+		revs = revs[len(revs)-store.historyLimit:]
+	}
+	store.history[key] = revs
+}
+
+// revertRevision removes the most recent revision of the key, when a change is reverted.
+func (store *InMemoryStore) revertRevision(key string) {
+	revs := store.history[key]
+	if len(revs) == 0 {
+		return
+	}
+	if len(revs) == 1 {
+		delete(store.history, key)
+		return
+	}
+	store.history[key] = revs[:len(revs)-1]
+}
+
+// retainedRevisions returns the revisions of the key that are retained, from the oldest one.
+// Revisions older than the retention window are discarded, except the most recent one of a key that exists.
+func (store *InMemoryStore) retainedRevisions(key string) []revision {
+	revs := store.history[key]
+	if store.historyRetention <= 0 || len(revs) == 0 {
+		return revs
+	}
+
+	cutoff := store.clock.Now().Add(-store.historyRetention)
+	i := 0
+	for i < len(revs) && revs[i].time.Before(cutoff) {
+		i++
+	}
+	if i == len(revs) && revs[i-1].item != nil {
+		i--
+	}
+	return revs[i:]
+}
+
+// doPruneHistory removes the revisions that aren't retained anymore.
+// It must be invoked while holding the write lock.
+func (store *InMemoryStore) doPruneHistory() {
+	if store.historyRetention <= 0 {
+		return
+	}
+	for key := range store.history {
+		revs := store.retainedRevisions(key)
+		switch {
+		case len(revs) == 0:
+			delete(store.history, key)
+		case len(revs) < len(store.history[key]):
+			// Copy the revisions, so the removed ones can be released
+			store.history[key] = slices.Clone(revs)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+func newVersionedStore(t *testing.T, props map[string]string) (*InMemoryStore, *clocktesting.FakeClock) {
+	t.Helper()
+
+	store := newStateStore(logger.NewLogger("test"))
+	clock := clocktesting.NewFakeClock(time.Now())
+	store.clock = clock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{Base: metadata.Base{Properties: props}}))
+	t.Cleanup(func() { store.Close() })
+	return store, clock
+}
+
+func TestVersioning(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		store, _ := newVersionedStore(t, nil)
+		assert.NotContains(t, store.Features(), state.FeatureVersioning)
+		_, err := store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "k"})
+		require.ErrorIs(t, err, errVersioningDisabled)
+	})
+
+	t.Run("revisions and point-in-time reads", func(t *testing.T) {
+		store, clock := newVersionedStore(t, map[string]string{"historyLimit": "10"})
+		assert.Contains(t, store.Features(), state.FeatureVersioning)
+
+		start := clock.Now()
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v1"}))
+		clock.Step(time.Minute)
+		require.NoError(t, store.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{state.SetRequest{Key: "k", Value: "v2"}},
+		}))
+		clock.Step(time.Minute)
+		require.NoError(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "k"}))
+		clock.Step(time.Minute)
+		_, err := store.Increment(t.Context(), &state.IncrementRequest{Key: "k", Delta: 3})
+		require.NoError(t, err)
+
+		res, err := store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "k"})
+		require.NoError(t, err)
+		require.Len(t, res.Revisions, 4)
+		assert.False(t, res.Revisions[0].Deleted)
+		assert.True(t, res.Revisions[1].Deleted)
+		assert.Nil(t, res.Revisions[1].ETag)
+		assert.Equal(t, start, res.Revisions[3].Time)
+		assert.Greater(t, res.Revisions[0].Revision, res.Revisions[1].Revision)
+
+		limited, err := store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "k", Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, res.Revisions[:2], limited.Revisions)
+
+		got, err := store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Revision: &res.Revisions[2].Revision})
+		require.NoError(t, err)
+		assert.Equal(t, `"v2"`, string(got.Data))
+		assert.Equal(t, res.Revisions[2].ETag, got.ETag)
+
+		got, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(start.Add(30 * time.Second))})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, string(got.Data))
+
+		got, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(start.Add(150 * time.Second))})
+		require.NoError(t, err)
+		assert.Nil(t, got.Data)
+
+		_, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(start.Add(-time.Second))})
+		require.ErrorIs(t, err, state.ErrRevisionNotFound)
+		_, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Revision: ptr.Of(int64(1000))})
+		require.ErrorIs(t, err, state.ErrRevisionNotFound)
+
+		_, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k"})
+		require.Error(t, err)
+	})
+
+	t.Run("expired values", func(t *testing.T) {
+		store, clock := newVersionedStore(t, map[string]string{"historyLimit": "10"})
+
+		start := clock.Now()
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "k", Value: "v", Metadata: map[string]string{"ttlInSeconds": "10"}}))
+
+		got, err := store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(start.Add(5 * time.Second))})
+		require.NoError(t, err)
+		assert.Equal(t, `"v"`, string(got.Data))
+		assert.Contains(t, got.Metadata, state.GetRespMetaKeyTTLExpireTime)
+
+		got, err = store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(start.Add(20 * time.Second))})
+		require.NoError(t, err)
+		assert.Nil(t, got.Data)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		store, clock := newVersionedStore(t, map[string]string{"historyLimit": "3", "historyRetention": "1h"})
+
+		for _, v := range []string{"v1", "v2", "v3", "v4"} {
+			require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "k", Value: v}))
+		}
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "deleted", Value: "v"}))
+		require.NoError(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "deleted"}))
+
+		res, err := store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Len(t, res.Revisions, 3)
+
+		// The current revision is retained after the retention window, unless the key was deleted
+		clock.Step(2 * time.Hour)
+		store.doCleanExpiredItems()
+		res, err = store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "k"})
+		require.NoError(t, err)
+		require.Len(t, res.Revisions, 1)
+		got, err := store.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "k", Time: ptr.Of(clock.Now())})
+		require.NoError(t, err)
+		assert.Equal(t, `"v4"`, string(got.Data))
+
+		res, err = store.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "deleted"})
+		require.NoError(t, err)
+		assert.Empty(t, res.Revisions)
+		assert.NotContains(t, store.history, "deleted")
+	})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// watcher holds the events that are waiting to be delivered to a handler.
+// Events are queued without blocking, so writes are never held up by slow handlers.
+type watcher struct {
+	req      *state.WatchRequest
+	queue    []*state.WatchEvent
+	lock     sync.Mutex
+	notifyCh chan struct{}
+}
+
+// Watch delivers the changes to the keys matched by the request to the handler.
+func (store *InMemoryStore) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if store.closed.Load() {
+		return errors.New("state store is closed")
+	}
+
+	w := &watcher{
+		req:      req,
+		notifyCh: make(chan struct{}, 1),
+	}
+	store.watchersLock.Lock()
+	store.watchers[w] = struct{}{}
+	store.watchersLock.Unlock()
+
+	store.wg.Add(1)
+	go func() {
+		defer store.wg.Done()
+		defer func() {
+			store.watchersLock.Lock()
+			delete(store.watchers, w)
+			store.watchersLock.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-store.closeCh:
+				return
+			case <-w.notifyCh:
+			}
+
+			for _, e := range w.drain() {
+				if err := handler(ctx, e); err != nil {
+					store.log.Errorf("Error from watch handler for key %s: %v", e.Key, err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// notify queues the event for the watchers of the key.
+// It's invoked while holding the write lock, so events are queued in the order the changes happened.
+func (store *InMemoryStore) notify(e *state.WatchEvent) {
+	store.watchersLock.Lock()
+	defer store.watchersLock.Unlock()
+
+	for w := range store.watchers {
+		if w.req.Matches(e.Key) {
+			w.push(e)
+		}
+	}
+}
+
+func (w *watcher) push(e *state.WatchEvent) {
+	w.lock.Lock()
+	w.queue = append(w.queue, e)
+	w.lock.Unlock()
+
+	select {
+	case w.notifyCh <- struct{}{}:
+	default:
+		// A notification is already pending
+	}
+}
+
+func (w *watcher) drain() []*state.WatchEvent {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	events := w.queue
+	w.queue = nil
+	return events
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInMemoryWatch(t *testing.T) {
+	store := NewInMemoryStateStore(logger.NewLogger("test")).(*InMemoryStore)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	store.clock = fakeClock
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	defer store.Close()
+
+	var _ state.Watcher = store
+	assert.True(t, state.FeatureWatch.IsPresent(store.Features()))
+
+	watch := func(t *testing.T, req *state.WatchRequest) <-chan *state.WatchEvent {
+		t.Helper()
+		ch := make(chan *state.WatchEvent, 10)
+		err := store.Watch(t.Context(), req, func(_ context.Context, e *state.WatchEvent) error {
+			ch <- e
+			return nil
+		})
+		require.NoError(t, err)
+		return ch
+	}
+	receive := func(t *testing.T, ch <-chan *state.WatchEvent) *state.WatchEvent {
+		t.Helper()
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for event")
+			return nil
+		}
+	}
+
+	t.Run("invalid request", func(t *testing.T) {
+		err := store.Watch(t.Context(), &state.WatchRequest{}, nil)
+		require.Error(t, err)
+		err = store.Watch(t.Context(), &state.WatchRequest{Key: "a", Prefix: "b"}, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("key", func(t *testing.T) {
+		ch := watch(t, &state.WatchRequest{Key: "key1"})
+
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "key2", Value: "ignored"}))
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "key1", Value: "hello"}))
+		res, err := store.Get(t.Context(), &state.GetRequest{Key: "key1"})
+		require.NoError(t, err)
+
+		e := receive(t, ch)
+		assert.Equal(t, state.WatchEventUpsert, e.Type)
+		assert.Equal(t, "key1", e.Key)
+		assert.Equal(t, `"hello"`, string(e.Value))
+		assert.Equal(t, res.ETag, e.ETag)
+		assert.Nil(t, e.ExpireTime)
+
+		require.NoError(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "key1"}))
+		e = receive(t, ch)
+		assert.Equal(t, state.WatchEventDelete, e.Type)
+		assert.Equal(t, "key1", e.Key)
+		assert.Nil(t, e.ETag)
+
+		// Deleting a key that doesn't exist doesn't generate events
+		require.NoError(t, store.Delete(t.Context(), &state.DeleteRequest{Key: "key1"}))
+		assert.Empty(t, ch)
+	})
+
+	t.Run("prefix and transactions", func(t *testing.T) {
+		ch := watch(t, &state.WatchRequest{Prefix: "app||"})
+
+		err := store.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "app||a", Value: "1", Metadata: map[string]string{"ttlInSeconds": "10"}},
+				state.SetRequest{Key: "other||b", Value: "2"},
+				state.DeleteRequest{Key: "app||a"},
+			},
+		})
+		require.NoError(t, err)
+
+		e := receive(t, ch)
+		assert.Equal(t, state.WatchEventUpsert, e.Type)
+		assert.Equal(t, "app||a", e.Key)
+		require.NotNil(t, e.ExpireTime)
+		assert.Equal(t, fakeClock.Now().Add(10*time.Second), *e.ExpireTime)
+
+		e = receive(t, ch)
+		assert.Equal(t, state.WatchEventDelete, e.Type)
+		assert.Equal(t, "app||a", e.Key)
+	})
+
+	t.Run("expired keys", func(t *testing.T) {
+		ch := watch(t, &state.WatchRequest{Key: "ttl"})
+
+		require.NoError(t, store.Set(t.Context(), &state.SetRequest{Key: "ttl", Value: "1", Metadata: map[string]string{"ttlInSeconds": "1"}}))
+		assert.Equal(t, state.WatchEventUpsert, receive(t, ch).Type)
+
+		fakeClock.Step(2 * time.Second)
+		store.doCleanExpiredItems()
+		e := receive(t, ch)
+		assert.Equal(t, state.WatchEventDelete, e.Type)
+		assert.Equal(t, "ttl", e.Key)
+	})
+
+	t.Run("stops when context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		err := store.Watch(ctx, &state.WatchRequest{Key: "stop"}, func(context.Context, *state.WatchEvent) error {
+			return nil
+		})
+		require.NoError(t, err)
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			store.watchersLock.Lock()
+			defer store.watchersLock.Unlock()
+			for w := range store.watchers {
+				if w.req.Key == "stop" {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -120,7 +120,10 @@ func NewMongoDB(logger logger.Logger) state.Store {
 			state.FeatureETag,
 			state.FeatureTransactional,
 			state.FeatureQueryAPI,
+			state.FeatureQueryAggregate,
 			state.FeatureTTL,
+			state.FeatureAtomicOperations,
+			state.FeatureTTLManagement,
 		},
 		logger: logger,
 	}
@@ -206,15 +209,7 @@ func (m *MongoDB) Ping(ctx context.Context) error {
 }
 
 func (m *MongoDB) setInternal(ctx context.Context, req *state.SetRequest) error {
-	var v interface{}
-	switch obj := req.Value.(type) {
-	case []byte:
-		v = string(obj)
-	case string:
-		v = fmt.Sprintf("%q", obj)
-	default:
-		v = req.Value
-	}
+	v := storedValue(req.Value)
 
 	// create a document based on request key and value
 	filter := bson.M{id: req.Key}
@@ -574,6 +569,11 @@ func (m *MongoDB) Query(ctx context.Context, req *state.QueryRequest) (*state.Qu
 	}, nil
 }
 
+// QueryAggregate executes a query with projections or aggregations against the collection.
+func (m *MongoDB) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	return m.Query(ctx, req)
+}
+
 func (metadata *mongoDBMetadata) getMongoConnectionString() string {
 	if metadata.ConnectionString != "" {
 		return metadata.ConnectionString
@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/dapr/components-contrib/state"
+	stateutils "github.com/dapr/components-contrib/state/utils"
+)
+
+// Maximum number of attempts to convert arrays stored as JSON strings before appending to them.
+const maxAppendAttempts = 3
+
+// The operations below are executed with a single update with an aggregation pipeline, which requires MongoDB 4.2 or higher.
+// The filter only matches documents with a value of the right type, or that have expired; for the other documents, the upsert fails with a duplicate key error.
+
+// Increment adds the delta to the integer value of the key.
+// Integers stored as strings, like values saved as raw bytes, are converted to numbers.
+func (m *MongoDB) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttlExpr, err := atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	isInteger := bson.D{{Key: "$in", Value: bson.A{bson.D{{Key: "$type", Value: "$" + value}}, bson.A{"int", "long"}}}}
+	isIntegerString := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$" + value}}, "string"}}},
+		bson.D{{Key: "$regexMatch", Value: bson.D{{Key: "input", Value: "$" + value}, {Key: "regex", Value: "^-?[0-9]+$"}}}},
+	}}}
+	current := bson.D{{Key: "$switch", Value: bson.D{
+		{Key: "branches", Value: bson.A{
+			bson.D{{Key: "case", Value: missingOrExpiredExpr()}, {Key: "then", Value: int64(0)}},
+			bson.D{{Key: "case", Value: isInteger}, {Key: "then", Value: "$" + value}},
+			bson.D{{Key: "case", Value: isIntegerString}, {Key: "then", Value: bson.D{{Key: "$toLong", Value: "$" + value}}}},
+		}},
+		{Key: "default", Value: primitive.Null{}},
+	}}}
+
+	filter := bson.D{
+		{Key: id, Value: req.Key},
+		{Key: "$expr", Value: bson.D{{Key: "$or", Value: bson.A{missingOrExpiredExpr(), isInteger, isIntegerString}}}},
+	}
+	newValue := bson.D{{Key: "$toLong", Value: bson.D{{Key: "$add", Value: bson.A{current, req.Delta}}}}}
+
+	var result Item
+	err = m.atomicUpdate(ctx, filter, newValue, ttlExpr, true, &result)
+	if err != nil {
+		return nil, atomicError(err)
+	}
+
+	res := &state.IncrementResponse{
+		ETag: &result.Etag,
+	}
+	switch v := result.Value.(type) {
+	case int64:
+		res.Value = v
+	case int32:
+		res.Value = int64(v)
+	default:
+		return nil, fmt.Errorf("invalid value after increment: %v", result.Value)
+	}
+	return res, nil
+}
+
+// Append adds the values at the end of the array stored in the key.
+// Arrays stored as JSON strings, like values saved as raw bytes, are converted to documents first.
+func (m *MongoDB) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttlExpr, err := atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	isArray := bson.D{{Key: "$isArray", Value: bson.A{"$" + value}}}
+	filter := bson.D{
+		{Key: id, Value: req.Key},
+		{Key: "$expr", Value: bson.D{{Key: "$or", Value: bson.A{missingOrExpiredExpr(), isArray}}}},
+	}
+	newValue := bson.D{{Key: "$concatArrays", Value: bson.A{
+		bson.D{{Key: "$cond", Value: bson.A{missingOrExpiredExpr(), bson.A{}, "$" + value}}},
+		bson.D{{Key: "$literal", Value: req.Values}},
+	}}}
+
+	var result Item
+	for i := 0; ; i++ {
+		err = m.atomicUpdate(ctx, filter, newValue, ttlExpr, true, &result)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) || i == maxAppendAttempts-1 {
+			return nil, atomicError(err)
+		}
+		if err = m.convertJSONArray(ctx, req.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	arr, ok := result.Value.(primitive.A)
+	if !ok {
+		return nil, fmt.Errorf("invalid value after append: %v", result.Value)
+	}
+	return &state.AppendResponse{
+		Length: int64(len(arr)),
+		ETag:   &result.Etag,
+	}, nil
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one, comparing the values as stored.
+func (m *MongoDB) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttlExpr, err := atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter bson.D
+	if req.Expected == nil {
+		// Insert the key, replacing it only if it has expired
+		filter = bson.D{
+			{Key: id, Value: req.Key},
+			{Key: "$expr", Value: missingOrExpiredExpr()},
+		}
+	} else {
+		filter = bson.D{
+			{Key: id, Value: req.Key},
+			{Key: value, Value: storedValue(req.Expected)},
+			{Key: "$expr", Value: bson.D{{Key: "$not", Value: bson.A{missingOrExpiredExpr()}}}},
+		}
+	}
+	newValue := bson.D{{Key: "$literal", Value: storedValue(req.Value)}}
+
+	var result Item
+	err = m.atomicUpdate(ctx, filter, newValue, ttlExpr, req.Expected == nil, &result)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments), mongo.IsDuplicateKeyError(err):
+		// The value didn't match
+		return &state.CompareAndSwapResponse{}, nil
+	case err != nil:
+		return nil, fmt.Errorf("error in updating document: %w", err)
+	}
+	return &state.CompareAndSwapResponse{
+		Swapped: true,
+		ETag:    &result.Etag,
+	}, nil
+}
+
+// atomicUpdate sets the value of the document matched by the filter, with a new ETag and the expiration time returned by ttlExpr, and decodes the updated document into result.
+func (m *MongoDB) atomicUpdate(ctx context.Context, filter bson.D, newValue any, ttlExpr any, upsert bool, result *Item) error {
+	etagV, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	// All the expressions in the stage are evaluated against the current document
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: value, Value: newValue},
+			{Key: etag, Value: etagV.String()},
+			{Key: ttl, Value: ttlExpr},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(upsert).
+		SetReturnDocument(options.After)
+	return m.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+}
+
+// convertJSONArray replaces the value of the key with the array it contains, if it's stored as a JSON string.
+// It returns ErrAtomicOperandType if the value isn't an array.
+func (m *MongoDB) convertJSONArray(ctx context.Context, key string) error {
+	var item Item
+	err := m.collection.FindOne(ctx, bson.D{{Key: id, Value: key}}).Decode(&item)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// The document has been deleted since, so the next attempt inserts it
+		return nil
+	} else if err != nil {
+		return err
+	}
+	str, ok := item.Value.(string)
+	if !ok {
+		return state.ErrAtomicOperandType
+	}
+	var arr []any
+	if err = json.Unmarshal([]byte(str), &arr); err != nil || arr == nil {
+		return state.ErrAtomicOperandType
+	}
+
+	// The update doesn't change the ETag, as the value is the same, but it fails if the document has been changed since
+	filter := bson.D{
+		{Key: id, Value: key},
+		{Key: etag, Value: item.Etag},
+	}
+	_, err = m.collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{{Key: value, Value: arr}}}})
+	if err != nil {
+		return fmt.Errorf("error in updating document: %w", err)
+	}
+	return nil
+}
+
+// missingOrExpiredExpr returns an expression that is true for documents that are being inserted, or that have expired but haven't been deleted yet.
+func missingOrExpiredExpr() bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$" + value}}, "missing"}}},
+		bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "$ne", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + ttl, primitive.Null{}}}}, primitive.Null{}}}},
+			bson.D{{Key: "$lt", Value: bson.A{"$" + ttl, "$$NOW"}}},
+		}}},
+	}}}
+}
+
+// atomicTTL returns the expression for the expiration time of updated documents.
+// Unless a TTL is set, documents keep their expiration time, except for expired ones that are replaced.
+func atomicTTL(metadata map[string]string) (any, error) {
+	reqTTL, err := stateutils.ParseTTL(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TTL: %w", err)
+	}
+	switch {
+	case reqTTL == nil:
+		return bson.D{{Key: "$cond", Value: bson.A{missingOrExpiredExpr(), primitive.Null{}, "$" + ttl}}}, nil
+	case *reqTTL > 0:
+		// MongoDB stores time in milliseconds so multiply seconds by 1000.
+		return bson.D{{Key: "$add", Value: bson.A{"$$NOW", *reqTTL * 1000}}}, nil
+	default:
+		return primitive.Null{}, nil
+	}
+}
+
+// storedValue returns the value as it's stored by Set.
+func storedValue(v any) any {
+	switch obj := v.(type) {
+	case []byte:
+		return string(obj)
+	case string:
+		return fmt.Sprintf("%q", obj)
+	default:
+		return v
+	}
+}
+
+// atomicError returns ErrAtomicOperandType when the upsert failed because the current value has the wrong type.
+func atomicError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return state.ErrAtomicOperandType
+	}
+	return fmt.Errorf("error in updating document: %w", err)
+}
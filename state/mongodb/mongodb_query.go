@@ -33,6 +33,13 @@ type Query struct {
 	query  string
 	filter interface{}
 	opts   *options.FindOptions
+
+	// Set when the query has a projection or aggregations
+	projection bson.D
+	aggregate  *query.Query
+	pipeline   mongo.Pipeline
+	limit      int64
+	skip       int64
 }
 
 func (q *Query) VisitEQ(f *query.EQ) (string, error) {
@@ -172,7 +179,10 @@ func (q *Query) visitFilters(op string, filters []query.Filter) (string, error)
 			}
 			arr = append(arr, str)
 		default:
-			return "", fmt.Errorf("unsupported filter type %#v", f)
+			if str, err = query.VisitFilter(q, f); err != nil {
+				return "", err
+			}
+			arr = append(arr, str)
 		}
 	}
 
@@ -189,6 +199,88 @@ func (q *Query) VisitOR(f *query.OR) (string, error) {
 	return q.visitFilters("$or", f.Filters)
 }
 
+func (q *Query) VisitNOT(f *query.NOT) (string, error) {
+	// { $nor: [ { <expression> } ] }
+	str, err := query.VisitFilter(q, f.Filter)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{ "$nor": [ %s ] }`, str), nil
+}
+
+func (q *Query) VisitNIN(f *query.NIN) (string, error) {
+	// { <key>: { $nin: [ <val1>, <val2>, ... , <valN> ] } }
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty NIN operator for key %q", f.Key)
+	}
+	vals := make([]string, len(f.Vals))
+	for i, v := range f.Vals {
+		val, err := formatValue(v)
+		if err != nil {
+			return "", err
+		}
+		vals[i] = val
+	}
+
+	return fmt.Sprintf(`{ "value.%s": { "$nin": [ %s ] } }`, f.Key, strings.Join(vals, ", ")), nil
+}
+
+func (q *Query) VisitEXISTS(f *query.EXISTS) (string, error) {
+	// { <key>: { $exists: <bool> } }
+	return fmt.Sprintf(`{ "value.%s": { "$exists": %t } }`, f.Key, f.Exists), nil
+}
+
+func (q *Query) VisitLIKE(f *query.LIKE) (string, error) {
+	// { <key>: /<regex>/ }
+	re, err := likeToRegex(f.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid LIKE pattern %q: %w", f.Pattern, err)
+	}
+	return fmt.Sprintf(`{ "value.%s": { "$regularExpression": { "pattern": %q, "options": "s" } } }`, f.Key, re.String()), nil
+}
+
+func (q *Query) VisitCONTAINS(f *query.CONTAINS) (string, error) {
+	// { <key>: { $elemMatch: { $eq: <val> } } }
+	val, err := formatValue(f.Val)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{ "value.%s": { "$elemMatch": { "$eq": %s } } }`, f.Key, val), nil
+}
+
+func (q *Query) VisitBETWEEN(f *query.BETWEEN) (string, error) {
+	// { <key>: { $gte: <from>, $lte: <to> } }
+	for _, v := range []any{f.From, f.To} {
+		if _, ok := v.(string); ok {
+			return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+		}
+	}
+	return fmt.Sprintf(`{ "value.%s": { "$gte": %v, "$lte": %v } }`, f.Key, f.From, f.To), nil
+}
+
+// formatValue encodes the value as JSON, so strings are escaped and objects and arrays are valid in the extended JSON filter.
+func formatValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %v: %w", v, err)
+	}
+	return string(b), nil
+}
+
+func (q *Query) VisitProjection(qq *query.Query) error {
+	if qq.IsAggregation() {
+		// The pipeline is built in Finalize, once the filter is known
+		q.aggregate = qq
+		return nil
+	}
+
+	q.projection = bson.D{{Key: "_id", Value: 1}, {Key: "_etag", Value: 1}}
+	for _, field := range qq.Select {
+		q.projection = append(q.projection, bson.E{Key: "value." + field, Value: 1})
+	}
+	return nil
+}
+
 func (q *Query) Finalize(filters string, qq *query.Query) error {
 	q.query = filters
 	if len(filters) == 0 {
@@ -196,7 +288,15 @@ func (q *Query) Finalize(filters string, qq *query.Query) error {
 	} else if err := bson.UnmarshalExtJSON([]byte(filters), false, &q.filter); err != nil {
 		return err
 	}
+
+	if q.aggregate != nil {
+		return q.finalizeAggregate(qq)
+	}
+
 	q.opts = options.Find()
+	if q.projection != nil {
+		q.opts.SetProjection(q.projection)
+	}
 
 	// sorting
 	if len(qq.Sort) > 0 {
@@ -225,7 +325,121 @@ func (q *Query) Finalize(filters string, qq *query.Query) error {
 	return nil
 }
 
+// finalizeAggregate builds the aggregation pipeline.
+// Group fields and aggregations are named g<n> and a<n> in the pipeline because names with dots are not allowed in $group.
+func (q *Query) finalizeAggregate(qq *query.Query) error {
+	group := bson.D{}
+	names := make(map[string]string, len(qq.GroupBy)+len(qq.Aggregate))
+	if len(qq.GroupBy) == 0 {
+		group = append(group, bson.E{Key: "_id", Value: nil})
+	} else {
+		id := bson.D{}
+		for i, g := range qq.GroupBy {
+			name := "g" + strconv.Itoa(i)
+			id = append(id, bson.E{Key: name, Value: "$value." + g})
+			names[g] = "_id." + name
+		}
+		group = append(group, bson.E{Key: "_id", Value: id})
+	}
+	for i, a := range qq.Aggregate {
+		name := "a" + strconv.Itoa(i)
+		names[a.Name()] = name
+
+		var acc bson.D
+		field := "$value." + a.Key
+		switch {
+		case a.Op == query.COUNT && a.Key == "":
+			acc = bson.D{{Key: "$sum", Value: 1}}
+		case a.Op == query.COUNT:
+			// Count the documents that have the field
+			exists := bson.D{{Key: "$ne", Value: bson.A{bson.D{{Key: "$type", Value: field}}, "missing"}}}
+			acc = bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{exists, 1, 0}}}}}
+		default:
+			// Values that are not numbers are ignored
+			isNumber := bson.D{{Key: "$isNumber", Value: field}}
+			acc = bson.D{{Key: "$" + strings.ToLower(a.Op), Value: bson.D{{Key: "$cond", Value: bson.A{isNumber, field, nil}}}}}
+		}
+		group = append(group, bson.E{Key: name, Value: acc})
+	}
+
+	q.pipeline = mongo.Pipeline{
+		{{Key: "$match", Value: q.filter}},
+		{{Key: "$group", Value: group}},
+	}
+
+	if len(qq.Sort) > 0 {
+		sort := bson.D{}
+		for _, s := range qq.Sort {
+			order := 1 // ascending
+			if s.Order == query.DESC {
+				order = -1
+			}
+			sort = append(sort, bson.E{Key: names[s.Key], Value: order})
+		}
+		q.pipeline = append(q.pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	if len(qq.Page.Token) != 0 {
+		skip, err := strconv.ParseInt(qq.Page.Token, 10, 64)
+		if err != nil {
+			return err
+		}
+		q.skip = skip
+		q.pipeline = append(q.pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+	if qq.Page.Limit > 0 {
+		q.limit = int64(qq.Page.Limit)
+		q.pipeline = append(q.pipeline, bson.D{{Key: "$limit", Value: q.limit}})
+	}
+
+	return nil
+}
+
+func (q *Query) executeAggregate(ctx context.Context, collection *mongo.Collection) ([]state.QueryItem, string, error) {
+	cur, err := collection.Aggregate(ctx, q.pipeline)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+	ret := []state.QueryItem{}
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err = cur.Decode(&doc); err != nil {
+			return nil, "", err
+		}
+
+		// Rename the fields back to the group fields and aggregation names
+		res := bson.D{}
+		id, _ := doc["_id"].(bson.M)
+		for i, g := range q.aggregate.GroupBy {
+			res = append(res, bson.E{Key: g, Value: id["g"+strconv.Itoa(i)]})
+		}
+		for i, a := range q.aggregate.Aggregate {
+			res = append(res, bson.E{Key: a.Name(), Value: doc["a"+strconv.Itoa(i)]})
+		}
+
+		var result state.QueryItem
+		if result.Data, err = bson.MarshalExtJSON(res, false, true); err != nil {
+			result.Error = err.Error()
+		}
+		ret = append(ret, result)
+	}
+	if err = cur.Err(); err != nil {
+		return nil, "", err
+	}
+	// set next query token only if limit is specified
+	var token string
+	if q.limit != 0 {
+		token = strconv.FormatInt(q.skip+int64(len(ret)), 10)
+	}
+
+	return ret, token, nil
+}
+
 func (q *Query) execute(ctx context.Context, collection *mongo.Collection) ([]state.QueryItem, string, error) {
+	if q.aggregate != nil {
+		return q.executeAggregate(ctx, collection)
+	}
+
 	cur, err := collection.Find(ctx, q.filter, []*options.FindOptions{q.opts}...)
 	if err != nil {
 		return nil, "", err
@@ -20,6 +20,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/dapr/components-contrib/state/query"
 )
@@ -57,6 +58,10 @@ func TestMongoQuery(t *testing.T) {
 			input: "../../tests/state/query/q7.json",
 			query: `{ "$or": [ { "value.person.id": {"$lt": 123} }, { "$and": [ { "value.person.org": {"$gte": 2} }, { "value.person.id": { "$in": [ 567, 890 ] } } ] } ] }`,
 		},
+		{
+			input: "../../tests/state/query/q9.json",
+			query: `{ "$and": [ { "$nor": [ { "value.state": "CA" } ] }, { "value.person.org": { "$nin": [ "A", "B" ] } }, { "value.person.name": { "$exists": true } }, { "value.person.name": { "$regularExpression": { "pattern": "^Jo.*$", "options": "s" } } }, { "value.tags": { "$elemMatch": { "$eq": "vip" } } }, { "value.age": { "$gte": 18, "$lte": 65 } } ] }`,
+		},
 	}
 	for _, test := range tests {
 		data, err := os.ReadFile(test.input)
@@ -72,3 +77,83 @@ func TestMongoQuery(t *testing.T) {
 		assert.Equal(t, test.query, q.query)
 	}
 }
+
+func TestMongoQueryValues(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		query  string
+		filter bson.D
+	}{
+		"CONTAINS with an object": {
+			input: `{"filter":{"CONTAINS":{"items":{"sku":"a\"b","qty":[1,2]}}}}`,
+			query: `{ "value.items": { "$elemMatch": { "$eq": {"qty":[1,2],"sku":"a\"b"} } } }`,
+			filter: bson.D{{Key: "value.items", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "$eq", Value: bson.D{
+				{Key: "qty", Value: bson.A{int32(1), int32(2)}},
+				{Key: "sku", Value: `a"b`},
+			}}}}}}},
+		},
+		"NIN with strings, arrays and null": {
+			input:  `{"filter":{"NIN":{"tag":["say \"hi\"\n",["x"],null]}}}`,
+			query:  `{ "value.tag": { "$nin": [ "say \"hi\"\n", ["x"], null ] } }`,
+			filter: bson.D{{Key: "value.tag", Value: bson.D{{Key: "$nin", Value: bson.A{"say \"hi\"\n", bson.A{"x"}, nil}}}}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var qq query.Query
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &qq))
+
+			q := &Query{}
+			require.NoError(t, query.NewQueryBuilder(q).BuildQuery(&qq))
+			assert.Equal(t, tt.query, q.query)
+			assert.Equal(t, tt.filter, q.filter)
+		})
+	}
+}
+
+func TestMongoQueryProjection(t *testing.T) {
+	buildQuery := func(t *testing.T, input string) *Query {
+		t.Helper()
+		data, err := os.ReadFile(input)
+		require.NoError(t, err)
+		var qq query.Query
+		require.NoError(t, json.Unmarshal(data, &qq))
+
+		q := &Query{}
+		require.NoError(t, query.NewQueryBuilder(q).BuildQuery(&qq))
+		return q
+	}
+
+	t.Run("select", func(t *testing.T) {
+		q := buildQuery(t, "../../tests/state/query/q11.json")
+		assert.Equal(t, `{ "value.state": "CA" }`, q.query)
+		assert.Equal(t, bson.D{
+			{Key: "_id", Value: 1},
+			{Key: "_etag", Value: 1},
+			{Key: "value.person.name", Value: 1},
+			{Key: "value.person.org", Value: 1},
+			{Key: "value.state", Value: 1},
+		}, q.opts.Projection)
+		assert.Nil(t, q.pipeline)
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		q := buildQuery(t, "../../tests/state/query/q10.json")
+		assert.Nil(t, q.opts)
+		require.Len(t, q.pipeline, 4)
+		assert.Equal(t, "$match", q.pipeline[0][0].Key)
+
+		pipeline, err := bson.MarshalExtJSON(bson.D{{Key: "pipeline", Value: q.pipeline[1:]}}, false, false)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"pipeline": [
+			{"$group": {
+				"_id": {"g0": "$value.state"},
+				"a0": {"$sum": 1},
+				"a1": {"$sum": {"$cond": [{"$isNumber": "$value.person.id"}, "$value.person.id", null]}},
+				"a2": {"$max": {"$cond": [{"$isNumber": "$value.person.id"}, "$value.person.id", null]}}
+			}},
+			{"$sort": {"a0": -1, "_id.g0": 1}},
+			{"$limit": 2}
+		]}`, string(pipeline))
+	})
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// Expire sets or removes the expiration time of the key, keeping its value and ETag.
+func (m *MongoDB) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var ttlExpr any = primitive.Null{}
+	if req.TTLInSeconds > 0 {
+		// MongoDB stores time in milliseconds so multiply seconds by 1000.
+		ttlExpr = bson.D{{Key: "$add", Value: bson.A{"$$NOW", req.TTLInSeconds * 1000}}}
+	}
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{{Key: ttl, Value: ttlExpr}}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.D{{Key: etag, Value: 1}, {Key: ttl, Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var result Item
+	err := m.collection.FindOneAndUpdate(ctx, ttlFilter(req.Key), update, opts).Decode(&result)
+	return ttlResponse(&result, err)
+}
+
+// GetTTL returns the expiration time of the key.
+func (m *MongoDB) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts := options.FindOne().
+		SetProjection(bson.D{{Key: etag, Value: 1}, {Key: ttl, Value: 1}})
+
+	var result Item
+	err := m.collection.FindOne(ctx, ttlFilter(req.Key), opts).Decode(&result)
+	return ttlResponse(&result, err)
+}
+
+// ttlFilter returns the filter matching the key, if it hasn't expired.
+func ttlFilter(key string) bson.D {
+	return bson.D{
+		{Key: "$and", Value: bson.A{
+			bson.D{{Key: id, Value: bson.M{"$eq": key}}},
+			getFilterTTL(),
+		}},
+	}
+}
+
+func ttlResponse(result *Item, err error) (*state.TTLResponse, error) {
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &state.TTLResponse{}, nil
+		}
+		return nil, fmt.Errorf("error in reading expiration time: %w", err)
+	}
+	return &state.TTLResponse{
+		Found:      true,
+		ExpireTime: result.TTL,
+		ETag:       &result.Etag,
+	}, nil
+}
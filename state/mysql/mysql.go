@@ -83,6 +83,7 @@ type MySQL struct {
 	schemaName        string
 	connectionString  string
 	timeout           time.Duration
+	inProcessQueries  bool
 
 	// Instance of the database to issue commands to
 	db *sql.DB
@@ -102,6 +103,8 @@ type mySQLMetadata struct {
 	PemPath           string
 	MetadataTableName string
 	CleanupInterval   *time.Duration
+	// If true, queries are evaluated in process, reading all the items in the table
+	EnableInProcessQueries bool
 }
 
 // NewMySQLStateStore creates a new instance of MySQL state store.
@@ -192,6 +195,7 @@ func (m *MySQL) parseMetadata(md map[string]string) error {
 		return errors.New(errMissingConnectionString)
 	}
 	m.connectionString = meta.ConnectionString
+	m.inProcessQueries = meta.EnableInProcessQueries
 
 	// Cleanup interval
 	if meta.CleanupInterval != nil {
@@ -227,12 +231,20 @@ func (m *MySQL) parseMetadata(md map[string]string) error {
 
 // Features returns the features available in this state store.
 func (m *MySQL) Features() []state.Feature {
-	return []state.Feature{
+	features := []state.Feature{
 		state.FeatureETag,
 		state.FeatureTransactional,
 		state.FeatureTTL,
 		state.FeatureKeysLike,
+		state.FeatureDeleteWithPrefix,
+		state.FeatureTTLManagement,
+		state.FeatureRangeScan,
+		state.FeatureOutbox,
 	}
+	if m.inProcessQueries {
+		features = append(features, state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
+	return features
 }
 
 // Ping the database.
@@ -276,6 +288,10 @@ func (m *MySQL) finishInit(ctx context.Context, db *sql.DB) error {
 		return err
 	}
 
+	if err = m.ensureOutboxTable(ctx, m.schemaName, m.outboxTableName()); err != nil {
+		return err
+	}
+
 	if m.cleanupInterval != nil {
 		gc, err := commonsql.ScheduleGarbageCollector(commonsql.GCOptions{
 			Logger: m.logger,
@@ -523,6 +539,32 @@ func (m *MySQL) deleteValue(parentCtx context.Context, querier querier, req *sta
 	return nil
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the cleanup of expired keys.
+func (m *MySQL) DeleteWithPrefix(parentCtx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	// Backslash is the default escape character of LIKE in MySQL
+	match, exclude := utils.DeleteWithPrefixPatterns(req.Prefix)
+
+	execCtx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	// Keys are compared as binary strings, as the default collation is case-insensitive
+	result, err := m.db.ExecContext(execCtx,
+		`DELETE FROM `+m.tableName+` WHERE BINARY id LIKE BINARY ? AND id NOT LIKE ?
+			AND (expiredate IS NULL OR expiredate > CURRENT_TIMESTAMP)`,
+		match, exclude)
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: count}, nil
+}
+
 // Get returns an entity from store
 // Store Interface.
 func (m *MySQL) Get(parentCtx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
@@ -817,6 +859,8 @@ func (m *MySQL) execMultiOperation(ctx context.Context, op state.TransactionalSt
 		return m.setValue(ctx, db, &req)
 	case state.DeleteRequest:
 		return m.deleteValue(ctx, db, &req)
+	case state.OutboxRequest:
+		return m.saveOutbox(ctx, db, &req)
 	default:
 		return fmt.Errorf("unsupported operation: %s", op.Operation())
 	}
@@ -871,6 +915,23 @@ func (m *MySQL) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
 	return
 }
 
+// Query executes a query against the state table.
+// Queries are evaluated in process by state.KeysLikeQuerier, which reads all the keys for each query, so they must be enabled with the enableInProcessQueries metadata property.
+func (m *MySQL) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !m.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(m).Query(ctx, req)
+}
+
+// QueryAggregate executes a query with projections or aggregations against the state table.
+func (m *MySQL) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !m.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(m).QueryAggregate(ctx, req)
+}
+
 func (m *MySQL) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
 	if len(req.Pattern) == 0 {
 		return nil, state.ErrKeysLikeEmptyPattern
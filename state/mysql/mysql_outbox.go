@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sqltransactions "github.com/dapr/components-contrib/common/component/sql/transactions"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// outboxTableName returns the name of the table that contains the messages saved by transactions.
+func (m *MySQL) outboxTableName() string {
+	return m.tableName + "_outbox"
+}
+
+func (m *MySQL) ensureOutboxTable(ctx context.Context, schemaName, outboxTableName string) error {
+	exists, err := tableExists(ctx, m.db, schemaName, outboxTableName, m.timeout)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		m.logger.Infof("Creating MySql outbox table '%s'", outboxTableName)
+		// seq is the order in which messages were saved, while id is the ID of the message chosen by the application
+		// Note that outboxTableName is sanitized
+		//nolint:gosec
+		_, err = m.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			seq BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+			id VARCHAR(255) NOT NULL,
+			pubsub_name VARCHAR(255) NOT NULL,
+			topic VARCHAR(255) NOT NULL,
+			data LONGBLOB NOT NULL,
+			content_type VARCHAR(255) NULL,
+			metadata TEXT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			leasedate DATETIME(3) NULL,
+			UNIQUE KEY id_uidx (id),
+			INDEX pubsub_idx (pubsub_name, seq)
+			);`, outboxTableName))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveOutbox saves a message in the outbox, unless a message with the same ID is already there.
+func (m *MySQL) saveOutbox(parentCtx context.Context, db querier, req *state.OutboxRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var md *string
+	if len(req.Metadata) > 0 {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata of outbox message: %w", err)
+		}
+		md = ptr.Of(string(b))
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := db.ExecContext(ctx, `INSERT INTO `+m.outboxTableName()+`
+			(id, pubsub_name, topic, data, content_type, metadata)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = id`,
+		req.ID, req.PubsubName, req.Topic, req.Data, req.ContentType, md,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
+
+// LeaseOutbox leases the oldest messages of the pubsub whose lease expired, or that were never leased.
+// Messages are selected with SKIP LOCKED, so concurrent relays don't wait for each other.
+func (m *MySQL) LeaseOutbox(parentCtx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	// MySQL doesn't support RETURNING, so the messages are selected and updated in a transaction
+	return sqltransactions.ExecuteInTransaction(ctx, m.logger, m.db, func(ctx context.Context, tx *sql.Tx) ([]state.OutboxMessage, error) {
+		//nolint:gosec
+		rows, err := tx.QueryContext(ctx, `SELECT seq, id, topic, data, content_type, metadata, attempts FROM `+m.outboxTableName()+`
+			WHERE pubsub_name = ? AND (leasedate IS NULL OR leasedate <= CURRENT_TIMESTAMP(3))
+			ORDER BY seq
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`,
+			req.PubsubName, req.Limit,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+		}
+		defer rows.Close()
+
+		var (
+			msgs []state.OutboxMessage
+			seqs []any
+		)
+		for rows.Next() {
+			var (
+				msg state.OutboxMessage
+				seq int64
+				md  sql.NullString
+				ct  sql.NullString
+			)
+			err = rows.Scan(&seq, &msg.ID, &msg.Topic, &msg.Data, &ct, &md, &msg.Attempts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+			}
+			msg.PubsubName = req.PubsubName
+			msg.Attempts++
+			if ct.Valid {
+				msg.ContentType = ptr.Of(ct.String)
+			}
+			if md.Valid {
+				err = json.Unmarshal([]byte(md.String), &msg.Metadata)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode metadata of outbox message %s: %w", msg.ID, err)
+				}
+			}
+			msgs = append(msgs, msg)
+			seqs = append(seqs, seq)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+		}
+		// The rows must be closed before the connection of the transaction can be used again
+		rows.Close()
+		if len(msgs) == 0 {
+			return nil, nil
+		}
+
+		inClause := strings.Repeat("?,", len(seqs))
+		inClause = inClause[:(len(inClause) - 1)]
+		//nolint:gosec
+		_, err = tx.ExecContext(ctx, `UPDATE `+m.outboxTableName()+`
+			SET attempts = attempts + 1, leasedate = DATE_ADD(CURRENT_TIMESTAMP(3), INTERVAL ?*1000 MICROSECOND)
+			WHERE seq IN (`+inClause+`)`,
+			append([]any{req.LeaseDuration.Milliseconds()}, seqs...)...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+		}
+		return msgs, nil
+	})
+}
+
+// DeleteOutbox removes messages that were published from the outbox.
+func (m *MySQL) DeleteOutbox(parentCtx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	inClause := strings.Repeat("?,", len(ids))
+	inClause = inClause[:(len(inClause) - 1)]
+	params := make([]any, len(ids))
+	for i, id := range ids {
+		params[i] = id
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := m.db.ExecContext(ctx, `DELETE FROM `+m.outboxTableName()+` WHERE id IN (`+inClause+`)`, params...)
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOutbox makes a leased message available again after the delay.
+func (m *MySQL) ReleaseOutbox(parentCtx context.Context, id string, delay time.Duration) error {
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := m.db.ExecContext(ctx, `UPDATE `+m.outboxTableName()+`
+		SET leasedate = DATE_ADD(CURRENT_TIMESTAMP(3), INTERVAL ?*1000 MICROSECOND)
+		WHERE id = ?`,
+		delay.Milliseconds(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message: %w", err)
+	}
+	return nil
+}
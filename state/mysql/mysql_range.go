@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+// Keys are compared byte-wise, regardless of the collation of the table, which may be case-insensitive.
+func (m *MySQL) RangeScan(parentCtx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	after, order := ">", "ASC"
+	if req.Reverse {
+		after, order = "<", "DESC"
+	}
+	var token string
+	if req.ContinuationToken != nil {
+		token = *req.ContinuationToken
+	}
+	// One more row is read to know if there's another page
+	var limit string
+	if req.Limit > 0 {
+		limit = " LIMIT " + strconv.FormatUint(uint64(req.Limit)+1, 10)
+	}
+
+	// Concatenation is required for table name because sql.DB does not substitute parameters for table names
+	//nolint:gosec
+	stmt := `SELECT id, value, eTag, isbinary, IFNULL(expiredate, "") FROM ` + m.tableName + `
+		WHERE
+			BINARY id >= BINARY ?
+			AND (? = '' OR BINARY id < BINARY ?)
+			AND (? = '' OR BINARY id ` + after + ` BINARY ?)
+			AND (expiredate IS NULL OR expiredate > CURRENT_TIMESTAMP)
+		ORDER BY BINARY id ` + order + limit
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	rows, err := m.db.QueryContext(ctx, stmt, req.Start, req.End, req.End, token, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan range: %w", err)
+	}
+	defer rows.Close()
+
+	res := &state.RangeScanResponse{}
+	for rows.Next() {
+		if req.Limit > 0 && len(res.Items) == int(req.Limit) {
+			res.ContinuationToken = ptr.Of(res.Items[len(res.Items)-1].Key)
+			break
+		}
+		key, value, etag, expireTime, err := readRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan range: %w", err)
+		}
+		item := state.RangeScanItem{
+			Key:  key,
+			Data: value,
+			ETag: etag,
+		}
+		if expireTime != nil {
+			item.Metadata = map[string]string{
+				state.GetRespMetaKeyTTLExpireTime: expireTime.UTC().Format(time.RFC3339),
+			}
+		}
+		res.Items = append(res.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan range: %w", err)
+	}
+	return res, nil
+}
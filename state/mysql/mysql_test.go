@@ -31,6 +31,7 @@ import (
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 const (
@@ -347,6 +348,26 @@ func TestDeleteWithErr(t *testing.T) {
 	})
 }
 
+func TestDeleteWithPrefix(t *testing.T) {
+	// Arrange
+	m, _ := mockDatabase(t)
+	defer m.mySQL.Close()
+
+	m.mock1.ExpectExec(`DELETE FROM state WHERE BINARY id LIKE BINARY \? AND id NOT LIKE \?`).
+		WithArgs(`my\_app||%`, `my\_app||%||%`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	// Act
+	res, err := m.mySQL.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "my_app"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Count)
+
+	_, err = m.mySQL.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{})
+	require.ErrorContains(t, err, "prefix is required")
+}
+
 func TestGetHandlesNoRows(t *testing.T) {
 	// Arrange
 	m, _ := mockDatabase(t)
@@ -451,6 +472,67 @@ func TestGetSucceeds(t *testing.T) {
 
 // Verifies that the correct query is executed to test if the table
 // already exists in the database or not.
+func TestTTLManagement(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.mySQL.Close()
+
+	t.Run("expire sets the expiration time", func(t *testing.T) {
+		expire := time.UnixMilli(20000).UTC()
+		m.mock1.ExpectBegin()
+		m.mock1.ExpectExec(`UPDATE state\s+SET expiredate = CURRENT_TIMESTAMP \+ INTERVAL 10 SECOND`).
+			WithArgs("UnitTest").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.mock1.ExpectQuery(`SELECT eTag, IFNULL\(expiredate, ""\) FROM state WHERE id = \?`).
+			WithArgs("UnitTest").
+			WillReturnRows(sqlmock.NewRows([]string{"eTag", "expiredate"}).AddRow("946af56e", expire.Format(time.DateTime)))
+		m.mock1.ExpectCommit()
+
+		res, err := m.mySQL.Expire(t.Context(), &state.ExpireRequest{Key: "UnitTest", TTLInSeconds: 10})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		require.NotNil(t, res.ETag)
+		assert.Equal(t, "946af56e", *res.ETag)
+		require.NotNil(t, res.ExpireTime)
+		assert.Equal(t, expire, *res.ExpireTime)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("expire removes the expiration time", func(t *testing.T) {
+		m.mock1.ExpectBegin()
+		m.mock1.ExpectExec(`UPDATE state\s+SET expiredate = NULL`).
+			WithArgs("UnitTest").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.mock1.ExpectQuery(`SELECT eTag, IFNULL\(expiredate, ""\) FROM state WHERE id = \?`).
+			WithArgs("UnitTest").
+			WillReturnRows(sqlmock.NewRows([]string{"eTag", "expiredate"}).AddRow("946af56e", ""))
+		m.mock1.ExpectCommit()
+
+		res, err := m.mySQL.Expire(t.Context(), &state.ExpireRequest{Key: "UnitTest"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("get TTL of a key that doesn't exist", func(t *testing.T) {
+		m.mock1.ExpectQuery(`SELECT eTag, IFNULL\(expiredate, ""\) FROM state WHERE id = \?`).
+			WithArgs("NotFound").
+			WillReturnError(sql.ErrNoRows)
+
+		res, err := m.mySQL.GetTTL(t.Context(), &state.GetTTLRequest{Key: "NotFound"})
+		require.NoError(t, err)
+		assert.False(t, res.Found)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := m.mySQL.GetTTL(t.Context(), &state.GetTTLRequest{})
+		require.Error(t, err)
+		_, err = m.mySQL.Expire(t.Context(), &state.ExpireRequest{})
+		require.Error(t, err)
+	})
+}
+
 func TestTableExists(t *testing.T) {
 	// Arrange
 	m, _ := mockDatabase(t)
@@ -586,6 +668,20 @@ func TestInitSetsTableName(t *testing.T) {
 	assert.Equal(t, "stateStore", m.mySQL.tableName, "table name did not default")
 }
 
+func TestInProcessQueries(t *testing.T) {
+	t.Parallel()
+	m, _ := mockDatabase(t)
+
+	// Queries are disabled by default
+	assert.NotContains(t, m.mySQL.Features(), state.FeatureQueryAPI)
+	_, err := m.mySQL.Query(t.Context(), &state.QueryRequest{})
+	require.ErrorIs(t, err, state.ErrInProcessQueriesDisabled)
+
+	require.NoError(t, m.mySQL.parseMetadata(map[string]string{keyConnectionString: "theUser:thePassword@/theDBName", "enableInProcessQueries": "true"}))
+	assert.Contains(t, m.mySQL.Features(), state.FeatureQueryAPI)
+	assert.Contains(t, m.mySQL.Features(), state.FeatureQueryInProcess)
+}
+
 func TestInitInvalidTableName(t *testing.T) {
 	// Arrange
 	t.Parallel()
@@ -936,3 +1032,109 @@ func Test_KeysLike(t *testing.T) {
 	m, _ := mockDatabase(t)
 	var _ state.KeysLiker = m.mySQL
 }
+
+func TestRangeScan(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.mySQL.Close()
+
+	t.Run("first page", func(t *testing.T) {
+		m.mock1.ExpectQuery(`(?s)SELECT id, value, eTag, isbinary, IFNULL\(expiredate, ""\) FROM state\s+WHERE\s+BINARY id >= BINARY \?.+BINARY id > BINARY \?.+ORDER BY BINARY id ASC LIMIT 3`).
+			WithArgs("orders||", "orders||~", "orders||~", "", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "value", "eTag", "isbinary", "expiredate"}).
+				AddRow("orders||1", `"a"`, "e1", false, "").
+				AddRow("orders||2", `"b"`, "e2", false, "2024-05-01 10:00:00").
+				AddRow("orders||3", `"c"`, "e3", false, ""))
+
+		res, err := m.mySQL.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||", End: "orders||~", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		assert.Equal(t, "orders||1", res.Items[0].Key)
+		assert.Equal(t, `"a"`, string(res.Items[0].Data))
+		assert.Equal(t, "e1", *res.Items[0].ETag)
+		assert.Equal(t, "2024-05-01T10:00:00Z", res.Items[1].Metadata[state.GetRespMetaKeyTTLExpireTime])
+		require.NotNil(t, res.ContinuationToken)
+		assert.Equal(t, "orders||2", *res.ContinuationToken)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("last page in reverse order", func(t *testing.T) {
+		m.mock1.ExpectQuery(`(?s)BINARY id < BINARY \?.+ORDER BY BINARY id DESC$`).
+			WithArgs("orders||", "", "", "orders||2", "orders||2").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "value", "eTag", "isbinary", "expiredate"}).
+				AddRow("orders||1", `"a"`, "e1", false, ""))
+
+		res, err := m.mySQL.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||", Reverse: true, ContinuationToken: ptr.Of("orders||2")})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "orders||1", res.Items[0].Key)
+		assert.Nil(t, res.ContinuationToken)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := m.mySQL.RangeScan(t.Context(), &state.RangeScanRequest{Start: "b", End: "a"})
+		require.Error(t, err)
+	})
+}
+
+func TestOutbox(t *testing.T) {
+	m, _ := mockDatabase(t)
+	defer m.mySQL.Close()
+
+	t.Run("messages are saved in the transaction", func(t *testing.T) {
+		m.mock1.ExpectBegin()
+		m.mock1.ExpectExec("REPLACE INTO").WillReturnResult(sqlmock.NewResult(0, 1))
+		m.mock1.ExpectExec(`(?s)INSERT INTO state_outbox.+ON DUPLICATE KEY UPDATE id = id`).
+			WithArgs("msg1", "events", "orders", []byte("created"), nil, `{"k":"v"}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.mock1.ExpectCommit()
+
+		err := m.mySQL.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: "k1", Value: "v1"},
+				state.OutboxRequest{ID: "msg1", PubsubName: "events", Topic: "orders", Data: []byte("created"), Metadata: map[string]string{"k": "v"}},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("lease selects and updates the messages in a transaction", func(t *testing.T) {
+		m.mock1.ExpectBegin()
+		m.mock1.ExpectQuery(`(?s)SELECT seq, id, topic, data, content_type, metadata, attempts FROM state_outbox.+ORDER BY seq.+FOR UPDATE SKIP LOCKED`).
+			WithArgs("events", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "topic", "data", "content_type", "metadata", "attempts"}).
+				AddRow(1, "msg1", "orders", []byte("a"), "text/plain", `{"k":"v"}`, 1).
+				AddRow(2, "msg2", "orders", []byte("b"), nil, nil, 0))
+		m.mock1.ExpectExec(`(?s)UPDATE state_outbox.+WHERE seq IN \(\?,\?\)`).
+			WithArgs(int64(30000), int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.mock1.ExpectCommit()
+
+		msgs, err := m.mySQL.LeaseOutbox(t.Context(), &state.LeaseOutboxRequest{PubsubName: "events", Limit: 10, LeaseDuration: 30 * time.Second})
+		require.NoError(t, err)
+		require.Len(t, msgs, 2)
+		assert.Equal(t, "msg1", msgs[0].ID)
+		assert.Equal(t, "events", msgs[0].PubsubName)
+		assert.Equal(t, "text/plain", *msgs[0].ContentType)
+		assert.Equal(t, map[string]string{"k": "v"}, msgs[0].Metadata)
+		assert.Equal(t, 2, msgs[0].Attempts)
+		assert.Equal(t, "msg2", msgs[1].ID)
+		assert.Nil(t, msgs[1].ContentType)
+		assert.Equal(t, 1, msgs[1].Attempts)
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+
+	t.Run("delete and release", func(t *testing.T) {
+		m.mock1.ExpectExec(`DELETE FROM state_outbox WHERE id IN \(\?,\?\)`).
+			WithArgs("msg1", "msg2").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.mock1.ExpectExec("UPDATE state_outbox").
+			WithArgs(int64(5000), "msg3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, m.mySQL.DeleteOutbox(t.Context(), []string{"msg1", "msg2"}))
+		require.NoError(t, m.mySQL.ReleaseOutbox(t.Context(), "msg3", 5*time.Second))
+		require.NoError(t, m.mock1.ExpectationsWereMet())
+	})
+}
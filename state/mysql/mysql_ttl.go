@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	sqltransactions "github.com/dapr/components-contrib/common/component/sql/transactions"
+	"github.com/dapr/components-contrib/state"
+)
+
+// Expire sets or removes the expiration time of the key, keeping its value and ETag.
+// MySQL doesn't return the updated rows, so the key is read again in the same transaction.
+func (m *MySQL) Expire(parentCtx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ttlQuery := "NULL"
+	if req.TTLInSeconds > 0 {
+		ttlQuery = "CURRENT_TIMESTAMP + INTERVAL " + strconv.FormatInt(req.TTLInSeconds, 10) + " SECOND"
+	}
+
+	return sqltransactions.ExecuteInTransaction(parentCtx, m.logger, m.db, func(parentCtx context.Context, tx *sql.Tx) (*state.TTLResponse, error) {
+		ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+		defer cancel()
+		// Concatenation is required for table name because sql.DB does not substitute parameters for table names
+		//nolint:gosec
+		_, err := tx.ExecContext(ctx, `UPDATE `+m.tableName+`
+			SET expiredate = `+ttlQuery+`
+			WHERE id = ?
+				AND (expiredate IS NULL OR expiredate > CURRENT_TIMESTAMP)`,
+			req.Key,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return m.readTTL(ctx, tx, req.Key)
+	})
+}
+
+// GetTTL returns the expiration time of the key.
+func (m *MySQL) GetTTL(parentCtx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, m.timeout)
+	defer cancel()
+	return m.readTTL(ctx, m.db, req.Key)
+}
+
+func (m *MySQL) readTTL(ctx context.Context, querier querier, key string) (*state.TTLResponse, error) {
+	var etag, expire string
+	//nolint:gosec
+	err := querier.QueryRowContext(ctx, `SELECT eTag, IFNULL(expiredate, "") FROM `+m.tableName+` WHERE id = ?
+			AND (expiredate IS NULL OR expiredate > CURRENT_TIMESTAMP)`,
+		key,
+	).Scan(&etag, &expire)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &state.TTLResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to read expiration time of key %s: %w", key, err)
+	}
+
+	res := &state.TTLResponse{Found: true, ETag: &etag}
+	if expire != "" {
+		expireT, err := time.Parse(time.DateTime, expire)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse expiration time: %w", err)
+		}
+		res.ExpireTime = &expireT
+	}
+	return res, nil
+}
@@ -29,6 +29,7 @@ type dbAccess interface {
 	BulkGet(ctx context.Context, req []state.GetRequest) ([]state.BulkGetResponse, error)
 	Delete(ctx context.Context, req *state.DeleteRequest) error
 	ExecuteMulti(parentCtx context.Context, reqs []state.TransactionalStateOperation) error
+	KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error)
 	Close() error // io.Closer.
 }
 
@@ -17,6 +17,7 @@ import (
 	"context"
 	"database/sql"
 	"reflect"
+	"slices"
 
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
@@ -30,6 +31,8 @@ type OracleDatabase struct {
 	features []state.Feature
 	logger   logger.Logger
 	dbaccess dbAccess
+
+	inProcessQueries bool
 }
 
 // NewOracleDatabaseStateStore creates a new instance of OracleDatabase state store.
@@ -48,6 +51,7 @@ func newOracleDatabaseStateStore(logger logger.Logger, dba dbAccess) *OracleData
 			state.FeatureETag,
 			state.FeatureTransactional,
 			state.FeatureTTL,
+			state.FeatureKeysLike,
 		},
 		logger:   logger,
 		dbaccess: dba,
@@ -56,6 +60,11 @@ func newOracleDatabaseStateStore(logger logger.Logger, dba dbAccess) *OracleData
 
 // Init initializes the SQL server state store.
 func (o *OracleDatabase) Init(ctx context.Context, metadata state.Metadata) error {
+	meta, err := parseMetadata(metadata.Properties)
+	if err != nil {
+		return err
+	}
+	o.inProcessQueries = meta.EnableInProcessQueries
 	return o.dbaccess.Init(ctx, metadata)
 }
 
@@ -65,6 +74,9 @@ func (o *OracleDatabase) Ping(ctx context.Context) error {
 
 // Features returns the features available in this state store.
 func (o *OracleDatabase) Features() []state.Feature {
+	if o.inProcessQueries {
+		return append(slices.Clone(o.features), state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
 	return o.features
 }
 
@@ -92,6 +104,31 @@ func (o *OracleDatabase) Multi(ctx context.Context, request *state.Transactional
 	return o.dbaccess.ExecuteMulti(ctx, request.Operations)
 }
 
+// KeysLike returns the keys that match the pattern.
+func (o *OracleDatabase) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	if len(req.Pattern) == 0 {
+		return nil, state.ErrKeysLikeEmptyPattern
+	}
+	return o.dbaccess.KeysLike(ctx, req)
+}
+
+// Query executes a query against the state table.
+// Queries are evaluated in process by state.KeysLikeQuerier, which reads all the keys for each query, so they must be enabled with the enableInProcessQueries metadata property.
+func (o *OracleDatabase) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !o.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(o).Query(ctx, req)
+}
+
+// QueryAggregate executes a query with projections or aggregations against the state table.
+func (o *OracleDatabase) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !o.inProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(o).QueryAggregate(ctx, req)
+}
+
 // Close implements io.Closer.
 func (o *OracleDatabase) Close() error {
 	if o.dbaccess != nil {
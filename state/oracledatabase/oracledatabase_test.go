@@ -76,6 +76,10 @@ func (m *fakeDBaccess) ExecuteMulti(parentCtx context.Context, reqs []state.Tran
 	return nil
 }
 
+func (m *fakeDBaccess) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	return &state.KeysLikeResponse{}, nil
+}
+
 func (m *fakeDBaccess) Close() error {
 	return nil
 }
@@ -170,3 +174,25 @@ func createOracleDatabase(t *testing.T) *OracleDatabase {
 
 	return odb
 }
+
+func TestInProcessQueries(t *testing.T) {
+	t.Parallel()
+
+	ods := createOracleDatabase(t)
+	assert.Contains(t, ods.Features(), state.FeatureKeysLike)
+	assert.NotContains(t, ods.Features(), state.FeatureQueryAPI)
+	_, err := ods.Query(t.Context(), &state.QueryRequest{})
+	require.ErrorIs(t, err, state.ErrInProcessQueriesDisabled)
+
+	err = ods.Init(t.Context(), state.Metadata{Base: metadata.Base{Properties: map[string]string{
+		connectionStringKey:      fakeConnectionString,
+		"enableInProcessQueries": "true",
+	}}})
+	require.NoError(t, err)
+	assert.Contains(t, ods.Features(), state.FeatureQueryAPI)
+	assert.Contains(t, ods.Features(), state.FeatureQueryInProcess)
+
+	res, err := ods.Query(t.Context(), &state.QueryRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Results)
+}
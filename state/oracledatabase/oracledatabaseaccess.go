@@ -53,6 +53,8 @@ type oracleDatabaseMetadata struct {
 	ConnectionString     string `json:"connectionString"`
 	OracleWalletLocation string `json:"oracleWalletLocation"`
 	TableName            string `json:"tableName"`
+	// If true, queries are evaluated in process, reading all the items in the table
+	EnableInProcessQueries bool `json:"enableInProcessQueries"`
 }
 
 // newOracleDatabaseAccess creates a new instance of oracleDatabaseAccess.
@@ -528,7 +530,7 @@ func tableExists(db *sql.DB, tableName string) (bool, error) {
 	return true, nil
 }
 
-func (o *oracleDatabaseAccess) KeysLike(ctx context.Context, req state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+func (o *oracleDatabaseAccess) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
 	if o.db == nil {
 		return nil, errors.New("oracle db not initialized")
 	}
@@ -0,0 +1,52 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package outbox implements the transactional outbox pattern on top of the state stores that implement state.OutboxStore.
+//
+// Messages are added to a transaction with NewRequest, so they're saved in the outbox of the state store only if the state changes are committed.
+// Relay publishes the messages in the outbox with any pubsub component, and removes them once they're published.
+// Delivery is at-least-once: messages may be published again if a relay fails before removing them, so subscribers should discard duplicates using the ID in the MetadataKeyMessageID metadata property.
+package outbox
+
+import (
+	"maps"
+
+	"github.com/google/uuid"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/state"
+)
+
+// MetadataKeyMessageID is the metadata property of published messages that contains the ID of the outbox message.
+// If it's set in the metadata of the request passed to NewRequest, it's used as the ID of the message.
+const MetadataKeyMessageID = "outboxMessageID"
+
+// NewRequest returns an operation that saves the message in the outbox, to be added to a transaction with the state changes.
+// The message gets a random ID, unless the request has one in the MetadataKeyMessageID metadata property; saving a message with the ID of one that's already in the outbox has no effect.
+func NewRequest(req *pubsub.PublishRequest) state.OutboxRequest {
+	md := maps.Clone(req.Metadata)
+	if md == nil {
+		md = make(map[string]string, 1)
+	}
+	if md[MetadataKeyMessageID] == "" {
+		md[MetadataKeyMessageID] = uuid.NewString()
+	}
+	return state.OutboxRequest{
+		ID:          md[MetadataKeyMessageID],
+		PubsubName:  req.PubsubName,
+		Topic:       req.Topic,
+		Data:        req.Data,
+		ContentType: req.ContentType,
+		Metadata:    md,
+	}
+}
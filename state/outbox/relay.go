@@ -0,0 +1,208 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultBatchSize        = 10
+	defaultPollInterval     = time.Second
+	defaultLeaseDuration    = 30 * time.Second
+	defaultRetryInterval    = time.Second
+	defaultMaxRetryInterval = time.Minute
+	defaultTimeout          = 20 * time.Second
+)
+
+// RelayOptions contains the options for Relay.
+// Durations and sizes that are zero are set to their defaults.
+type RelayOptions struct {
+	Logger logger.Logger
+	Store  state.OutboxStore
+	// Component the messages are published with.
+	PubSub pubsub.PubSub
+	// Name of the pubsub component: only the messages saved with this name are published.
+	PubsubName string
+
+	// Maximum number of messages leased at a time. Default: 10.
+	BatchSize int
+	// Interval between the queries for new messages, when there are none. Default: 1s.
+	PollInterval time.Duration
+	// Duration of the lease of the messages; if the relay doesn't publish them before it expires, they're leased by another relay. Default: 30s.
+	LeaseDuration time.Duration
+	// Delay before a message that failed to be published is published again; it doubles after each attempt, up to MaxRetryInterval. Default: 1s.
+	RetryInterval time.Duration
+	// Maximum delay before a message that failed to be published is published again. Default: 1m.
+	MaxRetryInterval time.Duration
+	// Timeout of each query and publish. Default: 20s.
+	Timeout time.Duration
+}
+
+// Relay publishes the messages in the outbox of the store, until the context is canceled.
+// Messages are leased oldest first and removed from the outbox once they're published, but they aren't guaranteed to be published in the order they were saved. When a message fails to be published, it's published again after a delay, and the following messages of the same topic in the batch are postponed with it; messages leased in later batches or by other relays can still be published before it.
+// Multiple relays can publish the messages of the same store concurrently, as each message is leased by one relay at a time.
+func Relay(ctx context.Context, opts RelayOptions) {
+	r := &relay{opts: opts}
+	r.setDefaults()
+	r.run(ctx)
+}
+
+type relay struct {
+	opts RelayOptions
+}
+
+func (r *relay) setDefaults() {
+	if r.opts.BatchSize <= 0 {
+		r.opts.BatchSize = defaultBatchSize
+	}
+	if r.opts.PollInterval <= 0 {
+		r.opts.PollInterval = defaultPollInterval
+	}
+	if r.opts.LeaseDuration <= 0 {
+		r.opts.LeaseDuration = defaultLeaseDuration
+	}
+	if r.opts.RetryInterval <= 0 {
+		r.opts.RetryInterval = defaultRetryInterval
+	}
+	if r.opts.MaxRetryInterval <= 0 {
+		r.opts.MaxRetryInterval = defaultMaxRetryInterval
+	}
+	if r.opts.Timeout <= 0 {
+		r.opts.Timeout = defaultTimeout
+	}
+}
+
+func (r *relay) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		n, err := r.publishBatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.opts.Logger.Errorf("Error publishing outbox messages of pubsub %s: %v", r.opts.PubsubName, err)
+		} else if n == r.opts.BatchSize {
+			// There may be more messages
+			continue
+		}
+
+		timer.Reset(r.opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// publishBatch leases a batch of messages and publishes them, returning the number of leased messages.
+func (r *relay) publishBatch(ctx context.Context) (int, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	msgs, err := r.opts.Store.LeaseOutbox(queryCtx, &state.LeaseOutboxRequest{
+		PubsubName:    r.opts.PubsubName,
+		Limit:         r.opts.BatchSize,
+		LeaseDuration: r.opts.LeaseDuration,
+	})
+	cancel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease messages: %w", err)
+	}
+
+	published := make([]string, 0, len(msgs))
+	// Delay of the topics with a message that failed to be published
+	postponed := make(map[string]time.Duration)
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			// Released with no delay, so they're published by another relay
+			r.release(ctx, msg, 0)
+			continue
+		}
+		if delay, ok := postponed[msg.Topic]; ok {
+			r.release(ctx, msg, delay)
+			continue
+		}
+
+		err = r.publish(ctx, msg)
+		if err != nil && ctx.Err() != nil {
+			r.release(ctx, msg, 0)
+			continue
+		}
+		if err != nil {
+			delay := r.retryDelay(msg.Attempts)
+			r.opts.Logger.Warnf("Error publishing outbox message %s to topic %s: %v. Retrying in %v...", msg.ID, msg.Topic, err, delay)
+			postponed[msg.Topic] = delay
+			r.release(ctx, msg, delay)
+			continue
+		}
+		published = append(published, msg.ID)
+	}
+
+	// Use a new context, so the messages are deleted even if the relay was stopped while publishing them
+	queryCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), r.opts.Timeout)
+	defer cancel()
+	err = r.opts.Store.DeleteOutbox(queryCtx, published)
+	if err != nil {
+		return len(msgs), fmt.Errorf("failed to delete %d published messages, which will be published again: %w", len(published), err)
+	}
+	return len(msgs), nil
+}
+
+func (r *relay) publish(ctx context.Context, msg state.OutboxMessage) error {
+	md := msg.Metadata
+	if md[MetadataKeyMessageID] != msg.ID {
+		md = maps.Clone(md)
+		if md == nil {
+			md = make(map[string]string, 1)
+		}
+		md[MetadataKeyMessageID] = msg.ID
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+	return r.opts.PubSub.Publish(publishCtx, &pubsub.PublishRequest{
+		Data:        msg.Data,
+		PubsubName:  msg.PubsubName,
+		Topic:       msg.Topic,
+		Metadata:    md,
+		ContentType: msg.ContentType,
+	})
+}
+
+func (r *relay) release(ctx context.Context, msg state.OutboxMessage, delay time.Duration) {
+	// Use a new context, as the relay's one may be canceled
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.opts.Timeout)
+	defer cancel()
+	err := r.opts.Store.ReleaseOutbox(queryCtx, msg.ID, delay)
+	if err != nil {
+		r.opts.Logger.Errorf("Failed to release outbox message %s, which will be published after the lease expires: %v", msg.ID, err)
+	}
+}
+
+// retryDelay returns the delay before a message is published again, after the number of attempts.
+func (r *relay) retryDelay(attempts int) time.Duration {
+	delay := r.opts.RetryInterval
+	for i := 1; i < attempts && delay < r.opts.MaxRetryInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, r.opts.MaxRetryInterval)
+}
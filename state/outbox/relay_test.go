@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/sqlite"
+	"github.com/dapr/kit/logger"
+)
+
+// fakePubSub records the published messages, failing the first attempts to publish the messages with data "fail".
+type fakePubSub struct {
+	pubsub.PubSub
+
+	lock      sync.Mutex
+	failures  int
+	published []*pubsub.PublishRequest
+}
+
+func (p *fakePubSub) Publish(_ context.Context, req *pubsub.PublishRequest) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if string(req.Data) == "fail" && p.failures > 0 {
+		p.failures--
+		return errors.New("simulated error")
+	}
+	p.published = append(p.published, req)
+	return nil
+}
+
+func (p *fakePubSub) messages() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	res := make([]string, len(p.published))
+	for i, req := range p.published {
+		res[i] = req.Topic + ":" + string(req.Data)
+	}
+	return res
+}
+
+func TestNewRequest(t *testing.T) {
+	t.Run("random ID", func(t *testing.T) {
+		md := map[string]string{"k": "v"}
+		req := NewRequest(&pubsub.PublishRequest{PubsubName: "events", Topic: "orders", Data: []byte("a"), Metadata: md})
+		require.NoError(t, req.Validate())
+		assert.NotEmpty(t, req.ID)
+		assert.Equal(t, map[string]string{"k": "v", MetadataKeyMessageID: req.ID}, req.Metadata)
+		assert.Equal(t, state.OperationOutbox, req.Operation())
+		// The metadata of the request is not modified
+		assert.Len(t, md, 1)
+
+		assert.NotEqual(t, req.ID, NewRequest(&pubsub.PublishRequest{PubsubName: "events", Topic: "orders"}).ID)
+	})
+
+	t.Run("ID in the metadata", func(t *testing.T) {
+		req := NewRequest(&pubsub.PublishRequest{PubsubName: "events", Topic: "orders", Metadata: map[string]string{MetadataKeyMessageID: "order-1-created"}})
+		assert.Equal(t, "order-1-created", req.ID)
+	})
+}
+
+func TestRelay(t *testing.T) {
+	store := sqlite.NewSQLiteStateStore(logger.NewLogger("test"))
+	require.NoError(t, store.Init(t.Context(), state.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"connectionString": filepath.Join(t.TempDir(), "state.db"),
+	}}}))
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	transactional := store.(state.TransactionalStore)
+
+	ops := []state.TransactionalStateOperation{state.SetRequest{Key: "order", Value: "created"}}
+	for _, msg := range []struct{ topic, data string }{
+		{"orders", "fail"},
+		{"orders", "b"},
+		{"payments", "c"},
+		{"other", "ignored"},
+	} {
+		pubsubName := "events"
+		if msg.topic == "other" {
+			pubsubName = "other"
+		}
+		ops = append(ops, NewRequest(&pubsub.PublishRequest{PubsubName: pubsubName, Topic: msg.topic, Data: []byte(msg.data)}))
+	}
+	require.NoError(t, transactional.Multi(t.Context(), &state.TransactionalStateRequest{Operations: ops}))
+
+	ps := &fakePubSub{failures: 2}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Relay(ctx, RelayOptions{
+			Logger:        logger.NewLogger("test"),
+			Store:         store.(state.OutboxStore),
+			PubSub:        ps,
+			PubsubName:    "events",
+			BatchSize:     2,
+			PollInterval:  10 * time.Millisecond,
+			RetryInterval: 10 * time.Millisecond,
+		})
+	}()
+
+	// Messages following the one that failed in the same topic and batch are published after it
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"payments:c", "orders:fail", "orders:b"}, ps.messages())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not stop")
+	}
+
+	ps.lock.Lock()
+	for i, req := range ps.published {
+		assert.Equal(t, "events", req.PubsubName)
+		assert.NotEmpty(t, req.Metadata[MetadataKeyMessageID], i)
+	}
+	ps.lock.Unlock()
+
+	// Published messages are removed from the outbox, while the ones of other pubsub components are kept
+	msgs, err := store.(state.OutboxStore).LeaseOutbox(t.Context(), &state.LeaseOutboxRequest{PubsubName: "events", Limit: 10, LeaseDuration: time.Minute})
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+	msgs, err = store.(state.OutboxStore).LeaseOutbox(t.Context(), &state.LeaseOutboxRequest{PubsubName: "other", Limit: 10, LeaseDuration: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "ignored", string(msgs[0].Data))
+}
+
+func TestRetryDelay(t *testing.T) {
+	r := &relay{opts: RelayOptions{RetryInterval: time.Second, MaxRetryInterval: 5 * time.Second}}
+	assert.Equal(t, time.Second, r.retryDelay(1))
+	assert.Equal(t, 2*time.Second, r.retryDelay(2))
+	assert.Equal(t, 4*time.Second, r.retryDelay(3))
+	assert.Equal(t, 5*time.Second, r.retryDelay(4))
+	assert.Equal(t, 5*time.Second, r.retryDelay(100))
+}
@@ -191,9 +191,189 @@ WHERE r.key = t.key
 
 			return nil
 		},
+
+		// Migration 3: add the trigger that notifies the changes to the state table, used by Watch
+		func(ctx context.Context) error {
+			opts.Logger.Infof("Creating watch trigger on state table '%s'", opts.StateTableName)
+			// The function is shared by the triggers of all state tables, which pass the channel name as argument
+			_, err := db.Exec(ctx, `CREATE OR REPLACE FUNCTION dapr_state_notify() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    PERFORM pg_notify(TG_ARGV[0], json_build_object('key', OLD.key, 'op', 'delete')::text);
+    RETURN OLD;
+  END IF;
+  PERFORM pg_notify(TG_ARGV[0], json_build_object('key', NEW.key, 'op', 'upsert')::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`)
+			if err != nil {
+				return fmt.Errorf("failed to create notify function: %w", err)
+			}
+
+			_, err = db.Exec(ctx, fmt.Sprintf(
+				`CREATE TRIGGER dapr_watch
+					AFTER INSERT OR UPDATE OR DELETE ON %s
+					FOR EACH ROW EXECUTE FUNCTION dapr_state_notify(%s)`,
+				opts.StateTableName, quoteLiteral(opts.WatchChannel),
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create watch trigger: %w", err)
+			}
+
+			return nil
+		},
+
+		// Migration 4: create the history table and the trigger that populates it, used by versioning
+		func(ctx context.Context) error {
+			opts.Logger.Infof("Creating history table '%s'", opts.HistoryTableName)
+			// The etag of a revision is the ID of the transaction that wrote it, which is the xmin of the row in the state table
+			_, err := db.Exec(ctx, fmt.Sprintf(
+				`CREATE TABLE IF NOT EXISTS %[1]s (
+					revision bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+					key text NOT NULL,
+					value jsonb,
+					isbinary boolean,
+					etag bigint,
+					expiredate TIMESTAMP WITH TIME ZONE,
+					deleted boolean NOT NULL DEFAULT false,
+					revision_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+				)`,
+				opts.HistoryTableName,
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create history table: %w", err)
+			}
+			_, err = db.Exec(ctx, fmt.Sprintf(
+				`CREATE INDEX IF NOT EXISTS %s ON %s (key, revision)`,
+				quoteIdent(historyIndexName(opts.HistoryTableName)), opts.HistoryTableName,
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create index on history table: %w", err)
+			}
+
+			// The function is shared by the triggers of all state tables, which pass the names of the history and metadata tables, and the key of the history configuration, as arguments
+			// Revisions are recorded only when the history configuration is present, and the ones that exceed the limit or the retention window are removed
+			_, err = db.Exec(ctx, `CREATE OR REPLACE FUNCTION dapr_state_history() RETURNS trigger AS $$
+DECLARE
+  cfg jsonb;
+  k text;
+  lim bigint;
+  ret bigint;
+BEGIN
+  EXECUTE format('SELECT value::jsonb FROM %s WHERE key = %L', TG_ARGV[1], TG_ARGV[2]) INTO cfg;
+  IF cfg IS NULL THEN
+    RETURN NULL;
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    k := OLD.key;
+    EXECUTE format('INSERT INTO %s (key, deleted) VALUES ($1, true)', TG_ARGV[0]) USING k;
+  ELSE
+    k := NEW.key;
+    EXECUTE format('INSERT INTO %s (key, value, isbinary, etag, expiredate) VALUES ($1, $2, $3, $4, $5)', TG_ARGV[0])
+      USING k, NEW.value, NEW.isbinary, txid_current() % 4294967296, NEW.expiredate;
+  END IF;
+
+  lim := COALESCE((cfg->>'limit')::bigint, 0);
+  IF lim > 0 THEN
+    EXECUTE format('DELETE FROM %1$s WHERE key = $1 AND revision < (SELECT revision FROM %1$s WHERE key = $1 ORDER BY revision DESC OFFSET $2 LIMIT 1)', TG_ARGV[0])
+      USING k, lim - 1;
+  END IF;
+  ret := COALESCE((cfg->>'retention')::bigint, 0);
+  IF ret > 0 THEN
+    EXECUTE format('DELETE FROM %1$s WHERE key = $1 AND revision < (SELECT max(revision) FROM %1$s WHERE key = $1) AND revision_time < now() - make_interval(secs => $2)', TG_ARGV[0])
+      USING k, ret;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`)
+			if err != nil {
+				return fmt.Errorf("failed to create history function: %w", err)
+			}
+
+			_, err = db.Exec(ctx, fmt.Sprintf(
+				`CREATE TRIGGER dapr_history
+					AFTER INSERT OR UPDATE OR DELETE ON %s
+					FOR EACH ROW EXECUTE FUNCTION dapr_state_history(%s, %s, %s)`,
+				opts.StateTableName,
+				quoteLiteral(opts.HistoryTableName), quoteLiteral(opts.MetadataTableName), quoteLiteral(postgresql.HistoryConfigKey),
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create history trigger: %w", err)
+			}
+
+			return nil
+		},
+		// Migration 5: add an index on the keys with the C collation, used by range scans
+		func(ctx context.Context) error {
+			opts.Logger.Infof("Creating index for range scans on state table '%s'", opts.StateTableName)
+			_, err := db.Exec(ctx, fmt.Sprintf(
+				`CREATE INDEX IF NOT EXISTS %s ON %s (key COLLATE "C")`,
+				quoteIdent(rangeIndexName(opts.StateTableName)), opts.StateTableName,
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create index for range scans: %w", err)
+			}
+			return nil
+		},
+		// Migration 6: create the outbox table, which contains the messages saved by transactions until they're published
+		func(ctx context.Context) error {
+			opts.Logger.Infof("Creating outbox table '%s'", opts.OutboxTableName)
+			_, err := db.Exec(ctx, fmt.Sprintf(
+				`CREATE TABLE IF NOT EXISTS %[1]s (
+					seq bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+					id text NOT NULL UNIQUE,
+					pubsub_name text NOT NULL,
+					topic text NOT NULL,
+					data bytea NOT NULL,
+					content_type text,
+					metadata text,
+					attempts integer NOT NULL DEFAULT 0,
+					lease_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT '-infinity'
+				)`,
+				opts.OutboxTableName,
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create outbox table: %w", err)
+			}
+			_, err = db.Exec(ctx, fmt.Sprintf(
+				`CREATE INDEX IF NOT EXISTS %s ON %s (pubsub_name, seq)`,
+				quoteIdent(outboxIndexName(opts.OutboxTableName)), opts.OutboxTableName,
+			))
+			if err != nil {
+				return fmt.Errorf("failed to create index on outbox table: %w", err)
+			}
+			return nil
+		},
 	})
 }
 
+// historyIndexName returns the name of the index on the history table, without the schema.
+func historyIndexName(historyTableName string) string {
+	return withoutSchema(historyTableName) + "_key_idx"
+}
+
+// rangeIndexName returns the name of the index on the keys of the state table with the C collation, without the schema.
+func rangeIndexName(stateTableName string) string {
+	return withoutSchema(stateTableName) + "_key_c_idx"
+}
+
+// outboxIndexName returns the name of the index on the outbox table, without the schema.
+func outboxIndexName(outboxTableName string) string {
+	return withoutSchema(outboxTableName) + "_pubsub_idx"
+}
+
+func withoutSchema(tableName string) string {
+	if i := strings.LastIndexByte(tableName, '.'); i >= 0 {
+		return tableName[i+1:]
+	}
+	return tableName
+}
+
 func quoteIdent(s string) string {
 	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
 }
+
+func quoteLiteral(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
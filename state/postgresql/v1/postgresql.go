@@ -22,10 +22,15 @@ import (
 // NewPostgreSQLStateStore creates a new instance of PostgreSQL state store.
 func NewPostgreSQLStateStore(logger logger.Logger) state.Store {
 	return postgresql.NewPostgreSQLQueryStateStore(logger, postgresql.Options{
-		ETagColumn:    "xmin",
-		EnableAzureAD: true,
-		EnableAWSIAM:  true,
-		MigrateFn:     performMigrations,
+		ETagColumn:             "xmin",
+		EnableAzureAD:          true,
+		EnableAWSIAM:           true,
+		EnableWatch:            true,
+		EnableAtomicOperations: true,
+		EnableVersioning:       true,
+		EnableRangeScan:        true,
+		EnableOutbox:           true,
+		MigrateFn:              performMigrations,
 		SetQueryFn: func(req *state.SetRequest, opts postgresql.SetQueryOptions) string {
 			// Sprintf is required for table name because the driver does not substitute parameters for table names.
 			if !req.HasETag() {
@@ -16,8 +16,10 @@ limitations under the License.
 package postgresql
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -31,6 +33,7 @@ import (
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 const (
@@ -139,6 +142,26 @@ func TestPostgreSQLIntegration(t *testing.T) {
 		t.Parallel()
 		multiWithSetOnly(t, pgs)
 	})
+
+	t.Run("Watch", func(t *testing.T) {
+		t.Parallel()
+		testWatch(t, pgs)
+	})
+
+	t.Run("Versioning", func(t *testing.T) {
+		t.Parallel()
+		testVersioning(t, connectionString)
+	})
+
+	t.Run("Range scan", func(t *testing.T) {
+		t.Parallel()
+		testRangeScan(t, pgs)
+	})
+
+	t.Run("Outbox", func(t *testing.T) {
+		t.Parallel()
+		testOutbox(t, pgs)
+	})
 }
 
 func Test_KeysLiker(t *testing.T) {
@@ -147,6 +170,160 @@ func Test_KeysLiker(t *testing.T) {
 	require.True(t, ok)
 }
 
+// testWatch validates that changes to the watched keys are notified.
+func testWatch(t *testing.T, pgs *postgresql.PostgreSQL) {
+	prefix := randomKey() + "||"
+	ch := make(chan *state.WatchEvent, 10)
+	err := pgs.Watch(t.Context(), &state.WatchRequest{Prefix: prefix}, func(_ context.Context, e *state.WatchEvent) error {
+		ch <- e
+		return nil
+	})
+	require.NoError(t, err)
+	receive := func() *state.WatchEvent {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for event")
+			return nil
+		}
+	}
+
+	value := randomJSON()
+	setItem(t, pgs, randomKey(), randomJSON(), nil)
+	setItem(t, pgs, prefix+"a", value, nil)
+	getResponse, _ := getItem(t, pgs, prefix+"a")
+
+	e := receive()
+	assert.Equal(t, state.WatchEventUpsert, e.Type)
+	assert.Equal(t, prefix+"a", e.Key)
+	assert.Equal(t, getResponse.Data, e.Value)
+	assert.Equal(t, getResponse.ETag, e.ETag)
+
+	deleteItem(t, pgs, prefix+"a", nil)
+	e = receive()
+	assert.Equal(t, state.WatchEventDelete, e.Type)
+	assert.Equal(t, prefix+"a", e.Key)
+	assert.Empty(t, ch)
+}
+
+// testRangeScan validates that keys in a range are returned in byte-wise order, page by page.
+func testRangeScan(t *testing.T, pgs *postgresql.PostgreSQL) {
+	prefix := randomKey() + "||"
+	// Byte-wise, uppercase letters are sorted before lowercase ones
+	for _, k := range []string{"b", "A", "a", "B"} {
+		setItem(t, pgs, prefix+k, randomJSON(), nil)
+	}
+
+	req := &state.RangeScanRequest{Start: prefix, End: prefix + "~", Limit: 3}
+	res, err := pgs.RangeScan(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, res.Items, 3)
+	assert.Equal(t, []string{prefix + "A", prefix + "B", prefix + "a"}, []string{res.Items[0].Key, res.Items[1].Key, res.Items[2].Key})
+	require.NotNil(t, res.ContinuationToken)
+
+	req.ContinuationToken = res.ContinuationToken
+	res, err = pgs.RangeScan(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, prefix+"b", res.Items[0].Key)
+	assert.Nil(t, res.ContinuationToken)
+
+	res, err = pgs.RangeScan(t.Context(), &state.RangeScanRequest{Start: prefix, End: prefix + "~", Reverse: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, prefix+"b", res.Items[0].Key)
+}
+
+// testOutbox validates that messages are saved only by transactions that are committed, and leased in order.
+func testOutbox(t *testing.T, pgs *postgresql.PostgreSQL) {
+	// Each run uses a different pubsub, so messages of previous runs are not leased
+	pubsubName := randomKey()
+	key := randomKey()
+	err := pgs.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: key, Value: randomJSON()},
+			state.OutboxRequest{ID: pubsubName + "-1", PubsubName: pubsubName, Topic: "orders", Data: []byte("created")},
+			state.OutboxRequest{ID: pubsubName + "-2", PubsubName: pubsubName, Topic: "orders", Data: []byte("updated")},
+		},
+	})
+	require.NoError(t, err)
+
+	err = pgs.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.OutboxRequest{ID: pubsubName + "-3", PubsubName: pubsubName, Topic: "orders", Data: []byte("failed")},
+			state.SetRequest{Key: key, Value: randomJSON(), ETag: ptr.Of("1")},
+		},
+	})
+	require.Error(t, err)
+
+	req := &state.LeaseOutboxRequest{PubsubName: pubsubName, Limit: 10, LeaseDuration: time.Minute}
+	msgs, err := pgs.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, pubsubName+"-1", msgs[0].ID)
+	assert.Equal(t, "created", string(msgs[0].Data))
+	assert.Equal(t, pubsubName+"-2", msgs[1].ID)
+
+	msgs, err = pgs.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	require.NoError(t, pgs.DeleteOutbox(t.Context(), []string{pubsubName + "-1"}))
+	require.NoError(t, pgs.ReleaseOutbox(t.Context(), pubsubName+"-2", 0))
+	msgs, err = pgs.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, 2, msgs[0].Attempts)
+	require.NoError(t, pgs.DeleteOutbox(t.Context(), []string{pubsubName + "-2"}))
+}
+
+// testVersioning validates that the revisions of the keys are recorded, and that past values can be read.
+func testVersioning(t *testing.T, connectionString string) {
+	s := NewPostgreSQLStateStore(logger.NewLogger("test"))
+	t.Cleanup(func() {
+		defer s.(io.Closer).Close()
+	})
+	err := s.Init(t.Context(), state.Metadata{
+		Base: metadata.Base{Properties: map[string]string{
+			"connectionString":  connectionString,
+			"tableName":         "versioned_state",
+			"metadataTableName": "versioned_state_metadata",
+			"historyLimit":      "3",
+		}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, s.Features(), state.FeatureVersioning)
+	vs := s.(state.VersionedStore)
+
+	key := randomKey()
+	for _, v := range []string{"v1", "v2", "v3", "v4"} {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: v}))
+	}
+	latest, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: key}))
+
+	res, err := vs.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: key})
+	require.NoError(t, err)
+	require.Len(t, res.Revisions, 3)
+	assert.True(t, res.Revisions[0].Deleted)
+	assert.Equal(t, latest.ETag, res.Revisions[1].ETag)
+
+	got, err := vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: key, Revision: &res.Revisions[1].Revision})
+	require.NoError(t, err)
+	assert.Equal(t, `"v4"`, string(got.Data))
+
+	now := time.Now()
+	got, err = vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: key, Time: &now})
+	require.NoError(t, err)
+	assert.Nil(t, got.Data)
+
+	before := res.Revisions[2].Time.Add(-time.Hour)
+	_, err = vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: key, Time: &before})
+	require.ErrorIs(t, err, state.ErrRevisionNotFound)
+}
+
 // setGetUpdateDeleteOneItem validates setting one item, getting it, and deleting it.
 func setGetUpdateDeleteOneItem(t *testing.T, pgs *postgresql.PostgreSQL) {
 	key := randomKey()
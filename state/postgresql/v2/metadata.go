@@ -42,6 +42,8 @@ type pgMetadata struct {
 	MetadataTableName string         `mapstructure:"metadataTableName"` // Could be in the format "schema.table" or just "table"
 	Timeout           time.Duration  `mapstructure:"timeout" mapstructurealiases:"timeoutInSeconds"`
 	CleanupInterval   *time.Duration `mapstructure:"cleanupInterval" mapstructurealiases:"cleanupIntervalInSeconds"`
+	// If true, queries are evaluated in process, reading all the items in the table
+	EnableInProcessQueries bool `mapstructure:"enableInProcessQueries"`
 
 	aws.DeprecatedPostgresIAM `mapstructure:",squash"`
 }
@@ -53,6 +55,7 @@ func (m *pgMetadata) InitWithMetadata(meta state.Metadata, opts pgauth.InitWithM
 	m.MetadataTableName = "dapr_metadata"
 	m.CleanupInterval = ptr.Of(defaultCleanupInternal)
 	m.Timeout = defaultTimeout
+	m.EnableInProcessQueries = false
 
 	// Decode the metadata
 	err := metadata.DecodeMetadata(meta.Properties, &m)
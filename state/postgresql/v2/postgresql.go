@@ -323,12 +323,17 @@ WHERE r.key = t.key
 
 // Features returns the features available in this state store.
 func (p *PostgreSQL) Features() []state.Feature {
-	return []state.Feature{
+	features := []state.Feature{
 		state.FeatureETag,
 		state.FeatureTransactional,
 		state.FeatureTTL,
 		state.FeatureKeysLike,
+		state.FeatureDeleteWithPrefix,
 	}
+	if p.metadata.EnableInProcessQueries {
+		features = append(features, state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
+	return features
 }
 
 func (p *PostgreSQL) GetDB() *pgxpool.Pool {
@@ -611,6 +616,27 @@ func (p *PostgreSQL) doDelete(parentCtx context.Context, db pginterfaces.DBQueri
 	return nil
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the cleanup of expired keys.
+func (p *PostgreSQL) DeleteWithPrefix(parentCtx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	match, exclude := stateutils.DeleteWithPrefixPatterns(req.Prefix)
+
+	ctx, cancel := context.WithTimeout(parentCtx, p.metadata.Timeout)
+	defer cancel()
+	result, err := p.db.Exec(ctx, `DELETE FROM `+p.metadata.TableName(pgTableState)+`
+WHERE key LIKE $1 ESCAPE '\'
+  AND key NOT LIKE $2 ESCAPE '\'
+  AND (expires_at IS NULL OR expires_at > now())`,
+		match, exclude)
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: result.RowsAffected()}, nil
+}
+
 func (p *PostgreSQL) Multi(parentCtx context.Context, request *state.TransactionalStateRequest) error {
 	if request == nil {
 		return nil
@@ -685,6 +711,23 @@ func (p *PostgreSQL) GetComponentMetadata() (metadataInfo metadata.MetadataMap)
 	return
 }
 
+// Query executes a query against the state table.
+// Queries are evaluated in process by state.KeysLikeQuerier, which reads all the keys for each query, so they must be enabled with the enableInProcessQueries metadata property.
+func (p *PostgreSQL) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !p.metadata.EnableInProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(p).Query(ctx, req)
+}
+
+// QueryAggregate executes a query with projections or aggregations against the state table.
+func (p *PostgreSQL) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !p.metadata.EnableInProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(p).QueryAggregate(ctx, req)
+}
+
 func (p *PostgreSQL) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
 	// 1) Validate pattern
 	if len(req.Pattern) == 0 {
@@ -0,0 +1,707 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Evaluator is a Visitor that evaluates queries in process against JSON documents.
+// It's used by state stores that can't translate queries into a native query language.
+// The string returned by each visit method is a human-readable form of the filter, which is returned by String.
+type Evaluator struct {
+	query  string
+	filter Filter
+	sort   []Sorting
+	limit  int
+	skip   int64
+	likes  map[*LIKE]*regexp.Regexp
+
+	// Set when the query has a projection or aggregations
+	selectFields []string
+	aggregates   []Aggregation
+	groupBy      []string
+}
+
+func (e *Evaluator) VisitEQ(f *EQ) (string, error) {
+	return formatCondition(f.Key, "=", f.Val), nil
+}
+
+func (e *Evaluator) VisitNEQ(f *NEQ) (string, error) {
+	return formatCondition(f.Key, "!=", f.Val), nil
+}
+
+func (e *Evaluator) VisitGT(f *GT) (string, error) {
+	if err := checkNumeric(f.Val); err != nil {
+		return "", err
+	}
+	return formatCondition(f.Key, ">", f.Val), nil
+}
+
+func (e *Evaluator) VisitGTE(f *GTE) (string, error) {
+	if err := checkNumeric(f.Val); err != nil {
+		return "", err
+	}
+	return formatCondition(f.Key, ">=", f.Val), nil
+}
+
+func (e *Evaluator) VisitLT(f *LT) (string, error) {
+	if err := checkNumeric(f.Val); err != nil {
+		return "", err
+	}
+	return formatCondition(f.Key, "<", f.Val), nil
+}
+
+func (e *Evaluator) VisitLTE(f *LTE) (string, error) {
+	if err := checkNumeric(f.Val); err != nil {
+		return "", err
+	}
+	return formatCondition(f.Key, "<=", f.Val), nil
+}
+
+func (e *Evaluator) VisitIN(f *IN) (string, error) {
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty IN operator for key %q", f.Key)
+	}
+
+	vals := make([]string, len(f.Vals))
+	for i, v := range f.Vals {
+		vals[i] = formatValue(v)
+	}
+	return "value." + f.Key + " IN (" + strings.Join(vals, ", ") + ")", nil
+}
+
+func (e *Evaluator) visitFilters(op string, filters []Filter) (string, error) {
+	arr := make([]string, len(filters))
+	for i, f := range filters {
+		str, err := VisitFilter(e, f)
+		if err != nil {
+			return "", err
+		}
+		arr[i] = str
+	}
+
+	return "(" + strings.Join(arr, " "+op+" ") + ")", nil
+}
+
+func (e *Evaluator) VisitAND(f *AND) (string, error) {
+	return e.visitFilters("AND", f.Filters)
+}
+
+func (e *Evaluator) VisitOR(f *OR) (string, error) {
+	return e.visitFilters("OR", f.Filters)
+}
+
+func (e *Evaluator) VisitNOT(f *NOT) (string, error) {
+	str, err := VisitFilter(e, f.Filter)
+	if err != nil {
+		return "", err
+	}
+	return "NOT " + str, nil
+}
+
+func (e *Evaluator) VisitNIN(f *NIN) (string, error) {
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty NIN operator for key %q", f.Key)
+	}
+
+	vals := make([]string, len(f.Vals))
+	for i, v := range f.Vals {
+		vals[i] = formatValue(v)
+	}
+	return "value." + f.Key + " NOT IN (" + strings.Join(vals, ", ") + ")", nil
+}
+
+func (e *Evaluator) VisitEXISTS(f *EXISTS) (string, error) {
+	if f.Exists {
+		return "value." + f.Key + " EXISTS", nil
+	}
+	return "value." + f.Key + " NOT EXISTS", nil
+}
+
+func (e *Evaluator) VisitLIKE(f *LIKE) (string, error) {
+	re, err := likeToRegex(f.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid LIKE pattern %q: %w", f.Pattern, err)
+	}
+	if e.likes == nil {
+		e.likes = make(map[*LIKE]*regexp.Regexp)
+	}
+	e.likes[f] = re
+	return formatCondition(f.Key, "LIKE", f.Pattern), nil
+}
+
+func (e *Evaluator) VisitCONTAINS(f *CONTAINS) (string, error) {
+	return formatCondition(f.Key, "CONTAINS", f.Val), nil
+}
+
+func (e *Evaluator) VisitBETWEEN(f *BETWEEN) (string, error) {
+	if err := checkNumeric(f.From); err != nil {
+		return "", err
+	}
+	if err := checkNumeric(f.To); err != nil {
+		return "", err
+	}
+	return "value." + f.Key + " BETWEEN " + formatValue(f.From) + " AND " + formatValue(f.To), nil
+}
+
+func (e *Evaluator) VisitProjection(qq *Query) error {
+	e.selectFields = qq.Select
+	e.aggregates = qq.Aggregate
+	e.groupBy = qq.GroupBy
+	return nil
+}
+
+func (e *Evaluator) Finalize(filters string, qq *Query) error {
+	e.query = filters
+	e.filter = qq.Filter
+	e.sort = qq.Sort
+
+	for _, s := range e.sort {
+		if s.Order != "" && s.Order != ASC && s.Order != DESC {
+			return fmt.Errorf("invalid sort order %q for key %q", s.Order, s.Key)
+		}
+	}
+
+	if qq.Page.Limit > 0 {
+		e.limit = qq.Page.Limit
+	}
+
+	if len(qq.Page.Token) != 0 {
+		skip, err := strconv.ParseInt(qq.Page.Token, 10, 64)
+		if err != nil {
+			return err
+		}
+		if skip < 0 {
+			return fmt.Errorf("invalid pagination token %q", qq.Page.Token)
+		}
+		e.skip = skip
+	}
+
+	return nil
+}
+
+// Document is a state item evaluated by the Evaluator.
+type Document struct {
+	Key  string
+	Data []byte
+	ETag *string
+
+	// Decoded JSON value
+	value   any
+	decoded bool
+	isJSON  bool
+}
+
+// String returns a human-readable form of the filter.
+func (e *Evaluator) String() string {
+	return e.query
+}
+
+// Filter returns the documents that match the filter of the query, keeping their order.
+// Values that are not valid JSON can only be returned by queries without a filter, sorting or projection.
+func (e *Evaluator) Filter(docs []Document) []Document {
+	res := make([]Document, 0, len(docs))
+	for _, d := range docs {
+		if !d.decoded {
+			d.isJSON = json.Unmarshal(d.Data, &d.value) == nil
+			d.decoded = true
+		}
+		if !d.isJSON {
+			if e.filter != nil || len(e.sort) > 0 || len(e.selectFields) > 0 || len(e.aggregates) > 0 {
+				continue
+			}
+		}
+		if e.filter != nil && !e.matchFilter(e.filter, d.value) {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+// Execute filters, sorts and paginates the documents, which must be in a stable order across pages.
+// It returns the documents in the page and the token for the next page, which is only set if the query has a limit.
+// When the query has a select list, the data of the results contains only the selected fields.
+// When the query has aggregations, the results have no key and their data is a JSON object with the groupBy fields and the aggregated values.
+func (e *Evaluator) Execute(docs []Document) ([]Document, string, error) {
+	docs = e.Filter(docs)
+
+	if len(e.aggregates) > 0 {
+		return e.executeAggregate(docs)
+	}
+
+	if len(e.sort) > 0 {
+		sort.SliceStable(docs, func(i, j int) bool {
+			for _, s := range e.sort {
+				c := compareSortValues(lookupField(docs[i].value, s.Key), lookupField(docs[j].value, s.Key))
+				if c == 0 {
+					continue
+				}
+				if s.Order == DESC {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+
+	docs = docs[e.pageStart(len(docs)):]
+	docs = docs[:e.pageLen(len(docs))]
+
+	ret := make([]Document, len(docs))
+	for i, d := range docs {
+		ret[i] = Document{
+			Key:  d.Key,
+			Data: d.Data,
+			ETag: d.ETag,
+		}
+		if len(e.selectFields) > 0 {
+			data, err := json.Marshal(projectFields(d.value, e.selectFields))
+			if err != nil {
+				return nil, "", err
+			}
+			ret[i].Data = data
+		}
+	}
+
+	return ret, e.nextToken(len(ret)), nil
+}
+
+// executeAggregate groups the documents and computes the aggregations.
+// Each result is a JSON object with the groupBy fields and the aggregation names as (flat) keys.
+func (e *Evaluator) executeAggregate(docs []Document) ([]Document, string, error) {
+	type group struct {
+		row  map[string]any
+		docs []any
+	}
+	groups := []*group{}
+	index := map[string]*group{}
+	for _, d := range docs {
+		vals := make([]string, len(e.groupBy))
+		for i, g := range e.groupBy {
+			vals[i] = formatValue(lookupField(d.value, g))
+		}
+		id := strings.Join(vals, "\x00")
+		grp, ok := index[id]
+		if !ok {
+			grp = &group{row: make(map[string]any, len(e.groupBy)+len(e.aggregates))}
+			for _, g := range e.groupBy {
+				grp.row[g] = lookupField(d.value, g)
+			}
+			index[id] = grp
+			groups = append(groups, grp)
+		}
+		grp.docs = append(grp.docs, d.value)
+	}
+	// Without groupBy there's always one result, even if no document matches
+	if len(e.groupBy) == 0 && len(groups) == 0 {
+		groups = append(groups, &group{row: map[string]any{}})
+	}
+
+	rows := make([]map[string]any, len(groups))
+	for i, grp := range groups {
+		for _, a := range e.aggregates {
+			grp.row[a.Name()] = aggregate(a, grp.docs)
+		}
+		rows[i] = grp.row
+	}
+
+	if len(e.sort) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			for _, s := range e.sort {
+				c := compareSortValues(rows[i][s.Key], rows[j][s.Key])
+				if c == 0 {
+					continue
+				}
+				if s.Order == DESC {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+
+	rows = rows[e.pageStart(len(rows)):]
+	rows = rows[:e.pageLen(len(rows))]
+
+	ret := make([]Document, len(rows))
+	for i, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, "", err
+		}
+		ret[i] = Document{Data: data}
+	}
+
+	return ret, e.nextToken(len(ret)), nil
+}
+
+// pageStart returns the index of the first result of the page.
+func (e *Evaluator) pageStart(n int) int {
+	if e.skip >= int64(n) {
+		return n
+	}
+	return int(e.skip)
+}
+
+// pageLen returns the number of results in the page.
+func (e *Evaluator) pageLen(n int) int {
+	if e.limit > 0 && n > e.limit {
+		return e.limit
+	}
+	return n
+}
+
+func (e *Evaluator) nextToken(n int) string {
+	// set next query token only if limit is specified
+	if e.limit > 0 {
+		return strconv.FormatInt(e.skip+int64(n), 10)
+	}
+	return ""
+}
+
+// aggregate computes the aggregation over the documents.
+// SUM, MIN and MAX ignore values that are not numbers, and return nil if there are none.
+func aggregate(a Aggregation, docs []any) any {
+	var (
+		res   float64
+		found bool
+		count int
+	)
+	for _, doc := range docs {
+		if a.Op == COUNT {
+			if a.Key == "" {
+				count++
+			} else if _, ok := lookupFieldOK(doc, a.Key); ok {
+				count++
+			}
+			continue
+		}
+
+		f, ok := toFloat(lookupField(doc, a.Key))
+		if !ok {
+			continue
+		}
+		switch {
+		case !found:
+			res = f
+		case a.Op == SUM:
+			res += f
+		case a.Op == MIN:
+			res = min(res, f)
+		case a.Op == MAX:
+			res = max(res, f)
+		}
+		found = true
+	}
+
+	if a.Op == COUNT {
+		return count
+	}
+	if !found {
+		return nil
+	}
+	return res
+}
+
+// projectFields returns a document with only the selected fields, keeping their nesting.
+// Fields that don't exist in the document are omitted.
+func projectFields(doc any, fields []string) map[string]any {
+	res := map[string]any{}
+	for _, field := range fields {
+		val, ok := lookupFieldOK(doc, field)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(field, ".")
+		m := res
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				m[part] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = val
+	}
+	return res
+}
+
+func (e *Evaluator) matchFilter(filter Filter, doc any) bool {
+	switch f := filter.(type) {
+	case *EQ:
+		return valuesEqual(lookupField(doc, f.Key), f.Val)
+	case *NEQ:
+		return !valuesEqual(lookupField(doc, f.Key), f.Val)
+	case *GT:
+		c, ok := compareNumbers(lookupField(doc, f.Key), f.Val)
+		return ok && c > 0
+	case *GTE:
+		c, ok := compareNumbers(lookupField(doc, f.Key), f.Val)
+		return ok && c >= 0
+	case *LT:
+		c, ok := compareNumbers(lookupField(doc, f.Key), f.Val)
+		return ok && c < 0
+	case *LTE:
+		c, ok := compareNumbers(lookupField(doc, f.Key), f.Val)
+		return ok && c <= 0
+	case *IN:
+		val := lookupField(doc, f.Key)
+		for _, v := range f.Vals {
+			if valuesEqual(val, v) {
+				return true
+			}
+		}
+		return false
+	case *AND:
+		for _, fil := range f.Filters {
+			if !e.matchFilter(fil, doc) {
+				return false
+			}
+		}
+		return true
+	case *OR:
+		for _, fil := range f.Filters {
+			if e.matchFilter(fil, doc) {
+				return true
+			}
+		}
+		return false
+	case *NOT:
+		return !e.matchFilter(f.Filter, doc)
+	case *NIN:
+		val := lookupField(doc, f.Key)
+		for _, v := range f.Vals {
+			if valuesEqual(val, v) {
+				return false
+			}
+		}
+		return true
+	case *EXISTS:
+		_, ok := lookupFieldOK(doc, f.Key)
+		return ok == f.Exists
+	case *LIKE:
+		val, ok := lookupField(doc, f.Key).(string)
+		if !ok {
+			return false
+		}
+		re, ok := e.likes[f]
+		return ok && re.MatchString(val)
+	case *CONTAINS:
+		arr, ok := lookupField(doc, f.Key).([]any)
+		if !ok {
+			return false
+		}
+		for _, v := range arr {
+			if valuesEqual(v, f.Val) {
+				return true
+			}
+		}
+		return false
+	case *BETWEEN:
+		val := lookupField(doc, f.Key)
+		from, ok := compareNumbers(val, f.From)
+		if !ok || from < 0 {
+			return false
+		}
+		to, ok := compareNumbers(val, f.To)
+		return ok && to <= 0
+	default:
+		return false
+	}
+}
+
+// lookupField returns the value at the dot-separated path in the document, or nil if it does not exist.
+func lookupField(doc any, key string) any {
+	val, _ := lookupFieldOK(doc, key)
+	return val
+}
+
+// lookupFieldOK returns the value at the dot-separated path in the document, and whether the field exists.
+func lookupFieldOK(doc any, key string) (any, bool) {
+	val := doc
+	for _, part := range strings.Split(key, ".") {
+		m, ok := val.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		val, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+func valuesEqual(a, b any) bool {
+	if c, ok := compareNumbers(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compareNumbers(a, b any) (int, bool) {
+	fa, ok := toFloat(a)
+	if !ok {
+		return 0, false
+	}
+	fb, ok := toFloat(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case fa < fb:
+		return -1, true
+	case fa > fb:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// compareSortValues orders values of different types as: missing, numbers, strings, booleans, others.
+func compareSortValues(a, b any) int {
+	ra, rb := sortRank(a), sortRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch va := a.(type) {
+	case nil:
+		return 0
+	case string:
+		return strings.Compare(va, b.(string))
+	case bool:
+		switch {
+		case va == b.(bool):
+			return 0
+		case !va:
+			return -1
+		default:
+			return 1
+		}
+	default:
+		if c, ok := compareNumbers(a, b); ok {
+			return c
+		}
+		return strings.Compare(formatValue(a), formatValue(b))
+	}
+}
+
+func sortRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case float64, float32, int, int64, int32, uint, uint64, uint32, json.Number:
+		return 1
+	case string:
+		return 2
+	case bool:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func checkNumeric(v any) error {
+	if _, ok := v.(string); ok {
+		return fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+	}
+	if _, ok := toFloat(v); !ok {
+		return fmt.Errorf("unsupported type of value %#v; expected a number", v)
+	}
+	return nil
+}
+
+func formatCondition(key string, op string, val any) string {
+	return "value." + key + " " + op + " " + formatValue(val)
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// likeToRegex converts a LIKE pattern to a regular expression.
+// As in SQL, % and _ also match newlines.
+func likeToRegex(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.Grow(len(pattern) + 8)
+	b.WriteString("(?s)^")
+
+	escaped := false
+	for _, r := range pattern {
+		if escaped {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if escaped {
+		b.WriteString(regexp.QuoteMeta(`\`))
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluatorBuildQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		query string
+	}{
+		{
+			input: "../../tests/state/query/q1.json",
+			query: "",
+		},
+		{
+			input: "../../tests/state/query/q2.json",
+			query: `value.state = "CA"`,
+		},
+		{
+			input: "../../tests/state/query/q3.json",
+			query: `(value.person.org = "A" AND value.state IN ("CA", "WA"))`,
+		},
+		{
+			input: "../../tests/state/query/q4-notequal.json",
+			query: `(value.person.org = "A" OR (value.person.org != "B" AND value.state IN ("CA", "WA")))`,
+		},
+		{
+			input: "../../tests/state/query/q8.json",
+			query: `(value.person.org >= 123 OR (value.person.org < 10 AND value.state IN ("CA", "WA")))`,
+		},
+		{
+			input: "../../tests/state/query/q9.json",
+			query: `(NOT value.state = "CA" AND value.person.org NOT IN ("A", "B") AND value.person.name EXISTS AND value.person.name LIKE "Jo%" AND value.tags CONTAINS "vip" AND value.age BETWEEN 18 AND 65)`,
+		},
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(test.input)
+		require.NoError(t, err)
+		var qq Query
+		err = json.Unmarshal(data, &qq)
+		require.NoError(t, err)
+
+		q := &Evaluator{}
+		qbuilder := NewQueryBuilder(q)
+		err = qbuilder.BuildQuery(&qq)
+		require.NoError(t, err)
+		assert.Equal(t, test.query, q.String())
+	}
+}
+
+func TestLikeToRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{pattern: "Jo%", value: "John", match: true},
+		{pattern: "Jo%", value: "Bob", match: false},
+		{pattern: "b_b", value: "bob", match: true},
+		{pattern: "b_b", value: "boob", match: false},
+		{pattern: "%line%", value: "first\nsecond line\nthird", match: true},
+		{pattern: "a_b", value: "a\nb", match: true},
+		{pattern: `100\%`, value: "100%", match: true},
+		{pattern: `100\%`, value: "1000", match: false},
+		{pattern: "a.b", value: "axb", match: false},
+	}
+	for _, test := range tests {
+		re, err := likeToRegex(test.pattern)
+		require.NoError(t, err)
+		assert.Equal(t, test.match, re.MatchString(test.value), "pattern %q, value %q", test.pattern, test.value)
+	}
+}
+
+func TestEvaluatorProjection(t *testing.T) {
+	var qq Query
+	require.NoError(t, json.Unmarshal([]byte(`{"select":["person.name","person.org","state"]}`), &qq))
+	q := &Evaluator{}
+	require.NoError(t, NewQueryBuilder(q).BuildQuery(&qq))
+
+	res, _, err := q.Execute([]Document{
+		{Key: "k1", Data: []byte(`{"person":{"name":"alice","id":1},"state":null}`)},
+		{Key: "k2", Data: []byte(`{"person":{"id":2},"city":"Seattle"}`)},
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	// Fields that are set to null are kept, while missing fields are omitted
+	assert.JSONEq(t, `{"person":{"name":"alice"},"state":null}`, string(res[0].Data))
+	assert.JSONEq(t, `{}`, string(res[1].Data))
+}
@@ -77,6 +77,36 @@ func ParseFilter(obj interface{}) (Filter, error) {
 			f := &OR{}
 			err := f.Parse(v)
 
+			return f, err
+		case "NOT":
+			f := &NOT{}
+			err := f.Parse(v)
+
+			return f, err
+		case "NIN":
+			f := &NIN{}
+			err := f.Parse(v)
+
+			return f, err
+		case "EXISTS":
+			f := &EXISTS{}
+			err := f.Parse(v)
+
+			return f, err
+		case "LIKE":
+			f := &LIKE{}
+			err := f.Parse(v)
+
+			return f, err
+		case "CONTAINS":
+			f := &CONTAINS{}
+			err := f.Parse(v)
+
+			return f, err
+		case "BETWEEN":
+			f := &BETWEEN{}
+			err := f.Parse(v)
+
 			return f, err
 		default:
 			return nil, fmt.Errorf("unsupported filter %q", k)
@@ -255,6 +285,142 @@ func (f *OR) Parse(obj interface{}) (err error) {
 	return
 }
 
+// NOT negates the nested filter.
+type NOT struct {
+	Filter Filter
+}
+
+func (f *NOT) Parse(obj interface{}) (err error) {
+	if _, ok := obj.(map[string]interface{}); !ok {
+		return errors.New("NOT filter must be a map")
+	}
+	f.Filter, err = ParseFilter(obj)
+
+	return
+}
+
+// NIN matches documents where the field is not equal to any of the values, including documents where the field is missing or null.
+type NIN struct {
+	Key  string
+	Vals []interface{}
+}
+
+func (f *NIN) Parse(obj interface{}) error {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return errors.New("NIN filter must be a map")
+	}
+	if len(m) != 1 {
+		return errors.New("NIN filter must contain a single key/value pair")
+	}
+	for k, v := range m {
+		f.Key = k
+		if f.Vals, ok = v.([]interface{}); !ok {
+			return errors.New("NIN filter value must be an array")
+		}
+	}
+
+	return nil
+}
+
+// EXISTS matches documents where the field is present (Exists is true) or absent (Exists is false).
+type EXISTS struct {
+	Key    string
+	Exists bool
+}
+
+func (f *EXISTS) Parse(obj interface{}) error {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return errors.New("EXISTS filter must be a map")
+	}
+	if len(m) != 1 {
+		return errors.New("EXISTS filter must contain a single key/value pair")
+	}
+	for k, v := range m {
+		f.Key = k
+		if f.Exists, ok = v.(bool); !ok {
+			return errors.New("EXISTS filter value must be a boolean")
+		}
+	}
+
+	return nil
+}
+
+// LIKE matches string fields against a SQL LIKE pattern, where "%" matches any sequence of characters and "_" matches a single character.
+// A prefix match is expressed as "prefix%".
+type LIKE struct {
+	Key     string
+	Pattern string
+}
+
+func (f *LIKE) Parse(obj interface{}) error {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return errors.New("LIKE filter must be a map")
+	}
+	if len(m) != 1 {
+		return errors.New("LIKE filter must contain a single key/value pair")
+	}
+	for k, v := range m {
+		f.Key = k
+		if f.Pattern, ok = v.(string); !ok {
+			return errors.New("LIKE filter value must be a string")
+		}
+	}
+
+	return nil
+}
+
+// CONTAINS matches documents where the field is an array that contains the value.
+type CONTAINS struct {
+	Key string
+	Val interface{}
+}
+
+func (f *CONTAINS) Parse(obj interface{}) error {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return errors.New("CONTAINS filter must be a map")
+	}
+	if len(m) != 1 {
+		return errors.New("CONTAINS filter must contain a single key/value pair")
+	}
+	for k, v := range m {
+		f.Key = k
+		f.Val = v
+	}
+
+	return nil
+}
+
+// BETWEEN matches documents where the field is greater than or equal to From and less than or equal to To.
+type BETWEEN struct {
+	Key  string
+	From interface{}
+	To   interface{}
+}
+
+func (f *BETWEEN) Parse(obj interface{}) error {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return errors.New("BETWEEN filter must be a map")
+	}
+	if len(m) != 1 {
+		return errors.New("BETWEEN filter must contain a single key/value pair")
+	}
+	for k, v := range m {
+		f.Key = k
+		arr, ok := v.([]interface{})
+		if !ok || len(arr) != 2 {
+			return errors.New("BETWEEN filter value must be an array with two entries")
+		}
+		f.From, f.To = arr[0], arr[1]
+	}
+
+	return nil
+}
+
 func parseFilters(t string, obj interface{}) ([]Filter, error) {
 	arr, ok := obj.([]interface{})
 	if !ok {
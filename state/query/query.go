@@ -15,7 +15,9 @@ package query
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 const (
@@ -24,6 +26,11 @@ const (
 	PAGE   = "page"
 	ASC    = "ASC"
 	DESC   = "DESC"
+
+	COUNT = "COUNT"
+	SUM   = "SUM"
+	MIN   = "MIN"
+	MAX   = "MAX"
 )
 
 type Sorting struct {
@@ -36,11 +43,40 @@ type Pagination struct {
 	Token string `json:"token,omitempty"`
 }
 
+// Aggregation is an aggregate function computed over the documents matching the filter.
+type Aggregation struct {
+	// One of COUNT, SUM, MIN and MAX
+	Op string `json:"op"`
+	// Field the function is applied to; optional for COUNT
+	Key string `json:"key,omitempty"`
+	// Name of the field in the results; see Name for the default
+	Alias string `json:"alias,omitempty"`
+}
+
+// Name returns the name of the field that holds the aggregated value in the results.
+// If no alias is set, it's the lowercase operation, followed by "_" and the key if present; for example "count" or "sum_order.total".
+func (a Aggregation) Name() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	if a.Key == "" {
+		return strings.ToLower(a.Op)
+	}
+	return strings.ToLower(a.Op) + "_" + a.Key
+}
+
 // used only for intermediate query value.
 type QueryFields struct {
 	Filters map[string]interface{} `json:"filter"`
 	Sort    []Sorting              `json:"sort"`
 	Page    Pagination             `json:"page"`
+
+	// List of fields to return instead of the full documents
+	Select []string `json:"select,omitempty"`
+	// Aggregations to compute instead of returning documents
+	Aggregate []Aggregation `json:"aggregate,omitempty"`
+	// Fields to group the aggregations by
+	GroupBy []string `json:"groupBy,omitempty"`
 }
 
 type Query struct {
@@ -50,6 +86,13 @@ type Query struct {
 	Filter Filter
 }
 
+var (
+	// ErrUnsupportedOperator is returned when a filter uses an operator that the state store does not support.
+	ErrUnsupportedOperator = errors.New("unsupported filter operator")
+	// ErrUnsupportedProjection is returned when a query uses select or aggregate, and the state store does not support them.
+	ErrUnsupportedProjection = errors.New("projections and aggregations are not supported by this state store")
+)
+
 type Visitor interface {
 	// returns "equal" expression
 	VisitEQ(*EQ) (string, error)
@@ -73,6 +116,51 @@ type Visitor interface {
 	Finalize(string, *Query) error
 }
 
+// The following interfaces are optional and are implemented by visitors that support the additional operators.
+// Filters using an operator whose interface is not implemented fail with ErrUnsupportedOperator.
+
+// NOTVisitor is implemented by visitors that support the NOT operator.
+type NOTVisitor interface {
+	// returns "not" expression
+	VisitNOT(*NOT) (string, error)
+}
+
+// NINVisitor is implemented by visitors that support the NIN operator.
+type NINVisitor interface {
+	// returns "not in" expression
+	VisitNIN(*NIN) (string, error)
+}
+
+// EXISTSVisitor is implemented by visitors that support the EXISTS operator.
+type EXISTSVisitor interface {
+	// returns "field exists" expression
+	VisitEXISTS(*EXISTS) (string, error)
+}
+
+// LIKEVisitor is implemented by visitors that support the LIKE operator.
+type LIKEVisitor interface {
+	// returns "like" expression
+	VisitLIKE(*LIKE) (string, error)
+}
+
+// CONTAINSVisitor is implemented by visitors that support the CONTAINS operator.
+type CONTAINSVisitor interface {
+	// returns "array contains" expression
+	VisitCONTAINS(*CONTAINS) (string, error)
+}
+
+// BETWEENVisitor is implemented by visitors that support the BETWEEN operator.
+type BETWEENVisitor interface {
+	// returns "between" expression
+	VisitBETWEEN(*BETWEEN) (string, error)
+}
+
+// ProjectionVisitor is implemented by visitors that support the select, aggregate and groupBy fields.
+type ProjectionVisitor interface {
+	// receives the validated query before the filters are visited
+	VisitProjection(*Query) error
+}
+
 type Builder struct {
 	visitor Visitor
 }
@@ -84,6 +172,19 @@ func NewQueryBuilder(visitor Visitor) *Builder {
 }
 
 func (h *Builder) BuildQuery(q *Query) error {
+	if q.HasProjection() {
+		v, ok := h.visitor.(ProjectionVisitor)
+		if !ok {
+			return ErrUnsupportedProjection
+		}
+		if err := q.validateProjection(); err != nil {
+			return err
+		}
+		if err := v.VisitProjection(q); err != nil {
+			return err
+		}
+	}
+
 	filters, err := h.buildFilter(q.Filter)
 	if err != nil {
 		return err
@@ -96,30 +197,128 @@ func (h *Builder) buildFilter(filter Filter) (string, error) {
 	if filter == nil {
 		return "", nil
 	}
+	return VisitFilter(h.visitor, filter)
+}
+
+// VisitFilter invokes the method of the visitor that matches the type of the filter.
+// Visitors can use it to visit the filters nested in AND, OR and NOT.
+func VisitFilter(visitor Visitor, filter Filter) (string, error) {
 	switch f := filter.(type) {
 	case *EQ:
-		return h.visitor.VisitEQ(f)
+		return visitor.VisitEQ(f)
 	case *NEQ:
-		return h.visitor.VisitNEQ(f)
+		return visitor.VisitNEQ(f)
 	case *GT:
-		return h.visitor.VisitGT(f)
+		return visitor.VisitGT(f)
 	case *GTE:
-		return h.visitor.VisitGTE(f)
+		return visitor.VisitGTE(f)
 	case *LT:
-		return h.visitor.VisitLT(f)
+		return visitor.VisitLT(f)
 	case *LTE:
-		return h.visitor.VisitLTE(f)
+		return visitor.VisitLTE(f)
 	case *IN:
-		return h.visitor.VisitIN(f)
+		return visitor.VisitIN(f)
 	case *OR:
-		return h.visitor.VisitOR(f)
+		return visitor.VisitOR(f)
 	case *AND:
-		return h.visitor.VisitAND(f)
+		return visitor.VisitAND(f)
+	case *NOT:
+		if v, ok := visitor.(NOTVisitor); ok {
+			return v.VisitNOT(f)
+		}
+		return "", unsupportedOperatorError("NOT")
+	case *NIN:
+		if v, ok := visitor.(NINVisitor); ok {
+			return v.VisitNIN(f)
+		}
+		return "", unsupportedOperatorError("NIN")
+	case *EXISTS:
+		if v, ok := visitor.(EXISTSVisitor); ok {
+			return v.VisitEXISTS(f)
+		}
+		return "", unsupportedOperatorError("EXISTS")
+	case *LIKE:
+		if v, ok := visitor.(LIKEVisitor); ok {
+			return v.VisitLIKE(f)
+		}
+		return "", unsupportedOperatorError("LIKE")
+	case *CONTAINS:
+		if v, ok := visitor.(CONTAINSVisitor); ok {
+			return v.VisitCONTAINS(f)
+		}
+		return "", unsupportedOperatorError("CONTAINS")
+	case *BETWEEN:
+		if v, ok := visitor.(BETWEENVisitor); ok {
+			return v.VisitBETWEEN(f)
+		}
+		return "", unsupportedOperatorError("BETWEEN")
 	default:
 		return "", fmt.Errorf("unsupported filter type %#v", filter)
 	}
 }
 
+func unsupportedOperatorError(op string) error {
+	return fmt.Errorf("%w %q: not supported by this state store", ErrUnsupportedOperator, op)
+}
+
+// HasProjection returns true if the query has a select list or aggregations.
+func (q *Query) HasProjection() bool {
+	return len(q.Select) > 0 || len(q.Aggregate) > 0 || len(q.GroupBy) > 0
+}
+
+// IsAggregation returns true if the query computes aggregations rather than returning documents.
+func (q *Query) IsAggregation() bool {
+	return len(q.Aggregate) > 0
+}
+
+func (q *Query) validateProjection() error {
+	if len(q.Select) > 0 && len(q.Aggregate) > 0 {
+		return errors.New("select and aggregate cannot be used in the same query")
+	}
+	if len(q.GroupBy) > 0 && len(q.Aggregate) == 0 {
+		return errors.New("groupBy requires at least one aggregation")
+	}
+	for _, s := range q.Select {
+		if s == "" {
+			return errors.New("select fields cannot be empty")
+		}
+	}
+
+	names := make(map[string]struct{}, len(q.Aggregate)+len(q.GroupBy))
+	for _, g := range q.GroupBy {
+		if g == "" {
+			return errors.New("groupBy fields cannot be empty")
+		}
+		names[g] = struct{}{}
+	}
+	for _, a := range q.Aggregate {
+		switch a.Op {
+		case COUNT:
+		case SUM, MIN, MAX:
+			if a.Key == "" {
+				return fmt.Errorf("aggregation %s requires a key", a.Op)
+			}
+		default:
+			return fmt.Errorf("unsupported aggregation %q", a.Op)
+		}
+		if _, ok := names[a.Name()]; ok {
+			return fmt.Errorf("duplicate field %q in aggregation results", a.Name())
+		}
+		names[a.Name()] = struct{}{}
+	}
+
+	// When aggregating, results can only be sorted by the fields they contain
+	if q.IsAggregation() {
+		for _, s := range q.Sort {
+			if _, ok := names[s.Key]; !ok {
+				return fmt.Errorf("sort key %q must be a groupBy field or an aggregation name", s.Key)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (q *Query) UnmarshalJSON(data []byte) error {
 	err := json.Unmarshal(data, &q.QueryFields)
 	if err != nil {
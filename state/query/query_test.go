@@ -141,3 +141,161 @@ func TestQuery(t *testing.T) {
 		assert.Equal(t, test.query, q)
 	}
 }
+
+func TestQueryExtendedOperators(t *testing.T) {
+	data, err := os.ReadFile("../../tests/state/query/q9.json")
+	require.NoError(t, err)
+	var q Query
+	err = json.Unmarshal(data, &q)
+	require.NoError(t, err)
+
+	assert.Equal(t, &AND{
+		Filters: []Filter{
+			&NOT{Filter: &EQ{Key: "state", Val: "CA"}},
+			&NIN{Key: "person.org", Vals: []any{"A", "B"}},
+			&EXISTS{Key: "person.name", Exists: true},
+			&LIKE{Key: "person.name", Pattern: "Jo%"},
+			&CONTAINS{Key: "tags", Val: "vip"},
+			&BETWEEN{Key: "age", From: float64(18), To: float64(65)},
+		},
+	}, q.Filter)
+
+	t.Run("invalid filters", func(t *testing.T) {
+		tests := []string{
+			`{"filter":{"NOT":[{"EQ":{"a":1}}]}}`,
+			`{"filter":{"NIN":{"a":1}}}`,
+			`{"filter":{"EXISTS":{"a":"yes"}}}`,
+			`{"filter":{"LIKE":{"a":1}}}`,
+			`{"filter":{"CONTAINS":{"a":1,"b":2}}}`,
+			`{"filter":{"BETWEEN":{"a":[1]}}}`,
+		}
+		for _, test := range tests {
+			var q Query
+			require.Error(t, json.Unmarshal([]byte(test), &q), test)
+		}
+	})
+
+	t.Run("unsupported operator", func(t *testing.T) {
+		v := &baseVisitor{}
+		err := NewQueryBuilder(v).BuildQuery(&q)
+		require.ErrorIs(t, err, ErrUnsupportedOperator)
+		assert.ErrorContains(t, err, `"NOT"`)
+	})
+
+	t.Run("optional operator", func(t *testing.T) {
+		v := &notVisitor{}
+		err := NewQueryBuilder(v).BuildQuery(&Query{
+			Filter: &NOT{Filter: &EQ{Key: "state", Val: "CA"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "NOT(EQ)", v.filters)
+	})
+}
+
+func TestQueryProjection(t *testing.T) {
+	data, err := os.ReadFile("../../tests/state/query/q10.json")
+	require.NoError(t, err)
+	var q Query
+	err = json.Unmarshal(data, &q)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Aggregation{
+		{Op: COUNT},
+		{Op: SUM, Key: "person.id", Alias: "total"},
+		{Op: MAX, Key: "person.id"},
+	}, q.Aggregate)
+	assert.Equal(t, []string{"state"}, q.GroupBy)
+	assert.Equal(t, "count", q.Aggregate[0].Name())
+	assert.Equal(t, "total", q.Aggregate[1].Name())
+	assert.Equal(t, "max_person.id", q.Aggregate[2].Name())
+
+	t.Run("unsupported projection", func(t *testing.T) {
+		v := &baseVisitor{}
+		err := NewQueryBuilder(v).BuildQuery(&q)
+		require.ErrorIs(t, err, ErrUnsupportedProjection)
+	})
+
+	t.Run("valid projection", func(t *testing.T) {
+		v := &projectionVisitor{}
+		require.NoError(t, NewQueryBuilder(v).BuildQuery(&q))
+		assert.Same(t, &q, v.projection)
+		assert.Equal(t, "NEQ", v.filters)
+	})
+
+	t.Run("invalid projections", func(t *testing.T) {
+		tests := []string{
+			`{"select":["a"],"aggregate":[{"op":"COUNT"}]}`,
+			`{"select":[""]}`,
+			`{"groupBy":["a"]}`,
+			`{"aggregate":[{"op":"AVG","key":"a"}]}`,
+			`{"aggregate":[{"op":"SUM"}]}`,
+			`{"aggregate":[{"op":"COUNT"},{"op":"MAX","key":"a","alias":"count"}]}`,
+			`{"aggregate":[{"op":"COUNT"}],"groupBy":["a"],"sort":[{"key":"b"}]}`,
+		}
+		for _, test := range tests {
+			var q Query
+			require.NoError(t, json.Unmarshal([]byte(test), &q), test)
+			v := &projectionVisitor{}
+			require.Error(t, NewQueryBuilder(v).BuildQuery(&q), test)
+			assert.Nil(t, v.projection, test)
+		}
+	})
+}
+
+// baseVisitor implements only the required methods of the Visitor interface.
+type baseVisitor struct {
+	filters string
+}
+
+func (v *baseVisitor) VisitEQ(*EQ) (string, error)   { return "EQ", nil }
+func (v *baseVisitor) VisitNEQ(*NEQ) (string, error) { return "NEQ", nil }
+func (v *baseVisitor) VisitGT(*GT) (string, error)   { return "GT", nil }
+func (v *baseVisitor) VisitGTE(*GTE) (string, error) { return "GTE", nil }
+func (v *baseVisitor) VisitLT(*LT) (string, error)   { return "LT", nil }
+func (v *baseVisitor) VisitLTE(*LTE) (string, error) { return "LTE", nil }
+func (v *baseVisitor) VisitIN(*IN) (string, error)   { return "IN", nil }
+func (v *baseVisitor) VisitAND(f *AND) (string, error) {
+	return v.visitFilters(f.Filters)
+}
+
+func (v *baseVisitor) VisitOR(f *OR) (string, error) {
+	return v.visitFilters(f.Filters)
+}
+
+func (v *baseVisitor) visitFilters(filters []Filter) (string, error) {
+	for _, f := range filters {
+		if _, err := VisitFilter(v, f); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func (v *baseVisitor) Finalize(filters string, _ *Query) error {
+	v.filters = filters
+	return nil
+}
+
+// notVisitor additionally implements the NOTVisitor interface.
+type notVisitor struct {
+	baseVisitor
+}
+
+func (v *notVisitor) VisitNOT(f *NOT) (string, error) {
+	str, err := VisitFilter(v, f.Filter)
+	if err != nil {
+		return "", err
+	}
+	return "NOT(" + str + ")", nil
+}
+
+// projectionVisitor additionally implements the ProjectionVisitor interface.
+type projectionVisitor struct {
+	baseVisitor
+	projection *Query
+}
+
+func (v *projectionVisitor) VisitProjection(q *Query) error {
+	v.projection = q
+	return nil
+}
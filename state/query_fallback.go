@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dapr/components-contrib/state/query"
+)
+
+// ErrInProcessQueriesDisabled is returned by the state stores that evaluate queries with KeysLikeQuerier, unless they're enabled with the enableInProcessQueries metadata property.
+var ErrInProcessQueriesDisabled = errors.New("queries are evaluated in process by reading all the items of the state store, and must be enabled with the enableInProcessQueries metadata property")
+
+// Number of keys retrieved in each page by KeysLikeQuerier.
+const defaultKeysLikeQuerierPageSize = 1000
+
+// KeysLikeBulkGetter is implemented by state stores that can list their keys and retrieve values in bulk.
+type KeysLikeBulkGetter interface {
+	KeysLiker
+	BulkGet(ctx context.Context, req []GetRequest, opts BulkGetOpts) ([]BulkGetResponse, error)
+}
+
+// KeysLikeQuerier implements the Querier and AggregateQuerier interfaces for state stores that cannot execute queries natively.
+// Queries are evaluated in process: all keys are listed page by page with KeysLike, their values are retrieved with BulkGet, and the filter, sorting and pagination are applied in memory.
+// Because every query reads the entire store, this is only suitable for small data sets: stores should use it only when the enableInProcessQueries metadata property is set, and then report FeatureQueryInProcess together with FeatureQueryAPI.
+type KeysLikeQuerier struct {
+	store    KeysLikeBulkGetter
+	pageSize uint32
+}
+
+// NewKeysLikeQuerier returns a KeysLikeQuerier for the store.
+func NewKeysLikeQuerier(store KeysLikeBulkGetter) *KeysLikeQuerier {
+	return &KeysLikeQuerier{
+		store:    store,
+		pageSize: defaultKeysLikeQuerierPageSize,
+	}
+}
+
+// Query executes the query against all the items in the store.
+func (q *KeysLikeQuerier) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	e := &query.Evaluator{}
+	qbuilder := query.NewQueryBuilder(e)
+	if err := qbuilder.BuildQuery(&req.Query); err != nil {
+		return &QueryResponse{}, err
+	}
+
+	docs, err := q.loadDocuments(ctx, e)
+	if err != nil {
+		return &QueryResponse{}, err
+	}
+
+	docs, token, err := e.Execute(docs)
+	if err != nil {
+		return &QueryResponse{}, err
+	}
+
+	data := make([]QueryItem, len(docs))
+	for i, d := range docs {
+		data[i] = QueryItem{
+			Key:  d.Key,
+			Data: d.Data,
+			ETag: d.ETag,
+		}
+	}
+
+	return &QueryResponse{
+		Results: data,
+		Token:   token,
+	}, nil
+}
+
+// QueryAggregate executes a query with projections or aggregations against all the items in the store.
+func (q *KeysLikeQuerier) QueryAggregate(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	return q.Query(ctx, req)
+}
+
+// loadDocuments retrieves the items in the store, in the order returned by KeysLike.
+// Items that don't match the filter are discarded after each page, so they are not all kept in memory.
+func (q *KeysLikeQuerier) loadDocuments(ctx context.Context, e *query.Evaluator) ([]query.Document, error) {
+	var (
+		docs  []query.Document
+		token *string
+	)
+	pageSize := q.pageSize
+	for {
+		keys, err := q.store.KeysLike(ctx, &KeysLikeRequest{
+			Pattern:           "%",
+			ContinuationToken: token,
+			PageSize:          &pageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list keys: %w", err)
+		}
+
+		if len(keys.Keys) > 0 {
+			reqs := make([]GetRequest, len(keys.Keys))
+			for i, key := range keys.Keys {
+				reqs[i] = GetRequest{Key: key}
+			}
+			res, err := q.store.BulkGet(ctx, reqs, BulkGetOpts{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get values: %w", err)
+			}
+
+			page := make([]query.Document, 0, len(res))
+			for _, r := range res {
+				if r.Error != "" {
+					return nil, fmt.Errorf("failed to get value for key %q: %s", r.Key, r.Error)
+				}
+				// Skip items that were deleted or expired after listing the keys
+				if len(r.Data) == 0 {
+					continue
+				}
+				page = append(page, query.Document{
+					Key:  r.Key,
+					Data: r.Data,
+					ETag: r.ETag,
+				})
+			}
+			docs = append(docs, e.Filter(page)...)
+		}
+
+		if keys.ContinuationToken == nil || *keys.ContinuationToken == "" {
+			return docs, nil
+		}
+		token = keys.ContinuationToken
+	}
+}
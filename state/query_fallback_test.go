@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysLikeQuerier(t *testing.T) {
+	s := &storeKeysLike{
+		keys: []string{"k1", "k2", "k3", "k4", "k5", "k6"},
+		values: map[string]string{
+			"k1": `{"person":{"org":"A","id":3},"state":"CA"}`,
+			"k2": `{"person":{"org":"B","id":1},"state":"WA"}`,
+			"k3": `{"person":{"org":"B","id":2},"state":"CA"}`,
+			"k4": `{"person":{"org":"C","id":4},"state":"TX"}`,
+			// k5 was deleted after listing the keys
+			"k6": `not json`,
+		},
+	}
+	querier := NewKeysLikeQuerier(s)
+	// Use a small page size to test paging through the keys
+	querier.pageSize = 2
+
+	var (
+		_ Querier          = querier
+		_ AggregateQuerier = querier
+	)
+
+	runQuery := func(t *testing.T, q string) *QueryResponse {
+		t.Helper()
+		var req QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(q), &req.Query))
+		resp, err := querier.Query(t.Context(), &req)
+		require.NoError(t, err)
+		return resp
+	}
+	keys := func(resp *QueryResponse) []string {
+		res := make([]string, len(resp.Results))
+		for i, r := range resp.Results {
+			res[i] = r.Key
+		}
+		return res
+	}
+
+	t.Run("no filter returns all items", func(t *testing.T) {
+		resp := runQuery(t, `{}`)
+		assert.Equal(t, []string{"k1", "k2", "k3", "k4", "k6"}, keys(resp))
+		assert.Equal(t, "etag-k1", *resp.Results[0].ETag)
+		assert.Equal(t, []byte("not json"), resp.Results[4].Data)
+		assert.Equal(t, 3, s.keysLikeCalls)
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"EQ":{"state":"CA"}},"sort":[{"key":"person.id"}]}`)
+		assert.Equal(t, []string{"k3", "k1"}, keys(resp))
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":{"NEQ":{"state":"XX"}},"sort":[{"key":"person.id","order":"DESC"}],"page":{"limit":3}}`)
+		assert.Equal(t, []string{"k4", "k1", "k3"}, keys(resp))
+		assert.Equal(t, "3", resp.Token)
+
+		resp = runQuery(t, `{"filter":{"NEQ":{"state":"XX"}},"sort":[{"key":"person.id","order":"DESC"}],"page":{"limit":3,"token":"3"}}`)
+		assert.Equal(t, []string{"k2"}, keys(resp))
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		resp := runQuery(t, `{"aggregate":[{"op":"COUNT"}],"groupBy":["person.org"],"sort":[{"key":"person.org"}]}`)
+		require.Len(t, resp.Results, 3)
+		assert.JSONEq(t, `{"person.org":"A","count":1}`, string(resp.Results[0].Data))
+		assert.JSONEq(t, `{"person.org":"B","count":2}`, string(resp.Results[1].Data))
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		var req QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"filter":{"GT":{"state":"CA"}}}`), &req.Query))
+		_, err := querier.Query(t.Context(), &req)
+		require.Error(t, err)
+	})
+
+	t.Run("errors from the store are returned", func(t *testing.T) {
+		s.values["k2"] = "error"
+		defer func() {
+			s.values["k2"] = `{"person":{"org":"B","id":1},"state":"WA"}`
+		}()
+		_, err := querier.Query(t.Context(), &QueryRequest{})
+		require.ErrorContains(t, err, `failed to get value for key "k2"`)
+	})
+}
+
+type storeKeysLike struct {
+	keys          []string
+	values        map[string]string
+	keysLikeCalls int
+}
+
+func (s *storeKeysLike) KeysLike(_ context.Context, req *KeysLikeRequest) (*KeysLikeResponse, error) {
+	s.keysLikeCalls++
+	start := 0
+	if req.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*req.ContinuationToken)
+	}
+	end := min(start+int(*req.PageSize), len(s.keys))
+
+	res := &KeysLikeResponse{
+		Keys: s.keys[start:end],
+	}
+	if end < len(s.keys) {
+		token := strconv.Itoa(end)
+		res.ContinuationToken = &token
+	}
+	return res, nil
+}
+
+func (s *storeKeysLike) BulkGet(_ context.Context, req []GetRequest, _ BulkGetOpts) ([]BulkGetResponse, error) {
+	res := make([]BulkGetResponse, len(req))
+	for i, r := range req {
+		res[i].Key = r.Key
+		val, ok := s.values[r.Key]
+		switch {
+		case !ok:
+		case val == "error":
+			res[i].Error = errSimulated.Error()
+		default:
+			etag := "etag-" + r.Key
+			res[i].Data = []byte(val)
+			res[i].ETag = &etag
+		}
+	}
+	return res, nil
+}
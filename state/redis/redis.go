@@ -29,9 +29,11 @@ import (
 	"github.com/dapr/components-contrib/contenttype"
 	daprmetadata "github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/codec"
 	"github.com/dapr/components-contrib/state/query"
 	"github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
+	kitmd "github.com/dapr/kit/metadata"
 	"github.com/dapr/kit/ptr"
 )
 
@@ -89,6 +91,8 @@ const (
 	defaultBase              = 10
 	defaultBitSize           = 0
 	defaultDB                = 0
+	// Number of keys scanned and deleted at each iteration of DeleteWithPrefix.
+	deleteWithPrefixBatchSize = 1000
 )
 
 // StateStore is a Redis state store.
@@ -102,6 +106,9 @@ type StateStore struct {
 	replicas                       int
 	querySchemas                   querySchemas
 	suppressActorStateStoreWarning atomic.Bool
+	closed                         atomic.Bool
+	closeCh                        chan struct{}
+	codec                          *codec.Codec
 
 	logger logger.Logger
 }
@@ -118,6 +125,7 @@ func newStateStore(log logger.Logger) *StateStore {
 		json:                           jsoniter.ConfigFastest,
 		logger:                         log,
 		suppressActorStateStoreWarning: atomic.Bool{},
+		closeCh:                        make(chan struct{}),
 	}
 }
 
@@ -137,6 +145,14 @@ func (r *StateStore) Init(ctx context.Context, metadata state.Metadata) error {
 		return err
 	}
 
+	var codecMetadata codec.Metadata
+	if err = kitmd.DecodeMetadata(metadata.Properties, &codecMetadata); err != nil {
+		return fmt.Errorf("redis store: error parsing metadata: %w", err)
+	}
+	if r.codec, err = codec.New(codecMetadata); err != nil {
+		return fmt.Errorf("redis store: %w", err)
+	}
+
 	// check for query schemas
 	if r.querySchemas, err = parseQuerySchemas(r.clientSettings.QueryIndexes); err != nil {
 		return fmt.Errorf("redis store: error parsing query index schema: %w", err)
@@ -161,11 +177,16 @@ func (r *StateStore) Init(ctx context.Context, metadata state.Metadata) error {
 
 // Features returns the features available in this state store.
 func (r *StateStore) Features() []state.Feature {
+	var features []state.Feature
 	if r.clientHasJSON {
-		return []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureTTL, state.FeatureQueryAPI, state.FeatureKeysLike}
+		features = []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureTTL, state.FeatureQueryAPI, state.FeatureKeysLike, state.FeatureDeleteWithPrefix, state.FeatureWatch, state.FeatureAtomicOperations, state.FeatureTTLManagement}
 	} else {
-		return []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureTTL, state.FeatureKeysLike}
+		features = []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureTTL, state.FeatureKeysLike, state.FeatureDeleteWithPrefix, state.FeatureWatch, state.FeatureAtomicOperations, state.FeatureTTLManagement}
 	}
+	if r.rangeIndex() != "" {
+		features = append(features, state.FeatureRangeScan)
+	}
+	return features
 }
 
 func (r *StateStore) getConnectedSlaves(ctx context.Context) (int, error) {
@@ -217,7 +238,7 @@ func (r *StateStore) Delete(ctx context.Context, req *state.DeleteRequest) error
 		return state.NewETagError(state.ETagMismatch, err)
 	}
 
-	return nil
+	return r.unindexKeys(ctx, req.Key)
 }
 
 func (r *StateStore) directGet(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
@@ -263,10 +284,20 @@ func (r *StateStore) getDefault(ctx context.Context, req *state.GetRequest) (*st
 	if err != nil {
 		return nil, err
 	}
+	value := []byte(data)
+	var contentType *string
+	// Values are decoded only if the store has a codec, so plain values are never altered
+	if r.codec != nil {
+		value, contentType, err = codec.Decode(value)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &state.GetResponse{
-		Data: []byte(data),
-		ETag: version,
+		Data:        value,
+		ETag:        version,
+		ContentType: contentType,
 	}, nil
 }
 
@@ -349,7 +380,11 @@ func (r *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
 		bt, _ := utils.Marshal(&jsonEntry{Data: req.Value}, r.json.Marshal)
 		err = r.client.DoWrite(ctx, "EVAL", setJSONQuery, 1, req.Key, ver, bt, firstWrite)
 	} else {
-		bt, _ := utils.Marshal(req.Value, r.json.Marshal)
+		var bt []byte
+		bt, err = r.marshalValue(req.Value, req.ContentType)
+		if err != nil {
+			return err
+		}
 		err = r.client.DoWrite(ctx, "EVAL", setDefaultQuery, 1, req.Key, ver, bt, firstWrite)
 	}
 
@@ -361,6 +396,11 @@ func (r *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
 		return fmt.Errorf("failed to set key %s: %w", req.Key, err)
 	}
 
+	err = r.indexKey(ctx, req.Key)
+	if err != nil {
+		return err
+	}
+
 	if ttl != nil && *ttl > 0 {
 		err = r.client.DoWrite(ctx, "EXPIRE", req.Key, *ttl)
 		if err != nil {
@@ -394,6 +434,7 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 	// Check if the entire transaction is using JSON based on the transactional request's metadata
 	isJSON := request.Metadata[daprmetadata.ContentType] == contenttype.JSONContentType && r.clientHasJSON
 
+	idx := r.rangeIndex()
 	pipe := r.client.TxPipeline()
 	for _, o := range request.Operations {
 		switch req := o.(type) {
@@ -417,7 +458,10 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 				bt, _ = utils.Marshal(&jsonEntry{Data: req.Value}, r.json.Marshal)
 				pipe.Do(ctx, "EVAL", setJSONQuery, 1, req.Key, ver, bt)
 			} else {
-				bt, _ = utils.Marshal(req.Value, r.json.Marshal)
+				bt, err = r.marshalValue(req.Value, req.ContentType)
+				if err != nil {
+					return err
+				}
 				pipe.Do(ctx, "EVAL", setDefaultQuery, 1, req.Key, ver, bt)
 			}
 			if ttl != nil && *ttl > 0 {
@@ -426,6 +470,9 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 			if ttl != nil && *ttl <= 0 {
 				pipe.Do(ctx, "PERSIST", req.Key)
 			}
+			if idx != "" {
+				pipe.Do(ctx, "ZADD", idx, 0, req.Key)
+			}
 
 		case state.DeleteRequest:
 			if !req.HasETag() {
@@ -438,6 +485,9 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 			} else {
 				pipe.Do(ctx, "EVAL", delDefaultQuery, 1, req.Key, *req.ETag)
 			}
+			if idx != "" {
+				pipe.Do(ctx, "ZREM", idx, req.Key)
+			}
 		}
 	}
 
@@ -446,6 +496,15 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 	return err
 }
 
+// marshalValue returns the value as stored in the hash, after encoding it with the codec if the store has one.
+func (r *StateStore) marshalValue(value any, contentType *string) ([]byte, error) {
+	if r.codec != nil {
+		return r.codec.Encode(value, contentType)
+	}
+	bt, _ := utils.Marshal(value, r.json.Marshal)
+	return bt, nil
+}
+
 func (r *StateStore) registerSchemas(ctx context.Context) error {
 	for name, elem := range r.querySchemas {
 		r.logger.Infof("create query index %s", name)
@@ -580,12 +639,17 @@ func (r *StateStore) Query(ctx context.Context, req *state.QueryRequest) (*state
 }
 
 func (r *StateStore) Close() error {
+	if r.closed.CompareAndSwap(false, true) && r.closeCh != nil {
+		close(r.closeCh)
+	}
 	return r.client.Close()
 }
 
 func (r *StateStore) GetComponentMetadata() (metadataInfo daprmetadata.MetadataMap) {
 	settingsStruct := rediscomponent.Settings{}
 	daprmetadata.GetMetadataInfoFromStructType(reflect.TypeOf(settingsStruct), &metadataInfo, daprmetadata.StateStoreType)
+	codecStruct := codec.Metadata{}
+	daprmetadata.GetMetadataInfoFromStructType(reflect.TypeOf(codecStruct), &metadataInfo, daprmetadata.StateStoreType)
 	return
 }
 
@@ -612,33 +676,12 @@ func (r *StateStore) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (
 			break
 		}
 
-		arr, ok := res.([]any)
-		if !ok || len(arr) != 2 {
-			return nil, errors.New("unexpected SCAN response")
-		}
-
-		// next cursor
-		if s, ok := toString(arr[0]); ok {
-			cursor = s
-		} else {
-			return nil, errors.New("unexpected SCAN cursor type")
-		}
-
-		// keys
-		switch ks := arr[1].(type) {
-		case []any:
-			for _, v := range ks {
-				if s, ok := toString(v); ok {
-					keys = append(keys, s)
-				}
-			}
-		case []string:
-			keys = append(keys, ks...)
-		default:
-			if s, ok := toString(arr[1]); ok && s != "" {
-				keys = append(keys, s)
-			}
+		var batch []string
+		cursor, batch, err = parseScanReply(res)
+		if err != nil {
+			return nil, err
 		}
+		keys = append(keys, batch...)
 
 		if cursor == "0" {
 			break
@@ -687,6 +730,102 @@ func (r *StateStore) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (
 	}, nil
 }
 
+// parseScanReply returns the next cursor and the keys in the reply to a SCAN command.
+func parseScanReply(res any) (cursor string, keys []string, err error) {
+	arr, ok := res.([]any)
+	if !ok || len(arr) != 2 {
+		return "", nil, errors.New("unexpected SCAN response")
+	}
+
+	// next cursor
+	cursor, ok = toString(arr[0])
+	if !ok {
+		return "", nil, errors.New("unexpected SCAN cursor type")
+	}
+
+	// keys
+	switch ks := arr[1].(type) {
+	case []any:
+		keys = make([]string, 0, len(ks))
+		for _, v := range ks {
+			if s, ok := toString(v); ok {
+				keys = append(keys, s)
+			}
+		}
+	case []string:
+		keys = ks
+	default:
+		if s, ok := toString(arr[1]); ok && s != "" {
+			keys = []string{s}
+		}
+	}
+	return cursor, keys, nil
+}
+
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys are found with SCAN and deleted with UNLINK, one batch at a time, so the operation is not atomic.
+func (r *StateStore) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	match, _ := utils.DeleteWithPrefixPatterns(req.Prefix)
+	glob, err := likeToRedisGlob(match)
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, fmt.Errorf("invalid prefix: %w", err)
+	}
+
+	var count int64
+	cursor := "0"
+	for {
+		res, err := r.client.DoRead(ctx, "SCAN", cursor, "MATCH", glob, "COUNT", deleteWithPrefixBatchSize)
+		if err != nil {
+			return state.DeleteWithPrefixResponse{Count: count}, fmt.Errorf("redis SCAN failed: %w", err)
+		}
+		if res == nil {
+			break
+		}
+
+		var keys []string
+		cursor, keys, err = parseScanReply(res)
+		if err != nil {
+			return state.DeleteWithPrefixResponse{Count: count}, err
+		}
+
+		args := make([]any, 1, len(keys)+1)
+		args[0] = "UNLINK"
+		deleted := make([]string, 0, len(keys))
+		for _, k := range keys {
+			// Exclude the keys with a longer prefix
+			if !strings.Contains(k[len(req.Prefix):], "||") {
+				args = append(args, k)
+				deleted = append(deleted, k)
+			}
+		}
+		if len(args) > 1 {
+			// Keys returned more than once by SCAN are counted only the first time, when they are actually removed
+			res, err = r.client.DoWriteResult(ctx, args...)
+			if err != nil {
+				return state.DeleteWithPrefixResponse{Count: count}, fmt.Errorf("redis UNLINK failed: %w", err)
+			}
+			n, ok := res.(int64)
+			if !ok {
+				return state.DeleteWithPrefixResponse{Count: count}, errors.New("unexpected UNLINK response")
+			}
+			count += n
+
+			err = r.unindexKeys(ctx, deleted...)
+			if err != nil {
+				return state.DeleteWithPrefixResponse{Count: count}, err
+			}
+		}
+
+		if cursor == "0" {
+			break
+		}
+	}
+	return state.DeleteWithPrefixResponse{Count: count}, nil
+}
+
 func likeToRedisGlob(pat string) (string, error) {
 	var b strings.Builder
 	b.Grow(len(pat))
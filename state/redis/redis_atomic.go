@@ -0,0 +1,247 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/utils"
+	"github.com/dapr/kit/ptr"
+)
+
+// The scripts below operate on the hashes written by setDefaultQuery, and return an error starting with "WRONGTYPE" if the value doesn't support the operation.
+// The last two arguments of each script are the TTL from the request, which is empty if not set, and the default TTL applied to keys that are created.
+const (
+	atomicTTLScript = `
+local function applyTTL(existed, ttl, defaultTTL)
+  if ttl == "" and not existed then
+    ttl = defaultTTL;
+  end;
+  if ttl ~= "" then
+    if tonumber(ttl) > 0 then
+      redis.call("EXPIRE", KEYS[1], ttl);
+    else
+      redis.call("PERSIST", KEYS[1]);
+    end;
+  end;
+end;
+local keyType = redis.call("TYPE", KEYS[1])["ok"];
+local existed = keyType ~= "none";
+`
+	// ARGV[1] is the delta.
+	incrementQuery = atomicTTLScript + `
+if existed and keyType ~= "hash" then
+  return redis.error_reply("WRONGTYPE value is not an integer");
+end;
+local value = redis.pcall("HINCRBY", KEYS[1], "data", ARGV[1]);
+if type(value) ~= "number" then
+  return redis.error_reply("WRONGTYPE value is not an integer");
+end;
+local version = redis.call("HINCRBY", KEYS[1], "version", 1);
+applyTTL(existed, ARGV[2], ARGV[3]);
+return {value, version}`
+
+	// ARGV[1] is the JSON array of the values to append, and ARGV[2] their number.
+	// The array is modified as a string, so the existing items are preserved exactly.
+	appendQuery = atomicTTLScript + `
+if existed and keyType ~= "hash" then
+  return redis.error_reply("WRONGTYPE value is not an array");
+end;
+local data = redis.call("HGET", KEYS[1], "data");
+local length = tonumber(ARGV[2]);
+if not data then
+  data = ARGV[1];
+else
+  local ok, current = pcall(cjson.decode, data);
+  if not ok or type(current) ~= "table" or not string.match(data, "^%s*%[") then
+    return redis.error_reply("WRONGTYPE value is not an array");
+  end;
+  length = length + #current;
+  if #current == 0 then
+    data = ARGV[1];
+  else
+    data = string.gsub(data, "%]%s*$", "") .. "," .. string.sub(ARGV[1], 2);
+  end;
+end;
+redis.call("HSET", KEYS[1], "data", data);
+local version = redis.call("HINCRBY", KEYS[1], "version", 1);
+applyTTL(existed, ARGV[3], ARGV[4]);
+return {length, version}`
+
+	// ARGV[1] is the expected value, ARGV[2] is "1" if the key must not exist, and ARGV[3] is the new value.
+	// Returns the new version, or nil if the value doesn't match.
+	compareAndSwapQuery = atomicTTLScript + `
+if ARGV[2] == "1" then
+  if existed then
+    return nil;
+  end;
+elseif keyType ~= "hash" or redis.call("HGET", KEYS[1], "data") ~= ARGV[1] then
+  return nil;
+end;
+redis.call("HSET", KEYS[1], "data", ARGV[3]);
+local version = redis.call("HINCRBY", KEYS[1], "version", 1);
+applyTTL(existed, ARGV[4], ARGV[5]);
+return version`
+)
+
+// Increment adds the delta to the integer value of the key with HINCRBY.
+func (r *StateStore) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttl, defaultTTL, err := r.atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.DoWriteResult(ctx, "EVAL", incrementQuery, 1, req.Key, req.Delta, ttl, defaultTTL)
+	if err != nil {
+		return nil, atomicError(req.Key, err)
+	}
+	value, version, err := parseAtomicResult(res)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.indexKey(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return &state.IncrementResponse{
+		Value: value,
+		ETag:  &version,
+	}, nil
+}
+
+// Append adds the values at the end of the JSON array stored in the key.
+func (r *StateStore) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttl, defaultTTL, err := r.atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	values, err := r.json.Marshal(req.Values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize values: %w", err)
+	}
+
+	res, err := r.client.DoWriteResult(ctx, "EVAL", appendQuery, 1, req.Key, values, len(req.Values), ttl, defaultTTL)
+	if err != nil {
+		return nil, atomicError(req.Key, err)
+	}
+	length, version, err := parseAtomicResult(res)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.indexKey(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return &state.AppendResponse{
+		Length: length,
+		ETag:   &version,
+	}, nil
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one, comparing the values as stored.
+func (r *StateStore) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ttl, defaultTTL, err := r.atomicTTL(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		expected []byte
+		notExist = "0"
+	)
+	if req.Expected == nil {
+		notExist = "1"
+	} else {
+		expected, err = r.marshalValue(req.Expected, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	value, err := r.marshalValue(req.Value, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.DoWriteResult(ctx, "EVAL", compareAndSwapQuery, 1, req.Key, expected, notExist, value, ttl, defaultTTL)
+	if err != nil {
+		if err.Error() == string(r.client.GetNilValueError()) {
+			return &state.CompareAndSwapResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to compare and swap key %s: %w", req.Key, err)
+	}
+	if res == nil {
+		return &state.CompareAndSwapResponse{}, nil
+	}
+	version, ok := res.(int64)
+	if !ok {
+		return nil, fmt.Errorf("invalid result from compare and swap: %v", res)
+	}
+	if err = r.indexKey(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return &state.CompareAndSwapResponse{
+		Swapped: true,
+		ETag:    ptr.Of(strconv.FormatInt(version, 10)),
+	}, nil
+}
+
+// atomicTTL returns the TTL from the request metadata, or an empty string to keep the current one, and the default TTL for keys that are created.
+func (r *StateStore) atomicTTL(metadata map[string]string) (ttl string, defaultTTL string, err error) {
+	reqTTL, err := utils.ParseTTL(metadata)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse ttl from metadata: %w", err)
+	}
+	if reqTTL != nil {
+		ttl = strconv.Itoa(*reqTTL)
+	}
+	if r.clientSettings != nil && r.clientSettings.TTLInSeconds != nil {
+		defaultTTL = strconv.Itoa(*r.clientSettings.TTLInSeconds)
+	}
+	return ttl, defaultTTL, nil
+}
+
+// parseAtomicResult parses the {value, version} array returned by the scripts.
+func parseAtomicResult(res any) (int64, string, error) {
+	arr, ok := res.([]any)
+	if !ok || len(arr) != 2 {
+		return 0, "", fmt.Errorf("invalid result from script: %v", res)
+	}
+	value, ok := arr[0].(int64)
+	if !ok {
+		return 0, "", fmt.Errorf("invalid value returned by script: %v", arr[0])
+	}
+	version, ok := arr[1].(int64)
+	if !ok {
+		return 0, "", fmt.Errorf("invalid version returned by script: %v", arr[1])
+	}
+	return value, strconv.FormatInt(version, 10), nil
+}
+
+func atomicError(key string, err error) error {
+	if strings.HasPrefix(err.Error(), "WRONGTYPE") {
+		return state.ErrAtomicOperandType
+	}
+	return fmt.Errorf("failed to update key %s: %w", key, err)
+}
@@ -15,6 +15,7 @@ package redis
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 
@@ -54,6 +55,10 @@ func TestRedisQuery(t *testing.T) {
 			input: "../../tests/state/query/q7.json",
 			query: []interface{}{"((@id:[-inf (123.000000])|((@org:[2.000000 +inf]) (((@id:[567.000000 567.000000])|(@id:[890.000000 890.000000])))))", "SORTBY", "id", "LIMIT", "0", "2"},
 		},
+		{
+			input: "../../tests/state/query/q9.json",
+			err:   errors.New(`unsupported filter operator "NOT": not supported by this state store`),
+		},
 	}
 	for _, test := range tests {
 		data, err := os.ReadFile(test.input)
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// Number of keys read from the range index at a time.
+const rangeScanBatchSize = 100
+
+// unindexQuery removes the key KEYS[2] from the range index KEYS[1], unless the key exists.
+// Keys that expire are removed from the index lazily, when they are found by a range scan.
+const unindexQuery = `
+if redis.call("EXISTS", KEYS[2]) == 0 then
+  redis.call("ZREM", KEYS[1], KEYS[2]);
+end;
+return 0`
+
+var errRangeIndexDisabled = errors.New("range scans are not enabled: set rangeIndex in the metadata")
+
+// rangeIndex returns the name of the sorted set that indexes the keys, or an empty string if range scans are disabled.
+func (r *StateStore) rangeIndex() string {
+	if r.clientSettings == nil {
+		return ""
+	}
+	return r.clientSettings.RangeIndex
+}
+
+// indexKey adds the key to the range index, if enabled.
+// All members of the index have the same score, so they are sorted lexicographically.
+func (r *StateStore) indexKey(ctx context.Context, key string) error {
+	idx := r.rangeIndex()
+	if idx == "" {
+		return nil
+	}
+	err := r.client.DoWrite(ctx, "ZADD", idx, 0, key)
+	if err != nil {
+		return fmt.Errorf("failed to add key %s to the range index: %w", key, err)
+	}
+	return nil
+}
+
+// unindexKeys removes the keys from the range index, if enabled.
+func (r *StateStore) unindexKeys(ctx context.Context, keys ...string) error {
+	idx := r.rangeIndex()
+	if idx == "" || len(keys) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(keys)+2)
+	args = append(args, "ZREM", idx)
+	for _, k := range keys {
+		args = append(args, k)
+	}
+	err := r.client.DoWrite(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to remove keys from the range index: %w", err)
+	}
+	return nil
+}
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+// Keys are read from the range index with ZRANGEBYLEX, and their values with separate requests, so the scan is not atomic.
+// Only keys saved as hashes are supported; keys that don't exist anymore are skipped and removed from the index.
+func (r *StateStore) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	idx := r.rangeIndex()
+	if idx == "" {
+		return nil, errRangeIndexDisabled
+	}
+
+	lower, upper := "["+req.Start, "+"
+	if req.End != "" {
+		upper = "(" + req.End
+	}
+	if req.ContinuationToken != nil {
+		// Continue after the last key of the previous page, which is excluded from the range
+		token := *req.ContinuationToken
+		switch {
+		case req.Reverse && (req.End == "" || token < req.End):
+			upper = "(" + token
+		case !req.Reverse && token >= req.Start:
+			lower = "(" + token
+		}
+	}
+
+	res := &state.RangeScanResponse{}
+	var stale []string
+	defer func() {
+		for _, k := range stale {
+			if err := r.client.DoWrite(ctx, "EVAL", unindexQuery, 2, idx, k); err != nil {
+				r.logger.Warnf("Failed to remove key %s from the range index: %v", k, err)
+			}
+		}
+	}()
+	for {
+		var (
+			reply any
+			err   error
+		)
+		if req.Reverse {
+			reply, err = r.client.DoRead(ctx, "ZREVRANGEBYLEX", idx, upper, lower, "LIMIT", 0, rangeScanBatchSize)
+		} else {
+			reply, err = r.client.DoRead(ctx, "ZRANGEBYLEX", idx, lower, upper, "LIMIT", 0, rangeScanBatchSize)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the range index: %w", err)
+		}
+		members, ok := reply.([]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid response from the range index: %v", reply)
+		}
+
+		for _, m := range members {
+			key, ok := toString(m)
+			if !ok {
+				return nil, fmt.Errorf("invalid key in the range index: %v", m)
+			}
+			if req.Reverse {
+				upper = "(" + key
+			} else {
+				lower = "(" + key
+			}
+
+			item, err := r.getDefault(ctx, &state.GetRequest{Key: key})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get key %s: %w", key, err)
+			}
+			if item.Data == nil && item.ETag == nil {
+				stale = append(stale, key)
+				continue
+			}
+			if req.Limit > 0 && len(res.Items) == int(req.Limit) {
+				res.ContinuationToken = ptr.Of(res.Items[len(res.Items)-1].Key)
+				return res, nil
+			}
+			res.Items = append(res.Items, state.RangeScanItem{
+				Key:         key,
+				Data:        item.Data,
+				ETag:        item.ETag,
+				ContentType: item.ContentType,
+			})
+		}
+
+		if len(members) < rangeScanBatchSize {
+			return res, nil
+		}
+	}
+}
@@ -14,7 +14,10 @@ limitations under the License.
 package redis
 
 import (
+	"context"
+	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,6 +29,7 @@ import (
 
 	rediscomponent "github.com/dapr/components-contrib/common/component/redis"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/codec"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
 )
@@ -522,6 +526,30 @@ func setupMiniredis() (*miniredis.Miniredis, rediscomponent.RedisClient) {
 	return s, rediscomponent.ClientFromV8Client(redis.NewClient(opts))
 }
 
+// readOnlyClient fails the commands sent with DoRead that modify the keys of the server, so the tests catch writes sent to the read path.
+type readOnlyClient struct {
+	rediscomponent.RedisClient
+	server *miniredis.Miniredis
+}
+
+func (c readOnlyClient) DoRead(ctx context.Context, args ...interface{}) (interface{}, error) {
+	before := c.snapshot()
+	res, err := c.RedisClient.DoRead(ctx, args...)
+	if c.snapshot() != before {
+		return nil, fmt.Errorf("%v modified the data with DoRead", args[0])
+	}
+	return res, err
+}
+
+func (c readOnlyClient) snapshot() string {
+	var sb strings.Builder
+	sb.WriteString(c.server.Dump())
+	for _, k := range c.server.Keys() {
+		fmt.Fprintf(&sb, "%s:%v\n", k, c.server.TTL(k))
+	}
+	return sb.String()
+}
+
 func TestToString(t *testing.T) {
 	// happy paths
 	if s, ok := toString("abc"); assert.True(t, ok) {
@@ -567,3 +595,354 @@ func Test_KeyList(t *testing.T) {
 	_, ok := s.(state.KeysLiker)
 	require.True(t, ok)
 }
+
+func TestWatch(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = c
+	ss.clientSettings = &rediscomponent.Settings{}
+
+	ch := make(chan *state.WatchEvent, 10)
+	err := ss.Watch(t.Context(), &state.WatchRequest{Prefix: "app||"}, func(_ context.Context, e *state.WatchEvent) error {
+		ch <- e
+		return nil
+	})
+	require.NoError(t, err)
+	receive := func(t *testing.T) *state.WatchEvent {
+		t.Helper()
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for event")
+			return nil
+		}
+	}
+
+	// Miniredis doesn't generate keyspace notifications, so they are published by the test
+	require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "app||a", Value: "1", Metadata: map[string]string{"ttlInSeconds": "100"}}))
+	s.Publish("__keyspace@0__:other", "hincrby")
+	s.Publish("__keyspace@0__:app||a", "hset")
+	s.Publish("__keyspace@0__:app||a", "hincrby")
+	s.Publish("__keyspace@0__:app||a", "expire")
+
+	e := receive(t)
+	assert.Equal(t, state.WatchEventUpsert, e.Type)
+	assert.Equal(t, "app||a", e.Key)
+	assert.Equal(t, `"1"`, string(e.Value))
+	require.NotNil(t, e.ETag)
+	assert.Equal(t, "1", *e.ETag)
+	require.NotNil(t, e.ExpireTime)
+	assert.WithinDuration(t, time.Now().Add(100*time.Second), *e.ExpireTime, 5*time.Second)
+
+	require.NoError(t, ss.Delete(t.Context(), &state.DeleteRequest{Key: "app||a"}))
+	s.Publish("__keyspace@0__:app||a", "del")
+	e = receive(t)
+	assert.Equal(t, state.WatchEventDelete, e.Type)
+	assert.Equal(t, "app||a", e.Key)
+	assert.Empty(t, ch)
+
+	assert.Equal(t, `app||\*\?`, escapeRedisGlob("app||*?"))
+}
+
+func TestAtomicOperations(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = readOnlyClient{RedisClient: c, server: s}
+	ss.clientSettings = &rediscomponent.Settings{}
+
+	t.Run("increment", func(t *testing.T) {
+		res, err := ss.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: 5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), res.Value)
+		require.NotNil(t, res.ETag)
+		assert.Equal(t, "1", *res.ETag)
+
+		res, err = ss.Increment(t.Context(), &state.IncrementRequest{Key: "counter", Delta: -7, Metadata: map[string]string{"ttlInSeconds": "100"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), res.Value)
+		assert.Equal(t, "2", *res.ETag)
+		assert.Equal(t, 100*time.Second, s.TTL("counter"))
+
+		get, err := ss.Get(t.Context(), &state.GetRequest{Key: "counter"})
+		require.NoError(t, err)
+		assert.Equal(t, "-2", string(get.Data))
+
+		require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "text", Value: "abc"}))
+		_, err = ss.Increment(t.Context(), &state.IncrementRequest{Key: "text", Delta: 1})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		res, err := ss.Append(t.Context(), &state.AppendRequest{Key: "list", Values: []any{1, "a"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Length)
+		assert.Equal(t, "1", *res.ETag)
+
+		res, err = ss.Append(t.Context(), &state.AppendRequest{Key: "list", Values: []any{map[string]int{"b": 2}}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Length)
+		assert.Equal(t, "2", *res.ETag)
+
+		get, err := ss.Get(t.Context(), &state.GetRequest{Key: "list"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1,"a",{"b":2}]`, string(get.Data))
+
+		require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "empty", Value: []any{}}))
+		res, err = ss.Append(t.Context(), &state.AppendRequest{Key: "empty", Values: []any{true}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Length)
+
+		require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "object", Value: map[string]int{"a": 1}}))
+		_, err = ss.Append(t.Context(), &state.AppendRequest{Key: "object", Values: []any{1}})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		res, err := ss.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Value: "a"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, "1", *res.ETag)
+
+		res, err = ss.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Value: "b"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+		assert.Nil(t, res.ETag)
+
+		res, err = ss.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Expected: "x", Value: "b"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+
+		res, err = ss.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: "cas", Expected: "a", Value: "b"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, "2", *res.ETag)
+
+		get, err := ss.Get(t.Context(), &state.GetRequest{Key: "cas"})
+		require.NoError(t, err)
+		assert.Equal(t, `"b"`, string(get.Data))
+	})
+}
+
+func TestTTLManagement(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = readOnlyClient{RedisClient: c, server: s}
+	ss.clientSettings = &rediscomponent.Settings{}
+
+	require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "session", Value: "data"}))
+
+	t.Run("key without TTL", func(t *testing.T) {
+		res, err := ss.GetTTL(t.Context(), &state.GetTTLRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+		require.NotNil(t, res.ETag)
+		assert.Equal(t, "1", *res.ETag)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		res, err := ss.Expire(t.Context(), &state.ExpireRequest{Key: "session", TTLInSeconds: 100})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		require.NotNil(t, res.ExpireTime)
+		assert.WithinDuration(t, time.Now().Add(100*time.Second), *res.ExpireTime, 5*time.Second)
+		assert.Equal(t, "1", *res.ETag)
+		assert.Equal(t, 100*time.Second, s.TTL("session"))
+
+		res, err = ss.GetTTL(t.Context(), &state.GetTTLRequest{Key: "session"})
+		require.NoError(t, err)
+		require.NotNil(t, res.ExpireTime)
+
+		get, err := ss.Get(t.Context(), &state.GetRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.Equal(t, `"data"`, string(get.Data))
+	})
+
+	t.Run("persist", func(t *testing.T) {
+		res, err := ss.Expire(t.Context(), &state.ExpireRequest{Key: "session"})
+		require.NoError(t, err)
+		assert.True(t, res.Found)
+		assert.Nil(t, res.ExpireTime)
+		assert.Zero(t, s.TTL("session"))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		res, err := ss.Expire(t.Context(), &state.ExpireRequest{Key: "missing", TTLInSeconds: 10})
+		require.NoError(t, err)
+		assert.False(t, res.Found)
+
+		res, err = ss.GetTTL(t.Context(), &state.GetTTLRequest{Key: "missing"})
+		require.NoError(t, err)
+		assert.False(t, res.Found)
+	})
+}
+
+func TestCodec(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = c
+	ss.clientSettings = &rediscomponent.Settings{}
+	var err error
+	ss.codec, err = codec.New(codec.Metadata{ValueCodec: "snappy", ValueFormat: "msgpack"})
+	require.NoError(t, err)
+
+	value := map[string]any{"session": strings.Repeat("abc", 100), "count": 3}
+	require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "key", Value: value, ContentType: ptr.Of("application/json")}))
+	require.NoError(t, ss.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "binary", Value: []byte{0xff, 0x00}, ContentType: ptr.Of("application/octet-stream")},
+		},
+	}))
+
+	stored := s.HGet("key", "data")
+	assert.True(t, codec.IsEncoded([]byte(stored)))
+	assert.Less(t, len(stored), 100)
+
+	res, err := ss.Get(t.Context(), &state.GetRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"session":"`+strings.Repeat("abc", 100)+`","count":3}`, string(res.Data))
+	assert.Equal(t, "application/json", *res.ContentType)
+
+	res, err = ss.Get(t.Context(), &state.GetRequest{Key: "binary"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0x00}, res.Data)
+	assert.Equal(t, "application/octet-stream", *res.ContentType)
+
+	// Values saved without the codec are returned as they are
+	s.HSet("plain", "data", `{"a":1}`, "version", "1")
+	res, err = ss.Get(t.Context(), &state.GetRequest{Key: "plain"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1}`, string(res.Data))
+	assert.Nil(t, res.ContentType)
+
+	t.Run("values are not decoded without a codec", func(t *testing.T) {
+		ss.codec = nil
+		res, err := ss.Get(t.Context(), &state.GetRequest{Key: "key"})
+		require.NoError(t, err)
+		assert.Equal(t, stored, string(res.Data))
+		assert.Nil(t, res.ContentType)
+	})
+}
+
+func TestDeleteWithPrefix(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = readOnlyClient{RedisClient: c, server: s}
+	ss.clientSettings = &rediscomponent.Settings{}
+
+	keys := []string{"my_app||k1", "my_app||k2", "my_app||actor||k3", "myXapp||k4", "my_app2||k5"}
+	for i := range 2500 {
+		keys = append(keys, "big*||k"+strconv.Itoa(i))
+	}
+	for _, k := range keys {
+		require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: k, Value: "v"}))
+	}
+
+	res, err := ss.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "my_app"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Count)
+	assert.False(t, s.Exists("my_app||k1"))
+	assert.False(t, s.Exists("my_app||k2"))
+	assert.True(t, s.Exists("my_app||actor||k3"))
+	assert.True(t, s.Exists("myXapp||k4"))
+	assert.True(t, s.Exists("my_app2||k5"))
+
+	// Keys are deleted in multiple batches
+	res, err = ss.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "big*||"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), res.Count)
+	assert.Len(t, s.Keys(), 3)
+
+	_, err = ss.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "||"})
+	require.ErrorContains(t, err, "prefix is required")
+}
+
+func TestRangeScan(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := newStateStore(logger.NewLogger("test"))
+	ss.client = c
+	ss.clientSettings = &rediscomponent.Settings{RangeIndex: "range-index"}
+
+	assert.Contains(t, ss.Features(), state.FeatureRangeScan)
+	for _, k := range []string{"orders||2024-05-03", "orders||2024-05-01", "orders||2024-05-02", "other||1"} {
+		require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: k, Value: k}))
+	}
+	require.NoError(t, ss.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "orders||2024-05-04", Value: "v"},
+			state.DeleteRequest{Key: "orders||2024-05-03"},
+		},
+	}))
+	// Keys that expired are skipped and removed from the index
+	require.NoError(t, ss.Set(t.Context(), &state.SetRequest{Key: "orders||2024-05-00", Value: "v"}))
+	s.Del("orders||2024-05-00")
+
+	t.Run("pages in order", func(t *testing.T) {
+		req := &state.RangeScanRequest{Start: "orders||", End: "orders||~", Limit: 2}
+		res, err := ss.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		assert.Equal(t, "orders||2024-05-01", res.Items[0].Key)
+		assert.JSONEq(t, `"orders||2024-05-01"`, string(res.Items[0].Data))
+		require.NotNil(t, res.Items[0].ETag)
+		assert.Equal(t, "orders||2024-05-02", res.Items[1].Key)
+		require.NotNil(t, res.ContinuationToken)
+
+		req.ContinuationToken = res.ContinuationToken
+		res, err = ss.RangeScan(t.Context(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "orders||2024-05-04", res.Items[0].Key)
+		assert.Nil(t, res.ContinuationToken)
+
+		members, err := s.ZMembers("range-index")
+		require.NoError(t, err)
+		assert.NotContains(t, members, "orders||2024-05-00")
+		assert.NotContains(t, members, "orders||2024-05-03")
+	})
+
+	t.Run("reverse order", func(t *testing.T) {
+		res, err := ss.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||", Reverse: true, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "other||1", res.Items[0].Key)
+
+		res, err = ss.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||", Reverse: true, ContinuationToken: res.ContinuationToken})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 3)
+		assert.Equal(t, "orders||2024-05-04", res.Items[0].Key)
+		assert.Equal(t, "orders||2024-05-01", res.Items[2].Key)
+	})
+
+	t.Run("deleted keys are removed from the index", func(t *testing.T) {
+		require.NoError(t, ss.Delete(t.Context(), &state.DeleteRequest{Key: "orders||2024-05-01"}))
+		_, err := ss.DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "other"})
+		require.NoError(t, err)
+
+		members, err := s.ZMembers("range-index")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders||2024-05-02", "orders||2024-05-04"}, members)
+	})
+
+	t.Run("range index not enabled", func(t *testing.T) {
+		disabled := newStateStore(logger.NewLogger("test"))
+		disabled.client = c
+		disabled.clientSettings = &rediscomponent.Settings{}
+		assert.NotContains(t, disabled.Features(), state.FeatureRangeScan)
+		_, err := disabled.RangeScan(t.Context(), &state.RangeScanRequest{Start: "orders||"})
+		require.ErrorIs(t, err, errRangeIndexDisabled)
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// ttlQuery changes the TTL of the key if ARGV[1] is not empty, with EXPIRE or PERSIST, and returns the remaining TTL in milliseconds and the version of the key.
+// Keys can be hashes written by setDefaultQuery or JSON documents written by setJSONQuery. Returns nil if the key doesn't exist.
+const ttlQuery = `
+local keyType = redis.call("TYPE", KEYS[1])["ok"];
+if keyType == "none" then
+  return nil;
+end;
+if ARGV[1] ~= "" then
+  if tonumber(ARGV[1]) > 0 then
+    redis.call("EXPIRE", KEYS[1], ARGV[1]);
+  else
+    redis.call("PERSIST", KEYS[1]);
+  end;
+end;
+local version = false;
+if keyType == "hash" then
+  version = redis.call("HGET", KEYS[1], "version");
+elseif keyType == "ReJSON-RL" then
+  version = redis.pcall("JSON.GET", KEYS[1], ".version");
+  if type(version) ~= "string" then
+    version = false;
+  end;
+end;
+return {redis.call("PTTL", KEYS[1]), version}`
+
+// Expire sets the TTL of the key with EXPIRE, or removes it with PERSIST, keeping its value and ETag.
+func (r *StateStore) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return r.doTTL(ctx, req.Key, strconv.FormatInt(req.TTLInSeconds, 10))
+}
+
+// GetTTL returns the expiration time of the key, computed from its remaining TTL.
+func (r *StateStore) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return r.doTTL(ctx, req.Key, "")
+}
+
+// doTTL runs ttlQuery, which changes the TTL of the key if ttl is not empty.
+// Changes are sent as writes, while reading the TTL is a read.
+func (r *StateStore) doTTL(ctx context.Context, key string, ttl string) (*state.TTLResponse, error) {
+	var (
+		res any
+		err error
+	)
+	if ttl != "" {
+		res, err = r.client.DoWriteResult(ctx, "EVAL", ttlQuery, 1, key, ttl)
+	} else {
+		res, err = r.client.DoRead(ctx, "EVAL", ttlQuery, 1, key, ttl)
+	}
+	if err != nil {
+		if err.Error() == string(r.client.GetNilValueError()) {
+			return &state.TTLResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to update TTL of key %s: %w", key, err)
+	}
+	if res == nil {
+		return &state.TTLResponse{}, nil
+	}
+	arr, ok := res.([]any)
+	if !ok || len(arr) == 0 {
+		return nil, fmt.Errorf("invalid result from TTL script: %v", res)
+	}
+	pttl, ok := arr[0].(int64)
+	if !ok {
+		return nil, fmt.Errorf("invalid TTL returned by script: %v", arr[0])
+	}
+
+	out := &state.TTLResponse{Found: true}
+	if pttl >= 0 {
+		out.ExpireTime = ptr.Of(time.Now().Add(time.Duration(pttl) * time.Millisecond))
+	}
+	if len(arr) > 1 {
+		if version, ok := toString(arr[1]); ok {
+			out.ETag = &version
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	rediscomponent "github.com/dapr/components-contrib/common/component/redis"
+	"github.com/dapr/components-contrib/state"
+)
+
+// Keyspace notification classes required by Watch: keyspace events (K), generic commands (g), hash commands (h), expired (x) and evicted (e) keys, and module commands (d) for RedisJSON.
+const watchNotifyKeyspaceEvents = "Kghxed"
+
+// Watch delivers the changes to the keys matched by the request to the handler, using Redis keyspace notifications.
+// The store tries to enable the notifications with CONFIG SET; if the server doesn't allow that (as with some managed services), "notify-keyspace-events" must include "Kghxed".
+// Keyspace notifications are not propagated across the nodes of a Redis Cluster.
+func (r *StateStore) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if r.closed.Load() {
+		return errors.New("redis store: state store is closed")
+	}
+
+	if err := r.enableKeyspaceEvents(ctx); err != nil {
+		r.logger.Warnf("Failed to enable keyspace notifications; make sure that notify-keyspace-events includes %q: %v", watchNotifyKeyspaceEvents, err)
+	}
+
+	prefix := fmt.Sprintf("__keyspace@%d__:", r.clientSettings.DB)
+	pattern := prefix + escapeRedisGlob(req.Key)
+	if req.Prefix != "" {
+		pattern = prefix + escapeRedisGlob(req.Prefix) + "*"
+	}
+
+	// Stop the subscription when the store is closed
+	watchCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-watchCtx.Done():
+		case <-r.closeCh:
+		}
+		cancel()
+	}()
+
+	// Messages are delivered sequentially, so the last event can be kept without locking
+	var last *state.WatchEvent
+	err := r.client.PSubscribe(watchCtx, &rediscomponent.PSubscribeArgs{
+		Pattern: pattern,
+		Handler: func(ctx context.Context, channel string, payload string) {
+			e, err := r.watchEvent(ctx, req, strings.TrimPrefix(channel, prefix), payload)
+			if err != nil {
+				r.logger.Errorf("Error reading the value of key %s: %v", strings.TrimPrefix(channel, prefix), err)
+				return
+			}
+			// Commands like a set with a TTL generate multiple notifications; skip the ones that don't change anything
+			if e == nil || sameWatchEvent(e, last) {
+				return
+			}
+			last = e
+			if err = handler(ctx, e); err != nil {
+				r.logger.Errorf("Error from watch handler for key %s: %v", e.Key, err)
+			}
+		},
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("redis store: error subscribing to keyspace notifications: %w", err)
+	}
+
+	return nil
+}
+
+// watchEvent returns the event for the keyspace notification, or nil if the notification is not relevant.
+func (r *StateStore) watchEvent(ctx context.Context, req *state.WatchRequest, key string, notification string) (*state.WatchEvent, error) {
+	switch notification {
+	case "del", "expired", "evicted", "json.del":
+		return &state.WatchEvent{
+			Type: state.WatchEventDelete,
+			Key:  key,
+		}, nil
+	case "hincrby", "json.set", "expire", "persist":
+		// The version is incremented as last command of each set, and TTLs are updated after the value
+	default:
+		return nil, nil
+	}
+
+	// The TTL is read before the value, so a key deleted in between is detected by the read of the value
+	ttl, err := r.client.TTLResult(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.Get(ctx, &state.GetRequest{Key: key, Metadata: req.Metadata})
+	if err != nil {
+		return nil, err
+	}
+	if res.Data == nil {
+		// The key has been deleted since; the delete event follows
+		return nil, nil
+	}
+
+	e := &state.WatchEvent{
+		Type:  state.WatchEventUpsert,
+		Key:   key,
+		Value: res.Data,
+		ETag:  res.ETag,
+	}
+	if ttl > 0 {
+		e.ExpireTime = new(time.Time)
+		*e.ExpireTime = time.Now().Add(ttl)
+	}
+	return e, nil
+}
+
+// enableKeyspaceEvents adds the notification classes required by Watch to the server configuration, keeping the existing ones.
+func (r *StateStore) enableKeyspaceEvents(ctx context.Context) error {
+	res, err := r.client.DoRead(ctx, "CONFIG", "GET", "notify-keyspace-events")
+	if err != nil {
+		return err
+	}
+
+	var current string
+	switch v := res.(type) {
+	case []any:
+		if len(v) == 2 {
+			current, _ = toString(v[1])
+		}
+	case map[any]any:
+		current, _ = toString(v["notify-keyspace-events"])
+	}
+
+	flags := current
+	for _, c := range watchNotifyKeyspaceEvents {
+		// "A" is an alias for all the classes except key-miss and new-key events
+		if !strings.ContainsRune(flags, c) && (c == 'K' || !strings.ContainsRune(flags, 'A')) {
+			flags += string(c)
+		}
+	}
+	if flags == current {
+		return nil
+	}
+	return r.client.DoWrite(ctx, "CONFIG", "SET", "notify-keyspace-events", flags)
+}
+
+func sameWatchEvent(a *state.WatchEvent, b *state.WatchEvent) bool {
+	if b == nil || a.Key != b.Key || a.Type != b.Type || !bytes.Equal(a.Value, b.Value) {
+		return false
+	}
+	if (a.ETag == nil) != (b.ETag == nil) || (a.ETag != nil && *a.ETag != *b.ETag) {
+		return false
+	}
+	// TTLs have a resolution of one second, so expiration times computed for the same TTL can differ slightly
+	if (a.ExpireTime == nil) != (b.ExpireTime == nil) || (a.ExpireTime != nil && a.ExpireTime.Sub(*b.ExpireTime).Abs() >= time.Second) {
+		return false
+	}
+	return true
+}
+
+// escapeRedisGlob escapes the characters that have a special meaning in Redis glob patterns.
+func escapeRedisGlob(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// Number of keys listed and compared at a time.
+const reconcileBatchSize = 100
+
+// ReconcileResponse is the response of Reconcile.
+type ReconcileResponse struct {
+	// Number of keys that were saved in the secondary stores, because they were missing or had a different value.
+	Updated int
+	// Number of keys that were deleted from the secondary stores, because they don't exist in the primary store.
+	Deleted int
+}
+
+// Reconcile compares the values of the secondary stores with the ones of the primary store, and repairs the ones that differ.
+// The keys are listed with KeysLike, which the primary store must implement; keys that only exist in a secondary store are deleted if it implements KeysLiker too.
+// Repairs are queued like the other writes, and read the value from the primary store when they're applied, so they can't overwrite more recent writes.
+// Expiration times are not compared.
+func (s *Store) Reconcile(ctx context.Context) (ReconcileResponse, error) {
+	s.reconcileLock.Lock()
+	defer s.reconcileLock.Unlock()
+
+	primary, ok := s.primary.(state.KeysLiker)
+	if !ok {
+		return ReconcileResponse{}, errors.New("reconciliation requires a primary state store that supports keys like")
+	}
+	keys, err := listKeys(ctx, primary)
+	if err != nil {
+		return ReconcileResponse{}, fmt.Errorf("failed to list the keys of the primary state store: %w", err)
+	}
+
+	var res ReconcileResponse
+	for _, r := range s.replicas {
+		updated, deleted, err := s.reconcileReplica(ctx, r, keys)
+		res.Updated += updated
+		res.Deleted += deleted
+		if err != nil {
+			return res, fmt.Errorf("failed to reconcile secondary state store %s: %w", r.name, err)
+		}
+	}
+	return res, nil
+}
+
+func (s *Store) reconcileReplica(ctx context.Context, r *replica, keys []string) (updated int, deleted int, err error) {
+	var stale []string
+	for start := 0; start < len(keys); start += reconcileBatchSize {
+		batch := keys[start:min(start+reconcileBatchSize, len(keys))]
+		diff, err := s.diff(ctx, r, batch)
+		if err != nil {
+			return updated, deleted, err
+		}
+		stale = append(stale, diff...)
+	}
+
+	// Keys that only exist in the secondary store
+	if keysLiker, ok := r.store.(state.KeysLiker); ok {
+		secondaryKeys, err := listKeys(ctx, keysLiker)
+		if err != nil {
+			return updated, deleted, fmt.Errorf("failed to list keys: %w", err)
+		}
+		primaryKeys := make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			primaryKeys[k] = struct{}{}
+		}
+		for _, k := range secondaryKeys {
+			if _, ok := primaryKeys[k]; !ok {
+				stale = append(stale, k)
+			}
+		}
+	}
+
+	for start := 0; start < len(stale); start += reconcileBatchSize {
+		batch := stale[start:min(start+reconcileBatchSize, len(stale))]
+		u, d, err := s.repair(ctx, r, batch)
+		updated += u
+		deleted += d
+		if err != nil {
+			return updated, deleted, err
+		}
+	}
+	return updated, deleted, nil
+}
+
+// diff returns the keys whose values differ between the primary and the secondary store.
+func (s *Store) diff(ctx context.Context, r *replica, keys []string) ([]string, error) {
+	req := make([]state.GetRequest, len(keys))
+	for i, k := range keys {
+		req[i] = state.GetRequest{Key: k}
+	}
+	primary, err := s.primary.BulkGet(ctx, req, state.BulkGetOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the primary state store: %w", err)
+	}
+	secondary, err := r.store.BulkGet(ctx, req, state.BulkGetOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+
+	values := make(map[string][]byte, len(secondary))
+	for _, item := range secondary {
+		if item.Error == "" && item.Data != nil {
+			values[item.Key] = item.Data
+		}
+	}
+	var diff []string
+	for _, item := range primary {
+		v, ok := values[item.Key]
+		if item.Error != "" || ok != (item.Data != nil) || !bytes.Equal(v, item.Data) {
+			diff = append(diff, item.Key)
+		}
+	}
+	return diff, nil
+}
+
+// repair queues a write that copies the current values of the keys from the primary store to the secondary store, and waits until it's applied.
+func (s *Store) repair(ctx context.Context, r *replica, keys []string) (int, int, error) {
+	// Set by the worker before the result is sent
+	var updated, deleted int
+	op := &replicationOp{
+		executed: time.Now(),
+		done:     make(chan error, 1),
+		apply: func(ctx context.Context, store state.Store) error {
+			req := make([]state.GetRequest, len(keys))
+			for i, k := range keys {
+				req[i] = state.GetRequest{Key: k}
+			}
+			items, err := s.primary.BulkGet(ctx, req, state.BulkGetOpts{})
+			if err != nil {
+				return fmt.Errorf("failed to read the primary state store: %w", err)
+			}
+
+			var (
+				sets    []state.SetRequest
+				deletes []state.DeleteRequest
+			)
+			for i := range items {
+				if items[i].Error != "" {
+					return fmt.Errorf("failed to read key %s from the primary state store: %s", items[i].Key, items[i].Error)
+				}
+				if items[i].Data == nil {
+					deletes = append(deletes, state.DeleteRequest{Key: items[i].Key})
+					continue
+				}
+				rec, err := state.NewSnapshotRecord(&items[i])
+				if err != nil {
+					return err
+				}
+				setReq, ok, err := rec.SetRequest()
+				if err != nil {
+					return err
+				}
+				if ok {
+					sets = append(sets, setReq)
+				} else {
+					deletes = append(deletes, state.DeleteRequest{Key: items[i].Key})
+				}
+			}
+			if len(sets) > 0 {
+				if err = store.BulkSet(ctx, sets, state.BulkStoreOpts{}); err != nil {
+					return err
+				}
+			}
+			if len(deletes) > 0 {
+				if err = store.BulkDelete(ctx, deletes, state.BulkStoreOpts{}); err != nil {
+					return err
+				}
+			}
+			updated, deleted = len(sets), len(deletes)
+			return nil
+		},
+	}
+	err := s.enqueue(ctx, r, op)
+	if err != nil {
+		return 0, 0, err
+	}
+	select {
+	case err = <-op.done:
+		return updated, deleted, err
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	}
+}
+
+// listKeys returns all the keys of the store.
+func listKeys(ctx context.Context, store state.KeysLiker) ([]string, error) {
+	var (
+		keys  []string
+		token *string
+	)
+	for {
+		res, err := store.KeysLike(ctx, &state.KeysLikeRequest{
+			Pattern:           "%",
+			ContinuationToken: token,
+			PageSize:          ptr.Of(uint32(reconcileBatchSize)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, res.Keys...)
+		if res.ContinuationToken == nil || *res.ContinuationToken == "" {
+			return keys, nil
+		}
+		token = res.ContinuationToken
+	}
+}
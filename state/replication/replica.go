@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+// ReplicaStatus is the replication status of a secondary store.
+type ReplicaStatus struct {
+	// Name of the secondary store.
+	Name string
+	// Number of writes that haven't been applied to the secondary store yet.
+	Pending int
+	// Time since the oldest write that hasn't been applied was executed on the primary store, or 0 if there are none.
+	Lag time.Duration
+	// Result of the last health check; secondary stores that don't implement health.Pinger are always healthy.
+	Healthy bool
+	// Time of the last write that failed to be applied, which is repaired by the next reconciliation, if any.
+	LastFailure time.Time
+	// Error of the last write that failed to be applied.
+	LastError error
+}
+
+// replicationOp is a write to apply to a secondary store.
+type replicationOp struct {
+	apply func(ctx context.Context, store state.Store) error
+	// Time the write was executed on the primary store
+	executed time.Time
+	// Receives the result, for synchronous replication; nil otherwise
+	done chan error
+}
+
+// replica is a secondary store, whose writes are applied in order by a worker.
+type replica struct {
+	name    string
+	store   state.Store
+	queue   chan *replicationOp
+	timeout time.Duration
+	logger  logger.Logger
+
+	pending atomic.Int64
+	healthy atomic.Bool
+
+	lock        sync.Mutex
+	current     time.Time
+	lastFailure time.Time
+	lastError   error
+}
+
+// run applies the writes in the queue, until it's closed.
+func (r *replica) run() {
+	for op := range r.queue {
+		r.lock.Lock()
+		r.current = op.executed
+		r.lock.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		err := op.apply(ctx, r.store)
+		cancel()
+
+		r.lock.Lock()
+		r.current = time.Time{}
+		if err != nil {
+			r.lastFailure = time.Now()
+			r.lastError = err
+		}
+		r.lock.Unlock()
+		r.pending.Add(-1)
+
+		if op.done != nil {
+			op.done <- err
+		} else if err != nil {
+			r.logger.Errorf("Failed to replicate a write to secondary store %s: %v", r.name, err)
+		}
+	}
+}
+
+// fail records a write that couldn't be queued with asynchronous replication.
+func (r *replica) fail(err error) {
+	r.lock.Lock()
+	r.lastFailure = time.Now()
+	r.lastError = err
+	r.lock.Unlock()
+	r.logger.Errorf("Failed to replicate a write to secondary store %s: %v", r.name, err)
+}
+
+func (r *replica) status() ReplicaStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s := ReplicaStatus{
+		Name:        r.name,
+		Pending:     int(r.pending.Load()),
+		Healthy:     r.healthy.Load(),
+		LastFailure: r.lastFailure,
+		LastError:   r.lastError,
+	}
+	if !r.current.IsZero() {
+		s.Lag = time.Since(r.current)
+	}
+	return s
+}
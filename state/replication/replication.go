@@ -0,0 +1,545 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replication implements a state store decorator that replicates the writes of a state store to secondary state stores.
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// Default maximum number of writes waiting to be applied to each secondary store.
+	defaultQueueSize = 1000
+	// Default timeout for applying a write to a secondary store.
+	defaultTimeout = 30 * time.Second
+	// Default interval between health checks.
+	defaultHealthCheckInterval = 5 * time.Second
+	// Timeout for pinging a store.
+	pingTimeout = 5 * time.Second
+)
+
+// ErrReplicationFailed is returned by writes that have been executed on the primary store, but failed to be applied to a secondary store, with synchronous replication.
+// The write is not reverted on the primary store, and is repaired on the secondary store by the next reconciliation.
+var ErrReplicationFailed = errors.New("failed to replicate the write")
+
+var errClosed = errors.New("the state store is closed")
+
+// Features of the primary store that are supported.
+var passthroughFeatures = []state.Feature{
+	state.FeatureETag,
+	state.FeatureTransactional,
+	state.FeatureTTL,
+	state.FeatureKeysLike,
+	state.FeatureQueryAPI,
+	state.FeatureQueryAggregate,
+	state.FeaturePartitionKey,
+}
+
+// Secondary is a state store the writes are replicated to.
+type Secondary struct {
+	// Name of the store, used in the status and in errors.
+	Name string
+	// State store, which must be initialized.
+	Store state.Store
+}
+
+// Options contains the options for the replicated store.
+type Options struct {
+	// Stores the writes are replicated to.
+	Secondaries []Secondary
+	// If true, writes return after they've been applied to all the secondary stores, failing with ErrReplicationFailed if they couldn't be applied even though they've been executed on the primary store.
+	// Otherwise, they're applied in background, and writes that couldn't be applied are only reported by Status.
+	Synchronous bool
+	// Maximum number of writes waiting to be applied to each secondary store; when it's reached, writes wait until there's room.
+	// With asynchronous replication, writes whose context is done before there's room are not replicated, and are reported by Status.
+	// Defaults to 1000.
+	QueueSize int
+	// Timeout for applying a write to a secondary store.
+	// Defaults to 30 seconds.
+	Timeout time.Duration
+	// Interval between the health checks of the stores that implement health.Pinger.
+	// Defaults to 5 seconds.
+	HealthCheckInterval time.Duration
+	// Interval between reconciliations; if 0, reconciliations are executed only by invoking Reconcile.
+	ReconcileInterval time.Duration
+	// Logger for the errors of the secondary stores and the changes of their health.
+	Logger logger.Logger
+}
+
+// Store is a state store decorator that executes the writes on a primary store, and replicates Set, Delete, BulkSet, BulkDelete and Multi to secondary stores.
+// Writes are applied to each secondary store in the order they were executed on the primary store; ETags are not replicated, as each store generates its own.
+// Writes that fail on a secondary store, and bulk writes that fail on the primary store after saving some of the keys, are repaired by Reconcile.
+//
+// Reads are executed on the primary store, or on the healthy secondary store with fewest pending writes while the primary store fails its health checks.
+// Atomic operations, TTL management and Watch are not supported.
+type Store struct {
+	primary        state.Store
+	replicas       []*replica
+	synchronous    bool
+	logger         logger.Logger
+	primaryHealthy atomic.Bool
+
+	closeLock sync.RWMutex
+	closed    bool
+	closeCh   chan struct{}
+	wg        sync.WaitGroup
+
+	reconcileLock sync.Mutex
+}
+
+// NewStore returns a store that replicates the writes of primary to the secondary stores in opts.
+// It starts the replication and the health checks, which are stopped by Close.
+func NewStore(primary state.Store, opts Options) (*Store, error) {
+	if primary == nil {
+		return nil, errors.New("primary state store is required")
+	}
+	if len(opts.Secondaries) == 0 {
+		return nil, errors.New("at least one secondary state store is required")
+	}
+	if opts.QueueSize < 0 || opts.Timeout < 0 || opts.HealthCheckInterval < 0 || opts.ReconcileInterval < 0 {
+		return nil, errors.New("replication options must not be negative")
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.HealthCheckInterval == 0 {
+		opts.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = logger.NewLogger("dapr.state.replication")
+	}
+
+	s := &Store{
+		primary:     primary,
+		replicas:    make([]*replica, len(opts.Secondaries)),
+		synchronous: opts.Synchronous,
+		logger:      opts.Logger,
+		closeCh:     make(chan struct{}),
+	}
+	s.primaryHealthy.Store(true)
+	names := make(map[string]struct{}, len(opts.Secondaries))
+	for i, sec := range opts.Secondaries {
+		if sec.Name == "" || sec.Store == nil {
+			return nil, errors.New("secondary state stores require a name and a store")
+		}
+		if _, ok := names[sec.Name]; ok {
+			return nil, fmt.Errorf("duplicate secondary state store %s", sec.Name)
+		}
+		names[sec.Name] = struct{}{}
+		s.replicas[i] = &replica{
+			name:    sec.Name,
+			store:   sec.Store,
+			queue:   make(chan *replicationOp, opts.QueueSize),
+			timeout: opts.Timeout,
+			logger:  opts.Logger,
+		}
+		s.replicas[i].healthy.Store(true)
+	}
+
+	for _, r := range s.replicas {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			r.run()
+		}()
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runPeriodically(opts.HealthCheckInterval, s.checkHealth)
+	}()
+	if opts.ReconcileInterval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runPeriodically(opts.ReconcileInterval, func(ctx context.Context) {
+				if _, err := s.Reconcile(ctx); err != nil {
+					s.logger.Errorf("Failed to reconcile the secondary state stores: %v", err)
+				}
+			})
+		}()
+	}
+	return s, nil
+}
+
+// Init initializes the primary store.
+func (s *Store) Init(ctx context.Context, metadata state.Metadata) error {
+	return s.primary.Init(ctx, metadata)
+}
+
+// Features returns the features of the primary store that are supported.
+func (s *Store) Features() []state.Feature {
+	var features []state.Feature
+	for _, f := range s.primary.Features() {
+		if slices.Contains(passthroughFeatures, f) {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// Status returns the replication status of the secondary stores.
+func (s *Store) Status() []ReplicaStatus {
+	res := make([]ReplicaStatus, len(s.replicas))
+	for i, r := range s.replicas {
+		res[i] = r.status()
+	}
+	return res
+}
+
+// PrimaryHealthy returns false if the primary store failed the last health check, so reads are executed on a secondary store.
+func (s *Store) PrimaryHealthy() bool {
+	return s.primaryHealthy.Load()
+}
+
+// Get retrieves the value of a key.
+func (s *Store) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	return s.reader().Get(ctx, req)
+}
+
+// BulkGet retrieves the values of multiple keys.
+func (s *Store) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	return s.reader().BulkGet(ctx, req, opts)
+}
+
+// Set saves the value of a key in the primary store, and replicates it.
+func (s *Store) Set(ctx context.Context, req *state.SetRequest) error {
+	err := s.primary.Set(ctx, req)
+	if err != nil {
+		return err
+	}
+	replicated := replicatedSet(*req)
+	return s.replicate(ctx, func(ctx context.Context, store state.Store) error {
+		return store.Set(ctx, &replicated)
+	})
+}
+
+// Delete deletes a key from the primary store, and replicates the deletion.
+func (s *Store) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	err := s.primary.Delete(ctx, req)
+	if err != nil {
+		return err
+	}
+	replicated := replicatedDelete(*req)
+	return s.replicate(ctx, func(ctx context.Context, store state.Store) error {
+		return store.Delete(ctx, &replicated)
+	})
+}
+
+// BulkSet saves the values of multiple keys in the primary store, and replicates them if they have all been saved.
+func (s *Store) BulkSet(ctx context.Context, req []state.SetRequest, opts state.BulkStoreOpts) error {
+	err := s.primary.BulkSet(ctx, req, opts)
+	if err != nil {
+		return err
+	}
+	replicated := make([]state.SetRequest, len(req))
+	for i := range req {
+		replicated[i] = replicatedSet(req[i])
+	}
+	return s.replicate(ctx, func(ctx context.Context, store state.Store) error {
+		return store.BulkSet(ctx, replicated, opts)
+	})
+}
+
+// BulkDelete deletes multiple keys from the primary store, and replicates the deletions if they have all been executed.
+func (s *Store) BulkDelete(ctx context.Context, req []state.DeleteRequest, opts state.BulkStoreOpts) error {
+	err := s.primary.BulkDelete(ctx, req, opts)
+	if err != nil {
+		return err
+	}
+	replicated := make([]state.DeleteRequest, len(req))
+	for i := range req {
+		replicated[i] = replicatedDelete(req[i])
+	}
+	return s.replicate(ctx, func(ctx context.Context, store state.Store) error {
+		return store.BulkDelete(ctx, replicated, opts)
+	})
+}
+
+// Multi executes the transaction on the primary store, and replicates it.
+// Secondary stores that don't support transactions apply the operations one at a time. Outbox messages are saved only in the primary store.
+func (s *Store) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	transactional, ok := s.primary.(state.TransactionalStore)
+	if !ok {
+		return errors.New("transactions are not supported by the state store")
+	}
+	err := transactional.Multi(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	replicated := &state.TransactionalStateRequest{
+		Operations: make([]state.TransactionalStateOperation, 0, len(request.Operations)),
+		Metadata:   request.Metadata,
+	}
+	for _, op := range request.Operations {
+		switch req := op.(type) {
+		case state.SetRequest:
+			replicated.Operations = append(replicated.Operations, replicatedSet(req))
+		case state.DeleteRequest:
+			replicated.Operations = append(replicated.Operations, replicatedDelete(req))
+		case state.OutboxRequest:
+			// Saving the messages in the secondary stores too would publish them more than once
+		default:
+			replicated.Operations = append(replicated.Operations, op)
+		}
+	}
+	if len(replicated.Operations) == 0 {
+		return nil
+	}
+	return s.replicate(ctx, func(ctx context.Context, store state.Store) error {
+		if transactional, ok := store.(state.TransactionalStore); ok {
+			return transactional.Multi(ctx, replicated)
+		}
+		for _, op := range replicated.Operations {
+			var err error
+			switch req := op.(type) {
+			case state.SetRequest:
+				err = store.Set(ctx, &req)
+			case state.DeleteRequest:
+				err = store.Delete(ctx, &req)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MultiMaxSize returns the maximum number of operations in a transaction of the primary store.
+func (s *Store) MultiMaxSize() int {
+	if m, ok := s.primary.(state.TransactionalStoreMultiMaxSize); ok {
+		return m.MultiMaxSize()
+	}
+	return -1
+}
+
+// Query executes the query on the store used for reads.
+func (s *Store) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.reader().(state.Querier)
+	if !ok {
+		return nil, errors.New("queries are not supported by the state store")
+	}
+	return querier.Query(ctx, req)
+}
+
+// QueryAggregate executes the query with projections or aggregations on the store used for reads.
+func (s *Store) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.reader().(state.AggregateQuerier)
+	if !ok {
+		return nil, errors.New("aggregate queries are not supported by the state store")
+	}
+	return querier.QueryAggregate(ctx, req)
+}
+
+// KeysLike returns the keys that match the pattern from the store used for reads.
+func (s *Store) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	keysLiker, ok := s.reader().(state.KeysLiker)
+	if !ok {
+		return nil, errors.New("keys like is not supported by the state store")
+	}
+	return keysLiker.KeysLike(ctx, req)
+}
+
+// Ping pings the primary store.
+func (s *Store) Ping(ctx context.Context) error {
+	if pinger, ok := s.primary.(health.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return state.ErrPingNotImplemented
+}
+
+// GetComponentMetadata returns the metadata of the primary store.
+func (s *Store) GetComponentMetadata() metadata.MetadataMap {
+	if m, ok := s.primary.(interface{ GetComponentMetadata() metadata.MetadataMap }); ok {
+		return m.GetComponentMetadata()
+	}
+	return nil
+}
+
+// Close applies the pending writes to the secondary stores, and closes all the stores.
+func (s *Store) Close() error {
+	s.closeLock.Lock()
+	if s.closed {
+		s.closeLock.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.closeCh)
+	for _, r := range s.replicas {
+		close(r.queue)
+	}
+	s.closeLock.Unlock()
+	s.wg.Wait()
+
+	errs := []error{s.primary.Close()}
+	for _, r := range s.replicas {
+		if err := r.store.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("secondary state store %s: %w", r.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reader returns the store used for reads: the primary store if it's healthy, or the healthy secondary store with fewest pending writes otherwise.
+// If all the stores are unhealthy, it returns the primary store.
+func (s *Store) reader() state.Store {
+	if s.primaryHealthy.Load() {
+		return s.primary
+	}
+	var best *replica
+	for _, r := range s.replicas {
+		if r.healthy.Load() && (best == nil || r.pending.Load() < best.pending.Load()) {
+			best = r
+		}
+	}
+	if best == nil {
+		return s.primary
+	}
+	return best.store
+}
+
+// replicate queues the write for all the secondary stores, and waits until it has been applied if replication is synchronous.
+// With asynchronous replication, it never fails, as the write has been executed on the primary store: writes that can't be queued are recorded in the status of the secondary store instead.
+func (s *Store) replicate(ctx context.Context, apply func(ctx context.Context, store state.Store) error) error {
+	executed := time.Now()
+	done := make([]chan error, len(s.replicas))
+	for i, r := range s.replicas {
+		op := &replicationOp{
+			apply:    apply,
+			executed: executed,
+		}
+		if s.synchronous {
+			op.done = make(chan error, 1)
+			done[i] = op.done
+		}
+		err := s.enqueue(ctx, r, op)
+		if err != nil {
+			if s.synchronous {
+				return fmt.Errorf("%w to secondary state store %s: %w", ErrReplicationFailed, r.name, err)
+			}
+			r.fail(fmt.Errorf("failed to queue a write: %w", err))
+		}
+	}
+	if !s.synchronous {
+		return nil
+	}
+
+	var errs []error
+	for i, ch := range done {
+		select {
+		case err := <-ch:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w to secondary state store %s: %w", ErrReplicationFailed, s.replicas[i].name, err))
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrReplicationFailed, ctx.Err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enqueue adds the write to the queue of the secondary store, waiting until there's room.
+func (s *Store) enqueue(ctx context.Context, r *replica, op *replicationOp) error {
+	s.closeLock.RLock()
+	defer s.closeLock.RUnlock()
+	if s.closed {
+		return errClosed
+	}
+
+	r.pending.Add(1)
+	select {
+	case r.queue <- op:
+		return nil
+	case <-ctx.Done():
+		r.pending.Add(-1)
+		return ctx.Err()
+	}
+}
+
+// checkHealth pings the stores, and updates the store used for reads.
+func (s *Store) checkHealth(ctx context.Context) {
+	healthy := ping(ctx, s.primary)
+	if s.primaryHealthy.Swap(healthy) != healthy {
+		if healthy {
+			s.logger.Info("The primary state store is available again")
+		} else {
+			s.logger.Warn("The primary state store is unavailable: reading from the secondary state stores")
+		}
+	}
+	for _, r := range s.replicas {
+		r.healthy.Store(ping(ctx, r.store))
+	}
+}
+
+// runPeriodically invokes fn at every interval, until the store is closed.
+func (s *Store) runPeriodically(interval time.Duration, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.closeCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
+
+// ping returns true if the store responds to pings, or doesn't implement health.Pinger.
+func ping(parentCtx context.Context, store state.Store) bool {
+	pinger, ok := store.(health.Pinger)
+	if !ok {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(parentCtx, pingTimeout)
+	defer cancel()
+	return pinger.Ping(ctx) == nil
+}
+
+// replicatedSet returns the request to replicate a write, which overwrites the value regardless of its ETag.
+func replicatedSet(req state.SetRequest) state.SetRequest {
+	req.ETag = nil
+	req.Options.Concurrency = state.LastWrite
+	return req
+}
+
+// replicatedDelete returns the request to replicate a deletion, which deletes the key regardless of its ETag.
+func replicatedDelete(req state.DeleteRequest) state.DeleteRequest {
+	req.ETag = nil
+	req.Options.Concurrency = state.LastWrite
+	return req
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+var errSimulated = errors.New("simulated error")
+
+// testStore is an in-memory store whose pings and writes can be made to fail.
+type testStore struct {
+	*inmemory.InMemoryStore
+
+	down       atomic.Bool
+	failWrites atomic.Bool
+	// Number of outbox messages received in transactions
+	outboxMessages atomic.Int32
+	// If not nil, writes wait until it's closed
+	blockWrites chan struct{}
+}
+
+func (s *testStore) Ping(context.Context) error {
+	if s.down.Load() {
+		return errSimulated
+	}
+	return nil
+}
+
+func (s *testStore) Set(ctx context.Context, req *state.SetRequest) error {
+	if s.blockWrites != nil {
+		<-s.blockWrites
+	}
+	if s.failWrites.Load() {
+		return errSimulated
+	}
+	return s.InMemoryStore.Set(ctx, req)
+}
+
+func (s *testStore) Multi(ctx context.Context, req *state.TransactionalStateRequest) error {
+	for _, op := range req.Operations {
+		if op.Operation() == state.OperationOutbox {
+			s.outboxMessages.Add(1)
+		}
+	}
+	return s.InMemoryStore.Multi(ctx, req)
+}
+
+func newTestStore(t *testing.T) *testStore {
+	t.Helper()
+	store := inmemory.NewInMemoryStateStore(logger.NewLogger("test")).(*inmemory.InMemoryStore)
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	return &testStore{InMemoryStore: store}
+}
+
+func newReplicatedStore(t *testing.T, synchronous bool) (*Store, *testStore, *testStore) {
+	t.Helper()
+	primary, secondary := newTestStore(t), newTestStore(t)
+	s, err := NewStore(primary, Options{
+		Secondaries: []Secondary{{Name: "secondary", Store: secondary}},
+		Synchronous: synchronous,
+		Logger:      logger.NewLogger("test"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+	return s, primary, secondary
+}
+
+// waitReplicated waits until all the writes have been applied to the secondary stores.
+func waitReplicated(t *testing.T, s *Store) {
+	t.Helper()
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		for _, st := range s.Status() {
+			assert.Zero(c, st.Pending)
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func getValue(t *testing.T, store state.Store, key string) string {
+	t.Helper()
+	res, err := store.Get(t.Context(), &state.GetRequest{Key: key})
+	require.NoError(t, err)
+	return string(res.Data)
+}
+
+func TestInvalidOptions(t *testing.T) {
+	primary, secondary := newTestStore(t), newTestStore(t)
+
+	tests := map[string]struct {
+		primary     state.Store
+		opts        Options
+		expectedErr string
+	}{
+		"Missing primary store": {
+			opts:        Options{Secondaries: []Secondary{{Name: "a", Store: secondary}}},
+			expectedErr: "primary state store is required",
+		},
+		"No secondary stores": {
+			primary:     primary,
+			opts:        Options{},
+			expectedErr: "at least one secondary state store is required",
+		},
+		"Negative queue size": {
+			primary:     primary,
+			opts:        Options{Secondaries: []Secondary{{Name: "a", Store: secondary}}, QueueSize: -1},
+			expectedErr: "must not be negative",
+		},
+		"Negative timeout": {
+			primary:     primary,
+			opts:        Options{Secondaries: []Secondary{{Name: "a", Store: secondary}}, Timeout: -time.Second},
+			expectedErr: "must not be negative",
+		},
+		"Missing secondary name": {
+			primary:     primary,
+			opts:        Options{Secondaries: []Secondary{{Store: secondary}}},
+			expectedErr: "require a name and a store",
+		},
+		"Missing secondary store": {
+			primary:     primary,
+			opts:        Options{Secondaries: []Secondary{{Name: "a"}}},
+			expectedErr: "require a name and a store",
+		},
+		"Duplicate secondary store": {
+			primary:     primary,
+			opts:        Options{Secondaries: []Secondary{{Name: "a", Store: secondary}, {Name: "a", Store: secondary}}},
+			expectedErr: "duplicate secondary state store a",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStore(tt.primary, tt.opts)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAsynchronousReplication(t *testing.T) {
+	s, primary, secondary := newReplicatedStore(t, false)
+
+	assert.Contains(t, s.Features(), state.FeatureETag)
+	assert.NotContains(t, s.Features(), state.FeatureAtomicOperations)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "1"}))
+	require.NoError(t, s.BulkSet(t.Context(), []state.SetRequest{{Key: "b", Value: "2"}, {Key: "c", Value: "3"}}, state.BulkStoreOpts{}))
+	require.NoError(t, s.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "d", Value: "4"},
+			state.DeleteRequest{Key: "c"},
+		},
+	}))
+
+	// ETags of the primary store are not used for the secondary stores
+	res, err := s.Get(t.Context(), &state.GetRequest{Key: "a"})
+	require.NoError(t, err)
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "5", ETag: res.ETag}))
+	require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: "b"}))
+
+	waitReplicated(t, s)
+	for _, store := range []state.Store{primary, secondary} {
+		assert.Equal(t, `"5"`, getValue(t, store, "a"))
+		assert.Empty(t, getValue(t, store, "b"))
+		assert.Empty(t, getValue(t, store, "c"))
+		assert.Equal(t, `"4"`, getValue(t, store, "d"))
+	}
+	st := s.Status()
+	require.Len(t, st, 1)
+	assert.Equal(t, "secondary", st[0].Name)
+	assert.True(t, st[0].Healthy)
+	assert.Zero(t, st[0].Lag)
+	require.NoError(t, st[0].LastError)
+
+	t.Run("failed writes are recorded", func(t *testing.T) {
+		secondary.failWrites.Store(true)
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "e", Value: "6"}))
+		waitReplicated(t, s)
+		secondary.failWrites.Store(false)
+
+		st := s.Status()
+		require.ErrorIs(t, st[0].LastError, errSimulated)
+		assert.False(t, st[0].LastFailure.IsZero())
+		assert.Empty(t, getValue(t, secondary, "e"))
+	})
+}
+
+func TestQueueFull(t *testing.T) {
+	primary, secondary := newTestStore(t), newTestStore(t)
+	secondary.blockWrites = make(chan struct{})
+	s, err := NewStore(primary, Options{
+		Secondaries: []Secondary{{Name: "secondary", Store: secondary}},
+		QueueSize:   1,
+		Logger:      logger.NewLogger("test"),
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// The first write is being applied, and the second one fills the queue
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "1"}))
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "b", Value: "2"}))
+	assert.Equal(t, 2, s.Status()[0].Pending)
+
+	// Writes that can't be queued don't fail, since they've been executed on the primary store
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	require.NoError(t, s.Set(ctx, &state.SetRequest{Key: "c", Value: "3"}))
+	assert.Equal(t, `"3"`, getValue(t, primary, "c"))
+	st := s.Status()[0]
+	require.ErrorIs(t, st.LastError, context.DeadlineExceeded)
+	assert.False(t, st.LastFailure.IsZero())
+
+	close(secondary.blockWrites)
+	waitReplicated(t, s)
+	assert.Equal(t, `"2"`, getValue(t, secondary, "b"))
+	assert.Empty(t, getValue(t, secondary, "c"))
+
+	// The write that wasn't queued is repaired by the reconciliation
+	res, err := s.Reconcile(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Updated)
+	waitReplicated(t, s)
+	assert.Equal(t, `"3"`, getValue(t, secondary, "c"))
+}
+
+func TestSynchronousReplication(t *testing.T) {
+	s, _, secondary := newReplicatedStore(t, true)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "1"}))
+	assert.Equal(t, `"1"`, getValue(t, secondary, "a"))
+
+	secondary.failWrites.Store(true)
+	err := s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "2"})
+	require.ErrorIs(t, err, ErrReplicationFailed)
+	require.ErrorIs(t, err, errSimulated)
+
+	// The write has been executed on the primary store
+	assert.Equal(t, `"2"`, getValue(t, s, "a"))
+	assert.Equal(t, `"1"`, getValue(t, secondary, "a"))
+}
+
+func TestOutboxNotReplicated(t *testing.T) {
+	s, primary, secondary := newReplicatedStore(t, true)
+
+	require.NoError(t, s.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "a", Value: "1"},
+			state.OutboxRequest{ID: "m1", PubsubName: "events", Topic: "orders"},
+		},
+	}))
+	assert.Equal(t, `"1"`, getValue(t, secondary, "a"))
+	assert.EqualValues(t, 1, primary.outboxMessages.Load())
+	assert.Zero(t, secondary.outboxMessages.Load())
+
+	// Transactions with only outbox messages are not replicated
+	require.NoError(t, s.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.OutboxRequest{ID: "m2", PubsubName: "events", Topic: "orders"},
+		},
+	}))
+	assert.EqualValues(t, 2, primary.outboxMessages.Load())
+	assert.Zero(t, secondary.outboxMessages.Load())
+}
+
+func TestFailover(t *testing.T) {
+	s, primary, secondary := newReplicatedStore(t, true)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "a", Value: "1"}))
+	require.NoError(t, secondary.InMemoryStore.Set(t.Context(), &state.SetRequest{Key: "a", Value: "secondary"}))
+
+	primary.down.Store(true)
+	s.checkHealth(t.Context())
+	assert.False(t, s.PrimaryHealthy())
+	assert.Equal(t, `"secondary"`, getValue(t, s, "a"))
+
+	// Secondary stores that are down are not used
+	secondary.down.Store(true)
+	s.checkHealth(t.Context())
+	assert.Equal(t, `"1"`, getValue(t, s, "a"))
+	assert.False(t, s.Status()[0].Healthy)
+
+	primary.down.Store(false)
+	secondary.down.Store(false)
+	s.checkHealth(t.Context())
+	assert.True(t, s.PrimaryHealthy())
+	assert.Equal(t, `"1"`, getValue(t, s, "a"))
+}
+
+func TestReconcile(t *testing.T) {
+	s, primary, secondary := newReplicatedStore(t, false)
+
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: k, Value: k}))
+	}
+	waitReplicated(t, s)
+
+	// Diverge the stores
+	require.NoError(t, primary.InMemoryStore.Set(t.Context(), &state.SetRequest{Key: "a", Value: "updated"}))
+	require.NoError(t, primary.InMemoryStore.Set(t.Context(), &state.SetRequest{Key: "d", Value: "new"}))
+	require.NoError(t, secondary.InMemoryStore.Delete(t.Context(), &state.DeleteRequest{Key: "b"}))
+	require.NoError(t, secondary.InMemoryStore.Set(t.Context(), &state.SetRequest{Key: "e", Value: "orphan"}))
+
+	res, err := s.Reconcile(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Updated)
+	assert.Equal(t, 1, res.Deleted)
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		assert.Equal(t, getValue(t, primary, k), getValue(t, secondary, k), "key %s", k)
+	}
+
+	res, err = s.Reconcile(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileResponse{}, res)
+}
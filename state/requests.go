@@ -16,6 +16,7 @@ package state
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/dapr/components-contrib/state/query"
 )
@@ -85,6 +86,32 @@ func (r *DeleteWithPrefixRequest) Validate() error {
 	return nil
 }
 
+// WatchRequest is the object describing a request to watch changes to state.
+// Exactly one of Key and Prefix must be set.
+type WatchRequest struct {
+	// Key to watch.
+	Key string `json:"key,omitempty"`
+	// Prefix of the keys to watch.
+	Prefix string `json:"prefix,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (r *WatchRequest) Validate() error {
+	if (r.Key == "") == (r.Prefix == "") {
+		return errors.New("exactly one of key and prefix is required for watch request")
+	}
+	return nil
+}
+
+// Matches returns true if the key is watched by the request.
+func (r *WatchRequest) Matches(key string) bool {
+	if r.Prefix != "" {
+		return strings.HasPrefix(key, r.Prefix)
+	}
+	return key == r.Key
+}
+
 // DeleteStateOption controls how a state store reacts to a delete request.
 type DeleteStateOption struct {
 	Concurrency string `json:"concurrency,omitempty"` // "concurrency"
@@ -135,10 +162,12 @@ const (
 	OperationUpsert OperationType = "upsert"
 	// OperationDelete is a delete transactional operation.
 	OperationDelete OperationType = "delete"
+	// OperationOutbox is a transactional operation that saves a message in the outbox, to be published after the transaction is committed.
+	OperationOutbox OperationType = "outbox"
 )
 
 // TransactionalStateRequest describes a transactional operation against a state store that comprises multiple types of operations
-// The Request field is either a DeleteRequest or SetRequest.
+// The Request field is either a DeleteRequest, a SetRequest or, for stores that implement OutboxStore, an OutboxRequest.
 type TransactionalStateRequest struct {
 	Operations []TransactionalStateOperation
 	Metadata   map[string]string
@@ -174,3 +203,210 @@ type KeysLikeRequest struct {
 	// to return.
 	PageSize *uint32 `json:"pageSize,omitempty"`
 }
+
+// IncrementRequest is the object describing a request to atomically add to the integer value of a key.
+type IncrementRequest struct {
+	Key string `json:"key"`
+	// Delta added to the value; use a negative number to decrement.
+	Delta int64 `json:"delta"`
+	// If the "ttlInSeconds" metadata property is set, the expiration time of the key is updated; otherwise, it is left unchanged.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (r *IncrementRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in increment request")
+	}
+	return nil
+}
+
+// AppendRequest is the object describing a request to atomically append to the JSON array stored in a key.
+type AppendRequest struct {
+	Key string `json:"key"`
+	// Values appended to the array; each value is serialized as JSON.
+	Values []any `json:"values"`
+	// If the "ttlInSeconds" metadata property is set, the expiration time of the key is updated; otherwise, it is left unchanged.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (r *AppendRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in append request")
+	}
+	if len(r.Values) == 0 {
+		return errors.New("at least one value is required in append request")
+	}
+	return nil
+}
+
+// CompareAndSwapRequest is the object describing a request to atomically replace the value of a key if it's equal to an expected value.
+type CompareAndSwapRequest struct {
+	Key string `json:"key"`
+	// Expected current value, serialized like the values of SetRequest. If nil, the key must not exist.
+	Expected any `json:"expected,omitempty"`
+	// New value, serialized like the values of SetRequest.
+	Value any `json:"value"`
+	// If the "ttlInSeconds" metadata property is set, the expiration time of the key is updated; otherwise, it is left unchanged.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (r *CompareAndSwapRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in compare-and-swap request")
+	}
+	if r.Value == nil {
+		return errors.New("missing value in compare-and-swap request")
+	}
+	return nil
+}
+
+// GetRevisionsRequest is the object describing a request to list the revisions of a key.
+type GetRevisionsRequest struct {
+	Key string `json:"key"`
+	// Maximum number of revisions returned, starting from the most recent one; if 0, all retained revisions are returned.
+	Limit int `json:"limit,omitempty"`
+}
+
+func (r *GetRevisionsRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in get revisions request")
+	}
+	if r.Limit < 0 {
+		return errors.New("limit in get revisions request cannot be negative")
+	}
+	return nil
+}
+
+// GetRevisionRequest is the object describing a request to read the value of a key at a revision or at a point in time.
+// Exactly one of Revision and Time must be set.
+type GetRevisionRequest struct {
+	Key string `json:"key"`
+	// Revision to read, as returned by GetRevisions.
+	Revision *int64 `json:"revision,omitempty"`
+	// Point in time to read: the value is the one of the most recent revision created at or before this time.
+	Time *time.Time `json:"time,omitempty"`
+}
+
+func (r *GetRevisionRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in get revision request")
+	}
+	if (r.Revision == nil) == (r.Time == nil) {
+		return errors.New("get revision request must have either a revision or a time")
+	}
+	return nil
+}
+
+// ExpireRequest is the object describing a request to change the expiration time of a key without changing its value.
+type ExpireRequest struct {
+	Key string `json:"key"`
+	// TTL of the key, in seconds from the time of the request. If 0 or negative, the expiration time is removed and the key never expires.
+	TTLInSeconds int64 `json:"ttlInSeconds"`
+}
+
+func (r *ExpireRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in expire request")
+	}
+	return nil
+}
+
+// GetTTLRequest is the object describing a request to read the expiration time of a key.
+type GetTTLRequest struct {
+	Key string `json:"key"`
+}
+
+func (r *GetTTLRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("missing key in get TTL request")
+	}
+	return nil
+}
+
+// RangeScanRequest is the object describing a request to read the keys in a range, with their values, in lexical order.
+type RangeScanRequest struct {
+	// First key of the range, inclusive. If empty, the range starts from the first key.
+	Start string `json:"start,omitempty"`
+	// Key after the end of the range, exclusive. If empty, the range ends with the last key.
+	End string `json:"end,omitempty"`
+	// Maximum number of items to return. If 0, all the items in the range are returned.
+	Limit uint32 `json:"limit,omitempty"`
+	// If true, items are returned in descending order of their keys.
+	Reverse bool `json:"reverse,omitempty"`
+	// Token returned by a previous request for the same range and order, to read the next page.
+	// It's the last key that was returned, so pages continue after it even if it's deleted in the meanwhile.
+	ContinuationToken *string `json:"continuationToken,omitempty"`
+}
+
+func (r *RangeScanRequest) Validate() error {
+	if r.Start != "" && r.End != "" && r.Start >= r.End {
+		return errors.New("the start key must be lower than the end key in range scan request")
+	}
+	return nil
+}
+
+// OutboxRequest is a transactional operation that saves a message in the outbox of the state store, in the same transaction as the other operations.
+// Messages in the outbox are published by a relay after the transaction is committed, so they're published if and only if the state changes are applied.
+type OutboxRequest struct {
+	// Unique ID of the message. Messages may be published more than once, so subscribers can use it to discard duplicates.
+	// If the outbox already contains a message with the same ID, the message is not saved again.
+	ID string `json:"id"`
+	// Name of the pubsub component the message is published to.
+	PubsubName string `json:"pubsubName"`
+	// Topic the message is published to.
+	Topic       string            `json:"topic"`
+	Data        []byte            `json:"data"`
+	ContentType *string           `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// GetKey returns the ID of the message; OutboxRequest doesn't modify any key.
+func (r OutboxRequest) GetKey() string {
+	return r.ID
+}
+
+// GetMetadata returns the metadata of the message.
+func (r OutboxRequest) GetMetadata() map[string]string {
+	return r.Metadata
+}
+
+// Operation returns the operation type for OutboxRequest, implementing TransactionalStateOperationRequest.
+func (r OutboxRequest) Operation() OperationType {
+	return OperationOutbox
+}
+
+func (r OutboxRequest) Validate() error {
+	if r.ID == "" {
+		return errors.New("missing ID in outbox request")
+	}
+	if r.PubsubName == "" {
+		return errors.New("missing pubsub name in outbox request")
+	}
+	if r.Topic == "" {
+		return errors.New("missing topic in outbox request")
+	}
+	return nil
+}
+
+// LeaseOutboxRequest is the object describing a request to lease the messages of a pubsub from the outbox.
+type LeaseOutboxRequest struct {
+	// Name of the pubsub component of the messages.
+	PubsubName string `json:"pubsubName"`
+	// Maximum number of messages to lease.
+	Limit int `json:"limit"`
+	// Duration of the lease; messages that aren't deleted or released before it expires can be leased again.
+	LeaseDuration time.Duration `json:"leaseDuration"`
+}
+
+func (r *LeaseOutboxRequest) Validate() error {
+	if r.PubsubName == "" {
+		return errors.New("missing pubsub name in lease outbox request")
+	}
+	if r.Limit <= 0 {
+		return errors.New("limit in lease outbox request must be positive")
+	}
+	if r.LeaseDuration <= 0 {
+		return errors.New("lease duration in lease outbox request must be positive")
+	}
+	return nil
+}
@@ -13,6 +13,10 @@ limitations under the License.
 
 package state
 
+import (
+	"time"
+)
+
 const (
 	// GetRespMetaKeyTTLExpireTime is the key for the metadata value of the TTL
 	// expire time. Value is a RFC3339 formatted string.
@@ -67,3 +71,98 @@ type KeysLikeResponse struct {
 	// request.
 	ContinuationToken *string
 }
+
+// WatchEventType is the type of a change delivered by Watcher.
+type WatchEventType string
+
+const (
+	// WatchEventUpsert is the type of events for keys that were created or updated.
+	WatchEventUpsert WatchEventType = "upsert"
+	// WatchEventDelete is the type of events for keys that were deleted or expired.
+	WatchEventDelete WatchEventType = "delete"
+)
+
+// WatchEvent is the object describing a change to a key.
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+	Key  string         `json:"key"`
+	// Value of the key after the change; not set for delete events.
+	Value []byte `json:"value,omitempty"`
+	// ETag of the key after the change; not set for delete events.
+	ETag *string `json:"etag,omitempty"`
+	// ExpireTime is set for upsert events if the key has a TTL.
+	ExpireTime *time.Time `json:"expireTime,omitempty"`
+}
+
+// IncrementResponse is the response object for IncrementRequest.
+type IncrementResponse struct {
+	// Value of the key after the increment.
+	Value int64   `json:"value"`
+	ETag  *string `json:"etag,omitempty"`
+}
+
+// AppendResponse is the response object for AppendRequest.
+type AppendResponse struct {
+	// Length of the array after the values were appended.
+	Length int64   `json:"length"`
+	ETag   *string `json:"etag,omitempty"`
+}
+
+// CompareAndSwapResponse is the response object for CompareAndSwapRequest.
+type CompareAndSwapResponse struct {
+	// Swapped is true if the value was replaced.
+	Swapped bool `json:"swapped"`
+	// ETag of the new value, if it was replaced.
+	ETag *string `json:"etag,omitempty"`
+}
+
+// Revision describes a revision of a key, created by each change to the key.
+type Revision struct {
+	// Revision number, which increases with each change.
+	Revision int64 `json:"revision"`
+	// Time when the revision was created; it's zero if the state store doesn't record it.
+	Time time.Time `json:"time"`
+	// Deleted is true if the key was deleted in this revision.
+	Deleted bool `json:"deleted,omitempty"`
+	// ETag of the value in this revision; not set for deletions.
+	ETag *string `json:"etag,omitempty"`
+}
+
+// GetRevisionsResponse is the response object for GetRevisionsRequest.
+type GetRevisionsResponse struct {
+	// Revisions of the key, from the most recent one.
+	Revisions []Revision `json:"revisions"`
+}
+
+// TTLResponse is the response object for ExpireRequest and GetTTLRequest.
+type TTLResponse struct {
+	// Found is false if the key doesn't exist or has expired.
+	Found bool `json:"found"`
+	// Expiration time of the key; nil if the key never expires.
+	ExpireTime *time.Time `json:"expireTime,omitempty"`
+	// ETag of the key.
+	ETag *string `json:"etag,omitempty"`
+}
+
+// RangeScanResponse is the response object for RangeScanRequest.
+type RangeScanResponse struct {
+	Items []RangeScanItem `json:"items"`
+	// Token to read the next page; nil if there are no more items in the range.
+	ContinuationToken *string `json:"continuationToken,omitempty"`
+}
+
+// RangeScanItem is a key returned by RangeScanner, with its value.
+type RangeScanItem struct {
+	Key         string            `json:"key"`
+	Data        []byte            `json:"data"`
+	ETag        *string           `json:"etag,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	ContentType *string           `json:"contentType,omitempty"`
+}
+
+// OutboxMessage is a message leased from the outbox by OutboxStore.
+type OutboxMessage struct {
+	OutboxRequest
+	// Number of times the message has been leased, including this one.
+	Attempts int `json:"attempts"`
+}
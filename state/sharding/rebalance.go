@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	// Number of locks that serialize the moves of the keys.
+	moveLockCount = 64
+	// Number of keys listed at a time while rebalancing.
+	rebalancePageSize = 1000
+)
+
+// AddShard adds a shard, and moves to it the keys that are assigned to it; all the shards must implement state.KeysLiker.
+// The store can be used while the keys are moved: keys that haven't been moved yet are read from their previous shard, and moved before they're modified.
+// If moving the keys fails, the shard remains added, and Rebalance must be invoked to complete the move.
+//
+// The partition keys aren't stored with the values, so Options.PartitionKey must be set if keys are saved with the partitionKey metadata.
+func (s *Store) AddShard(ctx context.Context, shard Shard) error {
+	err := s.addShard(shard)
+	if err != nil {
+		return err
+	}
+	return s.Rebalance(ctx)
+}
+
+func (s *Store) addShard(shard Shard) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.previous != nil {
+		return fmt.Errorf("the keys are being moved to shard %s: invoke Rebalance to complete the move before adding another shard", s.adding)
+	}
+	if err := s.validateShard(shard); err != nil {
+		return err
+	}
+	for _, name := range s.names {
+		if _, ok := s.shards[name].(state.KeysLiker); !ok {
+			return fmt.Errorf("shard %s doesn't support listing its keys, which is required to move them", name)
+		}
+	}
+
+	s.shards[shard.Name] = shard.Store
+	s.names = append(s.names, shard.Name)
+	s.previous = s.ring
+	s.ring = newRing(s.names, s.vnodes)
+	s.adding = shard.Name
+	return nil
+}
+
+// Rebalance moves the keys to the shard that was added last, completing AddShard.
+// It does nothing if all the keys have been moved already.
+//
+// It fails with ErrUnknownPartitionKey if keys saved with a partition key can't be moved, because Options.PartitionKey isn't set or doesn't return their partition key.
+// The rebalance isn't completed then, so these keys are still read from their previous shard.
+func (s *Store) Rebalance(ctx context.Context) error {
+	s.rebalanceLock.Lock()
+	defer s.rebalanceLock.Unlock()
+
+	s.lock.RLock()
+	current, previous, adding := s.ring, s.previous, s.adding
+	names := slices.Clone(s.names)
+	shards := maps.Clone(s.shards)
+	s.lock.RUnlock()
+	if previous == nil {
+		return nil
+	}
+
+	// All the keys are listed before any is moved, so nothing is moved if partition keys are unknown
+	var (
+		moves   []keyMove
+		unknown int
+	)
+	for _, name := range names {
+		if name == adding {
+			continue
+		}
+		keys, err := listKeys(ctx, shards[name])
+		if err != nil {
+			return fmt.Errorf("failed to list the keys of shard %s: %w", name, err)
+		}
+		for _, key := range keys {
+			rk, md := key, map[string]string(nil)
+			if s.partitionKey != nil {
+				if pk := s.partitionKey(key); pk != "" {
+					rk, md = pk, map[string]string{partitionKeyMetadata: pk}
+				}
+			}
+			if previous.owner(rk) != name {
+				// The key has been saved with a partition key, which is unknown
+				unknown++
+				continue
+			}
+			if current.owner(rk) != name {
+				moves = append(moves, keyMove{key: key, md: md, route: route{shard: current.owner(rk), from: name}})
+			}
+		}
+	}
+	if unknown > 0 {
+		// The previous ring is kept, so the keys are still found in their previous shard
+		return fmt.Errorf("%w: %d keys can't be moved to shard %s", ErrUnknownPartitionKey, unknown, adding)
+	}
+
+	for _, m := range moves {
+		err := s.move(ctx, m.key, m.md, m.route)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+	s.previous = nil
+	s.adding = ""
+	s.lock.Unlock()
+	s.logger.Infof("Moved %d keys to shard %s", len(moves), adding)
+	return nil
+}
+
+// keyMove is a key that Rebalance moves to the shard that was added.
+type keyMove struct {
+	key   string
+	md    map[string]string
+	route route
+}
+
+// move moves the key from the shard it was assigned to, if it's still there, to its new shard.
+// Values are copied with their expiration time, while their ETags change.
+func (s *Store) move(ctx context.Context, key string, md map[string]string, r route) error {
+	l := &s.moveLocks[xxhash.Sum64String(key)%moveLockCount]
+	l.Lock()
+	defer l.Unlock()
+
+	from, to := s.shards[r.from], s.shards[r.shard]
+	res, err := from.Get(ctx, &state.GetRequest{Key: key, Metadata: md})
+	if err != nil {
+		return fmt.Errorf("failed to read key %s from shard %s: %w", key, r.from, err)
+	}
+	if !found(res) {
+		return nil
+	}
+
+	rec, err := state.NewSnapshotRecord(&state.BulkGetResponse{
+		Key:         key,
+		Data:        res.Data,
+		ETag:        res.ETag,
+		Metadata:    res.Metadata,
+		ContentType: res.ContentType,
+	})
+	if err != nil {
+		return err
+	}
+	req, ok, err := rec.SetRequest()
+	if err != nil {
+		return err
+	}
+	// Expired keys are only removed from the previous shard
+	if ok {
+		if pk := md[partitionKeyMetadata]; pk != "" {
+			if req.Metadata == nil {
+				req.Metadata = make(map[string]string, 1)
+			}
+			req.Metadata[partitionKeyMetadata] = pk
+		}
+		err = to.Set(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("failed to move key %s to shard %s: %w", key, r.shard, err)
+		}
+	}
+	err = from.Delete(ctx, &state.DeleteRequest{Key: key, Metadata: md})
+	if err != nil {
+		return fmt.Errorf("failed to delete key %s from shard %s: %w", key, r.from, err)
+	}
+	return nil
+}
+
+// listKeys returns all the keys of the store.
+func listKeys(ctx context.Context, store state.Store) ([]string, error) {
+	keysLiker, ok := store.(state.KeysLiker)
+	if !ok {
+		return nil, errors.New("keys like is not supported by the state store")
+	}
+
+	var (
+		keys  []string
+		token *string
+	)
+	for {
+		res, err := keysLiker.KeysLike(ctx, &state.KeysLikeRequest{
+			Pattern:           "%",
+			ContinuationToken: token,
+			PageSize:          ptr.Of(uint32(rebalancePageSize)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, res.Keys...)
+		if res.ContinuationToken == nil || *res.ContinuationToken == "" {
+			return keys, nil
+		}
+		token = res.ContinuationToken
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// ring is a consistent hash ring, where each shard is placed at multiple points (virtual nodes).
+// A key belongs to the shard of the first point at or after its hash, wrapping around.
+type ring struct {
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard string
+}
+
+// newRing returns a ring with vnodes points for each shard.
+// The points of a shard depend only on its name, so adding a shard moves only the keys that are assigned to it.
+func newRing(shards []string, vnodes int) *ring {
+	r := &ring{
+		points: make([]ringPoint, 0, len(shards)*vnodes),
+	}
+	for _, name := range shards {
+		for i := range vnodes {
+			r.points = append(r.points, ringPoint{
+				hash:  xxhash.Sum64String(name + "#" + strconv.Itoa(i)),
+				shard: name,
+			})
+		}
+	}
+	// Ties, which are very unlikely, are broken by shard name so the ring doesn't depend on the order of the shards
+	slices.SortFunc(r.points, func(a, b ringPoint) int {
+		if c := cmp.Compare(a.hash, b.hash); c != 0 {
+			return c
+		}
+		return strings.Compare(a.shard, b.shard)
+	})
+	return r
+}
+
+// owner returns the name of the shard the key belongs to.
+func (r *ring) owner(key string) string {
+	h := xxhash.Sum64String(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].shard
+}
@@ -0,0 +1,538 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding implements a state store that distributes the keys over multiple state stores with consistent hashing.
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// Default number of virtual nodes of each shard on the hash ring.
+	defaultVirtualNodes = 128
+	// Metadata property with the partition key of requests, which assigns all the keys with the same partition key to the same shard.
+	partitionKeyMetadata = "partitionKey"
+)
+
+var (
+	// ErrCrossShardTransaction is returned by Multi when the keys of the transaction are assigned to different shards.
+	ErrCrossShardTransaction = errors.New("the keys of the transaction belong to different shards: set the same partitionKey metadata on all the operations")
+	// ErrUnknownPartitionKey is returned by Rebalance when keys saved with a partition key can't be moved, because their partition key is unknown.
+	ErrUnknownPartitionKey = errors.New("the partition keys of keys saved with the partitionKey metadata are unknown: set Options.PartitionKey to move them")
+)
+
+// Features that are supported if all the shards support them.
+var shardFeatures = []state.Feature{
+	state.FeatureETag,
+	state.FeatureTransactional,
+	state.FeatureTTL,
+	state.FeatureTTLManagement,
+}
+
+// Shard is one of the state stores of a sharded store.
+type Shard struct {
+	// Name of the shard, which determines the keys that are assigned to it.
+	// It must not change, or the keys of the shard would be assigned to other shards.
+	Name string
+	// State store of the shard, which must be initialized.
+	Store state.Store
+}
+
+// Options contains the options for the sharded store.
+type Options struct {
+	// Shards the keys are distributed over.
+	Shards []Shard
+	// Number of points of each shard on the hash ring; more points distribute the keys more evenly.
+	// Defaults to 128.
+	VirtualNodes int
+	// Returns the partition key of a key saved with the partitionKey metadata, or an empty string if it was saved without it.
+	// Partition keys aren't stored with the values, so it's required to move the keys saved with a partition key when a shard is added.
+	PartitionKey func(key string) string
+	// Logger for the progress of rebalances.
+	Logger logger.Logger
+}
+
+// Store is a state store that distributes the keys over multiple state stores (shards) with consistent hashing.
+// Each key is assigned to a shard by the hash of the partitionKey metadata of the request, if set, or of the key otherwise;
+// keys saved with a partition key must be read with the same partition key.
+// BulkGet, BulkSet and BulkDelete are split by shard and executed in parallel, while transactions are executed only if all their keys are assigned to the same shard.
+//
+// Shards can be added while the store is in use with AddShard, which moves the keys that are assigned to the new shard.
+// Queries, KeysLike, Watch and atomic operations are not supported.
+type Store struct {
+	vnodes       int
+	logger       logger.Logger
+	partitionKey func(key string) string
+
+	lock   sync.RWMutex
+	shards map[string]state.Store
+	names  []string
+	ring   *ring
+	// Ring before the last shard was added, while keys are moved to the new shard; nil otherwise
+	previous *ring
+	// Name of the shard that is being added
+	adding string
+
+	// Serializes rebalances
+	rebalanceLock sync.Mutex
+	// Serialize the moves of the keys, by hash of the key
+	moveLocks [moveLockCount]sync.Mutex
+}
+
+// route is the shard a key is assigned to.
+type route struct {
+	shard string
+	// Shard the key is being moved from, while the store is rebalanced; empty otherwise
+	from string
+}
+
+// NewStore returns a store that distributes the keys over the shards in opts.
+func NewStore(opts Options) (*Store, error) {
+	if len(opts.Shards) == 0 {
+		return nil, errors.New("at least one shard is required")
+	}
+	if opts.VirtualNodes < 0 {
+		return nil, errors.New("the number of virtual nodes must not be negative")
+	}
+	if opts.VirtualNodes == 0 {
+		opts.VirtualNodes = defaultVirtualNodes
+	}
+	if opts.Logger == nil {
+		opts.Logger = logger.NewLogger("dapr.state.sharding")
+	}
+
+	s := &Store{
+		vnodes:       opts.VirtualNodes,
+		logger:       opts.Logger,
+		partitionKey: opts.PartitionKey,
+		shards:       make(map[string]state.Store, len(opts.Shards)),
+		names:        make([]string, 0, len(opts.Shards)),
+	}
+	for _, shard := range opts.Shards {
+		if err := s.validateShard(shard); err != nil {
+			return nil, err
+		}
+		s.shards[shard.Name] = shard.Store
+		s.names = append(s.names, shard.Name)
+	}
+	s.ring = newRing(s.names, s.vnodes)
+	return s, nil
+}
+
+func (s *Store) validateShard(shard Shard) error {
+	if shard.Name == "" {
+		return errors.New("shard name is required")
+	}
+	if shard.Store == nil {
+		return fmt.Errorf("state store of shard %s is required", shard.Name)
+	}
+	if _, ok := s.shards[shard.Name]; ok {
+		return fmt.Errorf("duplicate shard %s", shard.Name)
+	}
+	return nil
+}
+
+// Init does nothing, as the shards are initialized when they're added.
+func (s *Store) Init(context.Context, state.Metadata) error {
+	return nil
+}
+
+// Features returns the features supported by all the shards.
+// Partition keys are always supported, as they determine the shards of the keys.
+func (s *Store) Features() []state.Feature {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	features := []state.Feature{state.FeaturePartitionKey}
+	for _, f := range shardFeatures {
+		supported := true
+		for _, store := range s.shards {
+			if !f.IsPresent(store.Features()) {
+				supported = false
+				break
+			}
+		}
+		if supported {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// Get retrieves the value of a key from its shard.
+func (s *Store) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r := s.route(req.Key, req.Metadata)
+	if r.from != "" {
+		// Keys are written to the new shard only after they're removed from the previous one, so if the key is still there its value is current
+		res, err := s.shards[r.from].Get(ctx, req)
+		if err != nil || found(res) {
+			return res, err
+		}
+	}
+	return s.shards[r.shard].Get(ctx, req)
+}
+
+// Set saves the value of a key in its shard.
+func (s *Store) Set(ctx context.Context, req *state.SetRequest) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	store, err := s.prepareWrite(ctx, req.Key, req.Metadata)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, req)
+}
+
+// Delete deletes a key from its shard.
+func (s *Store) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	store, err := s.prepareWrite(ctx, req.Key, req.Metadata)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, req)
+}
+
+// BulkGet retrieves the values of multiple keys, reading from the shards in parallel.
+func (s *Store) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	// Keys that are being moved are read from their previous shard first, like in Get
+	var (
+		groups = map[string][]state.GetRequest{}
+		moving = map[string][]state.GetRequest{}
+		routes = make(map[string]route, len(req))
+	)
+	for _, r := range req {
+		rt := s.route(r.Key, r.Metadata)
+		routes[r.Key] = rt
+		if rt.from != "" {
+			moving[rt.from] = append(moving[rt.from], r)
+		} else {
+			groups[rt.shard] = append(groups[rt.shard], r)
+		}
+	}
+
+	res := make([]state.BulkGetResponse, 0, len(req))
+	if len(moving) > 0 {
+		items, err := s.bulkGet(ctx, moving, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Error == "" && item.Data == nil && item.ETag == nil {
+				rt := routes[item.Key]
+				groups[rt.shard] = append(groups[rt.shard], state.GetRequest{Key: item.Key, Metadata: metadataOf(req, item.Key)})
+			} else {
+				res = append(res, item)
+			}
+		}
+	}
+	items, err := s.bulkGet(ctx, groups, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, items...), nil
+}
+
+func (s *Store) bulkGet(ctx context.Context, groups map[string][]state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	var (
+		lock sync.Mutex
+		res  []state.BulkGetResponse
+	)
+	err := parallel(groups, func(shard string, req []state.GetRequest) error {
+		items, err := s.shards[shard].BulkGet(ctx, req, opts)
+		if err != nil {
+			return fmt.Errorf("shard %s: %w", shard, err)
+		}
+		lock.Lock()
+		res = append(res, items...)
+		lock.Unlock()
+		return nil
+	})
+	return res, err
+}
+
+// BulkSet saves the values of multiple keys, writing to the shards in parallel.
+// The values saved in a shard are not affected by the failure of another shard.
+func (s *Store) BulkSet(ctx context.Context, req []state.SetRequest, opts state.BulkStoreOpts) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	groups := map[string][]state.SetRequest{}
+	moving := map[string]route{}
+	for _, r := range req {
+		rt := s.route(r.Key, r.Metadata)
+		groups[rt.shard] = append(groups[rt.shard], r)
+		if rt.from != "" {
+			moving[r.Key] = rt
+		}
+	}
+	return parallel(groups, func(shard string, req []state.SetRequest) error {
+		for _, r := range req {
+			if rt, ok := moving[r.Key]; ok {
+				if err := s.move(ctx, r.Key, r.Metadata, rt); err != nil {
+					return err
+				}
+			}
+		}
+		return s.shards[shard].BulkSet(ctx, req, opts)
+	})
+}
+
+// BulkDelete deletes multiple keys, deleting from the shards in parallel.
+// The keys deleted from a shard are not affected by the failure of another shard.
+func (s *Store) BulkDelete(ctx context.Context, req []state.DeleteRequest, opts state.BulkStoreOpts) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	groups := map[string][]state.DeleteRequest{}
+	moving := map[string]route{}
+	for _, r := range req {
+		rt := s.route(r.Key, r.Metadata)
+		groups[rt.shard] = append(groups[rt.shard], r)
+		if rt.from != "" {
+			moving[r.Key] = rt
+		}
+	}
+	return parallel(groups, func(shard string, req []state.DeleteRequest) error {
+		for _, r := range req {
+			if rt, ok := moving[r.Key]; ok {
+				if err := s.move(ctx, r.Key, r.Metadata, rt); err != nil {
+					return err
+				}
+			}
+		}
+		return s.shards[shard].BulkDelete(ctx, req, opts)
+	})
+}
+
+// Multi executes the transaction in the shard of its keys.
+// It fails with ErrCrossShardTransaction if the keys are assigned to different shards; the partitionKey metadata of the request applies to all the operations.
+// Outbox messages are saved in the shard of the keys of the transaction, so the outbox of each shard must be relayed.
+func (s *Store) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	if request == nil || len(request.Operations) == 0 {
+		return nil
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	routes := make([]route, len(request.Operations))
+	// Index of the operation that determines the shard: the first one that modifies a key, if any
+	first := -1
+	for i, op := range request.Operations {
+		md := op.GetMetadata()
+		if md[partitionKeyMetadata] == "" && request.Metadata[partitionKeyMetadata] != "" {
+			md = map[string]string{partitionKeyMetadata: request.Metadata[partitionKeyMetadata]}
+		}
+		routes[i] = s.route(op.GetKey(), md)
+		if op.Operation() == state.OperationOutbox {
+			continue
+		}
+		if first < 0 {
+			first = i
+		} else if routes[i].shard != routes[first].shard {
+			return ErrCrossShardTransaction
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	transactional, ok := s.shards[routes[first].shard].(state.TransactionalStore)
+	if !ok {
+		return errors.New("transactions are not supported by the state store")
+	}
+	for i, op := range request.Operations {
+		if routes[i].from != "" && op.Operation() != state.OperationOutbox {
+			if err := s.move(ctx, op.GetKey(), op.GetMetadata(), routes[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return transactional.Multi(ctx, request)
+}
+
+// MultiMaxSize returns the lowest maximum number of operations in a transaction of the shards.
+func (s *Store) MultiMaxSize() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	size := -1
+	for _, store := range s.shards {
+		if m, ok := store.(state.TransactionalStoreMultiMaxSize); ok {
+			if n := m.MultiMaxSize(); n > 0 && (size < 0 || n < size) {
+				size = n
+			}
+		}
+	}
+	return size
+}
+
+// Expire changes the expiration time of a key in its shard.
+// The request doesn't have metadata, so keys saved with a partition key are not supported.
+func (s *Store) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	store, err := s.prepareWrite(ctx, req.Key, nil)
+	if err != nil {
+		return nil, err
+	}
+	ttl, ok := store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.Expire(ctx, req)
+}
+
+// GetTTL returns the expiration time of a key from its shard.
+// The request doesn't have metadata, so keys saved with a partition key are not supported.
+func (s *Store) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r := s.route(req.Key, nil)
+	if r.from != "" {
+		if ttl, ok := s.shards[r.from].(state.TTLManager); ok {
+			res, err := ttl.GetTTL(ctx, req)
+			if err != nil || res.Found {
+				return res, err
+			}
+		}
+	}
+	ttl, ok := s.shards[r.shard].(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.GetTTL(ctx, req)
+}
+
+// Ping pings all the shards that support it.
+func (s *Store) Ping(ctx context.Context) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var errs []error
+	for _, name := range s.names {
+		if pinger, ok := s.shards[name].(health.Pinger); ok {
+			if err := pinger.Ping(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("shard %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetComponentMetadata returns nil, as the sharded store doesn't have metadata.
+func (s *Store) GetComponentMetadata() metadata.MetadataMap {
+	return nil
+}
+
+// Close closes all the shards.
+func (s *Store) Close() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var errs []error
+	for _, name := range s.names {
+		if err := s.shards[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("shard %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// route returns the shard of the key, and the shard it's moved from if the store is being rebalanced.
+// It must be called with the lock held.
+func (s *Store) route(key string, md map[string]string) route {
+	rk := key
+	if pk := md[partitionKeyMetadata]; pk != "" {
+		rk = pk
+	}
+	r := route{shard: s.ring.owner(rk)}
+	if s.previous != nil {
+		if prev := s.previous.owner(rk); prev != r.shard {
+			r.from = prev
+		}
+	}
+	return r
+}
+
+// prepareWrite returns the shard of the key, after moving the key to it if the store is being rebalanced.
+// It must be called with the lock held.
+func (s *Store) prepareWrite(ctx context.Context, key string, md map[string]string) (state.Store, error) {
+	r := s.route(key, md)
+	if r.from != "" {
+		if err := s.move(ctx, key, md, r); err != nil {
+			return nil, err
+		}
+	}
+	return s.shards[r.shard], nil
+}
+
+// found returns true if the response contains a value.
+func found(res *state.GetResponse) bool {
+	return res != nil && (res.Data != nil || res.ETag != nil)
+}
+
+// metadataOf returns the metadata of the request for the key.
+func metadataOf(req []state.GetRequest, key string) map[string]string {
+	i := slices.IndexFunc(req, func(r state.GetRequest) bool {
+		return r.Key == key
+	})
+	if i < 0 {
+		return nil
+	}
+	return req[i].Metadata
+}
+
+// parallel invokes fn for the items of each shard in parallel, and returns the errors.
+func parallel[T any](groups map[string][]T, fn func(shard string, items []T) error) error {
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs []error
+	)
+	for shard, items := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(shard, items); err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+func newShard(t *testing.T, name string) Shard {
+	t.Helper()
+	store := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+	require.NoError(t, store.Init(t.Context(), state.Metadata{}))
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return Shard{Name: name, Store: store}
+}
+
+func newTestStore(t *testing.T, names ...string) *Store {
+	t.Helper()
+	shards := make([]Shard, len(names))
+	for i, name := range names {
+		shards[i] = newShard(t, name)
+	}
+	s, err := NewStore(Options{Shards: shards, Logger: logger.NewLogger("test")})
+	require.NoError(t, err)
+	return s
+}
+
+// shardOf returns the name of the shard that contains the key, failing if it's in more than one.
+func shardOf(t *testing.T, s *Store, key string) string {
+	t.Helper()
+	var name string
+	for n, store := range s.shards {
+		res, err := store.Get(t.Context(), &state.GetRequest{Key: key})
+		require.NoError(t, err)
+		if res.Data != nil {
+			require.Empty(t, name, "key %s is in shards %s and %s", key, name, n)
+			name = n
+		}
+	}
+	return name
+}
+
+func TestInvalidOptions(t *testing.T) {
+	shard := newShard(t, "a")
+
+	tests := map[string]struct {
+		opts        Options
+		expectedErr string
+	}{
+		"No shards": {
+			opts:        Options{},
+			expectedErr: "at least one shard is required",
+		},
+		"Negative virtual nodes": {
+			opts:        Options{Shards: []Shard{shard}, VirtualNodes: -1},
+			expectedErr: "must not be negative",
+		},
+		"Duplicate shard": {
+			opts:        Options{Shards: []Shard{shard, shard}},
+			expectedErr: "duplicate shard a",
+		},
+		"Missing shard name": {
+			opts:        Options{Shards: []Shard{{Store: shard.Store}}},
+			expectedErr: "shard name is required",
+		},
+		"Missing shard store": {
+			opts:        Options{Shards: []Shard{{Name: "a"}}},
+			expectedErr: "state store of shard a is required",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStore(tt.opts)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestShardedStore(t *testing.T) {
+	s := newTestStore(t, "a", "b", "c")
+
+	features := s.Features()
+	assert.Contains(t, features, state.FeatureETag)
+	assert.Contains(t, features, state.FeatureTransactional)
+	assert.Contains(t, features, state.FeaturePartitionKey)
+
+	t.Run("keys are distributed over the shards", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := range 300 {
+			key := "key" + strconv.Itoa(i)
+			require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: i}))
+			shard := shardOf(t, s, key)
+			assert.Equal(t, s.ring.owner(key), shard)
+			counts[shard]++
+
+			res, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(i), string(res.Data))
+		}
+		require.Len(t, counts, 3)
+		for _, n := range counts {
+			assert.Greater(t, n, 50)
+		}
+	})
+
+	t.Run("bulk operations", func(t *testing.T) {
+		req := make([]state.SetRequest, 20)
+		for i := range req {
+			req[i] = state.SetRequest{Key: "bulk" + strconv.Itoa(i), Value: "v" + strconv.Itoa(i)}
+		}
+		require.NoError(t, s.BulkSet(t.Context(), req, state.BulkStoreOpts{}))
+
+		res, err := s.BulkGet(t.Context(), []state.GetRequest{{Key: "bulk1"}, {Key: "bulk2"}, {Key: "bulk3"}, {Key: "missing"}}, state.BulkGetOpts{})
+		require.NoError(t, err)
+		require.Len(t, res, 4)
+		for _, r := range res {
+			if r.Key == "missing" {
+				assert.Nil(t, r.Data)
+			} else {
+				assert.Equal(t, `"v`+r.Key[4:]+`"`, string(r.Data))
+			}
+		}
+
+		require.NoError(t, s.BulkDelete(t.Context(), []state.DeleteRequest{{Key: "bulk1"}, {Key: "bulk2"}}, state.BulkStoreOpts{}))
+		assert.Empty(t, shardOf(t, s, "bulk1"))
+		assert.Empty(t, shardOf(t, s, "bulk2"))
+		assert.NotEmpty(t, shardOf(t, s, "bulk3"))
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		// Find two keys in different shards
+		k1, k2 := "tx0", ""
+		for i := 1; k2 == ""; i++ {
+			if k := "tx" + strconv.Itoa(i); s.ring.owner(k) != s.ring.owner(k1) {
+				k2 = k
+			}
+		}
+
+		err := s.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: k1, Value: "1"},
+				state.SetRequest{Key: k2, Value: "2"},
+			},
+		})
+		require.ErrorIs(t, err, ErrCrossShardTransaction)
+
+		err = s.Multi(t.Context(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				state.SetRequest{Key: k1, Value: "1"},
+				state.SetRequest{Key: k2, Value: "2"},
+			},
+			Metadata: map[string]string{partitionKeyMetadata: "order1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, s.ring.owner("order1"), shardOf(t, s, k1))
+		assert.Equal(t, s.ring.owner("order1"), shardOf(t, s, k2))
+
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: k2, Metadata: map[string]string{partitionKeyMetadata: "order1"}})
+		require.NoError(t, err)
+		assert.Equal(t, `"2"`, string(res.Data))
+	})
+}
+
+func TestAddShard(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	const n = 300
+	for i := range n {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "key" + strconv.Itoa(i), Value: i}))
+	}
+	before := map[string]string{}
+	for i := range n {
+		key := "key" + strconv.Itoa(i)
+		before[key] = shardOf(t, s, key)
+	}
+
+	t.Run("keys are moved while the store is in use", func(t *testing.T) {
+		require.NoError(t, s.addShard(newShard(t, "c")))
+
+		var moving []string
+		for key, shard := range before {
+			if s.ring.owner(key) == "c" {
+				moving = append(moving, key)
+				// Keys that haven't been moved are read from their previous shard
+				assert.Equal(t, shard, shardOf(t, s, key))
+				res, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+				require.NoError(t, err)
+				assert.Equal(t, key[3:], string(res.Data))
+			}
+		}
+		require.NotEmpty(t, moving)
+
+		// Keys are moved before they're modified
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: moving[0], Value: "updated"}))
+		assert.Equal(t, "c", shardOf(t, s, moving[0]))
+		require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: moving[1]}))
+		assert.Empty(t, shardOf(t, s, moving[1]))
+
+		require.ErrorContains(t, s.addShard(newShard(t, "d")), "are being moved")
+
+		require.NoError(t, s.Rebalance(t.Context()))
+		assert.Nil(t, s.previous)
+		for key, shard := range before {
+			switch {
+			case key == moving[1]:
+				assert.Empty(t, shardOf(t, s, key))
+			case s.ring.owner(key) == "c":
+				assert.Equal(t, "c", shardOf(t, s, key))
+			default:
+				// Keys that aren't assigned to the new shard are not moved
+				assert.Equal(t, shard, shardOf(t, s, key))
+			}
+		}
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: moving[0]})
+		require.NoError(t, err)
+		assert.Equal(t, `"updated"`, string(res.Data))
+	})
+
+	t.Run("add another shard", func(t *testing.T) {
+		require.NoError(t, s.AddShard(t.Context(), newShard(t, "d")))
+		var inD int
+		for key := range before {
+			shard := shardOf(t, s, key)
+			if shard != "" {
+				assert.Equal(t, s.ring.owner(key), shard)
+			}
+			if shard == "d" {
+				inD++
+			}
+		}
+		assert.Positive(t, inD)
+	})
+}
+
+func TestRebalancePartitionKeys(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	const n = 100
+	pkOf := func(key string) string {
+		return "group" + key[3:]
+	}
+	for i := range n {
+		key := "key" + strconv.Itoa(i)
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: i, Metadata: map[string]string{"partitionKey": pkOf(key)}}))
+	}
+	get := func(t *testing.T, key string) string {
+		t.Helper()
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: key, Metadata: map[string]string{"partitionKey": pkOf(key)}})
+		require.NoError(t, err)
+		return string(res.Data)
+	}
+
+	t.Run("the rebalance isn't completed without the partition keys", func(t *testing.T) {
+		err := s.AddShard(t.Context(), newShard(t, "c"))
+		require.ErrorIs(t, err, ErrUnknownPartitionKey)
+		assert.NotNil(t, s.previous)
+
+		// Keys that haven't been moved are still read from their previous shard
+		for i := range n {
+			assert.Equal(t, strconv.Itoa(i), get(t, "key"+strconv.Itoa(i)))
+		}
+	})
+
+	t.Run("keys are moved with their partition keys", func(t *testing.T) {
+		s.partitionKey = func(key string) string {
+			return pkOf(key)
+		}
+		require.NoError(t, s.Rebalance(t.Context()))
+		assert.Nil(t, s.previous)
+
+		var inC int
+		for i := range n {
+			key := "key" + strconv.Itoa(i)
+			assert.Equal(t, s.ring.owner(pkOf(key)), shardOf(t, s, key))
+			assert.Equal(t, strconv.Itoa(i), get(t, key))
+			if s.ring.owner(pkOf(key)) == "c" {
+				inC++
+			}
+		}
+		assert.Positive(t, inC)
+	})
+}
+
+func TestRebalanceConcurrentWrites(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	const n = 300
+	for i := range n {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "key" + strconv.Itoa(i), Value: 0}))
+	}
+
+	// Keys are modified and deleted while they're moved
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < n; i += 4 {
+				key := "key" + strconv.Itoa(i)
+				if i%10 == 0 {
+					assert.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: key}))
+					continue
+				}
+				assert.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: i}))
+			}
+		}()
+	}
+	require.NoError(t, s.AddShard(t.Context(), newShard(t, "c")))
+	wg.Wait()
+
+	for i := range n {
+		key := "key" + strconv.Itoa(i)
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+		require.NoError(t, err)
+		if i%10 == 0 {
+			assert.Nil(t, res.Data, key)
+			assert.Empty(t, shardOf(t, s, key))
+			continue
+		}
+		assert.Equal(t, strconv.Itoa(i), string(res.Data), key)
+		assert.Equal(t, s.ring.owner(key), shardOf(t, s, key))
+	}
+}
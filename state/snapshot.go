@@ -0,0 +1,330 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	// Number of keys exported in each page by Export.
+	defaultExportPageSize = 1000
+	// Number of records saved in each batch by Import.
+	defaultImportBatchSize = 100
+)
+
+// SnapshotRecord is a record of a snapshot created by Export, which contains the value of a key.
+// Snapshots are streams of records serialized as JSON, one per line (NDJSON).
+type SnapshotRecord struct {
+	Key string `json:"key"`
+	// Value of the key, if it's a JSON document.
+	Value json.RawMessage `json:"value,omitempty"`
+	// Value of the key, if it's not a JSON document; it's serialized as base64.
+	Data []byte `json:"data,omitempty"`
+	// ETag of the value in the exported store, for reference; it's not preserved by Import.
+	ETag        *string    `json:"etag,omitempty"`
+	ContentType *string    `json:"contentType,omitempty"`
+	ExpireTime  *time.Time `json:"expireTime,omitempty"`
+}
+
+// BulkSetter is implemented by state stores that can save values in bulk.
+type BulkSetter interface {
+	BulkSet(ctx context.Context, req []SetRequest, opts BulkStoreOpts) error
+}
+
+// ExportOptions contains the options for Export.
+type ExportOptions struct {
+	// Pattern of the keys to export, in the format of KeysLikeRequest.
+	// Defaults to all keys.
+	Pattern string
+	// Number of keys retrieved in each page.
+	// Defaults to 1000.
+	PageSize uint32
+	// Continuation token returned by a previous export, to resume it.
+	ContinuationToken *string
+}
+
+// ExportResponse is the response of Export.
+type ExportResponse struct {
+	// Number of records written.
+	Count int64
+	// Token to resume the export after the last page that was written completely, if the export has been interrupted.
+	// It's nil if all keys have been exported.
+	ContinuationToken *string
+}
+
+// ImportOptions contains the options for Import.
+type ImportOptions struct {
+	// Number of records saved in each batch.
+	// Defaults to 100, or to the maximum number of operations in a transaction if that's lower.
+	BatchSize int
+	// If true and the store supports transactions, each batch is saved in a transaction with Multi.
+	// Otherwise, records are saved with BulkSet.
+	Transactional bool
+	// Continuation token returned by a previous import of the same snapshot, to resume it.
+	ContinuationToken *string
+}
+
+// ImportResponse is the response of Import.
+type ImportResponse struct {
+	// Number of records saved.
+	Count int64
+	// Number of records skipped because they are expired.
+	Expired int64
+	// Token to resume the import after the last batch that was saved, if the import has been interrupted.
+	// It's nil if all records have been imported.
+	ContinuationToken *string
+}
+
+// Export writes the values of the keys in the store to w, as a snapshot that can be imported in any store with Import.
+// Keys are listed page by page with KeysLike and their values are retrieved with BulkGet, so keys modified during the export may or may not be included.
+// If the export fails, the response contains the token to resume it after the last page that was written.
+func Export(ctx context.Context, store KeysLikeBulkGetter, w io.Writer, opts ExportOptions) (ExportResponse, error) {
+	if opts.Pattern == "" {
+		opts.Pattern = "%"
+	}
+	if opts.PageSize == 0 {
+		opts.PageSize = defaultExportPageSize
+	}
+
+	var res ExportResponse
+	enc := json.NewEncoder(w)
+	token := opts.ContinuationToken
+	for {
+		keys, err := store.KeysLike(ctx, &KeysLikeRequest{
+			Pattern:           opts.Pattern,
+			ContinuationToken: token,
+			PageSize:          &opts.PageSize,
+		})
+		if err != nil {
+			res.ContinuationToken = token
+			return res, fmt.Errorf("failed to list keys: %w", err)
+		}
+
+		if len(keys.Keys) > 0 {
+			records, err := exportRecords(ctx, store, keys.Keys)
+			if err != nil {
+				res.ContinuationToken = token
+				return res, err
+			}
+			for i := range records {
+				if err = enc.Encode(&records[i]); err != nil {
+					// The page has been written partially, so it's written again when the export is resumed
+					res.ContinuationToken = token
+					return res, fmt.Errorf("failed to write snapshot: %w", err)
+				}
+				res.Count++
+			}
+		}
+
+		if keys.ContinuationToken == nil || *keys.ContinuationToken == "" {
+			return res, nil
+		}
+		token = keys.ContinuationToken
+	}
+}
+
+// exportRecords returns the records for the keys.
+// Keys that have been deleted after they were listed are skipped.
+func exportRecords(ctx context.Context, store KeysLikeBulkGetter, keys []string) ([]SnapshotRecord, error) {
+	req := make([]GetRequest, len(keys))
+	for i, k := range keys {
+		req[i] = GetRequest{Key: k}
+	}
+	items, err := store.BulkGet(ctx, req, BulkGetOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve values: %w", err)
+	}
+
+	records := make([]SnapshotRecord, 0, len(items))
+	for _, item := range items {
+		if item.Error != "" {
+			return nil, fmt.Errorf("failed to retrieve value of key %s: %s", item.Key, item.Error)
+		}
+		if item.Data == nil {
+			continue
+		}
+
+		rec, err := NewSnapshotRecord(&item)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// NewSnapshotRecord returns the record for a value retrieved with Get or BulkGet.
+func NewSnapshotRecord(item *BulkGetResponse) (SnapshotRecord, error) {
+	rec := SnapshotRecord{
+		Key:         item.Key,
+		ETag:        item.ETag,
+		ContentType: item.ContentType,
+	}
+	if json.Valid(item.Data) {
+		rec.Value = item.Data
+	} else {
+		rec.Data = item.Data
+	}
+	if exp, ok := item.Metadata[GetRespMetaKeyTTLExpireTime]; ok {
+		expireTime, err := time.Parse(time.RFC3339, exp)
+		if err != nil {
+			return SnapshotRecord{}, fmt.Errorf("invalid expiration time for key %s: %w", item.Key, err)
+		}
+		rec.ExpireTime = &expireTime
+	}
+	return rec, nil
+}
+
+// Import saves the records of a snapshot created by Export in the store.
+// The ETags of the records are not preserved, and existing values are overwritten.
+// Records that are expired are skipped, and the other records are saved with the remaining TTL, rounded up to the second.
+// If the import fails, the response contains the token to resume it after the last batch that was saved.
+func Import(ctx context.Context, store BulkSetter, r io.Reader, opts ImportOptions) (ImportResponse, error) {
+	var res ImportResponse
+
+	var skip int64
+	if opts.ContinuationToken != nil && *opts.ContinuationToken != "" {
+		var err error
+		skip, err = strconv.ParseInt(*opts.ContinuationToken, 10, 64)
+		if err != nil || skip < 0 {
+			return res, errors.New("invalid continuation token")
+		}
+	}
+
+	transactional, _ := store.(TransactionalStore)
+	if !opts.Transactional {
+		transactional = nil
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	if m, ok := store.(TransactionalStoreMultiMaxSize); ok && transactional != nil && m.MultiMaxSize() > 0 {
+		opts.BatchSize = min(opts.BatchSize, m.MultiMaxSize())
+	}
+
+	// Position in the snapshot of the records that have been saved
+	saved := skip
+	var read int64
+	batch := make([]SetRequest, 0, opts.BatchSize)
+	flush := func() error {
+		if len(batch) > 0 {
+			var err error
+			if transactional != nil {
+				ops := make([]TransactionalStateOperation, len(batch))
+				for i := range batch {
+					ops[i] = batch[i]
+				}
+				err = transactional.Multi(ctx, &TransactionalStateRequest{Operations: ops})
+			} else {
+				err = store.BulkSet(ctx, batch, BulkStoreOpts{})
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save values: %w", err)
+			}
+			res.Count += int64(len(batch))
+			batch = batch[:0]
+		}
+		saved = read
+		return nil
+	}
+	fail := func(err error) (ImportResponse, error) {
+		token := strconv.FormatInt(saved, 10)
+		res.ContinuationToken = &token
+		return res, err
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var rec SnapshotRecord
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fail(fmt.Errorf("failed to read record %d of snapshot: %w", read+1, err))
+		}
+		read++
+		if read <= skip {
+			continue
+		}
+
+		req, ok, err := rec.SetRequest()
+		if err != nil {
+			return fail(fmt.Errorf("invalid record %d of snapshot: %w", read, err))
+		}
+		if !ok {
+			res.Expired++
+		} else {
+			batch = append(batch, req)
+		}
+		if len(batch) >= opts.BatchSize {
+			if err = flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+	return res, nil
+}
+
+// SetRequest returns the request to save the record.
+// It returns false if the record is expired.
+func (rec *SnapshotRecord) SetRequest() (SetRequest, bool, error) {
+	if rec.Key == "" {
+		return SetRequest{}, false, errors.New("key is empty")
+	}
+
+	req := SetRequest{
+		Key:         rec.Key,
+		ContentType: rec.ContentType,
+	}
+	// JSON values are saved as JSON documents, and other values as binary data
+	if len(rec.Value) > 0 {
+		req.Value = rec.Value
+	} else {
+		req.Value = rec.Data
+		if req.Value == nil {
+			req.Value = []byte{}
+		}
+	}
+
+	md := map[string]string{}
+	if rec.ContentType != nil {
+		md[metadata.ContentType] = *rec.ContentType
+	}
+	if rec.ExpireTime != nil {
+		ttl := time.Until(*rec.ExpireTime)
+		if ttl <= 0 {
+			return SetRequest{}, false, nil
+		}
+		md[metadata.TTLInSecondsMetadataKey] = strconv.FormatInt(int64(math.Ceil(ttl.Seconds())), 10)
+	}
+	if len(md) > 0 {
+		req.Metadata = md
+	}
+	return req, true, nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/kit/ptr"
+)
+
+func TestExport(t *testing.T) {
+	expire := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	s := &storeKeysLike{
+		keys: []string{"k1", "k2", "k3", "k4", "k5"},
+		values: map[string]string{
+			"k1": `{"a":1}`,
+			"k2": "not json",
+			"k3": `"string"`,
+			// k4 was deleted after listing the keys
+			"k5": `5`,
+		},
+	}
+
+	t.Run("all keys", func(t *testing.T) {
+		var buf bytes.Buffer
+		res, err := Export(t.Context(), s, &buf, ExportOptions{PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), res.Count)
+		assert.Nil(t, res.ContinuationToken)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.JSONEq(t, `{"key":"k1","value":{"a":1},"etag":"etag-k1"}`, lines[0])
+		assert.JSONEq(t, `{"key":"k2","data":"bm90IGpzb24=","etag":"etag-k2"}`, lines[1])
+		assert.JSONEq(t, `{"key":"k3","value":"string","etag":"etag-k3"}`, lines[2])
+		assert.JSONEq(t, `{"key":"k5","value":5,"etag":"etag-k5"}`, lines[3])
+	})
+
+	t.Run("resume after an error", func(t *testing.T) {
+		s.values["k3"] = "error"
+		defer func() { s.values["k3"] = `"string"` }()
+
+		var buf bytes.Buffer
+		res, err := Export(t.Context(), s, &buf, ExportOptions{PageSize: 2})
+		require.ErrorContains(t, err, "failed to retrieve value of key k3")
+		assert.Equal(t, int64(2), res.Count)
+		require.NotNil(t, res.ContinuationToken)
+		assert.Equal(t, "2", *res.ContinuationToken)
+
+		s.values["k3"] = `"string"`
+		res, err = Export(t.Context(), s, &buf, ExportOptions{PageSize: 2, ContinuationToken: res.ContinuationToken})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Count)
+		assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 4)
+	})
+
+	t.Run("expiration time", func(t *testing.T) {
+		es := &storeExpiring{storeKeysLike: storeKeysLike{keys: []string{"k1"}, values: map[string]string{"k1": "{}"}}, expire: expire}
+		var buf bytes.Buffer
+		_, err := Export(t.Context(), es, &buf, ExportOptions{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"key":"k1","value":{},"etag":"etag-k1","contentType":"application/json","expireTime":"`+expire.Format(time.RFC3339)+`"}`, buf.String())
+	})
+}
+
+func TestImport(t *testing.T) {
+	snapshot := `{"key":"k1","value":{"a":1},"etag":"etag-k1","contentType":"application/json"}
+{"key":"k2","data":"bm90IGpzb24="}
+{"key":"k3","value":"string","expireTime":"` + time.Now().Add(-time.Minute).Format(time.RFC3339) + `"}
+{"key":"k4","value":5,"expireTime":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}
+`
+
+	t.Run("bulk set", func(t *testing.T) {
+		s := &storeImport{}
+		res, err := Import(t.Context(), s, strings.NewReader(snapshot), ImportOptions{BatchSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Count)
+		assert.Equal(t, int64(1), res.Expired)
+		assert.Nil(t, res.ContinuationToken)
+		assert.Equal(t, 2, s.bulkSetCalls)
+		assert.Equal(t, 0, s.multiCalls)
+
+		require.Len(t, s.saved, 3)
+		assert.Equal(t, "k1", s.saved[0].Key)
+		assert.Equal(t, json.RawMessage(`{"a":1}`), s.saved[0].Value)
+		assert.Equal(t, ptr.Of("application/json"), s.saved[0].ContentType)
+		assert.Equal(t, "application/json", s.saved[0].Metadata["contentType"])
+		assert.Nil(t, s.saved[0].ETag)
+		assert.Equal(t, []byte("not json"), s.saved[1].Value)
+		assert.Equal(t, "k4", s.saved[2].Key)
+		assert.Equal(t, "3600", s.saved[2].Metadata["ttlInSeconds"])
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		s := &storeImport{maxSize: 1}
+		res, err := Import(t.Context(), s, strings.NewReader(snapshot), ImportOptions{Transactional: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), res.Count)
+		assert.Equal(t, 0, s.bulkSetCalls)
+		assert.Equal(t, 3, s.multiCalls)
+	})
+
+	t.Run("resume after an error", func(t *testing.T) {
+		s := &storeImport{failAt: 2}
+		res, err := Import(t.Context(), s, strings.NewReader(snapshot), ImportOptions{BatchSize: 1})
+		require.ErrorIs(t, err, errSimulated)
+		assert.Equal(t, int64(1), res.Count)
+		require.NotNil(t, res.ContinuationToken)
+		assert.Equal(t, "1", *res.ContinuationToken)
+
+		s.failAt = 0
+		res, err = Import(t.Context(), s, strings.NewReader(snapshot), ImportOptions{BatchSize: 1, ContinuationToken: res.ContinuationToken})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Count)
+		require.Len(t, s.saved, 3)
+		assert.Equal(t, []string{"k1", "k2", "k4"}, []string{s.saved[0].Key, s.saved[1].Key, s.saved[2].Key})
+	})
+
+	t.Run("invalid snapshot", func(t *testing.T) {
+		s := &storeImport{}
+		res, err := Import(t.Context(), s, strings.NewReader(`{"key":"k1","value":1}`+"\n"+`{"value":2}`), ImportOptions{})
+		require.ErrorContains(t, err, "invalid record 2")
+		assert.Equal(t, "0", *res.ContinuationToken)
+
+		_, err = Import(t.Context(), s, strings.NewReader(`not json`), ImportOptions{})
+		require.ErrorContains(t, err, "failed to read record 1")
+	})
+}
+
+// storeExpiring returns values with an expiration time and a content type.
+type storeExpiring struct {
+	storeKeysLike
+	expire time.Time
+}
+
+func (s *storeExpiring) BulkGet(ctx context.Context, req []GetRequest, opts BulkGetOpts) ([]BulkGetResponse, error) {
+	res, err := s.storeKeysLike.BulkGet(ctx, req, opts)
+	for i := range res {
+		res[i].ContentType = ptr.Of("application/json")
+		res[i].Metadata = map[string]string{GetRespMetaKeyTTLExpireTime: s.expire.Format(time.RFC3339)}
+	}
+	return res, err
+}
+
+// storeImport records the values saved by Import.
+type storeImport struct {
+	saved        []SetRequest
+	bulkSetCalls int
+	multiCalls   int
+	maxSize      int
+	// Number of the call that fails, if not 0
+	failAt int
+}
+
+func (s *storeImport) BulkSet(_ context.Context, req []SetRequest, _ BulkStoreOpts) error {
+	s.bulkSetCalls++
+	if s.bulkSetCalls == s.failAt {
+		return errSimulated
+	}
+	s.saved = append(s.saved, req...)
+	return nil
+}
+
+func (s *storeImport) Multi(_ context.Context, req *TransactionalStateRequest) error {
+	s.multiCalls++
+	for _, o := range req.Operations {
+		setReq, ok := o.(SetRequest)
+		if !ok {
+			return errors.New("unexpected operation")
+		}
+		s.saved = append(s.saved, setReq)
+	}
+	return nil
+}
+
+func (s *storeImport) MultiMaxSize() int {
+	return s.maxSize
+}
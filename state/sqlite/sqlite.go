@@ -16,6 +16,7 @@ package sqlite
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
@@ -50,6 +51,13 @@ func newSQLiteStateStore(logger logger.Logger, dba DBAccess) *SQLiteStore {
 			state.FeatureTransactional,
 			state.FeatureTTL,
 			state.FeatureKeysLike,
+			state.FeatureDeleteWithPrefix,
+			state.FeatureQueryAPI,
+			state.FeatureWatch,
+			state.FeatureAtomicOperations,
+			state.FeatureTTLManagement,
+			state.FeatureRangeScan,
+			state.FeatureOutbox,
 		},
 		dbaccess: dba,
 	}
@@ -57,7 +65,14 @@ func newSQLiteStateStore(logger logger.Logger, dba DBAccess) *SQLiteStore {
 
 // Init initializes the Sql server state store.
 func (s *SQLiteStore) Init(ctx context.Context, metadata state.Metadata) error {
-	return s.dbaccess.Init(ctx, metadata)
+	err := s.dbaccess.Init(ctx, metadata)
+	if err != nil {
+		return err
+	}
+	if s.dbaccess.VersioningEnabled() {
+		s.features = append(s.features, state.FeatureVersioning)
+	}
+	return nil
 }
 
 func (s *SQLiteStore) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
@@ -80,6 +95,11 @@ func (s *SQLiteStore) Delete(ctx context.Context, req *state.DeleteRequest) erro
 	return s.dbaccess.Delete(ctx, req)
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+func (s *SQLiteStore) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	return s.dbaccess.DeleteWithPrefix(ctx, req)
+}
+
 // Get returns an entity from store.
 func (s *SQLiteStore) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	return s.dbaccess.Get(ctx, req)
@@ -89,6 +109,71 @@ func (s *SQLiteStore) KeysLike(ctx context.Context, req *state.KeysLikeRequest)
 	return s.dbaccess.KeysLike(ctx, req)
 }
 
+// Query executes a query against the store.
+func (s *SQLiteStore) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	return s.dbaccess.Query(ctx, req)
+}
+
+// Watch delivers the changes to the keys matched by the request to the handler.
+func (s *SQLiteStore) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	return s.dbaccess.Watch(ctx, req, handler)
+}
+
+// Increment adds the delta to the integer value of the key.
+func (s *SQLiteStore) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	return s.dbaccess.Increment(ctx, req)
+}
+
+// Append adds the values at the end of the JSON array stored in the key.
+func (s *SQLiteStore) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	return s.dbaccess.Append(ctx, req)
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one.
+func (s *SQLiteStore) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	return s.dbaccess.CompareAndSwap(ctx, req)
+}
+
+// GetRevisions returns the revisions of the key that are retained, from the most recent one.
+func (s *SQLiteStore) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	return s.dbaccess.GetRevisions(ctx, req)
+}
+
+// GetRevision returns the value of the key at a revision, or at a point in time.
+func (s *SQLiteStore) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	return s.dbaccess.GetRevision(ctx, req)
+}
+
+// Expire sets or removes the expiration time of the key, keeping its value.
+func (s *SQLiteStore) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	return s.dbaccess.Expire(ctx, req)
+}
+
+// GetTTL returns the expiration time of the key.
+func (s *SQLiteStore) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	return s.dbaccess.GetTTL(ctx, req)
+}
+
+// RangeScan returns the keys in the range, with their values, in lexical order.
+func (s *SQLiteStore) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	return s.dbaccess.RangeScan(ctx, req)
+}
+
+// LeaseOutbox leases the oldest messages of the pubsub from the outbox.
+func (s *SQLiteStore) LeaseOutbox(ctx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error) {
+	return s.dbaccess.LeaseOutbox(ctx, req)
+}
+
+// DeleteOutbox removes messages that were published from the outbox.
+func (s *SQLiteStore) DeleteOutbox(ctx context.Context, ids []string) error {
+	return s.dbaccess.DeleteOutbox(ctx, ids)
+}
+
+// ReleaseOutbox makes a leased message available again after the delay.
+func (s *SQLiteStore) ReleaseOutbox(ctx context.Context, id string, delay time.Duration) error {
+	return s.dbaccess.ReleaseOutbox(ctx, id, delay)
+}
+
 // BulkGet performs a bulks get operations.
 // Options are ignored because this component requests all values in a single query.
 func (s *SQLiteStore) BulkGet(ctx context.Context, req []state.GetRequest, _ state.BulkGetOpts) ([]state.BulkGetResponse, error) {
@@ -0,0 +1,232 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/dapr/components-contrib/state"
+	stateutils "github.com/dapr/components-contrib/state/utils"
+)
+
+// Condition for rows that have expired but haven't been garbage collected yet, which are treated as if they didn't exist.
+const expiredCondition = "(expiration_time IS NOT NULL AND expiration_time <= CURRENT_TIMESTAMP)"
+
+// Increment adds the delta to the integer value of the key with a single upsert, which fails to update values that aren't integers.
+func (a *sqliteDBAccess) Increment(parentCtx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	etag, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:gosec
+	stmt := `INSERT INTO ` + a.metadata.TableName + `
+			(key, value, is_binary, etag, update_time, expiration_time)
+		VALUES (?, ?, false, ?, CURRENT_TIMESTAMP, ` + insertExp + `)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN ` + expiredCondition + ` THEN excluded.value ELSE CAST(CAST(value AS INTEGER) + ? AS TEXT) END,
+			etag = excluded.etag,
+			update_time = CURRENT_TIMESTAMP,
+			expiration_time = ` + updateExp + `
+		WHERE ` + expiredCondition + `
+			OR (NOT is_binary AND json_valid(value) AND json_type(value) = 'integer')
+		RETURNING value, etag`
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	var (
+		value string
+		res   state.IncrementResponse
+	)
+	err = a.db.QueryRowContext(ctx, stmt, req.Key, strconv.FormatInt(req.Delta, 10), etag.String(), req.Delta).
+		Scan(&value, &res.ETag)
+	if err != nil {
+		return nil, atomicError(err)
+	}
+	res.Value, err = strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value after increment: %w", err)
+	}
+	return &res, nil
+}
+
+// Append adds the values at the end of the JSON array stored in the key with a single upsert, which fails to update values that aren't arrays.
+func (a *sqliteDBAccess) Append(parentCtx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	etag, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	// The array inserted for new keys is passed as first argument, followed by the values to append
+	initial, err := json.Marshal(req.Values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize values: %w", err)
+	}
+	args := make([]any, 0, len(req.Values)+3)
+	args = append(args, req.Key, string(initial), etag.String())
+	paths := make([]string, len(req.Values))
+	for i, v := range req.Values {
+		bt, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize value: %w", err)
+		}
+		paths[i] = "'$[#]', json(?)"
+		args = append(args, string(bt))
+	}
+
+	//nolint:gosec
+	stmt := `INSERT INTO ` + a.metadata.TableName + `
+			(key, value, is_binary, etag, update_time, expiration_time)
+		VALUES (?, ?, false, ?, CURRENT_TIMESTAMP, ` + insertExp + `)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN ` + expiredCondition + ` THEN excluded.value ELSE json_insert(value, ` + strings.Join(paths, ", ") + `) END,
+			etag = excluded.etag,
+			update_time = CURRENT_TIMESTAMP,
+			expiration_time = ` + updateExp + `
+		WHERE ` + expiredCondition + `
+			OR (NOT is_binary AND json_valid(value) AND json_type(value) = 'array')
+		RETURNING json_array_length(value), etag`
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	var res state.AppendResponse
+	err = a.db.QueryRowContext(ctx, stmt, args...).Scan(&res.Length, &res.ETag)
+	if err != nil {
+		return nil, atomicError(err)
+	}
+	return &res, nil
+}
+
+// CompareAndSwap replaces the value of the key if the current value equals the expected one, comparing the values as stored.
+func (a *sqliteDBAccess) CompareAndSwap(parentCtx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	insertExp, updateExp, err := atomicExpiration(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	value, isBinary, err := encodeValue(req.Value)
+	if err != nil {
+		return nil, err
+	}
+	etag, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stmt string
+		args []any
+	)
+	if req.Expected == nil {
+		// Insert the key, replacing it only if it has expired
+		//nolint:gosec
+		stmt = `INSERT INTO ` + a.metadata.TableName + `
+				(key, value, is_binary, etag, update_time, expiration_time)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, ` + insertExp + `)
+			ON CONFLICT (key) DO UPDATE SET
+				value = excluded.value,
+				is_binary = excluded.is_binary,
+				etag = excluded.etag,
+				update_time = CURRENT_TIMESTAMP,
+				expiration_time = excluded.expiration_time
+			WHERE ` + expiredCondition + `
+			RETURNING etag`
+		args = []any{req.Key, value, isBinary, etag.String()}
+	} else {
+		expected, expectedIsBinary, err := encodeValue(req.Expected)
+		if err != nil {
+			return nil, err
+		}
+		//nolint:gosec
+		stmt = `UPDATE ` + a.metadata.TableName + ` SET
+				value = ?,
+				is_binary = ?,
+				etag = ?,
+				update_time = CURRENT_TIMESTAMP,
+				expiration_time = ` + updateExp + `
+			WHERE key = ?
+				AND value = ?
+				AND is_binary = ?
+				AND NOT ` + expiredCondition + `
+			RETURNING etag`
+		args = []any{value, isBinary, etag.String(), req.Key, expected, expectedIsBinary}
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	var res state.CompareAndSwapResponse
+	err = a.db.QueryRowContext(ctx, stmt, args...).Scan(&res.ETag)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// The value didn't match
+		return &res, nil
+	case err != nil:
+		return nil, err
+	}
+	res.Swapped = true
+	return &res, nil
+}
+
+// atomicExpiration returns the expressions for the expiration time of inserted and updated rows.
+// Unless a TTL is set, updated rows keep their expiration time, except for expired rows that are replaced.
+func atomicExpiration(metadata map[string]string) (insertExp string, updateExp string, err error) {
+	ttl, err := stateutils.ParseTTL(metadata)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing TTL: %w", err)
+	}
+	switch {
+	case ttl == nil:
+		return "NULL", "CASE WHEN " + expiredCondition + " THEN NULL ELSE expiration_time END", nil
+	case *ttl > 0:
+		exp := "DATETIME(CURRENT_TIMESTAMP, '+" + strconv.Itoa(*ttl) + " seconds')"
+		return exp, exp, nil
+	default:
+		return "NULL", "NULL", nil
+	}
+}
+
+// atomicError returns ErrAtomicOperandType when no row was returned because the current value has the wrong type.
+func atomicError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return state.ErrAtomicOperandType
+	}
+	return err
+}
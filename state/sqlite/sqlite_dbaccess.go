@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -45,9 +47,24 @@ type DBAccess interface {
 	Set(ctx context.Context, req *state.SetRequest) error
 	Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error)
 	Delete(ctx context.Context, req *state.DeleteRequest) error
+	DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error)
 	BulkGet(ctx context.Context, req []state.GetRequest) ([]state.BulkGetResponse, error)
 	ExecuteMulti(ctx context.Context, reqs []state.TransactionalStateOperation) error
 	KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error)
+	Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error)
+	Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error
+	Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error)
+	Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error)
+	CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error)
+	VersioningEnabled() bool
+	GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error)
+	GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error)
+	Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error)
+	GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error)
+	RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error)
+	LeaseOutbox(ctx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error)
+	DeleteOutbox(ctx context.Context, ids []string) error
+	ReleaseOutbox(ctx context.Context, id string, delay time.Duration) error
 	Close() error
 }
 
@@ -64,12 +81,19 @@ type sqliteDBAccess struct {
 	metadata sqliteMetadataStruct
 	db       *sql.DB
 	gc       commonsql.GarbageCollector
+
+	watchPollInterval time.Duration
+	closed            atomic.Bool
+	closeCh           chan struct{}
+	wg                sync.WaitGroup
 }
 
 // newSqliteDBAccess creates a new instance of sqliteDbAccess.
 func newSqliteDBAccess(logger logger.Logger) *sqliteDBAccess {
 	return &sqliteDBAccess{
-		logger: logger,
+		logger:            logger,
+		watchPollInterval: defaultWatchPollInterval,
+		closeCh:           make(chan struct{}),
 	}
 }
 
@@ -106,11 +130,19 @@ func (a *sqliteDBAccess) Init(ctx context.Context, md state.Metadata) error {
 	err = performMigrations(ctx, a.db, a.logger, migrationOptions{
 		StateTableName:    a.metadata.TableName,
 		MetadataTableName: a.metadata.MetadataTableName,
+		ChangesTableName:  a.changesTableName(),
+		HistoryTableName:  a.historyTableName(),
+		OutboxTableName:   a.outboxTableName(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to perform migrations: %w", err)
 	}
 
+	err = a.initHistory(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Init the background GC
 	err = a.initGC()
 	if err != nil {
@@ -294,6 +326,19 @@ func readRow(row interface{ Scan(dest ...any) error }) (string, []byte, *string,
 	return key, value, &etag, expireTime, nil
 }
 
+// encodeValue returns the value as stored in the state table: byte slices are encoded with base64, and all other values are serialized as JSON.
+func encodeValue(v any) (value string, isBinary bool, err error) {
+	byteArray, isBinary := v.([]uint8)
+	if isBinary {
+		return base64.StdEncoding.EncodeToString(byteArray), true, nil
+	}
+	bt, err := json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(bt), false, nil
+}
+
 func (a *sqliteDBAccess) Set(ctx context.Context, req *state.SetRequest) error {
 	return a.doSet(ctx, a.db, req)
 }
@@ -319,17 +364,9 @@ func (a *sqliteDBAccess) doSet(parentCtx context.Context, db querier, req *state
 	}
 
 	// Encode the value
-	var requestValue string
-	byteArray, isBinary := req.Value.([]uint8)
-	if isBinary {
-		requestValue = base64.StdEncoding.EncodeToString(byteArray)
-	} else {
-		var bt []byte
-		bt, err = json.Marshal(req.Value)
-		if err != nil {
-			return err
-		}
-		requestValue = string(bt)
+	requestValue, isBinary, err := encodeValue(req.Value)
+	if err != nil {
+		return err
 	}
 
 	// New ETag
@@ -421,6 +458,35 @@ func (a *sqliteDBAccess) Delete(ctx context.Context, req *state.DeleteRequest) e
 	return a.doDelete(ctx, a.db, req)
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the garbage collector.
+func (a *sqliteDBAccess) DeleteWithPrefix(parentCtx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	_, exclude := stateutils.DeleteWithPrefixPatterns(req.Prefix)
+	// LIKE is case-insensitive in SQLite, so the keys with the prefix are selected by comparing them byte-wise with the range [prefix, prefix's successor)
+	// The prefix ends with "||", so it always has a successor
+	end, _ := prefixEnd(req.Prefix)
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	// Concatenation is required for table name because sql.DB does not substitute parameters for table names.
+	result, err := a.db.ExecContext(ctx, `DELETE FROM `+a.metadata.TableName+`
+		WHERE key >= ? AND key < ?
+			AND key NOT LIKE ? ESCAPE '\'
+			AND (expiration_time IS NULL OR expiration_time > CURRENT_TIMESTAMP)`,
+		req.Prefix, end, exclude)
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: count}, nil
+}
+
 func (a *sqliteDBAccess) ExecuteMulti(parentCtx context.Context, reqs []state.TransactionalStateOperation) error {
 	// If there's only 1 operation, skip starting a transaction
 	switch len(reqs) {
@@ -448,6 +514,8 @@ func (a *sqliteDBAccess) execMultiOperation(parentCtx context.Context, op state.
 		return a.doSet(parentCtx, db, &req)
 	case state.DeleteRequest:
 		return a.doDelete(parentCtx, db, &req)
+	case state.OutboxRequest:
+		return a.doSaveOutbox(parentCtx, db, &req)
 	default:
 		return fmt.Errorf("unsupported operation: %s", op.Operation())
 	}
@@ -457,6 +525,12 @@ func (a *sqliteDBAccess) execMultiOperation(parentCtx context.Context, op state.
 func (a *sqliteDBAccess) Close() (err error) {
 	errs := make([]error, 0)
 
+	// Stop the watchers before closing the database
+	if a.closed.CompareAndSwap(false, true) {
+		close(a.closeCh)
+	}
+	a.wg.Wait()
+
 	if a.gc != nil {
 		err = a.gc.Close()
 		if err != nil {
@@ -14,11 +14,13 @@ limitations under the License.
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 const (
@@ -160,6 +163,538 @@ func TestSqliteIntegration(t *testing.T) {
 		assert.NotEmpty(t, res.ETag)
 		assert.Equal(t, "🤖", string(res.Data))
 	})
+
+	t.Run("Query", func(t *testing.T) {
+		testQuery(t, s)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		testWatch(t, s)
+	})
+
+	t.Run("Atomic operations", func(t *testing.T) {
+		testAtomicOperations(t, s)
+	})
+
+	t.Run("Delete with prefix", func(t *testing.T) {
+		testDeleteWithPrefix(t, s)
+	})
+
+	t.Run("Versioning", func(t *testing.T) {
+		testVersioning(t, connectionString)
+	})
+
+	t.Run("TTL management", func(t *testing.T) {
+		testTTLManagement(t, s)
+	})
+
+	t.Run("Range scan", func(t *testing.T) {
+		testRangeScan(t, s)
+	})
+
+	t.Run("Outbox", func(t *testing.T) {
+		testOutbox(t, s)
+	})
+}
+
+func testOutbox(t *testing.T, s state.Store) {
+	outbox, ok := s.(state.OutboxStore)
+	require.True(t, ok)
+	transactional := s.(state.TransactionalStore)
+
+	err := transactional.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "outbox_order", Value: "created"},
+			state.OutboxRequest{ID: "msg1", PubsubName: "events", Topic: "orders", Data: []byte("created"), Metadata: map[string]string{"k": "v"}},
+			state.OutboxRequest{ID: "msg2", PubsubName: "events", Topic: "orders", Data: []byte("updated")},
+			state.OutboxRequest{ID: "other", PubsubName: "other", Topic: "orders", Data: []byte("other")},
+		},
+	})
+	require.NoError(t, err)
+
+	// Messages of transactions that fail are not saved
+	err = transactional.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.OutboxRequest{ID: "msg3", PubsubName: "events", Topic: "orders", Data: []byte("failed")},
+			state.SetRequest{Key: "outbox_order", Value: "failed", ETag: ptr.Of("bad-etag")},
+		},
+	})
+	require.Error(t, err)
+
+	// Messages with the same ID are saved once
+	err = transactional.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.OutboxRequest{ID: "msg1", PubsubName: "events", Topic: "orders", Data: []byte("duplicate")},
+		},
+	})
+	require.NoError(t, err)
+
+	req := &state.LeaseOutboxRequest{PubsubName: "events", Limit: 10, LeaseDuration: time.Minute}
+	msgs, err := outbox.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "msg1", msgs[0].ID)
+	assert.Equal(t, "orders", msgs[0].Topic)
+	assert.Equal(t, "created", string(msgs[0].Data))
+	assert.Equal(t, map[string]string{"k": "v"}, msgs[0].Metadata)
+	assert.Equal(t, 1, msgs[0].Attempts)
+	assert.Equal(t, "msg2", msgs[1].ID)
+	assert.Nil(t, msgs[1].Metadata)
+
+	// Leased messages are not returned until they're released
+	msgs, err = outbox.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	require.NoError(t, outbox.DeleteOutbox(t.Context(), []string{"msg1"}))
+	require.NoError(t, outbox.ReleaseOutbox(t.Context(), "msg2", 0))
+	msgs, err = outbox.LeaseOutbox(t.Context(), req)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "msg2", msgs[0].ID)
+	assert.Equal(t, 2, msgs[0].Attempts)
+}
+
+func testRangeScan(t *testing.T, s state.Store) {
+	scanner, ok := s.(state.RangeScanner)
+	require.True(t, ok)
+
+	for _, k := range []string{"range||03", "range||01", "range||02", "range||04", "range}"} {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: k, Value: k}))
+	}
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "range||00", Value: "expired", Metadata: map[string]string{"ttlInSeconds": "1"}}))
+	time.Sleep(1500 * time.Millisecond)
+
+	keys := func(res *state.RangeScanResponse) []string {
+		k := make([]string, len(res.Items))
+		for i, item := range res.Items {
+			k[i] = item.Key
+		}
+		return k
+	}
+
+	req := &state.RangeScanRequest{Start: "range||", End: "range||~", Limit: 3}
+	res, err := scanner.RangeScan(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"range||01", "range||02", "range||03"}, keys(res))
+	assert.Equal(t, `"range||01"`, string(res.Items[0].Data))
+	require.NotNil(t, res.ContinuationToken)
+
+	req.ContinuationToken = res.ContinuationToken
+	res, err = scanner.RangeScan(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"range||04"}, keys(res))
+	assert.Nil(t, res.ContinuationToken)
+
+	res, err = scanner.RangeScan(t.Context(), &state.RangeScanRequest{Start: "range||02", End: "range||04", Reverse: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"range||03", "range||02"}, keys(res))
+}
+
+func testTTLManagement(t *testing.T, s state.Store) {
+	ttl, ok := s.(state.TTLManager)
+	require.True(t, ok)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "ttl_session", Value: "data"}))
+	got, err := s.Get(t.Context(), &state.GetRequest{Key: "ttl_session"})
+	require.NoError(t, err)
+
+	res, err := ttl.GetTTL(t.Context(), &state.GetTTLRequest{Key: "ttl_session"})
+	require.NoError(t, err)
+	assert.True(t, res.Found)
+	assert.Nil(t, res.ExpireTime)
+
+	res, err = ttl.Expire(t.Context(), &state.ExpireRequest{Key: "ttl_session", TTLInSeconds: 100})
+	require.NoError(t, err)
+	assert.True(t, res.Found)
+	require.NotNil(t, res.ExpireTime)
+	assert.WithinDuration(t, time.Now().Add(100*time.Second), *res.ExpireTime, 5*time.Second)
+	assert.Equal(t, *got.ETag, *res.ETag)
+
+	res, err = ttl.GetTTL(t.Context(), &state.GetTTLRequest{Key: "ttl_session"})
+	require.NoError(t, err)
+	require.NotNil(t, res.ExpireTime)
+	after, err := s.Get(t.Context(), &state.GetRequest{Key: "ttl_session"})
+	require.NoError(t, err)
+	assert.Equal(t, got.Data, after.Data)
+
+	res, err = ttl.Expire(t.Context(), &state.ExpireRequest{Key: "ttl_session", TTLInSeconds: 0})
+	require.NoError(t, err)
+	assert.True(t, res.Found)
+	assert.Nil(t, res.ExpireTime)
+
+	res, err = ttl.Expire(t.Context(), &state.ExpireRequest{Key: "ttl_missing", TTLInSeconds: 10})
+	require.NoError(t, err)
+	assert.False(t, res.Found)
+}
+
+func testVersioning(t *testing.T, connectionString string) {
+	s := NewSQLiteStateStore(logger.NewLogger("test"))
+	t.Cleanup(func() {
+		defer s.Close()
+	})
+	err := s.Init(t.Context(), state.Metadata{
+		Base: metadata.Base{
+			Properties: map[string]string{
+				"connectionString":  connectionString,
+				"tableName":         "test_versioned",
+				"metadataTableName": "test_versioned_metadata",
+				"historyLimit":      "3",
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, s.Features(), state.FeatureVersioning)
+	vs := s.(state.VersionedStore)
+
+	for _, v := range []string{"v1", "v2", "v3", "v4"} {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "ver", Value: v}))
+	}
+	require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: "ver"}))
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "other", Value: "x"}))
+
+	res, err := vs.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "ver"})
+	require.NoError(t, err)
+	require.Len(t, res.Revisions, 3)
+	assert.True(t, res.Revisions[0].Deleted)
+	assert.Nil(t, res.Revisions[0].ETag)
+	assert.Greater(t, res.Revisions[0].Revision, res.Revisions[1].Revision)
+	assert.NotNil(t, res.Revisions[1].ETag)
+	assert.False(t, res.Revisions[1].Time.IsZero())
+
+	t.Run("get at revision", func(t *testing.T) {
+		got, err := vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "ver", Revision: &res.Revisions[1].Revision})
+		require.NoError(t, err)
+		assert.Equal(t, `"v4"`, string(got.Data))
+		assert.Equal(t, *res.Revisions[1].ETag, *got.ETag)
+
+		got, err = vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "ver", Revision: &res.Revisions[0].Revision})
+		require.NoError(t, err)
+		assert.Nil(t, got.Data)
+	})
+
+	t.Run("get at time", func(t *testing.T) {
+		now := time.Now()
+		got, err := vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "ver", Time: &now})
+		require.NoError(t, err)
+		assert.Nil(t, got.Data)
+
+		before := res.Revisions[2].Time.Add(-time.Hour)
+		_, err = vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "ver", Time: &before})
+		require.ErrorIs(t, err, state.ErrRevisionNotFound)
+	})
+
+	t.Run("revisions beyond the limit are removed", func(t *testing.T) {
+		var oldest int64 = 1
+		_, err := vs.GetRevision(t.Context(), &state.GetRevisionRequest{Key: "ver", Revision: &oldest})
+		require.ErrorIs(t, err, state.ErrRevisionNotFound)
+
+		limited, err := vs.GetRevisions(t.Context(), &state.GetRevisionsRequest{Key: "ver", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, res.Revisions[:1], limited.Revisions)
+	})
+}
+
+func testDeleteWithPrefix(t *testing.T, s state.Store) {
+	err := s.BulkSet(t.Context(), []state.SetRequest{
+		{Key: "del_app||k1", Value: "v"},
+		{Key: "del_app||k2", Value: "v"},
+		{Key: "del_app||actor||k3", Value: "v"},
+		{Key: "delXapp||k4", Value: "v"},
+		{Key: "DEL_APP||k5", Value: "v"},
+		{Key: "Del_App||actor||k6", Value: "v"},
+		{Key: "del_app||expired", Value: "v", Metadata: map[string]string{"ttlInSeconds": "1"}},
+	}, state.BulkStoreOpts{})
+	require.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+
+	res, err := s.(state.DeleteWithPrefix).DeleteWithPrefix(t.Context(), state.DeleteWithPrefixRequest{Prefix: "del_app"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Count)
+
+	for key, exists := range map[string]bool{
+		"del_app||k1":        false,
+		"del_app||k2":        false,
+		"del_app||actor||k3": true,
+		"delXapp||k4":        true,
+		"DEL_APP||k5":        true,
+		"Del_App||actor||k6": true,
+	} {
+		got, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+		require.NoError(t, err)
+		assert.Equal(t, exists, got.Data != nil, key)
+	}
+}
+
+func testAtomicOperations(t *testing.T, s state.Store) {
+	op, ok := s.(state.AtomicOperator)
+	require.True(t, ok)
+	get := func(t *testing.T, key string) *state.GetResponse {
+		t.Helper()
+		res, err := s.Get(t.Context(), &state.GetRequest{Key: key})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("increment", func(t *testing.T) {
+		key := randomKey()
+		res, err := op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), res.Value)
+
+		res, err = op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: -7})
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), res.Value)
+
+		got := get(t, key)
+		assert.Equal(t, "-2", string(got.Data))
+		assert.Equal(t, res.ETag, got.ETag)
+
+		// Values set with Set can be incremented too
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: 10}))
+		res, err = op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), res.Value)
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: "hello"}))
+		_, err = op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 1})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("increment with TTL", func(t *testing.T) {
+		key := randomKey()
+		_, err := op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 1, Metadata: map[string]string{"ttlInSeconds": "100"}})
+		require.NoError(t, err)
+
+		// The expiration time is kept when no TTL is set
+		_, err = op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 1})
+		require.NoError(t, err)
+		assert.Contains(t, get(t, key).Metadata, state.GetRespMetaKeyTTLExpireTime)
+
+		// Expired keys start again from the delta
+		_, err = s.(*SQLiteStore).GetDBAccess().db.ExecContext(t.Context(), "UPDATE test_state SET expiration_time = DATETIME(CURRENT_TIMESTAMP, '-1 seconds') WHERE key = ?", key)
+		require.NoError(t, err)
+		res, err := op.Increment(t.Context(), &state.IncrementRequest{Key: key, Delta: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Value)
+		assert.NotContains(t, get(t, key).Metadata, state.GetRespMetaKeyTTLExpireTime)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		key := randomKey()
+		res, err := op.Append(t.Context(), &state.AppendRequest{Key: key, Values: []any{"a", 1}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Length)
+
+		res, err = op.Append(t.Context(), &state.AppendRequest{Key: key, Values: []any{map[string]any{"b": true}, nil}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), res.Length)
+		got := get(t, key)
+		assert.JSONEq(t, `["a",1,{"b":true},null]`, string(got.Data))
+		assert.Equal(t, res.ETag, got.ETag)
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: key, Value: map[string]any{"a": 1}}))
+		_, err = op.Append(t.Context(), &state.AppendRequest{Key: key, Values: []any{"a"}})
+		require.ErrorIs(t, err, state.ErrAtomicOperandType)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		key := randomKey()
+		res, err := op.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: key, Value: "v1"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, get(t, key).ETag, res.ETag)
+
+		res, err = op.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: key, Value: "v1"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+
+		res, err = op.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: key, Expected: "other", Value: "v2"})
+		require.NoError(t, err)
+		assert.False(t, res.Swapped)
+
+		res, err = op.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: key, Expected: "v1", Value: []byte("v2")})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, "v2", string(get(t, key).Data))
+
+		res, err = op.CompareAndSwap(t.Context(), &state.CompareAndSwapRequest{Key: key, Expected: []byte("v2"), Value: "v3"})
+		require.NoError(t, err)
+		assert.True(t, res.Swapped)
+		assert.Equal(t, `"v3"`, string(get(t, key).Data))
+	})
+}
+
+func testWatch(t *testing.T, s state.Store) {
+	s.(*SQLiteStore).GetDBAccess().watchPollInterval = 50 * time.Millisecond
+	watcher, ok := s.(state.Watcher)
+	require.True(t, ok)
+
+	prefix := randomKey() + "||"
+	ch := make(chan *state.WatchEvent, 10)
+	err := watcher.Watch(t.Context(), &state.WatchRequest{Prefix: prefix}, func(_ context.Context, e *state.WatchEvent) error {
+		ch <- e
+		return nil
+	})
+	require.NoError(t, err)
+	receive := func(t *testing.T) *state.WatchEvent {
+		t.Helper()
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for event")
+			return nil
+		}
+	}
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "other", Value: "ignored"}))
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: prefix + "a", Value: "1", Metadata: map[string]string{"ttlInSeconds": "100"}}))
+	res, err := s.Get(t.Context(), &state.GetRequest{Key: prefix + "a"})
+	require.NoError(t, err)
+
+	e := receive(t)
+	assert.Equal(t, state.WatchEventUpsert, e.Type)
+	assert.Equal(t, prefix+"a", e.Key)
+	assert.Equal(t, `"1"`, string(e.Value))
+	assert.Equal(t, res.ETag, e.ETag)
+	require.NotNil(t, e.ExpireTime)
+	assert.WithinDuration(t, time.Now().Add(100*time.Second), *e.ExpireTime, 10*time.Second)
+
+	require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: prefix + "b", Value: []byte("🤖")}))
+	e = receive(t)
+	assert.Equal(t, prefix+"b", e.Key)
+	assert.Equal(t, "🤖", string(e.Value))
+	assert.Nil(t, e.ExpireTime)
+
+	require.NoError(t, s.Delete(t.Context(), &state.DeleteRequest{Key: prefix + "a"}))
+	e = receive(t)
+	assert.Equal(t, state.WatchEventDelete, e.Type)
+	assert.Equal(t, prefix+"a", e.Key)
+	assert.Nil(t, e.Value)
+	assert.Empty(t, ch)
+
+	t.Run("non-ASCII prefix", func(t *testing.T) {
+		prefix := randomKey() + "||café||"
+		err := watcher.Watch(t.Context(), &state.WatchRequest{Prefix: prefix}, func(_ context.Context, e *state.WatchEvent) error {
+			ch <- e
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: strings.TrimSuffix(prefix, "é||") + "e||a", Value: "ignored"}))
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: prefix + "a", Value: "1"}))
+		e := receive(t)
+		assert.Equal(t, prefix+"a", e.Key)
+		assert.Empty(t, ch)
+	})
+}
+
+func testQuery(t *testing.T, s state.Store) {
+	prefix := randomKey()
+	docs := []struct {
+		key   string
+		value any
+	}{
+		{prefix + "-1", map[string]any{"person": map[string]any{"org": "A", "name": "alice", "id": 3}, "state": "CA", "prefix": prefix, "tags": []any{"vip", "new"}}},
+		{prefix + "-2", map[string]any{"person": map[string]any{"org": "B", "name": "bob", "id": 1}, "state": "WA", "prefix": prefix, "tags": "vip"}},
+		{prefix + "-3", map[string]any{"person": map[string]any{"org": "B", "name": "carl", "id": 2}, "state": "CA", "prefix": prefix, "active": true, "tags": []any{"vip"}}},
+		{prefix + "-4", map[string]any{"person": map[string]any{"org": "C", "name": "dave", "id": 4.5}, "state": "TX", "prefix": prefix}},
+		{prefix + "-5", []byte("not json")},
+	}
+	for _, d := range docs {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: d.key, Value: d.value}))
+	}
+
+	querier, ok := s.(state.Querier)
+	require.True(t, ok)
+
+	runQuery := func(t *testing.T, q string) *state.QueryResponse {
+		t.Helper()
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(q), &req.Query))
+		resp, err := querier.Query(t.Context(), &req)
+		require.NoError(t, err)
+		return resp
+	}
+	keys := func(resp *state.QueryResponse) []string {
+		res := make([]string, len(resp.Results))
+		for i, r := range resp.Results {
+			res[i] = strings.TrimPrefix(r.Key, prefix)
+		}
+		return res
+	}
+	withPrefix := func(filter string) string {
+		return `{"AND":[{"EQ":{"prefix":"` + prefix + `"}},` + filter + `]}`
+	}
+
+	t.Run("EQ on nested field", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":`+withPrefix(`{"EQ":{"person.org":"B"}}`)+`}`)
+		assert.Equal(t, []string{"-2", "-3"}, keys(resp))
+		assert.JSONEq(t, `{"person":{"org":"B","name":"bob","id":1},"state":"WA","prefix":"`+prefix+`","tags":"vip"}`, string(resp.Results[0].Data))
+		assert.NotNil(t, resp.Results[0].ETag)
+	})
+
+	t.Run("NEQ and IN", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":`+withPrefix(`{"NEQ":{"state":"CA"}}`)+`}`)
+		assert.Equal(t, []string{"-2", "-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"IN":{"state":["TX","WA"]}}`)+`}`)
+		assert.Equal(t, []string{"-2", "-4"}, keys(resp))
+	})
+
+	t.Run("numeric and boolean comparisons", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":`+withPrefix(`{"GT":{"person.id":1}},{"LTE":{"person.id":3}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-3"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"OR":[{"LT":{"person.id":2}},{"GTE":{"person.id":4}}]}`)+`}`)
+		assert.Equal(t, []string{"-2", "-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"EQ":{"active":true}}`)+`}`)
+		assert.Equal(t, []string{"-3"}, keys(resp))
+	})
+
+	t.Run("extended operators", func(t *testing.T) {
+		resp := runQuery(t, `{"filter":`+withPrefix(`{"NOT":{"EQ":{"active":true}}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-2", "-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"NIN":{"person.org":["A","B"]}}`)+`}`)
+		assert.Equal(t, []string{"-4"}, keys(resp))
+
+		// Documents where the field is missing match
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"NIN":{"active":[true]}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-2", "-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"EXISTS":{"tags":false}}`)+`}`)
+		assert.Equal(t, []string{"-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"LIKE":{"person.name":"%a%"}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-3", "-4"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"CONTAINS":{"tags":"vip"}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-3"}, keys(resp))
+
+		resp = runQuery(t, `{"filter":`+withPrefix(`{"BETWEEN":{"person.id":[2,3]}}`)+`}`)
+		assert.Equal(t, []string{"-1", "-3"}, keys(resp))
+	})
+
+	t.Run("sorting and pagination", func(t *testing.T) {
+		const q = `{"filter":%s,"sort":[{"key":"state","order":"DESC"},{"key":"person.name"}],"page":{"limit":3%s}}`
+		resp := runQuery(t, fmt.Sprintf(q, withPrefix(`{"NEQ":{"state":"XX"}}`), ""))
+		assert.Equal(t, []string{"-2", "-4", "-1"}, keys(resp))
+		assert.Equal(t, "3", resp.Token)
+
+		resp = runQuery(t, fmt.Sprintf(q, withPrefix(`{"NEQ":{"state":"XX"}}`), `,"token":"3"`))
+		assert.Equal(t, []string{"-3"}, keys(resp))
+		assert.Equal(t, "4", resp.Token)
+	})
+
+	t.Run("string comparison is rejected", func(t *testing.T) {
+		var req state.QueryRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"filter":{"GT":{"state":"CA"}}}`), &req.Query))
+		_, err := querier.Query(t.Context(), &req)
+		require.Error(t, err)
+	})
 }
 
 // setGetUpdateDeleteOneItem validates setting one item, getting it, and deleting it.
@@ -14,6 +14,7 @@ limitations under the License.
 package sqlite
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -34,6 +35,12 @@ type sqliteMetadataStruct struct {
 	TableName         string        `mapstructure:"tableName"`
 	MetadataTableName string        `mapstructure:"metadataTableName"`
 	CleanupInterval   time.Duration `mapstructure:"cleanupInterval" mapstructurealiases:"cleanupIntervalInSeconds"`
+
+	// Maximum number of revisions retained for each key, including the current one.
+	// Versioning is enabled if this or HistoryRetention is set.
+	HistoryLimit int `mapstructure:"historyLimit"`
+	// Duration for which previous revisions are retained.
+	HistoryRetention time.Duration `mapstructure:"historyRetention"`
 }
 
 func (m *sqliteMetadataStruct) InitWithMetadata(meta state.Metadata) error {
@@ -57,6 +64,9 @@ func (m *sqliteMetadataStruct) InitWithMetadata(meta state.Metadata) error {
 	if !authSqlite.ValidIdentifier(m.MetadataTableName) {
 		return fmt.Errorf("invalid identifier: %s", m.MetadataTableName)
 	}
+	if m.HistoryLimit < 0 || m.HistoryRetention < 0 {
+		return errors.New("historyLimit and historyRetention cannot be negative")
+	}
 
 	return nil
 }
@@ -68,4 +78,6 @@ func (m *sqliteMetadataStruct) reset() {
 	m.TableName = defaultTableName
 	m.MetadataTableName = defaultMetadataTableName
 	m.CleanupInterval = defaultCleanupInterval
+	m.HistoryLimit = 0
+	m.HistoryRetention = 0
 }
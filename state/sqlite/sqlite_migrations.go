@@ -26,6 +26,9 @@ import (
 type migrationOptions struct {
 	StateTableName    string
 	MetadataTableName string
+	ChangesTableName  string
+	HistoryTableName  string
+	OutboxTableName   string
 }
 
 // Perform the required migrations
@@ -61,5 +64,129 @@ func performMigrations(ctx context.Context, db *sql.DB, logger logger.Logger, op
 			}
 			return nil
 		},
+		// Migration 1: create the changes table and the triggers that populate it, used to watch changes
+		func(ctx context.Context) error {
+			logger.Infof("Creating changes table '%s'", opts.ChangesTableName)
+			_, err := m.GetConn().ExecContext(
+				ctx,
+				fmt.Sprintf(
+					`CREATE TABLE %[1]s (
+							id INTEGER PRIMARY KEY AUTOINCREMENT,
+							key TEXT NOT NULL,
+							op TEXT NOT NULL
+						);
+					CREATE TRIGGER %[1]s_cleanup AFTER INSERT ON %[1]s
+						BEGIN
+							DELETE FROM %[1]s WHERE id <= NEW.id - %[3]d;
+						END;
+					CREATE TRIGGER %[2]s_watch_insert AFTER INSERT ON %[2]s
+						BEGIN
+							INSERT INTO %[1]s (key, op) VALUES (NEW.key, 'upsert');
+						END;
+					CREATE TRIGGER %[2]s_watch_update AFTER UPDATE ON %[2]s
+						BEGIN
+							INSERT INTO %[1]s (key, op) VALUES (NEW.key, 'upsert');
+						END;
+					CREATE TRIGGER %[2]s_watch_delete AFTER DELETE ON %[2]s
+						BEGIN
+							INSERT INTO %[1]s (key, op) VALUES (OLD.key, 'delete');
+						END;`,
+					opts.ChangesTableName, opts.StateTableName, changesRetention,
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create changes table: %w", err)
+			}
+			return nil
+		},
+		// Migration 2: create the history table and the triggers that populate it, used by versioning
+		// Triggers record revisions only when the history configuration is present in the metadata table, and remove the revisions that exceed the limit or the retention window
+		func(ctx context.Context) error {
+			logger.Infof("Creating history table '%s'", opts.HistoryTableName)
+			_, err := m.GetConn().ExecContext(
+				ctx,
+				fmt.Sprintf(
+					`CREATE TABLE %[1]s (
+							revision INTEGER PRIMARY KEY AUTOINCREMENT,
+							key TEXT NOT NULL,
+							value TEXT,
+							is_binary BOOLEAN,
+							etag TEXT,
+							expiration_time TIMESTAMP DEFAULT NULL,
+							deleted BOOLEAN NOT NULL DEFAULT FALSE,
+							revision_time TEXT NOT NULL DEFAULT (strftime('%%Y-%%m-%%d %%H:%%M:%%f', 'now'))
+						);
+					CREATE INDEX %[1]s_key ON %[1]s (key, revision);
+					CREATE TRIGGER %[1]s_prune AFTER INSERT ON %[1]s
+						BEGIN
+							DELETE FROM %[1]s
+							WHERE key = NEW.key
+								AND revision < (
+									SELECT revision FROM %[1]s
+									WHERE key = NEW.key
+									ORDER BY revision DESC
+									LIMIT 1 OFFSET COALESCE((SELECT max(json_extract(value, '$.limit') - 1, 0) FROM %[3]s WHERE key = '%[4]s'), 0)
+								)
+								AND (SELECT json_extract(value, '$.limit') FROM %[3]s WHERE key = '%[4]s') > 0;
+							DELETE FROM %[1]s
+							WHERE key = NEW.key
+								AND revision < NEW.revision
+								AND revision_time < (
+									SELECT strftime('%%Y-%%m-%%d %%H:%%M:%%f', 'now', '-' || json_extract(value, '$.retention') || ' seconds')
+									FROM %[3]s WHERE key = '%[4]s' AND json_extract(value, '$.retention') > 0
+								);
+						END;
+					CREATE TRIGGER %[2]s_history_insert AFTER INSERT ON %[2]s
+						WHEN EXISTS (SELECT 1 FROM %[3]s WHERE key = '%[4]s')
+						BEGIN
+							INSERT INTO %[1]s (key, value, is_binary, etag, expiration_time)
+							VALUES (NEW.key, NEW.value, NEW.is_binary, NEW.etag, NEW.expiration_time);
+						END;
+					CREATE TRIGGER %[2]s_history_update AFTER UPDATE ON %[2]s
+						WHEN EXISTS (SELECT 1 FROM %[3]s WHERE key = '%[4]s')
+						BEGIN
+							INSERT INTO %[1]s (key, value, is_binary, etag, expiration_time)
+							VALUES (NEW.key, NEW.value, NEW.is_binary, NEW.etag, NEW.expiration_time);
+						END;
+					CREATE TRIGGER %[2]s_history_delete AFTER DELETE ON %[2]s
+						WHEN EXISTS (SELECT 1 FROM %[3]s WHERE key = '%[4]s')
+						BEGIN
+							INSERT INTO %[1]s (key, deleted) VALUES (OLD.key, TRUE);
+						END;`,
+					opts.HistoryTableName, opts.StateTableName, opts.MetadataTableName, historyConfigKey,
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create history table: %w", err)
+			}
+			return nil
+		},
+		// Migration 3: create the outbox table, which contains the messages saved by transactions until they're published
+		// Lease times are in milliseconds since the epoch
+		func(ctx context.Context) error {
+			logger.Infof("Creating outbox table '%s'", opts.OutboxTableName)
+			_, err := m.GetConn().ExecContext(
+				ctx,
+				fmt.Sprintf(
+					`CREATE TABLE %[1]s (
+							seq INTEGER PRIMARY KEY AUTOINCREMENT,
+							id TEXT NOT NULL UNIQUE,
+							pubsub_name TEXT NOT NULL,
+							topic TEXT NOT NULL,
+							data BLOB NOT NULL,
+							content_type TEXT,
+							metadata TEXT,
+							attempts INTEGER NOT NULL DEFAULT 0,
+							lease_time INTEGER NOT NULL DEFAULT 0
+						);
+					CREATE INDEX %[1]s_pubsub ON %[1]s (pubsub_name, seq);`,
+					opts.OutboxTableName,
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create outbox table: %w", err)
+			}
+			return nil
+		},
 	})
 }
@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+// Current time, in milliseconds since the epoch, which is how lease times are stored.
+const outboxNowExpr = `CAST(unixepoch('now', 'subsec') * 1000 AS INTEGER)`
+
+// outboxTableName returns the name of the table that contains the messages saved by transactions.
+func (a *sqliteDBAccess) outboxTableName() string {
+	return a.metadata.TableName + "_outbox"
+}
+
+// doSaveOutbox saves a message in the outbox, unless a message with the same ID is already there.
+func (a *sqliteDBAccess) doSaveOutbox(parentCtx context.Context, db querier, req *state.OutboxRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var md *string
+	if len(req.Metadata) > 0 {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata of outbox message: %w", err)
+		}
+		md = ptr.Of(string(b))
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := db.ExecContext(ctx, `INSERT INTO `+a.outboxTableName()+`
+			(id, pubsub_name, topic, data, content_type, metadata)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		req.ID, req.PubsubName, req.Topic, req.Data, req.ContentType, md,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
+
+// LeaseOutbox leases the oldest messages of the pubsub whose lease expired, or that were never leased.
+func (a *sqliteDBAccess) LeaseOutbox(parentCtx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	//nolint:gosec
+	rows, err := a.db.QueryContext(ctx, `UPDATE `+a.outboxTableName()+`
+		SET attempts = attempts + 1, lease_time = `+outboxNowExpr+` + ?
+		WHERE seq IN (
+			SELECT seq FROM `+a.outboxTableName()+`
+			WHERE pubsub_name = ? AND lease_time <= `+outboxNowExpr+`
+			ORDER BY seq
+			LIMIT ?
+		)
+		RETURNING seq, id, topic, data, content_type, metadata, attempts`,
+		req.LeaseDuration.Milliseconds(), req.PubsubName, req.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	type leased struct {
+		seq int64
+		msg state.OutboxMessage
+	}
+	var res []leased
+	for rows.Next() {
+		var (
+			r  leased
+			md *string
+		)
+		err = rows.Scan(&r.seq, &r.msg.ID, &r.msg.Topic, &r.msg.Data, &r.msg.ContentType, &md, &r.msg.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+		}
+		r.msg.PubsubName = req.PubsubName
+		if md != nil {
+			err = json.Unmarshal([]byte(*md), &r.msg.Metadata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode metadata of outbox message %s: %w", r.msg.ID, err)
+			}
+		}
+		res = append(res, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+	}
+
+	// The order of the rows returned by RETURNING is not defined
+	slices.SortFunc(res, func(x, y leased) int {
+		return cmp.Compare(x.seq, y.seq)
+	})
+	msgs := make([]state.OutboxMessage, len(res))
+	for i := range res {
+		msgs[i] = res[i].msg
+	}
+	return msgs, nil
+}
+
+// DeleteOutbox removes messages that were published from the outbox.
+func (a *sqliteDBAccess) DeleteOutbox(parentCtx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// SQLite doesn't support passing an array for an IN clause, so we need to build a custom query
+	inClause := strings.Repeat("?,", len(ids))
+	inClause = inClause[:(len(inClause) - 1)]
+	params := make([]any, len(ids))
+	for i, id := range ids {
+		params[i] = id
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := a.db.ExecContext(ctx, `DELETE FROM `+a.outboxTableName()+` WHERE id IN (`+inClause+`)`, params...)
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOutbox makes a leased message available again after the delay.
+func (a *sqliteDBAccess) ReleaseOutbox(parentCtx context.Context, id string, delay time.Duration) error {
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	//nolint:gosec
+	_, err := a.db.ExecContext(ctx, `UPDATE `+a.outboxTableName()+`
+		SET lease_time = `+outboxNowExpr+` + ?
+		WHERE id = ?`,
+		delay.Milliseconds(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message: %w", err)
+	}
+	return nil
+}
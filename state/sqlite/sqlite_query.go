@@ -0,0 +1,298 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/query"
+)
+
+// Query executes a query against the state table.
+func (a *sqliteDBAccess) Query(parentCtx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	q := &Query{
+		tableName: a.metadata.TableName,
+	}
+	qbuilder := query.NewQueryBuilder(q)
+	if err := qbuilder.BuildQuery(&req.Query); err != nil {
+		return &state.QueryResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	data, token, err := q.execute(ctx, a.db)
+	if err != nil {
+		return &state.QueryResponse{}, err
+	}
+
+	return &state.QueryResponse{
+		Results: data,
+		Token:   token,
+	}, nil
+}
+
+// Query is a query.Visitor that translates filters into a SQLite statement.
+// Fields are read from the JSON documents with json_extract.
+type Query struct {
+	query     string
+	params    []any
+	limit     int
+	skip      *int64
+	tableName string
+}
+
+func (q *Query) VisitEQ(f *query.EQ) (string, error) {
+	return q.whereField(f.Key, "=", f.Val), nil
+}
+
+func (q *Query) VisitNEQ(f *query.NEQ) (string, error) {
+	return q.whereField(f.Key, "!=", f.Val), nil
+}
+
+func (q *Query) VisitGT(f *query.GT) (string, error) {
+	switch v := f.Val.(type) {
+	case string:
+		return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+	default:
+		return q.whereField(f.Key, ">", v), nil
+	}
+}
+
+func (q *Query) VisitGTE(f *query.GTE) (string, error) {
+	switch v := f.Val.(type) {
+	case string:
+		return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+	default:
+		return q.whereField(f.Key, ">=", v), nil
+	}
+}
+
+func (q *Query) VisitLT(f *query.LT) (string, error) {
+	switch v := f.Val.(type) {
+	case string:
+		return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+	default:
+		return q.whereField(f.Key, "<", v), nil
+	}
+}
+
+func (q *Query) VisitLTE(f *query.LTE) (string, error) {
+	switch v := f.Val.(type) {
+	case string:
+		return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+	default:
+		return q.whereField(f.Key, "<=", v), nil
+	}
+}
+
+func (q *Query) VisitIN(f *query.IN) (string, error) {
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty IN operator for key %q", f.Key)
+	}
+
+	placeholders := make([]string, len(f.Vals))
+	field := q.translateFieldToFilter(f.Key)
+	for i, v := range f.Vals {
+		placeholders[i] = q.addParam(v)
+	}
+	return field + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+func (q *Query) visitFilters(op string, filters []query.Filter) (string, error) {
+	arr := make([]string, len(filters))
+	for i, f := range filters {
+		str, err := query.VisitFilter(q, f)
+		if err != nil {
+			return "", err
+		}
+		arr[i] = str
+	}
+
+	return "(" + strings.Join(arr, " "+op+" ") + ")", nil
+}
+
+func (q *Query) VisitAND(f *query.AND) (string, error) {
+	return q.visitFilters("AND", f.Filters)
+}
+
+func (q *Query) VisitOR(f *query.OR) (string, error) {
+	return q.visitFilters("OR", f.Filters)
+}
+
+func (q *Query) VisitNOT(f *query.NOT) (string, error) {
+	str, err := query.VisitFilter(q, f.Filter)
+	if err != nil {
+		return "", err
+	}
+	// Conditions on missing fields evaluate to NULL, which must be negated to true
+	return "NOT COALESCE(" + str + ", FALSE)", nil
+}
+
+func (q *Query) VisitNIN(f *query.NIN) (string, error) {
+	if len(f.Vals) == 0 {
+		return "", fmt.Errorf("empty NIN operator for key %q", f.Key)
+	}
+
+	placeholders := make([]string, len(f.Vals))
+	field := q.translateFieldToFilter(f.Key)
+	for i, v := range f.Vals {
+		placeholders[i] = q.addParam(v)
+	}
+	// Missing fields evaluate to NULL, and those documents match too
+	return "NOT COALESCE(" + field + " IN (" + strings.Join(placeholders, ", ") + "), FALSE)", nil
+}
+
+func (q *Query) VisitEXISTS(f *query.EXISTS) (string, error) {
+	// json_type returns NULL only if the field is missing, and 'null' if it's set to null
+	str := "json_type(value, " + q.addParam(jsonPath(f.Key)) + ")"
+	if f.Exists {
+		return str + " IS NOT NULL", nil
+	}
+	return str + " IS NULL", nil
+}
+
+func (q *Query) VisitLIKE(f *query.LIKE) (string, error) {
+	return q.translateFieldToFilter(f.Key) + " LIKE " + q.addParam(f.Pattern) + ` ESCAPE '\'`, nil
+}
+
+func (q *Query) VisitCONTAINS(f *query.CONTAINS) (string, error) {
+	path := jsonPath(f.Key)
+	// The value column needs to be qualified with the table name because json_each has a "value" column too
+	isArray := "json_type(value, " + q.addParam(path) + ") = 'array'"
+	elements := "json_each(" + q.tableName + ".value, " + q.addParam(path) + ")"
+	return "(" + isArray + " AND EXISTS (SELECT 1 FROM " + elements + " AS e WHERE e.value = " + q.addParam(f.Val) + "))", nil
+}
+
+func (q *Query) VisitBETWEEN(f *query.BETWEEN) (string, error) {
+	for _, v := range []any{f.From, f.To} {
+		if _, ok := v.(string); ok {
+			return "", fmt.Errorf("unsupported type of value %s; string type not permitted", v)
+		}
+	}
+	field := q.translateFieldToFilter(f.Key)
+	return field + " BETWEEN " + q.addParam(f.From) + " AND " + q.addParam(f.To), nil
+}
+
+func (q *Query) Finalize(filters string, qq *query.Query) error {
+	// Binary values are stored base64-encoded and are not valid JSON, so they are excluded when filtering or sorting
+	where := []string{"(expiration_time IS NULL OR expiration_time > CURRENT_TIMESTAMP)"}
+	if filters != "" || len(qq.Sort) > 0 {
+		where = append(where, "is_binary = 0")
+	}
+	if filters != "" {
+		where = append(where, filters)
+	}
+
+	//nolint:gosec
+	q.query = "SELECT key, value, is_binary, etag, expiration_time FROM " + q.tableName +
+		" WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY "
+
+	for _, sortItem := range qq.Sort {
+		q.query += q.translateFieldToFilter(sortItem.Key)
+		switch sortItem.Order {
+		case "":
+		case query.ASC, query.DESC:
+			q.query += " " + sortItem.Order
+		default:
+			return fmt.Errorf("invalid sort order %q for key %q", sortItem.Order, sortItem.Key)
+		}
+		q.query += ", "
+	}
+
+	// Sort by rowid last so the order is stable across pages
+	q.query += "rowid"
+
+	if qq.Page.Limit > 0 {
+		q.query += " LIMIT " + strconv.Itoa(qq.Page.Limit)
+		q.limit = qq.Page.Limit
+	}
+
+	if len(qq.Page.Token) != 0 {
+		skip, err := strconv.ParseInt(qq.Page.Token, 10, 64)
+		if err != nil || skip < 0 {
+			return fmt.Errorf("invalid pagination token %q", qq.Page.Token)
+		}
+		if q.limit == 0 {
+			// SQLite does not allow OFFSET without LIMIT
+			q.query += " LIMIT -1"
+		}
+		q.query += " OFFSET " + strconv.FormatInt(skip, 10)
+		q.skip = &skip
+	}
+
+	return nil
+}
+
+func (q *Query) execute(ctx context.Context, db querier) ([]state.QueryItem, string, error) {
+	rows, err := db.QueryContext(ctx, q.query, q.params...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	ret := []state.QueryItem{}
+	for rows.Next() {
+		var item state.QueryItem
+		item.Key, item.Data, item.ETag, _, err = readRow(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	// set next query token only if limit is specified
+	var token string
+	if q.limit != 0 {
+		var skip int64
+		if q.skip != nil {
+			skip = *q.skip
+		}
+		token = strconv.FormatInt(skip+int64(len(ret)), 10)
+	}
+
+	return ret, token, nil
+}
+
+func (q *Query) addParam(value any) string {
+	q.params = append(q.params, value)
+	return "?"
+}
+
+// translateFieldToFilter returns the expression that extracts the field from the JSON document.
+// The JSON path is passed as a parameter, quoting each element so keys can contain characters such as dashes.
+func (q *Query) translateFieldToFilter(key string) string {
+	return "json_extract(value, " + q.addParam(jsonPath(key)) + ")"
+}
+
+func jsonPath(key string) string {
+	path := "$"
+	for _, part := range strings.Split(key, ".") {
+		path += `."` + part + `"`
+	}
+	return path
+}
+
+func (q *Query) whereField(key string, op string, value any) string {
+	filterField := q.translateFieldToFilter(key)
+	return filterField + op + q.addParam(value)
+}
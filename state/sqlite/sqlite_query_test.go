@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/state/query"
+)
+
+func TestSqliteQueryBuildQuery(t *testing.T) {
+	const (
+		selectStmt = "SELECT key, value, is_binary, etag, expiration_time FROM state WHERE (expiration_time IS NULL OR expiration_time > CURRENT_TIMESTAMP)"
+		field      = "json_extract(value, ?)"
+	)
+
+	tests := []struct {
+		input  string
+		query  string
+		params []any
+	}{
+		{
+			input:  "../../tests/state/query/q1.json",
+			query:  selectStmt + " ORDER BY rowid LIMIT 2",
+			params: []any{},
+		},
+		{
+			input:  "../../tests/state/query/q2.json",
+			query:  selectStmt + " AND is_binary = 0 AND " + field + "=? ORDER BY rowid LIMIT 2",
+			params: []any{`$."state"`, "CA"},
+		},
+		{
+			input:  "../../tests/state/query/q2-token.json",
+			query:  selectStmt + " AND is_binary = 0 AND " + field + "=? ORDER BY rowid LIMIT 2 OFFSET 2",
+			params: []any{`$."state"`, "CA"},
+		},
+		{
+			input:  "../../tests/state/query/q3.json",
+			query:  selectStmt + " AND is_binary = 0 AND (" + field + "=? AND " + field + " IN (?, ?)) ORDER BY " + field + " DESC, " + field + ", rowid",
+			params: []any{`$."person"."org"`, "A", `$."state"`, "CA", "WA", `$."state"`, `$."person"."name"`},
+		},
+		{
+			input:  "../../tests/state/query/q4-notequal.json",
+			query:  selectStmt + " AND is_binary = 0 AND (" + field + "=? OR (" + field + "!=? AND " + field + " IN (?, ?))) ORDER BY " + field + " DESC, " + field + ", rowid LIMIT 2",
+			params: []any{`$."person"."org"`, "A", `$."person"."org"`, "B", `$."state"`, "CA", "WA", `$."state"`, `$."person"."name"`},
+		},
+		{
+			input:  "../../tests/state/query/q8.json",
+			query:  selectStmt + " AND is_binary = 0 AND (" + field + ">=? OR (" + field + "<? AND " + field + " IN (?, ?))) ORDER BY " + field + " DESC, " + field + ", rowid LIMIT 2",
+			params: []any{`$."person"."org"`, 123.0, `$."person"."org"`, 10.0, `$."state"`, "CA", "WA", `$."state"`, `$."person"."name"`},
+		},
+		{
+			input:  "../../tests/state/query/q9.json",
+			query:  selectStmt + " AND is_binary = 0 AND (NOT COALESCE(" + field + "=?, FALSE) AND NOT COALESCE(" + field + " IN (?, ?), FALSE) AND json_type(value, ?) IS NOT NULL AND " + field + ` LIKE ? ESCAPE '\' AND (json_type(value, ?) = 'array' AND EXISTS (SELECT 1 FROM json_each(state.value, ?) AS e WHERE e.value = ?)) AND ` + field + " BETWEEN ? AND ?) ORDER BY " + field + ", rowid",
+			params: []any{`$."state"`, "CA", `$."person"."org"`, "A", "B", `$."person"."name"`, `$."person"."name"`, "Jo%", `$."tags"`, `$."tags"`, "vip", `$."age"`, 18.0, 65.0, `$."age"`},
+		},
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(test.input)
+		require.NoError(t, err)
+		var qq query.Query
+		err = json.Unmarshal(data, &qq)
+		require.NoError(t, err)
+
+		q := &Query{
+			tableName: defaultTableName,
+			params:    []any{},
+		}
+		qbuilder := query.NewQueryBuilder(q)
+		err = qbuilder.BuildQuery(&qq)
+		require.NoError(t, err)
+		assert.Equal(t, test.query, q.query)
+		assert.Equal(t, test.params, q.params)
+	}
+}
+
+func TestSqliteQueryInvalidToken(t *testing.T) {
+	tests := map[string]struct {
+		token       string
+		expectedErr string
+	}{
+		"Malformed token": {
+			token:       "abc",
+			expectedErr: `invalid pagination token "abc"`,
+		},
+		"Negative offset": {
+			token:       "-1",
+			expectedErr: `invalid pagination token "-1"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var qq query.Query
+			require.NoError(t, json.Unmarshal([]byte(`{"page":{"limit":2,"token":"`+tt.token+`"}}`), &qq))
+
+			q := &Query{
+				tableName: defaultTableName,
+				params:    []any{},
+			}
+			err := query.NewQueryBuilder(q).BuildQuery(&qq)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
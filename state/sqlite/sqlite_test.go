@@ -292,6 +292,15 @@ func Test_KeysLike(t *testing.T) {
 	var _ state.KeysLiker = state.KeysLiker(ods)
 }
 
+func Test_Querier(t *testing.T) {
+	t.Parallel()
+
+	ods := createSqlite(t)
+
+	var _ state.Querier = state.Querier(ods)
+	assert.True(t, state.FeatureQueryAPI.IsPresent(ods.Features()))
+}
+
 func TestValidMultiDeleteRequest(t *testing.T) {
 	t.Parallel()
 
@@ -350,6 +359,10 @@ func (m *fakeDBaccess) Delete(ctx context.Context, req *state.DeleteRequest) err
 	return nil
 }
 
+func (m *fakeDBaccess) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	return state.DeleteWithPrefixResponse{}, nil
+}
+
 func (m *fakeDBaccess) ExecuteMulti(ctx context.Context, reqs []state.TransactionalStateOperation) error {
 	return nil
 }
@@ -358,6 +371,62 @@ func (m *fakeDBaccess) KeysLike(ctx context.Context, req *state.KeysLikeRequest)
 	return nil, nil
 }
 
+func (m *fakeDBaccess) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	return nil
+}
+
+func (m *fakeDBaccess) Increment(ctx context.Context, req *state.IncrementRequest) (*state.IncrementResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) Append(ctx context.Context, req *state.AppendRequest) (*state.AppendResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) CompareAndSwap(ctx context.Context, req *state.CompareAndSwapRequest) (*state.CompareAndSwapResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) VersioningEnabled() bool {
+	return false
+}
+
+func (m *fakeDBaccess) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) LeaseOutbox(ctx context.Context, req *state.LeaseOutboxRequest) ([]state.OutboxMessage, error) {
+	return nil, nil
+}
+
+func (m *fakeDBaccess) DeleteOutbox(ctx context.Context, ids []string) error {
+	return nil
+}
+
+func (m *fakeDBaccess) ReleaseOutbox(ctx context.Context, id string, delay time.Duration) error {
+	return nil
+}
+
 func (m *fakeDBaccess) Close() error {
 	return nil
 }
@@ -418,3 +487,20 @@ type fakeItem struct {
 func randomJSON() *fakeItem {
 	return &fakeItem{Color: randomKey()}
 }
+
+func TestPrefixEnd(t *testing.T) {
+	end, ok := prefixEnd("abc")
+	assert.True(t, ok)
+	assert.Equal(t, "abd", end)
+
+	end, ok = prefixEnd("café")
+	assert.True(t, ok)
+	assert.Equal(t, "caf\xc3\xaa", end)
+
+	end, ok = prefixEnd("a\xff\xff")
+	assert.True(t, ok)
+	assert.Equal(t, "b", end)
+
+	_, ok = prefixEnd("\xff")
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// Expire sets or removes the expiration time of the key with a single update, keeping its value and ETag.
+func (a *sqliteDBAccess) Expire(parentCtx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	expiration := "NULL"
+	if req.TTLInSeconds > 0 {
+		expiration = "DATETIME(CURRENT_TIMESTAMP, '+" + strconv.FormatInt(req.TTLInSeconds, 10) + " seconds')"
+	}
+
+	//nolint:gosec
+	stmt := `UPDATE ` + a.metadata.TableName + `
+		SET expiration_time = ` + expiration + `
+		WHERE key = ? AND NOT ` + expiredCondition + `
+		RETURNING etag, expiration_time`
+	return a.readTTL(parentCtx, stmt, req.Key)
+}
+
+// GetTTL returns the expiration time of the key.
+func (a *sqliteDBAccess) GetTTL(parentCtx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	//nolint:gosec
+	stmt := `SELECT etag, expiration_time FROM ` + a.metadata.TableName + `
+		WHERE key = ? AND NOT ` + expiredCondition
+	return a.readTTL(parentCtx, stmt, req.Key)
+}
+
+// readTTL executes a statement that returns the ETag and expiration time of the key, if it exists.
+func (a *sqliteDBAccess) readTTL(parentCtx context.Context, stmt string, key string) (*state.TTLResponse, error) {
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+
+	var (
+		etag   string
+		expire sql.NullTime
+	)
+	err := a.db.QueryRowContext(ctx, stmt, key).Scan(&etag, &expire)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &state.TTLResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to read expiration time of key %s: %w", key, err)
+	}
+
+	res := &state.TTLResponse{Found: true, ETag: &etag}
+	if expire.Valid {
+		res.ExpireTime = &expire.Time
+	}
+	return res, nil
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	// Key of the history configuration in the metadata table.
+	// The triggers on the state table record revisions only when it's present.
+	historyConfigKey = "history"
+
+	// Format of the times of the revisions, as stored in the history table.
+	historyTimeFormat = "2006-01-02 15:04:05.000"
+)
+
+var errVersioningDisabled = errors.New("versioning is not enabled: set historyLimit or historyRetention in the metadata")
+
+// historyConfig is the history configuration saved in the metadata table, which is read by the triggers.
+type historyConfig struct {
+	Limit int `json:"limit"`
+	// Retention window, in seconds.
+	Retention int64 `json:"retention"`
+}
+
+// historyTableName returns the name of the table populated by triggers with the revisions of the keys.
+func (a *sqliteDBAccess) historyTableName() string {
+	return a.metadata.TableName + "_history"
+}
+
+// VersioningEnabled returns true if the revisions of the keys are recorded.
+func (a *sqliteDBAccess) VersioningEnabled() bool {
+	return a.metadata.HistoryLimit > 0 || a.metadata.HistoryRetention > 0
+}
+
+// initHistory saves the history configuration in the metadata table, or removes it if versioning is disabled.
+// All instances of the component that use the same state table must have the same configuration.
+func (a *sqliteDBAccess) initHistory(parentCtx context.Context) error {
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+
+	if !a.VersioningEnabled() {
+		_, err := a.db.ExecContext(ctx, "DELETE FROM "+a.metadata.MetadataTableName+" WHERE key = ?", historyConfigKey)
+		if err != nil {
+			return fmt.Errorf("failed to disable versioning: %w", err)
+		}
+		return nil
+	}
+
+	cfg, err := json.Marshal(historyConfig{
+		Limit:     a.metadata.HistoryLimit,
+		Retention: int64(a.metadata.HistoryRetention.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.db.ExecContext(ctx,
+		"INSERT INTO "+a.metadata.MetadataTableName+` (key, value) VALUES (?, ?)
+			ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		historyConfigKey, string(cfg),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enable versioning: %w", err)
+	}
+	return nil
+}
+
+// GetRevisions returns the revisions of the key that are retained, from the most recent one.
+func (a *sqliteDBAccess) GetRevisions(parentCtx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if !a.VersioningEnabled() {
+		return nil, errVersioningDisabled
+	}
+
+	revs, err := a.retainedRevisions(parentCtx, req.Key, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &state.GetRevisionsResponse{Revisions: revs}, nil
+}
+
+// GetRevision returns the value of the key at a revision, or at a point in time.
+func (a *sqliteDBAccess) GetRevision(parentCtx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if !a.VersioningEnabled() {
+		return nil, errVersioningDisabled
+	}
+
+	revs, err := a.retainedRevisions(parentCtx, req.Key, 0)
+	if err != nil {
+		return nil, err
+	}
+	var found *state.Revision
+	for i := range revs {
+		if (req.Revision != nil && revs[i].Revision == *req.Revision) ||
+			(req.Time != nil && !revs[i].Time.After(*req.Time)) {
+			found = &revs[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, state.ErrRevisionNotFound
+	}
+	if found.Deleted {
+		return &state.GetResponse{}, nil
+	}
+
+	// Concatenation is required for table name because sql.DB does not substitute parameters for table names
+	//nolint:gosec
+	stmt := `SELECT key, value, is_binary, etag, expiration_time FROM ` + a.historyTableName() + `
+		WHERE key = ? AND revision = ?`
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	_, value, etag, expireTime, err := readRow(a.db.QueryRowContext(ctx, stmt, req.Key, found.Revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The revision has been removed in the meanwhile
+			return nil, state.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	res := &state.GetResponse{
+		Data: value,
+		ETag: etag,
+	}
+	if expireTime != nil {
+		if req.Time != nil && expireTime.Before(*req.Time) {
+			return &state.GetResponse{}, nil
+		}
+		res.Metadata = map[string]string{
+			state.GetRespMetaKeyTTLExpireTime: expireTime.UTC().Format(time.RFC3339),
+		}
+	}
+	return res, nil
+}
+
+// retainedRevisions returns up to limit revisions of the key that are retained, from the most recent one.
+// Revisions that exceed the history limit or are older than the retention window are discarded, except the most recent one of a key that exists, even if the triggers haven't removed them yet.
+func (a *sqliteDBAccess) retainedRevisions(parentCtx context.Context, key string, limit int) ([]state.Revision, error) {
+	var cutoff string
+	if a.metadata.HistoryRetention > 0 {
+		cutoff = time.Now().Add(-a.metadata.HistoryRetention).UTC().Format(historyTimeFormat)
+	}
+
+	// Concatenation is required for table name because sql.DB does not substitute parameters for table names
+	//nolint:gosec
+	stmt := `SELECT revision, revision_time, deleted, etag FROM (
+			SELECT revision, revision_time, deleted, etag, ROW_NUMBER() OVER (ORDER BY revision DESC) AS n
+			FROM ` + a.historyTableName() + `
+			WHERE key = ?1
+		)
+		WHERE
+			(?2 = 0 OR n <= ?2)
+			AND (?3 = '' OR revision_time >= ?3 OR (n = 1 AND NOT deleted))
+			AND (?4 = 0 OR n <= ?4)
+		ORDER BY revision DESC`
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+	rows, err := a.db.QueryContext(ctx, stmt, key, a.metadata.HistoryLimit, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revs []state.Revision
+	for rows.Next() {
+		var (
+			r       state.Revision
+			revTime string
+			etag    sql.NullString
+		)
+		err = rows.Scan(&r.Revision, &revTime, &r.Deleted, &etag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read revisions: %w", err)
+		}
+		r.Time, err = time.ParseInLocation(historyTimeFormat, revTime, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time for revision %d: %w", r.Revision, err)
+		}
+		if etag.Valid && !r.Deleted {
+			r.ETag = ptr.Of(etag.String)
+		}
+		revs = append(revs, r)
+	}
+	return revs, rows.Err()
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+const (
+	// Interval for polling the changes table.
+	defaultWatchPollInterval = time.Second
+
+	// Number of rows kept in the changes table; older rows are removed by a trigger.
+	// Watchers that fall behind by more than this number of changes miss events.
+	changesRetention = 10000
+
+	// Maximum number of changes read at each poll.
+	watchBatchSize = 1000
+)
+
+// changesTableName returns the name of the table populated by triggers with the changes to the state table.
+func (a *sqliteDBAccess) changesTableName() string {
+	return a.metadata.TableName + "_changes"
+}
+
+// Watch delivers the changes to the keys matched by the request to the handler.
+// Changes are recorded by triggers in the changes table, which is polled periodically.
+// Delete events for expired keys are delivered when the garbage collector removes them.
+func (a *sqliteDBAccess) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if a.closed.Load() {
+		return errors.New("state store is closed")
+	}
+
+	// Start from the most recent change, so only changes happening after this point are delivered
+	var lastID int64
+	queryCtx, cancel := context.WithTimeout(ctx, a.metadata.Timeout)
+	defer cancel()
+	err := a.db.QueryRowContext(queryCtx, "SELECT COALESCE(MAX(id), 0) FROM "+a.changesTableName()).Scan(&lastID)
+	if err != nil {
+		return fmt.Errorf("failed to read the last change: %w", err)
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(a.watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.closeCh:
+				return
+			case <-ticker.C:
+			}
+
+			// Keep reading while there are more changes than fit in a batch
+			for {
+				events, id, pollErr := a.pollChanges(ctx, req, lastID)
+				if pollErr != nil {
+					if ctx.Err() == nil {
+						a.logger.Errorf("Error reading changes: %v", pollErr)
+					}
+					break
+				}
+				for _, e := range events {
+					if err := handler(ctx, e); err != nil {
+						a.logger.Errorf("Error from watch handler for key %s: %v", e.Key, err)
+					}
+				}
+				full := id-lastID == watchBatchSize
+				lastID = id
+				if !full {
+					break
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// pollChanges returns the events for the changes after lastID, and the ID of the last change that was read.
+// The values of upserted keys are read from the state table, so they may be more recent than the change.
+func (a *sqliteDBAccess) pollChanges(parentCtx context.Context, req *state.WatchRequest, lastID int64) ([]*state.WatchEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(parentCtx, a.metadata.Timeout)
+	defer cancel()
+
+	// IDs are assigned in the order changes are committed, so all changes up to the current maximum can be read
+	var maxID int64
+	err := a.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM "+a.changesTableName()).Scan(&maxID)
+	if err != nil {
+		return nil, lastID, err
+	}
+	toID := min(maxID, lastID+watchBatchSize)
+	if toID <= lastID {
+		return nil, lastID, nil
+	}
+
+	// Concatenation is required for table names because sql.DB does not substitute parameters for table names.
+	// The state columns are empty for deleted keys.
+	//nolint:gosec
+	q := `SELECT c.id, c.key, c.op, s.key IS NOT NULL,
+			COALESCE(s.key, ''), COALESCE(s.value, ''), COALESCE(s.is_binary, 0), COALESCE(s.etag, ''), s.expiration_time
+		FROM ` + a.changesTableName() + ` AS c
+		LEFT JOIN ` + a.metadata.TableName + ` AS s ON c.op = 'upsert' AND s.key = c.key
+		WHERE c.id > ? AND c.id <= ?`
+	args := []any{lastID, toID}
+	if req.Prefix != "" {
+		// Keys are compared byte-wise, so the keys with the prefix are the ones in the range [prefix, prefix's successor)
+		q += " AND c.key >= ?"
+		args = append(args, req.Prefix)
+		if end, ok := prefixEnd(req.Prefix); ok {
+			q += " AND c.key < ?"
+			args = append(args, end)
+		}
+	} else {
+		q += " AND c.key = ?"
+		args = append(args, req.Key)
+	}
+	q += " ORDER BY c.id"
+
+	rows, err := a.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, lastID, err
+	}
+	defer rows.Close()
+
+	var events []*state.WatchEvent
+	for rows.Next() {
+		var (
+			e      state.WatchEvent
+			id     int64
+			op     string
+			exists bool
+		)
+		row := changeRow{rows: rows, prefix: []any{&id, &e.Key, &op, &exists}}
+		_, e.Value, e.ETag, e.ExpireTime, err = readRow(row)
+		if err != nil {
+			return nil, lastID, err
+		}
+
+		e.Type = state.WatchEventType(op)
+		switch {
+		case e.Type == state.WatchEventDelete:
+			e.Value, e.ETag, e.ExpireTime = nil, nil, nil
+		case !exists:
+			// The key has been deleted since; the delete event follows
+			continue
+		}
+		events = append(events, &e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, lastID, err
+	}
+
+	return events, toID, nil
+}
+
+// changeRow scans the columns of the changes table before the columns read by readRow.
+type changeRow struct {
+	rows   *sql.Rows
+	prefix []any
+}
+
+func (r changeRow) Scan(dest ...any) error {
+	return r.rows.Scan(append(r.prefix, dest...)...)
+}
+
+// prefixEnd returns the smallest key that is greater than all the keys with the prefix.
+// It returns false if there's none, because the prefix contains only 0xFF bytes.
+func prefixEnd(prefix string) (string, bool) {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xFF {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
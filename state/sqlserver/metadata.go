@@ -43,6 +43,8 @@ type sqlServerMetadata struct {
 	KeyLength         int
 	IndexedProperties string
 	CleanupInterval   *time.Duration `mapstructure:"cleanupInterval" mapstructurealiases:"cleanupIntervalInSeconds"`
+	// If true, queries are evaluated in process, reading all the items in the table
+	EnableInProcessQueries bool
 
 	// Internal properties
 	keyTypeParsed           KeyType
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	commonsql "github.com/dapr/components-contrib/common/component/sql"
@@ -70,6 +71,8 @@ func New(logger logger.Logger) state.Store {
 			state.FeatureETag,
 			state.FeatureTransactional,
 			state.FeatureTTL,
+			state.FeatureDeleteWithPrefix,
+			state.FeatureKeysLike,
 		},
 		logger:          logger,
 		migratorFactory: newMigration,
@@ -171,6 +174,9 @@ COMMIT TRANSACTION;`, s.metadata.SchemaName, s.metadata.MetadataTableName), sql.
 
 // Features returns the features available in this state store.
 func (s *SQLServer) Features() []state.Feature {
+	if s.metadata.EnableInProcessQueries {
+		return append(slices.Clone(s.features), state.FeatureQueryAPI, state.FeatureQueryAggregate, state.FeatureQueryInProcess)
+	}
 	return s.features
 }
 
@@ -251,6 +257,31 @@ func (s *SQLServer) executeDelete(ctx context.Context, db dbExecutor, req *state
 	return nil
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the cleanup of expired keys.
+func (s *SQLServer) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	match, exclude := utils.DeleteWithPrefixPatterns(req.Prefix)
+
+	// The prefix is matched with a binary collation, as the default collation is case-insensitive
+	query := fmt.Sprintf(`DELETE FROM [%s].[%s]
+WHERE [Key] LIKE @match COLLATE Latin1_General_BIN2 ESCAPE '\'
+  AND [Key] NOT LIKE @exclude ESCAPE '\'
+  AND ([ExpireDate] IS NULL OR [ExpireDate] > GETDATE())`,
+		s.metadata.SchemaName, s.metadata.TableName)
+	res, err := s.db.ExecContext(ctx, query, sql.Named("match", match), sql.Named("exclude", exclude))
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: count}, nil
+}
+
 // Get returns an entity from store.
 func (s *SQLServer) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	rows, err := s.db.QueryContext(ctx, s.getCommand, sql.Named(keyColumnName, req.Key))
@@ -389,7 +420,24 @@ func (s *SQLServer) CleanupExpired() error {
 	return nil
 }
 
-func (s *SQLServer) KeysLike(ctx context.Context, req state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+// Query executes a query against the state table.
+// Queries are evaluated in process by state.KeysLikeQuerier, which reads all the keys for each query, so they must be enabled with the enableInProcessQueries metadata property.
+func (s *SQLServer) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !s.metadata.EnableInProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(s).Query(ctx, req)
+}
+
+// QueryAggregate executes a query with projections or aggregations against the state table.
+func (s *SQLServer) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	if !s.metadata.EnableInProcessQueries {
+		return nil, state.ErrInProcessQueriesDisabled
+	}
+	return state.NewKeysLikeQuerier(s).QueryAggregate(ctx, req)
+}
+
+func (s *SQLServer) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
 	if len(req.Pattern) == 0 {
 		return nil, state.ErrKeysLikeEmptyPattern
 	}
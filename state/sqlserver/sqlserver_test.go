@@ -538,3 +538,16 @@ func TestSupportedFeatures(t *testing.T) {
 	assert.Equal(t, state.FeatureETag, actual[0])
 	assert.Equal(t, state.FeatureTransactional, actual[1])
 }
+
+func TestInProcessQueries(t *testing.T) {
+	sqlStore := New(logger.NewLogger("test")).(*SQLServer)
+	assert.Contains(t, sqlStore.Features(), state.FeatureKeysLike)
+	assert.NotContains(t, sqlStore.Features(), state.FeatureQueryAPI)
+	_, err := sqlStore.Query(t.Context(), &state.QueryRequest{})
+	require.ErrorIs(t, err, state.ErrInProcessQueriesDisabled)
+
+	sqlStore.metadata = newMetadata()
+	require.NoError(t, sqlStore.metadata.Parse(map[string]string{"connectionString": sampleConnectionString, "enableInProcessQueries": "true"}))
+	assert.Contains(t, sqlStore.Features(), state.FeatureQueryAPI)
+	assert.Contains(t, sqlStore.Features(), state.FeatureQueryInProcess)
+}
@@ -70,6 +70,7 @@ func New(logger logger.Logger) state.Store {
 			state.FeatureETag,
 			state.FeatureTransactional,
 			state.FeatureTTL,
+			state.FeatureDeleteWithPrefix,
 		},
 		logger:          logger,
 		migratorFactory: newMigration,
@@ -252,6 +253,31 @@ func (s *SQLServer) executeDelete(ctx context.Context, db dbExecutor, req *state
 	return nil
 }
 
+// DeleteWithPrefix deletes the keys that start with the prefix, excluding the keys with a longer prefix.
+// Keys that are expired are not deleted nor counted, as they are removed by the cleanup of expired keys.
+func (s *SQLServer) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	if err := req.Validate(); err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	match, exclude := utils.DeleteWithPrefixPatterns(req.Prefix)
+
+	// The prefix is matched with a binary collation, as the default collation is case-insensitive
+	query := fmt.Sprintf(`DELETE FROM [%s].[%s]
+WHERE [Key] LIKE @match COLLATE Latin1_General_BIN2 ESCAPE '\'
+  AND [Key] NOT LIKE @exclude ESCAPE '\'
+  AND ([ExpireDate] IS NULL OR [ExpireDate] > GETDATE())`,
+		s.metadata.SchemaName, s.metadata.TableName)
+	res, err := s.db.ExecContext(ctx, query, sql.Named("match", match), sql.Named("exclude", exclude))
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return state.DeleteWithPrefixResponse{}, err
+	}
+	return state.DeleteWithPrefixResponse{Count: count}, nil
+}
+
 // Get returns an entity from store.
 func (s *SQLServer) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	rows, err := s.db.QueryContext(ctx, s.getCommand, sql.Named(keyColumnName, req.Key))
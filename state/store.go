@@ -17,6 +17,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/dapr/components-contrib/health"
 	"github.com/dapr/components-contrib/metadata"
@@ -58,6 +59,13 @@ type Querier interface {
 	Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error)
 }
 
+// AggregateQuerier is an optional interface to execute queries with projections and aggregations.
+// When the query has a "select" list, the data of each result contains only the selected fields.
+// When the query has aggregations, results have no key and their data is a JSON object with the "groupBy" fields and the aggregated values.
+type AggregateQuerier interface {
+	QueryAggregate(ctx context.Context, req *QueryRequest) (*QueryResponse, error)
+}
+
 func Ping(ctx context.Context, store Store) error {
 	// checks if this store has the ping option then executes
 	if storeWithPing, ok := store.(health.Pinger); ok {
@@ -72,6 +80,69 @@ type DeleteWithPrefix interface {
 	DeleteWithPrefix(ctx context.Context, req DeleteWithPrefixRequest) (DeleteWithPrefixResponse, error)
 }
 
+// Watcher is an optional interface to subscribe to changes to state.
+type Watcher interface {
+	// Watch starts delivering events for the keys matched by the request to the handler, in the order they happened.
+	// It returns once the subscription is established; events are delivered in background until the context is canceled or the store is closed.
+	// Errors returned by the handler are logged and don't stop the subscription.
+	Watch(ctx context.Context, req *WatchRequest, handler WatchHandler) error
+}
+
+// WatchHandler is the handler invoked by Watcher for each change.
+type WatchHandler func(ctx context.Context, e *WatchEvent) error
+
+// AtomicOperator is an optional interface for state stores that can modify values atomically, without read-modify-write cycles that fail under ETag contention.
+// Keys that are expired are treated as if they didn't exist. Each operation assigns a new ETag to the key.
+type AtomicOperator interface {
+	// Increment adds the delta to the integer value of the key and returns the new value.
+	// If the key doesn't exist, it's created with the delta as value. If the value is not an integer, ErrAtomicOperandType is returned.
+	Increment(ctx context.Context, req *IncrementRequest) (*IncrementResponse, error)
+	// Append adds the values at the end of the JSON array stored in the key and returns the new length of the array.
+	// If the key doesn't exist, it's created with an array of the values. If the value is not an array, ErrAtomicOperandType is returned.
+	Append(ctx context.Context, req *AppendRequest) (*AppendResponse, error)
+	// CompareAndSwap replaces the value of the key only if the current value equals the expected one.
+	CompareAndSwap(ctx context.Context, req *CompareAndSwapRequest) (*CompareAndSwapResponse, error)
+}
+
+// VersionedStore is an optional interface for state stores that keep the previous revisions of the keys, to read the values they had in the past.
+// Which revisions are retained depends on the configuration of the state store, such as a maximum number of revisions per key or a retention window.
+type VersionedStore interface {
+	// GetRevisions returns the revisions of the key that are retained, from the most recent one.
+	GetRevisions(ctx context.Context, req *GetRevisionsRequest) (*GetRevisionsResponse, error)
+	// GetRevision returns the value of the key at a revision, or at a point in time.
+	// The response has no data if the key was deleted or expired at that revision or point in time.
+	// If the revision is not retained, or no revision created before the point in time is retained, ErrRevisionNotFound is returned.
+	GetRevision(ctx context.Context, req *GetRevisionRequest) (*GetResponse, error)
+}
+
+// TTLManager is an optional interface for state stores that can change and read the expiration time of keys without rewriting their values, for example to implement sliding expiration.
+// Keys that are expired are treated as if they didn't exist.
+type TTLManager interface {
+	// Expire sets the TTL of the key, or removes it so the key never expires, leaving the value unchanged.
+	// Depending on the state store, the ETag of the key may change; the response contains the current one.
+	Expire(ctx context.Context, req *ExpireRequest) (*TTLResponse, error)
+	// GetTTL returns the expiration time of the key.
+	GetTTL(ctx context.Context, req *GetTTLRequest) (*TTLResponse, error)
+}
+
+// RangeScanner is an optional interface for state stores that can read the keys between a start and an end key, in lexical (byte-wise) order.
+// Keys that are expired are not returned.
+type RangeScanner interface {
+	// RangeScan returns the keys in the range, with their values, up to the limit of the request.
+	RangeScan(ctx context.Context, req *RangeScanRequest) (*RangeScanResponse, error)
+}
+
+// OutboxStore is an optional interface for transactional state stores that save OutboxRequest operations in the same transaction as the state changes.
+// Relays lease the messages, publish them and delete them; messages whose lease expires are leased again, so they're published at least once.
+type OutboxStore interface {
+	// LeaseOutbox leases the oldest messages of the pubsub that aren't leased, in the order they were saved.
+	LeaseOutbox(ctx context.Context, req *LeaseOutboxRequest) ([]OutboxMessage, error)
+	// DeleteOutbox removes messages from the outbox, after they were published.
+	DeleteOutbox(ctx context.Context, ids []string) error
+	// ReleaseOutbox makes a leased message available again after the delay, for example after it failed to be published.
+	ReleaseOutbox(ctx context.Context, id string, delay time.Duration) error
+}
+
 // KeysLiker is an optional interface to list state keys with an
 // optional SQL style wildcard pattern.
 type KeysLiker interface {
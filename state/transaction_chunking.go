@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/dapr/components-contrib/metadata"
+	kitstrings "github.com/dapr/kit/strings"
+)
+
+// MetadataKeyRelaxAtomicity is the key of the metadata of a TransactionalStateRequest that allows MultiChunked to split it in multiple transactions.
+const MetadataKeyRelaxAtomicity = "relaxAtomicity"
+
+// TransactionalBulkGetter is implemented by transactional state stores that can retrieve values in bulk.
+type TransactionalBulkGetter interface {
+	TransactionalStore
+	BulkGet(ctx context.Context, req []GetRequest, opts BulkGetOpts) ([]BulkGetResponse, error)
+}
+
+// MultiChunked executes the transaction with Multi, splitting it in chunks if it exceeds the maximum size supported by the store.
+// Transactions are split only if the metadata of the request has the relaxAtomicity key set to true; otherwise, or if the store doesn't have a maximum size, the request is passed to Multi as is.
+// Chunks are applied in order, each one in a transaction. Before the first chunk is applied, the current values of the keys are retrieved with BulkGet; if a chunk fails, the keys modified by the chunks that were applied are restored to those values, and a *ChunkedTransactionError is returned.
+// Compensation is best-effort: changes made by other clients to the same keys in the meanwhile are overwritten, and restored values get new ETags.
+// Values are retrieved and restored with the metadata of the request and of the first operation on each key, so they're read from and written to the same partition as the transaction.
+// Transactions with OutboxRequest operations are never split, as messages may be published before a later chunk fails.
+func MultiChunked(ctx context.Context, store TransactionalBulkGetter, req *TransactionalStateRequest) error {
+	maxSize := 0
+	if m, ok := store.(TransactionalStoreMultiMaxSize); ok {
+		maxSize = m.MultiMaxSize()
+	}
+	if maxSize <= 0 || len(req.Operations) <= maxSize || !kitstrings.IsTruthy(req.Metadata[MetadataKeyRelaxAtomicity]) || hasOutboxOperations(req.Operations) {
+		return store.Multi(ctx, req)
+	}
+
+	preImages, err := transactionPreImages(ctx, store, req)
+	if err != nil {
+		return err
+	}
+
+	chunks := chunkOperations(req.Operations, maxSize)
+	for i, ops := range chunks {
+		err = store.Multi(ctx, &TransactionalStateRequest{
+			Operations: ops,
+			Metadata:   req.Metadata,
+		})
+		if err == nil {
+			continue
+		}
+		if i == 0 {
+			// Nothing has been applied
+			return err
+		}
+		return &ChunkedTransactionError{
+			chunk:           i + 1,
+			chunks:          len(chunks),
+			err:             err,
+			compensationErr: compensate(ctx, store, preImages, req.Metadata, req.Operations[:i*maxSize], maxSize),
+		}
+	}
+	return nil
+}
+
+// transactionPreImages returns the current values of the keys modified by the operations of the transaction.
+// Each value is retrieved with the metadata of the transaction, merged with the metadata of the operation.
+func transactionPreImages(ctx context.Context, store TransactionalBulkGetter, req *TransactionalStateRequest) (map[string]*BulkGetResponse, error) {
+	ops := keyOperations(req.Operations)
+	getReqs := make([]GetRequest, len(ops))
+	for i, o := range ops {
+		md := make(map[string]string, len(req.Metadata)+len(o.GetMetadata()))
+		maps.Copy(md, req.Metadata)
+		maps.Copy(md, o.GetMetadata())
+		getReqs[i] = GetRequest{Key: o.GetKey(), Metadata: md}
+	}
+	items, err := store.BulkGet(ctx, getReqs, BulkGetOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the values before the transaction: %w", err)
+	}
+
+	preImages := make(map[string]*BulkGetResponse, len(items))
+	for i := range items {
+		if items[i].Error != "" {
+			return nil, fmt.Errorf("failed to retrieve the value of key %s before the transaction: %s", items[i].Key, items[i].Error)
+		}
+		preImages[items[i].Key] = &items[i]
+	}
+	return preImages, nil
+}
+
+// compensate restores the values of the keys modified by the operations that were applied.
+// The transactions have the metadata of the original request, and each operation has the metadata of the first operation on its key.
+// It attempts to restore all keys, returning the errors of the chunks that failed.
+func compensate(ctx context.Context, store TransactionalStore, preImages map[string]*BulkGetResponse, reqMetadata map[string]string, applied []TransactionalStateOperation, maxSize int) error {
+	keyOps := keyOperations(applied)
+	ops := make([]TransactionalStateOperation, 0, len(keyOps))
+	for _, o := range keyOps {
+		k := o.GetKey()
+		item := preImages[k]
+		if item == nil || item.Data == nil {
+			ops = append(ops, DeleteRequest{Key: k, Metadata: o.GetMetadata()})
+			continue
+		}
+
+		rec, err := NewSnapshotRecord(item)
+		if err != nil {
+			return err
+		}
+		setReq, ok, err := rec.SetRequest()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// The value has expired in the meanwhile
+			ops = append(ops, DeleteRequest{Key: k, Metadata: o.GetMetadata()})
+			continue
+		}
+		setReq.Metadata = restoreMetadata(o.GetMetadata(), setReq.Metadata)
+		ops = append(ops, setReq)
+	}
+
+	var errs []error
+	for _, chunk := range chunkOperations(ops, maxSize) {
+		err := store.Multi(ctx, &TransactionalStateRequest{
+			Operations: chunk,
+			Metadata:   reqMetadata,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func hasOutboxOperations(ops []TransactionalStateOperation) bool {
+	for _, o := range ops {
+		if o.Operation() == OperationOutbox {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreMetadata returns the metadata of an operation that restores a value, which is the metadata of the original operation with the content type and TTL of the restored value.
+func restoreMetadata(opMetadata map[string]string, valueMetadata map[string]string) map[string]string {
+	if len(opMetadata) == 0 {
+		return valueMetadata
+	}
+	md := maps.Clone(opMetadata)
+	delete(md, metadata.ContentType)
+	delete(md, metadata.TTLInSecondsMetadataKey)
+	maps.Copy(md, valueMetadata)
+	return md
+}
+
+// keyOperations returns the first operation on each of the distinct keys of the operations, in the order they appear.
+func keyOperations(ops []TransactionalStateOperation) []TransactionalStateOperation {
+	res := make([]TransactionalStateOperation, 0, len(ops))
+	seen := make(map[string]struct{}, len(ops))
+	for _, o := range ops {
+		k := o.GetKey()
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		res = append(res, o)
+	}
+	return res
+}
+
+// chunkOperations splits the operations in chunks of up to size operations, keeping their order.
+func chunkOperations(ops []TransactionalStateOperation, size int) [][]TransactionalStateOperation {
+	chunks := make([][]TransactionalStateOperation, 0, (len(ops)+size-1)/size)
+	for len(ops) > size {
+		chunks = append(chunks, ops[:size:size])
+		ops = ops[size:]
+	}
+	if len(ops) > 0 {
+		chunks = append(chunks, ops)
+	}
+	return chunks
+}
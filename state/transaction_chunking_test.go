@@ -0,0 +1,238 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChunked(t *testing.T) {
+	newStore := func() *storeTransactional {
+		return &storeTransactional{
+			storeKeysLike: storeKeysLike{values: map[string]string{
+				"k1": `"old1"`,
+				"k2": "old2",
+			}},
+			maxSize: 2,
+		}
+	}
+	ops := func(n int) []TransactionalStateOperation {
+		res := make([]TransactionalStateOperation, n)
+		for i := range n {
+			res[i] = SetRequest{Key: fmt.Sprintf("k%d", i+1), Value: []byte(fmt.Sprintf("new%d", i+1))}
+		}
+		return res
+	}
+	relaxed := map[string]string{MetadataKeyRelaxAtomicity: "true"}
+
+	t.Run("transaction within the maximum size", func(t *testing.T) {
+		s := newStore()
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(2), Metadata: relaxed})
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.multiCalls)
+		assert.Equal(t, 0, s.bulkGetCalls)
+	})
+
+	t.Run("atomicity not relaxed", func(t *testing.T) {
+		s := newStore()
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(5)})
+		require.ErrorIs(t, err, errTooManyOperations)
+		assert.Equal(t, 1, s.multiCalls)
+		assert.Equal(t, "old2", s.values["k2"])
+	})
+
+	t.Run("split in chunks", func(t *testing.T) {
+		s := newStore()
+		req := &TransactionalStateRequest{Operations: ops(5), Metadata: relaxed}
+		req.Operations = append(req.Operations, DeleteRequest{Key: "k1"})
+		err := MultiChunked(t.Context(), s, req)
+		require.NoError(t, err)
+		assert.Equal(t, 3, s.multiCalls)
+		assert.Equal(t, 1, s.bulkGetCalls)
+		assert.Equal(t, map[string]string{"k2": "new2", "k3": "new3", "k4": "new4", "k5": "new5"}, s.values)
+	})
+
+	t.Run("transactions with outbox messages are not split", func(t *testing.T) {
+		s := newStore()
+		req := &TransactionalStateRequest{Operations: ops(2), Metadata: relaxed}
+		req.Operations = append(req.Operations, OutboxRequest{ID: "m1", PubsubName: "events", Topic: "orders"})
+		err := MultiChunked(t.Context(), s, req)
+		require.ErrorIs(t, err, errTooManyOperations)
+		assert.Equal(t, 1, s.multiCalls)
+		assert.Equal(t, 0, s.bulkGetCalls)
+	})
+
+	t.Run("failed chunk is compensated", func(t *testing.T) {
+		s := newStore()
+		s.failKey = "k5"
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(5), Metadata: relaxed})
+		require.ErrorIs(t, err, errSimulated)
+		var chunkErr *ChunkedTransactionError
+		require.ErrorAs(t, err, &chunkErr)
+		assert.Equal(t, 3, chunkErr.Chunk())
+		assert.True(t, chunkErr.Compensated())
+
+		// 3 chunks, of which the last one failed, and 2 compensating transactions for 4 keys
+		assert.Equal(t, 5, s.multiCalls)
+		assert.Equal(t, map[string]string{"k1": `"old1"`, "k2": "old2"}, s.values)
+	})
+
+	t.Run("partitioned transaction is compensated", func(t *testing.T) {
+		withPartition := func(md map[string]string) map[string]string {
+			res := map[string]string{"partitionKey": "p1"}
+			maps.Copy(res, md)
+			return res
+		}
+		tests := map[string]struct {
+			reqMetadata map[string]string
+			opMetadata  map[string]string
+		}{
+			"partition key of the request":    {reqMetadata: withPartition(relaxed)},
+			"partition key of the operations": {reqMetadata: relaxed, opMetadata: withPartition(nil)},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				s := newStore()
+				s.failKey = "k5"
+				s.partitionKey = "p1"
+				req := &TransactionalStateRequest{Operations: ops(5), Metadata: tt.reqMetadata}
+				for i, o := range req.Operations {
+					setReq := o.(SetRequest)
+					setReq.Metadata = tt.opMetadata
+					req.Operations[i] = setReq
+				}
+				req.Operations[2] = DeleteRequest{Key: "k3", Metadata: tt.opMetadata}
+
+				err := MultiChunked(t.Context(), s, req)
+				var chunkErr *ChunkedTransactionError
+				require.ErrorAs(t, err, &chunkErr)
+				require.NoError(t, chunkErr.CompensationError())
+				assert.Equal(t, map[string]string{"k1": `"old1"`, "k2": "old2"}, s.values)
+			})
+		}
+	})
+
+	t.Run("failed compensation", func(t *testing.T) {
+		s := newStore()
+		s.failKey = "k3"
+		s.failCompensation = true
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(3), Metadata: relaxed})
+		var chunkErr *ChunkedTransactionError
+		require.ErrorAs(t, err, &chunkErr)
+		assert.False(t, chunkErr.Compensated())
+		require.ErrorIs(t, chunkErr.CompensationError(), errSimulated)
+		assert.Equal(t, "new1", s.values["k1"])
+	})
+
+	t.Run("first chunk fails", func(t *testing.T) {
+		s := newStore()
+		s.failKey = "k1"
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(3), Metadata: relaxed})
+		require.ErrorIs(t, err, errSimulated)
+		var chunkErr *ChunkedTransactionError
+		require.False(t, errors.As(err, &chunkErr))
+		assert.Equal(t, 1, s.multiCalls)
+	})
+
+	t.Run("pre-images can't be retrieved", func(t *testing.T) {
+		s := newStore()
+		s.values["k2"] = "error"
+		err := MultiChunked(t.Context(), s, &TransactionalStateRequest{Operations: ops(3), Metadata: relaxed})
+		require.ErrorContains(t, err, "failed to retrieve the value of key k2")
+		assert.Equal(t, 0, s.multiCalls)
+	})
+}
+
+var errTooManyOperations = errors.New("too many operations")
+
+// storeTransactional applies transactions to the values of storeKeysLike.
+type storeTransactional struct {
+	storeKeysLike
+	maxSize      int
+	multiCalls   int
+	bulkGetCalls int
+	// Transactions that contain this key fail
+	failKey string
+	// If true, transactions that restore values fail
+	failCompensation bool
+	failed           bool
+	// If set, requests fail unless their partitionKey metadata, or the one of the transaction, has this value
+	partitionKey string
+}
+
+func (s *storeTransactional) BulkGet(ctx context.Context, req []GetRequest, opts BulkGetOpts) ([]BulkGetResponse, error) {
+	s.bulkGetCalls++
+	for _, r := range req {
+		if err := s.checkPartition(nil, r.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return s.storeKeysLike.BulkGet(ctx, req, opts)
+}
+
+func (s *storeTransactional) Multi(_ context.Context, req *TransactionalStateRequest) error {
+	s.multiCalls++
+	if len(req.Operations) > s.maxSize {
+		return errTooManyOperations
+	}
+	for _, o := range req.Operations {
+		if err := s.checkPartition(req.Metadata, o.GetMetadata()); err != nil {
+			return err
+		}
+		if o.GetKey() == s.failKey || (s.failCompensation && s.failed) {
+			s.failed = true
+			return errSimulated
+		}
+	}
+	for _, o := range req.Operations {
+		switch r := o.(type) {
+		case SetRequest:
+			switch v := r.Value.(type) {
+			case []byte:
+				s.values[r.Key] = string(v)
+			case json.RawMessage:
+				s.values[r.Key] = string(v)
+			}
+		case DeleteRequest:
+			delete(s.values, r.Key)
+		}
+	}
+	return nil
+}
+
+func (s *storeTransactional) checkPartition(reqMetadata map[string]string, opMetadata map[string]string) error {
+	if s.partitionKey == "" {
+		return nil
+	}
+	pk, ok := opMetadata["partitionKey"]
+	if !ok {
+		pk = reqMetadata["partitionKey"]
+	}
+	if pk != s.partitionKey {
+		return fmt.Errorf("wrong partition key %q", pk)
+	}
+	return nil
+}
+
+func (s *storeTransactional) MultiMaxSize() int {
+	return s.maxSize
+}
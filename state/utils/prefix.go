@@ -0,0 +1,32 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `[`, `\[`)
+
+// EscapeLike escapes the characters with a special meaning in SQL LIKE patterns, using backslash as escape character.
+// The "[" character is escaped as well, as it has a special meaning in SQL Server.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// DeleteWithPrefixPatterns returns the SQL LIKE patterns, with backslash as escape character, for the keys deleted by DeleteWithPrefix.
+// Keys must match the first pattern, which selects the keys starting with the prefix, and must not match the second one, which selects the keys with a longer prefix.
+// The prefix must have been validated with DeleteWithPrefixRequest.Validate.
+func DeleteWithPrefixPatterns(prefix string) (match string, exclude string) {
+	escaped := EscapeLike(prefix)
+	return escaped + "%", escaped + "%||%"
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "app||actor", EscapeLike("app||actor"))
+	assert.Equal(t, `my\_app\%\\\[1]||`, EscapeLike(`my_app%\[1]||`))
+}
+
+func TestDeleteWithPrefixPatterns(t *testing.T) {
+	match, exclude := DeleteWithPrefixPatterns("my_app||")
+	assert.Equal(t, `my\_app||%`, match)
+	assert.Equal(t, `my\_app||%||%`, exclude)
+}
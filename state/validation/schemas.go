@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dapr/components-contrib/configuration"
+	"github.com/dapr/components-contrib/secretstores"
+)
+
+// Schemas maps key prefixes to the JSON Schemas of the values of the keys.
+// The empty prefix matches all keys.
+//
+// Schemas are serialized as a JSON object, for example:
+//
+//	{
+//	  "orders||": {"type": "object", "required": ["id"]},
+//	  "counters||": {"type": "integer"}
+//	}
+type Schemas map[string]json.RawMessage
+
+// SecretGetter is implemented by secret stores.
+type SecretGetter interface {
+	GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error)
+}
+
+// ConfigurationGetter is implemented by configuration stores.
+type ConfigurationGetter interface {
+	Get(ctx context.Context, req *configuration.GetRequest) (*configuration.GetResponse, error)
+}
+
+// ParseSchemas parses the schemas serialized as a JSON object.
+func ParseSchemas(data []byte) (Schemas, error) {
+	var schemas Schemas
+	err := json.Unmarshal(data, &schemas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schemas: %w", err)
+	}
+	return schemas, nil
+}
+
+// LoadSchemasFromFile loads the schemas from a JSON file.
+func LoadSchemasFromFile(path string) (Schemas, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schemas: %w", err)
+	}
+	return ParseSchemas(data)
+}
+
+// LoadSchemasFromSecret loads the schemas from a key of a secret.
+func LoadSchemasFromSecret(ctx context.Context, store SecretGetter, name string, key string) (Schemas, error) {
+	res, err := store.GetSecret(ctx, secretstores.GetSecretRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	data, ok := res.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s doesn't have key %s", name, key)
+	}
+	return ParseSchemas([]byte(data))
+}
+
+// LoadSchemasFromConfiguration loads the schemas from a configuration item.
+func LoadSchemasFromConfiguration(ctx context.Context, store ConfigurationGetter, key string) (Schemas, error) {
+	res, err := store.Get(ctx, &configuration.GetRequest{Keys: []string{key}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration item %s: %w", key, err)
+	}
+	item := res.Items[key]
+	if item == nil {
+		return nil, fmt.Errorf("configuration item %s not found", key)
+	}
+	return ParseSchemas([]byte(item.Value))
+}
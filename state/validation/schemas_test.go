@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/configuration"
+	"github.com/dapr/components-contrib/secretstores"
+)
+
+func TestLoadSchemas(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schemas.json")
+		require.NoError(t, os.WriteFile(path, []byte(testSchemas), 0o600))
+
+		schemas, err := LoadSchemasFromFile(path)
+		require.NoError(t, err)
+		assert.Len(t, schemas, 3)
+		assert.Contains(t, schemas, "orders||")
+
+		_, err = LoadSchemasFromFile(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		store := fakeSecretStore{"schemas": {"json": testSchemas}}
+		schemas, err := LoadSchemasFromSecret(t.Context(), store, "schemas", "json")
+		require.NoError(t, err)
+		assert.Len(t, schemas, 3)
+
+		_, err = LoadSchemasFromSecret(t.Context(), store, "schemas", "yaml")
+		require.ErrorContains(t, err, "doesn't have key yaml")
+		_, err = LoadSchemasFromSecret(t.Context(), store, "missing", "json")
+		require.Error(t, err)
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		store := fakeConfigurationStore{"schemas": testSchemas, "invalid": "not json"}
+		schemas, err := LoadSchemasFromConfiguration(t.Context(), store, "schemas")
+		require.NoError(t, err)
+		assert.Len(t, schemas, 3)
+
+		_, err = LoadSchemasFromConfiguration(t.Context(), store, "missing")
+		require.ErrorContains(t, err, "not found")
+		_, err = LoadSchemasFromConfiguration(t.Context(), store, "invalid")
+		require.ErrorContains(t, err, "invalid schemas")
+	})
+}
+
+type fakeSecretStore map[string]map[string]string
+
+func (f fakeSecretStore) GetSecret(_ context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	data, ok := f[req.Name]
+	if !ok {
+		return secretstores.GetSecretResponse{}, errors.New("secret not found")
+	}
+	return secretstores.GetSecretResponse{Data: data}, nil
+}
+
+type fakeConfigurationStore map[string]string
+
+func (f fakeConfigurationStore) Get(_ context.Context, req *configuration.GetRequest) (*configuration.GetResponse, error) {
+	res := &configuration.GetResponse{Items: map[string]*configuration.Item{}}
+	for _, k := range req.Keys {
+		if v, ok := f[k]; ok {
+			res.Items[k] = &configuration.Item{Value: v}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation implements a state store decorator that validates the values written to a state store against JSON Schemas.
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/components-contrib/state/utils"
+)
+
+// Options contains the options for the validated store.
+type Options struct {
+	// JSON Schemas of the values, by key prefix.
+	// When multiple prefixes match a key, the longest one is used; values of keys that don't match any prefix are not validated.
+	Schemas Schemas
+}
+
+// Store is a state store decorator that validates the values written to the underlying store against the JSON Schema of their key prefix.
+// Set, BulkSet and Multi fail with a *state.SchemaValidationError, without writing anything, if any of the values doesn't conform.
+//
+// Atomic operations are not supported, as they compute the new values in the underlying store.
+type Store struct {
+	store   state.Store
+	schemas []prefixSchema
+}
+
+// prefixSchema is the compiled schema of a key prefix.
+type prefixSchema struct {
+	prefix string
+	schema *jsonschema.Schema
+}
+
+// NewStore returns a store that validates the values saved in store.
+func NewStore(store state.Store, opts Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("state store is required")
+	}
+	if len(opts.Schemas) == 0 {
+		return nil, errors.New("at least one schema is required")
+	}
+
+	s := &Store{
+		store:   store,
+		schemas: make([]prefixSchema, 0, len(opts.Schemas)),
+	}
+	// Longest prefixes first, so they take precedence
+	prefixes := slices.Collect(maps.Keys(opts.Schemas))
+	slices.SortFunc(prefixes, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	compiler := jsonschema.NewCompiler()
+	for i, prefix := range prefixes {
+		// Each schema is a separate resource, so references are resolved within it
+		url := "mem:///schema" + strconv.Itoa(i) + ".json"
+		err := compiler.AddResource(url, bytes.NewReader(opts.Schemas[prefix]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema for prefix %q: %w", prefix, err)
+		}
+		compiled, err := compiler.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema for prefix %q: %w", prefix, err)
+		}
+		s.schemas = append(s.schemas, prefixSchema{prefix: prefix, schema: compiled})
+	}
+	return s, nil
+}
+
+// Init initializes the underlying store.
+func (s *Store) Init(ctx context.Context, metadata state.Metadata) error {
+	return s.store.Init(ctx, metadata)
+}
+
+// Features returns the features of the underlying store, except atomic operations.
+func (s *Store) Features() []state.Feature {
+	inner := s.store.Features()
+	features := make([]state.Feature, 0, len(inner))
+	for _, f := range inner {
+		if f != state.FeatureAtomicOperations {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// Get retrieves the value of a key from the underlying store.
+func (s *Store) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	return s.store.Get(ctx, req)
+}
+
+// Set validates and saves the value of a key.
+func (s *Store) Set(ctx context.Context, req *state.SetRequest) error {
+	if err := s.validate(req); err != nil {
+		return err
+	}
+	return s.store.Set(ctx, req)
+}
+
+// Delete deletes a key from the underlying store.
+func (s *Store) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	return s.store.Delete(ctx, req)
+}
+
+// BulkGet retrieves the values of multiple keys from the underlying store.
+func (s *Store) BulkGet(ctx context.Context, req []state.GetRequest, opts state.BulkGetOpts) ([]state.BulkGetResponse, error) {
+	return s.store.BulkGet(ctx, req, opts)
+}
+
+// BulkSet validates and saves the values of multiple keys.
+// If any value doesn't conform, none is saved, and the error contains a state.BulkStoreError for each of them.
+func (s *Store) BulkSet(ctx context.Context, req []state.SetRequest, opts state.BulkStoreOpts) error {
+	var errs []error
+	for i := range req {
+		if err := s.validate(&req[i]); err != nil {
+			errs = append(errs, state.NewBulkStoreError(req[i].Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return s.store.BulkSet(ctx, req, opts)
+}
+
+// BulkDelete deletes multiple keys from the underlying store.
+func (s *Store) BulkDelete(ctx context.Context, req []state.DeleteRequest, opts state.BulkStoreOpts) error {
+	return s.store.BulkDelete(ctx, req, opts)
+}
+
+// Multi validates the values of the upsert operations and executes the transaction in the underlying store.
+// If any value doesn't conform, the transaction isn't executed.
+func (s *Store) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	transactional, ok := s.store.(state.TransactionalStore)
+	if !ok {
+		return errors.New("transactions are not supported by the state store")
+	}
+
+	var errs []error
+	for _, o := range request.Operations {
+		if req, ok := o.(state.SetRequest); ok {
+			if err := s.validate(&req); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return transactional.Multi(ctx, request)
+}
+
+// MultiMaxSize returns the maximum number of operations in a transaction of the underlying store.
+func (s *Store) MultiMaxSize() int {
+	if m, ok := s.store.(state.TransactionalStoreMultiMaxSize); ok {
+		return m.MultiMaxSize()
+	}
+	return -1
+}
+
+// Query executes the query on the underlying store.
+func (s *Store) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.store.(state.Querier)
+	if !ok {
+		return nil, errors.New("queries are not supported by the state store")
+	}
+	return querier.Query(ctx, req)
+}
+
+// QueryAggregate executes the query with projections or aggregations on the underlying store.
+func (s *Store) QueryAggregate(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
+	querier, ok := s.store.(state.AggregateQuerier)
+	if !ok {
+		return nil, errors.New("aggregate queries are not supported by the state store")
+	}
+	return querier.QueryAggregate(ctx, req)
+}
+
+// KeysLike returns the keys of the underlying store that match the pattern.
+func (s *Store) KeysLike(ctx context.Context, req *state.KeysLikeRequest) (*state.KeysLikeResponse, error) {
+	keysLiker, ok := s.store.(state.KeysLiker)
+	if !ok {
+		return nil, errors.New("keys like is not supported by the state store")
+	}
+	return keysLiker.KeysLike(ctx, req)
+}
+
+// DeleteWithPrefix deletes the keys with the prefix from the underlying store.
+func (s *Store) DeleteWithPrefix(ctx context.Context, req state.DeleteWithPrefixRequest) (state.DeleteWithPrefixResponse, error) {
+	deleter, ok := s.store.(state.DeleteWithPrefix)
+	if !ok {
+		return state.DeleteWithPrefixResponse{}, errors.New("delete with prefix is not supported by the state store")
+	}
+	return deleter.DeleteWithPrefix(ctx, req)
+}
+
+// RangeScan returns the keys in the range, with their values, from the underlying store.
+func (s *Store) RangeScan(ctx context.Context, req *state.RangeScanRequest) (*state.RangeScanResponse, error) {
+	scanner, ok := s.store.(state.RangeScanner)
+	if !ok {
+		return nil, errors.New("range scans are not supported by the state store")
+	}
+	return scanner.RangeScan(ctx, req)
+}
+
+// GetRevisions returns the revisions of a key from the underlying store.
+func (s *Store) GetRevisions(ctx context.Context, req *state.GetRevisionsRequest) (*state.GetRevisionsResponse, error) {
+	versioned, ok := s.store.(state.VersionedStore)
+	if !ok {
+		return nil, errors.New("versioning is not supported by the state store")
+	}
+	return versioned.GetRevisions(ctx, req)
+}
+
+// GetRevision returns the value of a key at a revision from the underlying store.
+func (s *Store) GetRevision(ctx context.Context, req *state.GetRevisionRequest) (*state.GetResponse, error) {
+	versioned, ok := s.store.(state.VersionedStore)
+	if !ok {
+		return nil, errors.New("versioning is not supported by the state store")
+	}
+	return versioned.GetRevision(ctx, req)
+}
+
+// Expire changes the expiration time of a key in the underlying store.
+func (s *Store) Expire(ctx context.Context, req *state.ExpireRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.Expire(ctx, req)
+}
+
+// GetTTL returns the expiration time of a key in the underlying store.
+func (s *Store) GetTTL(ctx context.Context, req *state.GetTTLRequest) (*state.TTLResponse, error) {
+	ttl, ok := s.store.(state.TTLManager)
+	if !ok {
+		return nil, errors.New("TTL management is not supported by the state store")
+	}
+	return ttl.GetTTL(ctx, req)
+}
+
+// Watch delivers the changes of the underlying store to the handler.
+func (s *Store) Watch(ctx context.Context, req *state.WatchRequest, handler state.WatchHandler) error {
+	watcher, ok := s.store.(state.Watcher)
+	if !ok {
+		return errors.New("watch is not supported by the state store")
+	}
+	return watcher.Watch(ctx, req, handler)
+}
+
+// Ping pings the underlying store.
+func (s *Store) Ping(ctx context.Context) error {
+	if pinger, ok := s.store.(health.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return state.ErrPingNotImplemented
+}
+
+// GetComponentMetadata returns the metadata of the underlying store.
+func (s *Store) GetComponentMetadata() metadata.MetadataMap {
+	if m, ok := s.store.(interface{ GetComponentMetadata() metadata.MetadataMap }); ok {
+		return m.GetComponentMetadata()
+	}
+	return nil
+}
+
+// Close closes the underlying store.
+func (s *Store) Close() error {
+	return s.store.Close()
+}
+
+// validate returns a *state.SchemaValidationError if the value of the request doesn't conform to the schema of its key.
+func (s *Store) validate(req *state.SetRequest) error {
+	schema := s.schemaFor(req.Key)
+	if schema == nil {
+		return nil
+	}
+
+	data, err := utils.Marshal(req.Value, json.Marshal)
+	if err != nil {
+		return state.NewSchemaValidationError(req.Key, fmt.Errorf("failed to serialize value: %w", err))
+	}
+	// Numbers are decoded as json.Number, as expected by the validator
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	err = dec.Decode(&v)
+	if err == nil {
+		if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+			err = errors.New("unexpected data after the JSON document")
+		} else {
+			err = nil
+		}
+	}
+	if err != nil {
+		return state.NewSchemaValidationError(req.Key, fmt.Errorf("the value is not a JSON document: %w", err))
+	}
+
+	err = schema.Validate(v)
+	if err != nil {
+		return state.NewSchemaValidationError(req.Key, err)
+	}
+	return nil
+}
+
+// schemaFor returns the schema of the longest prefix that matches the key, or nil if none does.
+func (s *Store) schemaFor(key string) *jsonschema.Schema {
+	for _, ps := range s.schemas {
+		if strings.HasPrefix(key, ps.prefix) {
+			return ps.schema
+		}
+	}
+	return nil
+}
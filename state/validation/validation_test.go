@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+const testSchemas = `{
+	"": {"type": "object"},
+	"orders||": {
+		"type": "object",
+		"properties": {"id": {"type": "string"}, "amount": {"type": "number", "minimum": 0}},
+		"required": ["id"]
+	},
+	"counters||": {"type": "integer"}
+}`
+
+func newTestStore(t *testing.T) (*Store, state.Store) {
+	t.Helper()
+
+	inner := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+	require.NoError(t, inner.Init(t.Context(), state.Metadata{}))
+	t.Cleanup(func() { inner.Close() })
+
+	schemas, err := ParseSchemas([]byte(testSchemas))
+	require.NoError(t, err)
+	s, err := NewStore(inner, Options{Schemas: schemas})
+	require.NoError(t, err)
+	return s, inner
+}
+
+func TestInvalidOptions(t *testing.T) {
+	inner := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+
+	tests := map[string]struct {
+		store       state.Store
+		opts        Options
+		expectedErr string
+	}{
+		"Missing state store": {
+			opts:        Options{Schemas: Schemas{"": json.RawMessage(`{}`)}},
+			expectedErr: "state store is required",
+		},
+		"No schemas": {
+			store:       inner,
+			opts:        Options{},
+			expectedErr: "at least one schema is required",
+		},
+		"Schema is not valid JSON": {
+			store:       inner,
+			opts:        Options{Schemas: Schemas{"a": json.RawMessage(`{`)}},
+			expectedErr: `invalid schema for prefix "a"`,
+		},
+		"Schema is not a valid JSON schema": {
+			store:       inner,
+			opts:        Options{Schemas: Schemas{"a": json.RawMessage(`{"type": 1}`)}},
+			expectedErr: `invalid schema for prefix "a"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStore(tt.store, tt.opts)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	s, inner := newTestStore(t)
+
+	t.Run("valid values", func(t *testing.T) {
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "orders||1", Value: []byte(`{"id":"1","amount":10}`)}))
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "orders||2", Value: map[string]any{"id": "2"}}))
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "counters||1", Value: 5}))
+		require.NoError(t, s.Set(t.Context(), &state.SetRequest{Key: "other", Value: []byte(`{}`)}))
+
+		res, err := inner.Get(t.Context(), &state.GetRequest{Key: "orders||1"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"1","amount":10}`, string(res.Data))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for key, value := range map[string]any{
+			"orders||3":   []byte(`{"amount":10}`),
+			"orders||4":   []byte(`{"id":"4","amount":-1}`),
+			"counters||2": 1.5,
+			"other||2":    []byte(`"string"`),
+			"not-json":    []byte("not json"),
+		} {
+			err := s.Set(t.Context(), &state.SetRequest{Key: key, Value: value})
+			var validationErr *state.SchemaValidationError
+			require.ErrorAs(t, err, &validationErr, key)
+			assert.Equal(t, key, validationErr.Key())
+
+			res, err := inner.Get(t.Context(), &state.GetRequest{Key: key})
+			require.NoError(t, err)
+			assert.Nil(t, res.Data)
+		}
+	})
+}
+
+func TestBulkSet(t *testing.T) {
+	s, inner := newTestStore(t)
+
+	err := s.BulkSet(t.Context(), []state.SetRequest{
+		{Key: "orders||1", Value: []byte(`{"id":"1"}`)},
+		{Key: "orders||2", Value: []byte(`{}`)},
+	}, state.BulkStoreOpts{})
+	var bulkErr state.BulkStoreError
+	require.ErrorAs(t, err, &bulkErr)
+	assert.Equal(t, "orders||2", bulkErr.Key())
+	var validationErr *state.SchemaValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	// Nothing has been saved
+	res, err := inner.Get(t.Context(), &state.GetRequest{Key: "orders||1"})
+	require.NoError(t, err)
+	assert.Nil(t, res.Data)
+
+	err = s.BulkSet(t.Context(), []state.SetRequest{
+		{Key: "orders||1", Value: []byte(`{"id":"1"}`)},
+		{Key: "orders||2", Value: []byte(`{"id":"2"}`)},
+	}, state.BulkStoreOpts{})
+	require.NoError(t, err)
+}
+
+func TestMulti(t *testing.T) {
+	s, inner := newTestStore(t)
+
+	err := s.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "orders||1", Value: []byte(`{"id":"1"}`)},
+			state.DeleteRequest{Key: "orders||2"},
+			state.SetRequest{Key: "counters||1", Value: []byte(`"1"`)},
+		},
+	})
+	var validationErr *state.SchemaValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "counters||1", validationErr.Key())
+
+	res, err := inner.Get(t.Context(), &state.GetRequest{Key: "orders||1"})
+	require.NoError(t, err)
+	assert.Nil(t, res.Data)
+
+	err = s.Multi(t.Context(), &state.TransactionalStateRequest{
+		Operations: []state.TransactionalStateOperation{
+			state.SetRequest{Key: "orders||1", Value: []byte(`{"id":"1"}`)},
+			state.SetRequest{Key: "counters||1", Value: []byte(`1`)},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestFeatures(t *testing.T) {
+	s, inner := newTestStore(t)
+	assert.Contains(t, inner.Features(), state.FeatureAtomicOperations)
+	assert.NotContains(t, s.Features(), state.FeatureAtomicOperations)
+	assert.Contains(t, s.Features(), state.FeatureTransactional)
+}
@@ -37,6 +37,7 @@ import (
 	p_rabbitmq "github.com/dapr/components-contrib/pubsub/rabbitmq"
 	p_redis "github.com/dapr/components-contrib/pubsub/redis"
 	p_solaceamqp "github.com/dapr/components-contrib/pubsub/solace/amqp"
+	p_sqlite "github.com/dapr/components-contrib/pubsub/sqlite"
 	conf_pubsub "github.com/dapr/components-contrib/tests/conformance/pubsub"
 )
 
@@ -91,6 +92,8 @@ func loadPubSub(name string) pubsub.PubSub {
 		return p_rabbitmq.NewRabbitMQ(testLogger)
 	case "in-memory":
 		return p_inmemory.New(testLogger)
+	case "sqlite":
+		return p_sqlite.NewSQLite(testLogger)
 	case "aws.snssqs.terraform":
 		return p_snssqs.NewSnsSqs(testLogger)
 	case "aws.snssqs.docker":
@@ -1526,6 +1526,7 @@ func ConformanceTests(t *testing.T, props map[string]string, statestore state.St
 			"prefix||key2":          true,
 			"prefix||prefix2||key3": true,
 			"other-prefix||key1":    true,
+			"PREFIX||key4":          true,
 			"no-prefix":             true,
 		}
 		validateFn := func() func(t *testing.T) {
@@ -1560,6 +1561,7 @@ func ConformanceTests(t *testing.T, props map[string]string, statestore state.St
 				{Key: "prefix||key2", Value: []byte("In nova fert animus mutatas dicere formas")},
 				{Key: "prefix||prefix2||key3", Value: []byte("corpora; di, coeptis (nam vos mutastis et illas)")},
 				{Key: "other-prefix||key1", Value: []byte("adspirate meis primaque ab origine mundi")}, // Note this still has "prefix||" but not at the start of the string
+				{Key: "PREFIX||key4", Value: []byte("Lucretius, De rerum natura")},                     // Prefixes are case-sensitive
 				{Key: "no-prefix", Value: []byte("ad mea perpetuum deducite tempora carmen.")},
 			}, state.BulkStoreOpts{})
 			require.NoError(t, err)